@@ -31,6 +31,11 @@ type ClusterScanSpec struct {
 	// +optional
 	ScanPolicy string `json:"scanPolicy,omitempty"`
 
+	// ClusterScanPolicy references a cluster-scoped policy to use for all node
+	// scans. Values set by ScanPolicy take precedence over it.
+	// +optional
+	ClusterScanPolicy string `json:"clusterScanPolicy,omitempty"`
+
 	// Concurrent is the maximum number of nodes to scan in parallel
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=50
@@ -47,6 +52,64 @@ type ClusterScanSpec struct {
 	// NodeScanTemplate contains the template for creating NodeScans
 	// +optional
 	NodeScanTemplate *NodeScanSpec `json:"nodeScanTemplate,omitempty"`
+
+	// MaintenanceWindows restricts when NodeScans are started. NodeScans that
+	// are already running are not interrupted when a window closes.
+	// +optional
+	MaintenanceWindows *MaintenanceWindows `json:"maintenanceWindows,omitempty"`
+
+	// Notifications sends a single digest when the cluster scan finishes
+	// +optional
+	Notifications *ClusterScanNotifications `json:"notifications,omitempty"`
+}
+
+// ClusterScanNotifications configures the digest of a ClusterScan. The digest
+// groups infected nodes and signatures and replaces the per-node notifications
+// of the scan policy unless PerNodeNotifications is set.
+type ClusterScanNotifications struct {
+	NotificationConfig `json:",inline"`
+
+	// PerNodeNotifications keeps the notifications of the scan policy for
+	// every NodeScan in addition to the digest
+	// +optional
+	PerNodeNotifications bool `json:"perNodeNotifications,omitempty"`
+}
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
+// TimeWindow is a recurring daily time range
+type TimeWindow struct {
+	// Days the window opens on. Defaults to every day.
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start of the window (HH:MM)
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End of the window (HH:MM). An end before the start spans midnight,
+	// an end equal to the start spans the whole day.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// MaintenanceWindows defines when scans are allowed to start
+type MaintenanceWindows struct {
+	// TimeZone is the IANA time zone the windows are evaluated in.
+	// Defaults to the time zone of the operator.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Allowed windows. When set, scans only start inside one of them.
+	// +optional
+	Allowed []TimeWindow `json:"allowed,omitempty"`
+
+	// Forbidden (blackout) windows. Scans never start inside them, even
+	// within an allowed window.
+	// +optional
+	Forbidden []TimeWindow `json:"forbidden,omitempty"`
 }
 
 // ClusterScanPhase represents the current phase of a ClusterScan
@@ -131,10 +194,19 @@ type ClusterScanStatus struct {
 	// +optional
 	TotalFilesInfected int64 `json:"totalFilesInfected,omitempty"`
 
+	// NextWindowTime is when the next maintenance window opens while the
+	// cluster scan is waiting for one
+	// +optional
+	NextWindowTime *metav1.Time `json:"nextWindowTime,omitempty"`
+
 	// NodeScans contains references to individual node scans
 	// +optional
 	NodeScans []NodeScanReference `json:"nodeScans,omitempty"`
 
+	// Notifications tracks the delivery of the digest on each channel
+	// +optional
+	Notifications []NotificationStatus `json:"notifications,omitempty"`
+
 	// Conditions represent the latest available observations
 	// +optional
 	// +patchMergeKey=type
@@ -77,43 +77,59 @@ func (r *ClusterScan) ValidateDelete(ctx context.Context, obj runtime.Object) (a
 
 // validateClusterScan performs comprehensive validation of ClusterScan spec
 func (r *ClusterScan) validateClusterScan() field.ErrorList {
+	return validateClusterScanSpec(&r.Spec, field.NewPath("spec"))
+}
+
+// validateClusterScanSpec validates a ClusterScan spec, also used for ScanSchedule templates
+func validateClusterScanSpec(spec *ClusterScanSpec, specPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
-	specPath := field.NewPath("spec")
 
 	// Validate concurrent (1-50)
-	allErrs = append(allErrs, ValidateClusterScanConcurrent(r.Spec.Concurrent, specPath.Child("concurrent"))...)
+	allErrs = append(allErrs, ValidateClusterScanConcurrent(spec.Concurrent, specPath.Child("concurrent"))...)
 
 	// Validate priority
-	allErrs = append(allErrs, ValidatePriority(r.Spec.Priority, specPath.Child("priority"))...)
+	allErrs = append(allErrs, ValidatePriority(spec.Priority, specPath.Child("priority"))...)
 
 	// Validate NodeScanTemplate if provided
-	if r.Spec.NodeScanTemplate != nil {
+	if spec.NodeScanTemplate != nil {
 		templatePath := specPath.Child("nodeScanTemplate")
 
 		// Validate paths in template
-		if len(r.Spec.NodeScanTemplate.Paths) > 0 {
-			allErrs = append(allErrs, ValidatePaths(r.Spec.NodeScanTemplate.Paths, templatePath.Child("paths"))...)
+		if len(spec.NodeScanTemplate.Paths) > 0 {
+			allErrs = append(allErrs, ValidatePaths(spec.NodeScanTemplate.Paths, templatePath.Child("paths"))...)
 		}
 
+		// Validate workloads in template
+		allErrs = append(allErrs, ValidateWorkloadTarget(spec.NodeScanTemplate.Workloads,
+			spec.NodeScanTemplate.Paths, templatePath.Child("workloads"))...)
+
 		// Validate maxConcurrent in template
 		allErrs = append(allErrs, ValidateNodeScanConcurrent(
-			r.Spec.NodeScanTemplate.MaxConcurrent,
+			spec.NodeScanTemplate.MaxConcurrent,
 			templatePath.Child("maxConcurrent"))...)
 
 		// Validate resources in template
-		if r.Spec.NodeScanTemplate.Resources != nil {
+		if spec.NodeScanTemplate.Resources != nil {
 			allErrs = append(allErrs, validateResources(
-				r.Spec.NodeScanTemplate.Resources,
+				spec.NodeScanTemplate.Resources,
 				templatePath.Child("resources"))...)
 		}
 	}
 
+	// Validate maintenance windows
+	allErrs = append(allErrs, ValidateMaintenanceWindows(spec.MaintenanceWindows, specPath.Child("maintenanceWindows"))...)
+
+	// Validate the digest notifications
+	if spec.Notifications != nil {
+		allErrs = append(allErrs, ValidateNotifications(&spec.Notifications.NotificationConfig, specPath.Child("notifications"))...)
+	}
+
 	// Validate nodeSelector if provided
-	if r.Spec.NodeSelector != nil {
-		if len(r.Spec.NodeSelector.MatchLabels) == 0 && len(r.Spec.NodeSelector.MatchExpressions) == 0 {
+	if spec.NodeSelector != nil {
+		if len(spec.NodeSelector.MatchLabels) == 0 && len(spec.NodeSelector.MatchExpressions) == 0 {
 			allErrs = append(allErrs, field.Invalid(
 				specPath.Child("nodeSelector"),
-				r.Spec.NodeSelector,
+				spec.NodeSelector,
 				"nodeSelector must have at least one matchLabel or matchExpression"))
 		}
 	}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=csp;clusterscanpolicy
+// +kubebuilder:printcolumn:name="Paths",type=string,JSONPath=`.spec.paths`
+// +kubebuilder:printcolumn:name="MaxConcurrent",type=integer,JSONPath=`.spec.maxConcurrent`
+// +kubebuilder:printcolumn:name="UsageCount",type=integer,JSONPath=`.status.usageCount`
+// +kubebuilder:printcolumn:name="LastUsed",type=date,JSONPath=`.status.lastUsed`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterScanPolicy is the Schema for the clusterscanpolicies API.
+// It is a cluster-scoped ScanPolicy that can be referenced from any namespace.
+// Values set by a namespaced ScanPolicy take precedence over it.
+type ClusterScanPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScanPolicySpec   `json:"spec,omitempty"`
+	Status ScanPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterScanPolicyList contains a list of ClusterScanPolicy
+type ClusterScanPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterScanPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterScanPolicy{}, &ClusterScanPolicyList{})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var clusterscanpolicylog = logf.Log.WithName("clusterscanpolicy-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *ClusterScanPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ClusterScanPolicy{}).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-clusterscanpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=clusterscanpolicies,verbs=create;update,versions=v1alpha1,name=mclusterscanpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &ClusterScanPolicy{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (r *ClusterScanPolicy) Default(ctx context.Context, obj runtime.Object) error {
+	clusterScanPolicy, ok := obj.(*ClusterScanPolicy)
+	if !ok {
+		return fmt.Errorf("expected a ClusterScanPolicy but got %T", obj)
+	}
+	clusterscanpolicylog.Info("default", "name", clusterScanPolicy.Name)
+
+	defaultScanPolicySpec(&clusterScanPolicy.Spec)
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-clusterscanpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=clusterscanpolicies,verbs=create;update,versions=v1alpha1,name=vclusterscanpolicy.kb.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &ClusterScanPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterScanPolicy) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	clusterScanPolicy, ok := obj.(*ClusterScanPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterScanPolicy but got %T", obj)
+	}
+	clusterscanpolicylog.Info("validate create", "name", clusterScanPolicy.Name)
+
+	allErrs := validateScanPolicySpec(&clusterScanPolicy.Spec, field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterScanPolicy) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	clusterScanPolicy, ok := newObj.(*ClusterScanPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterScanPolicy but got %T", newObj)
+	}
+	clusterscanpolicylog.Info("validate update", "name", clusterScanPolicy.Name)
+
+	allErrs := validateScanPolicySpec(&clusterScanPolicy.Spec, field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterScanPolicy) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	// No validation needed for delete
+	return nil, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Default values applied by the defaulting webhooks. They match the
+// +kubebuilder:default markers of the CRDs.
+const (
+	// DefaultMaxConcurrent is the default number of files to scan in parallel
+	DefaultMaxConcurrent = 5
+
+	// DefaultFileTimeout is the default timeout for scanning a single file (ms)
+	DefaultFileTimeout = 300000 // 5 minutes
+
+	// DefaultMaxFileSize is the default maximum file size to scan (bytes)
+	DefaultMaxFileSize = 104857600 // 100MB
+
+	// DefaultConnectTimeout is the default timeout for connecting to ClamAV (ms)
+	DefaultConnectTimeout = 60000 // 60 seconds
+
+	// DefaultConcurrencyPolicy is the default ScanSchedule concurrency policy
+	DefaultConcurrencyPolicy = "Forbid"
+
+	// DefaultSuccessfulScansHistoryLimit is the default number of successful scheduled scans kept
+	DefaultSuccessfulScansHistoryLimit = 10
+
+	// DefaultFailedScansHistoryLimit is the default number of failed scheduled scans kept
+	DefaultFailedScansHistoryLimit = 3
+
+	// DefaultNotificationMaxAttempts is the default number of delivery attempts per notification
+	DefaultNotificationMaxAttempts = 5
+
+	// DefaultNotificationInitialBackoffSeconds is the default delay before retrying a notification
+	DefaultNotificationInitialBackoffSeconds = 30
+
+	// DefaultNotificationMaxBackoffSeconds is the default maximum delay between two attempts
+	DefaultNotificationMaxBackoffSeconds = 900 // 15 minutes
+
+	// DefaultNotificationDeduplicationWindowSeconds is the default time a
+	// notified infection is not notified again
+	DefaultNotificationDeduplicationWindowSeconds = 86400 // 24 hours
+
+	// DefaultNotificationRateLimitWindowSeconds is the default window of a channel rate limit
+	DefaultNotificationRateLimitWindowSeconds = 3600 // 1 hour
+
+	// DefaultMinInfectedFiles is the default number of infected files from
+	// which response actions isolate a Node
+	DefaultMinInfectedFiles = 1
+
+	// DefaultConcurrentImageScans is the default number of images an
+	// ImageScan scans in parallel
+	DefaultConcurrentImageScans = 2
+
+	// DefaultMaxLayerSize is the default maximum compressed size of a scanned
+	// image layer (bytes)
+	DefaultMaxLayerSize = 1073741824 // 1GiB
+)
+
+// DefaultNotificationTriggers are the events notified on a channel without triggers
+var DefaultNotificationTriggers = []NotificationTrigger{NotificationTriggerInfection}
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// DefaultOpsgenieAPIURL is the address of the Opsgenie API
+const DefaultOpsgenieAPIURL = "https://api.opsgenie.com"
+
+// DefaultTTLSecondsAfterFinished is the default TTL for completed scan jobs
+const DefaultTTLSecondsAfterFinished = 86400 // 24 hours
+
+// DefaultScanPaths are the paths scanned when neither the NodeScan nor its
+// ScanPolicy specify any
+var DefaultScanPaths = []string{
+	"/host/var/lib",
+	"/host/opt",
+}
+
+// Default resource limits and requests for scan jobs.
+// These values are applied when no custom resources are specified
+// in NodeScan, ClusterScan, or ScanPolicy resources.
+var (
+	// DefaultScannerResources defines the default resource requirements for scanner jobs.
+	// These values balance scan performance with cluster resource conservation.
+	DefaultScannerResources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			// CPU request: minimum CPU guaranteed for the scan job
+			corev1.ResourceCPU: resource.MustParse("100m"),
+			// Memory request: minimum memory guaranteed for the scan job
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			// CPU limit: maximum CPU the scan job can use
+			// Set to 1 core to prevent scans from impacting node performance
+			corev1.ResourceCPU: resource.MustParse("1000m"),
+			// Memory limit: maximum memory the scan job can use
+			// Set to 512Mi to handle large file scanning without OOM
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+
+	// HighPriorityScannerResources defines resources for high-priority scans.
+	// Used when NodeScan.Spec.Priority is set to "high".
+	HighPriorityScannerResources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2000m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+
+	// LowPriorityScannerResources defines resources for low-priority/background scans.
+	// Used when NodeScan.Spec.Priority is set to "low".
+	LowPriorityScannerResources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("50m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+)
+
+// ResourcesForPriority returns the default resource requirements for a scan priority
+func ResourcesForPriority(priority string) corev1.ResourceRequirements {
+	switch priority {
+	case "high":
+		return *HighPriorityScannerResources.DeepCopy()
+	case "low":
+		return *LowPriorityScannerResources.DeepCopy()
+	default:
+		return *DefaultScannerResources.DeepCopy()
+	}
+}
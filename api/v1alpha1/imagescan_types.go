@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImageScanSpec defines the desired state of ImageScan. Exactly one of Image
+// and RunningImages must be set.
+type ImageScanSpec struct {
+	// Image is the reference of the image to scan, e.g.
+	// registry.example.com/shop/web:1.2. It is pulled on a node of the cluster.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// RunningImages scans the images of the running containers of the
+	// selected pods, once per image digest
+	// +optional
+	RunningImages *WorkloadTarget `json:"runningImages,omitempty"`
+
+	// ImagePullSecrets are the kubernetes.io/dockerconfigjson Secrets used to
+	// pull Image
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Concurrent is the maximum number of images scanned in parallel
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	// +kubebuilder:default=2
+	// +optional
+	Concurrent int32 `json:"concurrent,omitempty"`
+
+	// MaxLayerSize in bytes - compressed layers larger than this are not
+	// scanned. Defaults to 1073741824.
+	// +optional
+	MaxLayerSize int64 `json:"maxLayerSize,omitempty"`
+
+	// Resources for the scan jobs
+	// If not specified, uses the medium priority defaults
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of the scan Jobs once they
+	// finished. Defaults to 86400.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// ImageScanPhase represents the current phase of an ImageScan
+// +kubebuilder:validation:Enum=Pending;Running;Completed;Failed;PartiallyCompleted
+type ImageScanPhase string
+
+const (
+	ImageScanPhasePending           ImageScanPhase = "Pending"
+	ImageScanPhaseRunning           ImageScanPhase = "Running"
+	ImageScanPhaseCompleted         ImageScanPhase = "Completed"
+	ImageScanPhaseFailed            ImageScanPhase = "Failed"
+	ImageScanPhasePartiallyComplete ImageScanPhase = "PartiallyCompleted"
+)
+
+// ImagePhase represents the scan state of one image of an ImageScan
+// +kubebuilder:validation:Enum=Pending;Running;Completed;Failed;Skipped
+type ImagePhase string
+
+const (
+	// ImagePhasePending means the image waits for a scan Job
+	ImagePhasePending ImagePhase = "Pending"
+	// ImagePhaseRunning means the scan Job of the image is running
+	ImagePhaseRunning ImagePhase = "Running"
+	// ImagePhaseCompleted means the layers of the image were scanned
+	ImagePhaseCompleted ImagePhase = "Completed"
+	// ImagePhaseFailed means the image could not be pulled or scanned
+	ImagePhaseFailed ImagePhase = "Failed"
+	// ImagePhaseSkipped means the image cannot be scanned, see the message
+	ImagePhaseSkipped ImagePhase = "Skipped"
+)
+
+// InfectedLayer is an image layer found to contain malware
+type InfectedLayer struct {
+	// Digest of the layer blob
+	Digest string `json:"digest"`
+
+	// Viruses detected in the layer
+	Viruses []string `json:"viruses"`
+
+	// Size of the layer blob in bytes
+	// +optional
+	Size int64 `json:"size,omitempty"`
+}
+
+// ImageResult reports the scan of one image digest
+type ImageResult struct {
+	// Digest of the image manifest or index. It is resolved by the scan Job
+	// for images pulled by reference.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// References are the image references resolving to the digest
+	References []string `json:"references"`
+
+	// Workloads running the image, as namespace/pod/container. Limited to
+	// the first 20.
+	// +optional
+	Workloads []string `json:"workloads,omitempty"`
+
+	// NodeName is the node the layers were read from
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Phase of the image scan
+	Phase ImagePhase `json:"phase"`
+
+	// Message explains a skipped or failed image scan
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// JobName is the name of the scan Job
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// LayersScanned is the number of layers scanned
+	// +optional
+	LayersScanned int32 `json:"layersScanned,omitempty"`
+
+	// LayersSkipped is the number of layers larger than maxLayerSize
+	// +optional
+	LayersSkipped int32 `json:"layersSkipped,omitempty"`
+
+	// InfectedLayers are the layers containing malware
+	// +optional
+	InfectedLayers []InfectedLayer `json:"infectedLayers,omitempty"`
+
+	// CompletionTime of the image scan
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ImageScanStatus defines the observed state of ImageScan
+type ImageScanStatus struct {
+	// Phase of the image scan
+	// +optional
+	Phase ImageScanPhase `json:"phase,omitempty"`
+
+	// StartTime of the image scan
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime of the image scan
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// TotalImages is the number of distinct images to scan
+	// +optional
+	TotalImages int32 `json:"totalImages,omitempty"`
+
+	// CompletedImages is the number of images whose layers were scanned
+	// +optional
+	CompletedImages int32 `json:"completedImages,omitempty"`
+
+	// FailedImages is the number of images that could not be scanned
+	// +optional
+	FailedImages int32 `json:"failedImages,omitempty"`
+
+	// SkippedImages is the number of images that cannot be scanned
+	// +optional
+	SkippedImages int32 `json:"skippedImages,omitempty"`
+
+	// InfectedImages is the number of images with infected layers
+	// +optional
+	InfectedImages int32 `json:"infectedImages,omitempty"`
+
+	// Images contains the result of each image
+	// +optional
+	Images []ImageResult `json:"images,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=is;imagescan
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
+// +kubebuilder:printcolumn:name="Images",type=integer,JSONPath=`.status.totalImages`
+// +kubebuilder:printcolumn:name="Completed",type=integer,JSONPath=`.status.completedImages`
+// +kubebuilder:printcolumn:name="Infected",type=integer,JSONPath=`.status.infectedImages`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ImageScan is the Schema for the imagescans API
+type ImageScan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImageScanSpec   `json:"spec,omitempty"`
+	Status ImageScanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImageScanList contains a list of ImageScan
+type ImageScanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImageScan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImageScan{}, &ImageScanList{})
+}
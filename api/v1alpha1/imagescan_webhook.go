@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var imagescanlog = logf.Log.WithName("imagescan-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *ImageScan) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ImageScan{}).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-imagescan,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=imagescans,verbs=create;update,versions=v1alpha1,name=mimagescan.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &ImageScan{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (r *ImageScan) Default(ctx context.Context, obj runtime.Object) error {
+	imageScan, ok := obj.(*ImageScan)
+	if !ok {
+		return fmt.Errorf("expected an ImageScan but got %T", obj)
+	}
+	imagescanlog.Info("default", "name", imageScan.Name)
+
+	defaultImageScanSpec(&imageScan.Spec)
+
+	return nil
+}
+
+// defaultImageScanSpec applies the defaults of an ImageScan spec
+func defaultImageScanSpec(spec *ImageScanSpec) {
+	if spec.Concurrent == 0 {
+		spec.Concurrent = DefaultConcurrentImageScans
+	}
+	if spec.MaxLayerSize == 0 {
+		spec.MaxLayerSize = DefaultMaxLayerSize
+	}
+	if spec.TTLSecondsAfterFinished == nil {
+		spec.TTLSecondsAfterFinished = ptr.To(int32(DefaultTTLSecondsAfterFinished))
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-imagescan,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=imagescans,verbs=create;update,versions=v1alpha1,name=vimagescan.kb.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &ImageScan{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ImageScan) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	imageScan, ok := obj.(*ImageScan)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImageScan but got %T", obj)
+	}
+	imagescanlog.Info("validate create", "name", imageScan.Name)
+
+	allErrs := imageScan.validateImageScan()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ImageScan) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	imageScan, ok := newObj.(*ImageScan)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImageScan but got %T", newObj)
+	}
+	imagescanlog.Info("validate update", "name", imageScan.Name)
+
+	allErrs := imageScan.validateImageScan()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ImageScan) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	// No validation needed for delete
+	return nil, nil
+}
+
+// validateImageScan performs comprehensive validation of ImageScan spec
+func (r *ImageScan) validateImageScan() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	switch {
+	case r.Spec.Image == "" && r.Spec.RunningImages == nil:
+		allErrs = append(allErrs, field.Required(specPath.Child("image"), "image or runningImages is required"))
+	case r.Spec.Image != "" && r.Spec.RunningImages != nil:
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("runningImages"), "image and runningImages are mutually exclusive"))
+	case r.Spec.Image != "":
+		allErrs = append(allErrs, ValidateImageReference(r.Spec.Image, specPath.Child("image"))...)
+	default:
+		allErrs = append(allErrs, ValidateWorkloadTarget(r.Spec.RunningImages, nil, specPath.Child("runningImages"))...)
+	}
+
+	if r.Spec.RunningImages != nil && len(r.Spec.ImagePullSecrets) > 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("imagePullSecrets"),
+			"running images are read from the nodes and are not pulled"))
+	}
+	for i, secret := range r.Spec.ImagePullSecrets {
+		if !isValidDNS1123Name(secret.Name) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("imagePullSecrets").Index(i).Child("name"),
+				secret.Name, "must be a valid secret name"))
+		}
+	}
+
+	if r.Spec.Concurrent < 0 || r.Spec.Concurrent > 20 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("concurrent"), r.Spec.Concurrent, "must be between 1 and 20"))
+	}
+	if r.Spec.MaxLayerSize < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxLayerSize"), r.Spec.MaxLayerSize, "must be non-negative"))
+	}
+	if r.Spec.Resources != nil {
+		allErrs = append(allErrs, validateResources(r.Spec.Resources, specPath.Child("resources"))...)
+	}
+
+	return allErrs
+}
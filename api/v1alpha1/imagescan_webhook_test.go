@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImageScan_Default(t *testing.T) {
+	imageScan := &ImageScan{Spec: ImageScanSpec{Image: "nginx:1.27"}}
+
+	require.NoError(t, (&ImageScan{}).Default(context.Background(), imageScan))
+
+	assert.Equal(t, int32(DefaultConcurrentImageScans), imageScan.Spec.Concurrent)
+	assert.Equal(t, int64(DefaultMaxLayerSize), imageScan.Spec.MaxLayerSize)
+	require.NotNil(t, imageScan.Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, int32(DefaultTTLSecondsAfterFinished), *imageScan.Spec.TTLSecondsAfterFinished)
+}
+
+func TestImageScan_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    ImageScanSpec
+		wantErr string
+	}{
+		{name: "image reference", spec: ImageScanSpec{Image: "registry.example.com:5000/shop/web:1.2"}},
+		{name: "image digest", spec: ImageScanSpec{
+			Image: "nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}},
+		{name: "running images", spec: ImageScanSpec{RunningImages: &WorkloadTarget{Namespaces: []string{"shop"}}}},
+		{name: "nothing to scan", spec: ImageScanSpec{}, wantErr: "spec.image"},
+		{name: "both targets", spec: ImageScanSpec{Image: "nginx", RunningImages: &WorkloadTarget{}},
+			wantErr: "spec.runningImages"},
+		{name: "invalid reference", spec: ImageScanSpec{Image: "Shop/Web:latest"}, wantErr: "spec.image"},
+		{name: "invalid selector", spec: ImageScanSpec{RunningImages: &WorkloadTarget{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "-web"}}}},
+			wantErr: "spec.runningImages.podSelector"},
+		{name: "pull secrets of running images", spec: ImageScanSpec{RunningImages: &WorkloadTarget{},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}}},
+			wantErr: "spec.imagePullSecrets"},
+		{name: "concurrent out of range", spec: ImageScanSpec{Image: "nginx", Concurrent: 21}, wantErr: "spec.concurrent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&ImageScan{}).ValidateCreate(context.Background(), &ImageScan{Spec: tt.spec})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -36,6 +36,11 @@ type NodeScanSpec struct {
 	// +optional
 	ScanPolicy string `json:"scanPolicy,omitempty"`
 
+	// ClusterScanPolicy references a cluster-scoped policy. Values set by
+	// ScanPolicy take precedence over it.
+	// +optional
+	ClusterScanPolicy string `json:"clusterScanPolicy,omitempty"`
+
 	// Priority of the scan (high, medium, low)
 	// Affects scheduling and resource allocation
 	// +kubebuilder:validation:Enum=high;medium;low
@@ -53,31 +58,31 @@ type NodeScanSpec struct {
 	ExcludePatterns []string `json:"excludePatterns,omitempty"`
 
 	// MaxConcurrent files to scan in parallel
+	// If not specified, uses the ScanPolicy value or 5
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=20
-	// +kubebuilder:default=5
 	// +optional
 	MaxConcurrent int32 `json:"maxConcurrent,omitempty"`
 
 	// FileTimeout in milliseconds for scanning each file
-	// +kubebuilder:default=300000
+	// If not specified, uses the ScanPolicy value or 300000
 	// +optional
 	FileTimeout int64 `json:"fileTimeout,omitempty"`
 
 	// MaxFileSize in bytes - files larger than this will be skipped
-	// +kubebuilder:default=104857600
+	// If not specified, uses the ScanPolicy value or 104857600
 	// +optional
 	MaxFileSize int64 `json:"maxFileSize,omitempty"`
 
 	// Resources for the scan job
+	// If not specified, uses the ScanPolicy resources or the priority-based defaults
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
 	// TTLSecondsAfterFinished limits the lifetime of a Job that has finished
 	// execution (either Complete or Failed). If this field is set,
 	// ttlSecondsAfterFinished after the Job finishes, it is eligible to be
-	// automatically deleted.
-	// +kubebuilder:default=86400
+	// automatically deleted. Defaults to 86400.
 	// +optional
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 
@@ -94,6 +99,66 @@ type NodeScanSpec struct {
 	// ForceFullScan forces a full scan even if incremental is enabled
 	// +optional
 	ForceFullScan bool `json:"forceFullScan,omitempty"`
+
+	// SuppressNotifications disables the scan notifications of the scan
+	// policy, quarantine reports are still sent. ClusterScans that send a
+	// digest set it on their NodeScans.
+	// +optional
+	SuppressNotifications bool `json:"suppressNotifications,omitempty"`
+
+	// Workloads scans the filesystems of the selected running containers of
+	// the node instead of host paths. Cannot be combined with Paths.
+	// +optional
+	Workloads *WorkloadTarget `json:"workloads,omitempty"`
+}
+
+// WorkloadTarget selects the pods of the node whose container filesystems
+// are scanned. Pods must match all the set selectors.
+type WorkloadTarget struct {
+	// Namespaces of the pods. If empty, pods of all namespaces are selected.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the namespaces of the pods by label
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// PodSelector selects the pods by label
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+}
+
+// WorkloadScanStatus reports the scan of one container filesystem
+type WorkloadScanStatus struct {
+	// Namespace of the pod
+	Namespace string `json:"namespace"`
+
+	// Pod the container belongs to
+	Pod string `json:"pod"`
+
+	// Container name
+	Container string `json:"container"`
+
+	// Image of the container
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ContainerID is the runtime ID of the container
+	// +optional
+	ContainerID string `json:"containerID,omitempty"`
+
+	// Paths scanned for the container, its root filesystem including the
+	// writable layer
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// SkipReason explains why the container was not scanned
+	// +optional
+	SkipReason string `json:"skipReason,omitempty"`
+
+	// FilesInfected is the number of infected files found in the container
+	// +optional
+	FilesInfected int64 `json:"filesInfected,omitempty"`
 }
 
 // NodeScanPhase represents the current phase of a NodeScan
@@ -126,6 +191,242 @@ type InfectedFile struct {
 	// DetectedAt is when the infection was detected
 	// +optional
 	DetectedAt metav1.Time `json:"detectedAt,omitempty"`
+
+	// Attribution identifies the pod and container the file belongs to when
+	// it is stored under the container runtime or kubelet directories
+	// +optional
+	Attribution *FileAttribution `json:"attribution,omitempty"`
+}
+
+// FileAttribution identifies the workload an infected file belongs to
+type FileAttribution struct {
+	// Namespace of the pod
+	Namespace string `json:"namespace"`
+
+	// Pod the file belongs to
+	Pod string `json:"pod"`
+
+	// Container the file belongs to. Files in pod volumes mounted by several
+	// containers have no container.
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Image of the container
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Volume is the pod volume holding the file, if any
+	// +optional
+	Volume string `json:"volume,omitempty"`
+}
+
+// QuarantinePhase represents the current phase of the quarantine step
+// +kubebuilder:validation:Enum=Pending;Running;Completed;Failed
+type QuarantinePhase string
+
+const (
+	// QuarantinePhasePending means the completed scan requires a quarantine
+	// that has not started yet
+	QuarantinePhasePending QuarantinePhase = "Pending"
+	// QuarantinePhaseRunning means the quarantine job is executing
+	QuarantinePhaseRunning QuarantinePhase = "Running"
+	// QuarantinePhaseCompleted means the quarantine job has processed every file
+	QuarantinePhaseCompleted QuarantinePhase = "Completed"
+	// QuarantinePhaseFailed means the quarantine job could not process the files
+	QuarantinePhaseFailed QuarantinePhase = "Failed"
+)
+
+// Outcomes recorded for each file handled by the quarantine step
+const (
+	QuarantineOutcomeQuarantined = "quarantined"
+	QuarantineOutcomeDeleted     = "deleted"
+	QuarantineOutcomeFailed      = "failed"
+)
+
+// QuarantinedFile records what happened to a single infected file
+type QuarantinedFile struct {
+	// Path to the infected file on the node
+	Path string `json:"path"`
+
+	// Outcome of the action (quarantined, deleted, failed)
+	// +kubebuilder:validation:Enum=quarantined;deleted;failed
+	Outcome string `json:"outcome"`
+
+	// QuarantinePath is where the file was moved to on the node
+	// Only set when Outcome is "quarantined"
+	// +optional
+	QuarantinePath string `json:"quarantinePath,omitempty"`
+
+	// Message gives details when the action failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// QuarantineStatus reports the remediation applied to infected files
+type QuarantineStatus struct {
+	// Action applied to the infected files (move or delete)
+	Action string `json:"action"`
+
+	// Phase of the quarantine step
+	// +optional
+	Phase QuarantinePhase `json:"phase,omitempty"`
+
+	// QuarantineDir is the directory on the node where files were moved
+	// +optional
+	QuarantineDir string `json:"quarantineDir,omitempty"`
+
+	// JobRef is a reference to the quarantine Job
+	// +optional
+	JobRef *corev1.ObjectReference `json:"jobRef,omitempty"`
+
+	// FilesQuarantined is the number of files moved to the quarantine directory
+	// +optional
+	FilesQuarantined int64 `json:"filesQuarantined,omitempty"`
+
+	// FilesDeleted is the number of files deleted
+	// +optional
+	FilesDeleted int64 `json:"filesDeleted,omitempty"`
+
+	// FilesFailed is the number of files the action could not be applied to
+	// +optional
+	FilesFailed int64 `json:"filesFailed,omitempty"`
+
+	// FilesUntouched is the number of infected files the action was not applied
+	// to because they are missing from the scan findings, e.g. when the scanner
+	// truncated its result
+	// +optional
+	FilesUntouched int64 `json:"filesUntouched,omitempty"`
+
+	// Files contains the outcome for each infected file
+	// +optional
+	Files []QuarantinedFile `json:"files,omitempty"`
+
+	// CompletionTime of the quarantine step
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// NodeIsolationPhase represents the state of the isolation of a Node
+// +kubebuilder:validation:Enum=Pending;Skipped;Draining;Isolated;Released
+type NodeIsolationPhase string
+
+const (
+	// NodeIsolationPhasePending means the scan found malware and the response
+	// actions are about to be applied
+	NodeIsolationPhasePending NodeIsolationPhase = "Pending"
+	// NodeIsolationPhaseSkipped means the infection did not require isolating
+	// the Node, e.g. it only matched allowed signatures
+	NodeIsolationPhaseSkipped NodeIsolationPhase = "Skipped"
+	// NodeIsolationPhaseDraining means the Node is cordoned and its pods are
+	// being evicted
+	NodeIsolationPhaseDraining NodeIsolationPhase = "Draining"
+	// NodeIsolationPhaseIsolated means every response action was applied
+	NodeIsolationPhaseIsolated NodeIsolationPhase = "Isolated"
+	// NodeIsolationPhaseReleased means the response actions were undone
+	NodeIsolationPhaseReleased NodeIsolationPhase = "Released"
+)
+
+// NodeIsolationStatus records the response actions applied to a Node so
+// that they can be undone. Actions that were already in place, e.g. a Node
+// that was already cordoned, are not recorded and not undone.
+type NodeIsolationStatus struct {
+	// Phase of the isolation
+	Phase NodeIsolationPhase `json:"phase"`
+
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Cordoned is set when the operator cordoned the Node
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+
+	// Taint is the taint the operator added to the Node
+	// +optional
+	Taint *corev1.Taint `json:"taint,omitempty"`
+
+	// EvictedPods are the pods evicted from the Node, as namespace/name
+	// +optional
+	EvictedPods []string `json:"evictedPods,omitempty"`
+
+	// IsolationTime is when the Node was isolated
+	// +optional
+	IsolationTime *metav1.Time `json:"isolationTime,omitempty"`
+
+	// ReleaseTime is when the response actions were undone
+	// +optional
+	ReleaseTime *metav1.Time `json:"releaseTime,omitempty"`
+}
+
+// NotificationState represents the delivery state of a notification
+// +kubebuilder:validation:Enum=Pending;Sent;Failed
+type NotificationState string
+
+const (
+	// NotificationStatePending means the notification is waiting to be (re)delivered
+	NotificationStatePending NotificationState = "Pending"
+	// NotificationStateSent means the notification was delivered
+	NotificationStateSent NotificationState = "Sent"
+	// NotificationStateFailed means every attempt failed and the notification was dead-lettered
+	NotificationStateFailed NotificationState = "Failed"
+)
+
+// Notification channels
+const (
+	NotificationChannelSlack     = "slack"
+	NotificationChannelEmail     = "email"
+	NotificationChannelWebhook   = "webhook"
+	NotificationChannelTeams     = "teams"
+	NotificationChannelPagerDuty = "pagerduty"
+	NotificationChannelOpsgenie  = "opsgenie"
+)
+
+// Notification events
+const (
+	NotificationEventScanStarted          = "ScanStarted"
+	NotificationEventScanCompleted        = "ScanCompleted"
+	NotificationEventScanFailed           = "ScanFailed"
+	NotificationEventParseFailed          = "ParseFailed"
+	NotificationEventQuarantineCompleted  = "QuarantineCompleted"
+	NotificationEventClusterScanCompleted = "ClusterScanCompleted"
+	NotificationEventScanOverdue          = "ScanOverdue"
+)
+
+// NotificationStatus tracks the delivery of one notification on one channel
+type NotificationStatus struct {
+	// Channel the notification is delivered to (slack, email, webhook, teams,
+	// pagerduty, opsgenie)
+	Channel string `json:"channel"`
+
+	// Event the notification is about
+	Event string `json:"event"`
+
+	// State of the delivery
+	State NotificationState `json:"state"`
+
+	// Attempts is the number of delivery attempts so far
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// LastAttemptTime is when delivery was last attempted
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// NextAttemptTime is when delivery will be retried
+	// +optional
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
+
+	// SentTime is when the notification was delivered
+	// +optional
+	SentTime *metav1.Time `json:"sentTime,omitempty"`
+
+	// LastError is the error of the last failed attempt
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// DeadLetter is the name of the ConfigMap recording the failed notification
+	// +optional
+	DeadLetter string `json:"deadLetter,omitempty"`
 }
 
 // NodeScanStatus defines the observed state of NodeScan
@@ -181,6 +482,11 @@ type NodeScanStatus struct {
 	// +optional
 	ReportPath string `json:"reportPath,omitempty"`
 
+	// Report references the ScanReports holding the complete findings of the scan
+	// (InfectedFiles above is capped at 100 entries)
+	// +optional
+	Report *ScanReportReference `json:"report,omitempty"`
+
 	// LastTransitionTime is the last time the phase transitioned
 	// +optional
 	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
@@ -200,6 +506,30 @@ type NodeScanStatus struct {
 	// TimeSaved is the estimated time saved by incremental scanning (in seconds)
 	// +optional
 	TimeSaved int64 `json:"timeSaved,omitempty"`
+
+	// Notifications tracks the delivery of the notifications of this scan
+	// +optional
+	Notifications []NotificationStatus `json:"notifications,omitempty"`
+
+	// ParameterSources reports where each effective scan parameter comes from:
+	// NodeScan, ScanPolicy/<name>, ClusterScanPolicy/<name> or Default
+	// +optional
+	ParameterSources map[string]string `json:"parameterSources,omitempty"`
+
+	// Quarantine reports the remediation applied to infected files
+	// when the ScanPolicy enables quarantine
+	// +optional
+	Quarantine *QuarantineStatus `json:"quarantine,omitempty"`
+
+	// Isolation records the response actions applied to the scanned Node
+	// when the ScanPolicy enables them
+	// +optional
+	Isolation *NodeIsolationStatus `json:"isolation,omitempty"`
+
+	// Workloads lists the containers selected by spec.workloads and the
+	// infected files found in each
+	// +optional
+	Workloads []WorkloadScanStatus `json:"workloads,omitempty"`
 }
 
 // +kubebuilder:object:root=true
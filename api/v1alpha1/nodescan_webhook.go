@@ -17,9 +17,16 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -28,13 +35,108 @@ import (
 // log is for logging in this package.
 var nodescanlog = logf.Log.WithName("nodescan-resource")
 
+// ResolvedSpecAnnotation holds the JSON encoded ResolvedScanParameters of a
+// NodeScan, as computed by the defaulting webhook
+const ResolvedSpecAnnotation = "clamav.io/resolved-spec"
+
 // SetupWebhookWithManager sets up the webhook with the Manager
 func (r *NodeScan) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithDefaulter(&nodeScanDefaulter{reader: mgr.GetClient()}).
 		Complete()
 }
 
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-nodescan,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=nodescans,verbs=create;update,versions=v1alpha1,name=mnodescan.kb.io,admissionReviewVersions=v1
+
+// nodeScanDefaulter applies the ScanPolicy values and the operator defaults to
+// NodeScans. It needs a client to read the referenced ScanPolicy.
+type nodeScanDefaulter struct {
+	reader client.Reader
+}
+
+var _ webhook.CustomDefaulter = &nodeScanDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (d *nodeScanDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	nodeScan, ok := obj.(*NodeScan)
+	if !ok {
+		return fmt.Errorf("expected a NodeScan but got %T", obj)
+	}
+	nodescanlog.Info("default", "name", nodeScan.Name)
+
+	// The parameters are resolved once, when the NodeScan is created
+	if _, ok := nodeScan.Annotations[ResolvedSpecAnnotation]; ok {
+		return nil
+	}
+
+	// A missing policy is resolved to the operator defaults, the controller
+	// reports it. The resolution is still recorded so that creating the
+	// policy later does not rewrite the spec of the NodeScan.
+	var policies ScanPolicies
+	if nodeScan.Spec.ClusterScanPolicy != "" {
+		clusterScanPolicy := &ClusterScanPolicy{}
+		err := d.reader.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.ClusterScanPolicy}, clusterScanPolicy)
+		switch {
+		case err == nil:
+			policies.Cluster = clusterScanPolicy
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get ClusterScanPolicy %s: %w", nodeScan.Spec.ClusterScanPolicy, err)
+		}
+	}
+	if nodeScan.Spec.ScanPolicy != "" {
+		scanPolicy := &ScanPolicy{}
+		err := d.reader.Get(ctx, types.NamespacedName{
+			Name:      nodeScan.Spec.ScanPolicy,
+			Namespace: nodeScan.Namespace,
+		}, scanPolicy)
+		switch {
+		case err == nil:
+			policies.Namespace = scanPolicy
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get ScanPolicy %s: %w", nodeScan.Spec.ScanPolicy, err)
+		}
+	}
+
+	return nodeScan.applyResolvedParameters(ResolveScanParameters(&nodeScan.Spec, policies))
+}
+
+// applyResolvedParameters writes the resolved parameters into the spec and
+// records them in the resolved-spec annotation
+func (r *NodeScan) applyResolvedParameters(params ResolvedScanParameters) error {
+	r.Spec.Paths = append([]string(nil), params.Paths...)
+	r.Spec.MaxConcurrent = params.MaxConcurrent
+	r.Spec.FileTimeout = params.FileTimeout
+	r.Spec.MaxFileSize = params.MaxFileSize
+	r.Spec.Resources = params.Resources.DeepCopy()
+	ttl := params.TTLSecondsAfterFinished
+	r.Spec.TTLSecondsAfterFinished = &ttl
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode resolved spec: %w", err)
+	}
+	if r.Annotations == nil {
+		r.Annotations = map[string]string{}
+	}
+	r.Annotations[ResolvedSpecAnnotation] = string(data)
+	return nil
+}
+
+// ResolvedParameters returns the parameters recorded in the resolved-spec
+// annotation by the defaulting webhook, if any
+func (r *NodeScan) ResolvedParameters() (*ResolvedScanParameters, bool) {
+	data, ok := r.Annotations[ResolvedSpecAnnotation]
+	if !ok {
+		return nil, false
+	}
+	var params ResolvedScanParameters
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		return nil, false
+	}
+	return &params, true
+}
+
 // +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-nodescan,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=nodescans,verbs=create;update,versions=v1alpha1,name=vnodescan.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &NodeScan{}
@@ -101,6 +203,9 @@ func (r *NodeScan) validateNodeScan() field.ErrorList {
 		allErrs = append(allErrs, ValidatePaths(r.Spec.Paths, specPath.Child("paths"))...)
 	}
 
+	// Validate the workloads, which replace the paths
+	allErrs = append(allErrs, ValidateWorkloadTarget(r.Spec.Workloads, r.Spec.Paths, specPath.Child("workloads"))...)
+
 	// Validate exclude patterns if specified
 	if len(r.Spec.ExcludePatterns) > 0 {
 		allErrs = append(allErrs, ValidateExcludePatterns(r.Spec.ExcludePatterns, specPath.Child("excludePatterns"))...)
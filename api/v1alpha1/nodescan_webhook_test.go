@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestNodeScanDefaulter(objs ...runtime.Object) *nodeScanDefaulter {
+	scheme := runtime.NewScheme()
+	_ = AddToScheme(scheme)
+	return &nodeScanDefaulter{
+		reader: fakeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+	}
+}
+
+func resolvedSpecAnnotation(t *testing.T, nodeScan *NodeScan) ResolvedScanParameters {
+	t.Helper()
+	var params ResolvedScanParameters
+	require.Contains(t, nodeScan.Annotations, ResolvedSpecAnnotation)
+	require.NoError(t, json.Unmarshal([]byte(nodeScan.Annotations[ResolvedSpecAnnotation]), &params))
+	return params
+}
+
+func TestNodeScan_Default_WithoutPolicy(t *testing.T) {
+	nodeScan := &NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan", Namespace: "default"},
+		Spec:       NodeScanSpec{NodeName: "node-1", Priority: "high"},
+	}
+
+	require.NoError(t, newTestNodeScanDefaulter().Default(context.Background(), nodeScan))
+
+	assert.Equal(t, DefaultScanPaths, nodeScan.Spec.Paths)
+	assert.Equal(t, int32(DefaultMaxConcurrent), nodeScan.Spec.MaxConcurrent)
+	assert.Equal(t, int64(DefaultFileTimeout), nodeScan.Spec.FileTimeout)
+	assert.Equal(t, int64(DefaultMaxFileSize), nodeScan.Spec.MaxFileSize)
+	require.NotNil(t, nodeScan.Spec.Resources)
+	assert.True(t, HighPriorityScannerResources.Limits.Cpu().Equal(*nodeScan.Spec.Resources.Limits.Cpu()))
+	require.NotNil(t, nodeScan.Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, int32(DefaultTTLSecondsAfterFinished), *nodeScan.Spec.TTLSecondsAfterFinished)
+
+	params := resolvedSpecAnnotation(t, nodeScan)
+	assert.Equal(t, int64(DefaultConnectTimeout), params.ConnectTimeout)
+	assert.Equal(t, "high", params.Priority)
+}
+
+func TestNodeScan_Default_WithPolicy(t *testing.T) {
+	policyResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+	scanPolicy := &ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default"},
+		Spec: ScanPolicySpec{
+			Paths:          []string{"/host/etc"},
+			MaxConcurrent:  10,
+			FileTimeout:    600000,
+			ConnectTimeout: 30000,
+			Resources:      &policyResources,
+		},
+	}
+	nodeScan := &NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan", Namespace: "default"},
+		Spec: NodeScanSpec{
+			NodeName:    "node-1",
+			ScanPolicy:  "strict",
+			MaxFileSize: 1024,
+		},
+	}
+
+	require.NoError(t, newTestNodeScanDefaulter(scanPolicy).Default(context.Background(), nodeScan))
+
+	// Policy values fill the gaps, NodeScan values win
+	assert.Equal(t, []string{"/host/etc"}, nodeScan.Spec.Paths)
+	assert.Equal(t, int32(10), nodeScan.Spec.MaxConcurrent)
+	assert.Equal(t, int64(600000), nodeScan.Spec.FileTimeout)
+	assert.Equal(t, int64(1024), nodeScan.Spec.MaxFileSize)
+	require.NotNil(t, nodeScan.Spec.Resources)
+	assert.Equal(t, "2Gi", nodeScan.Spec.Resources.Limits.Memory().String())
+
+	params := resolvedSpecAnnotation(t, nodeScan)
+	assert.Equal(t, "strict", params.ScanPolicy)
+	assert.Equal(t, int64(30000), params.ConnectTimeout)
+}
+
+func TestNodeScan_Default_MissingPolicy(t *testing.T) {
+	nodeScan := &NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan", Namespace: "default"},
+		Spec:       NodeScanSpec{NodeName: "node-1", ScanPolicy: "missing"},
+	}
+
+	defaulter := newTestNodeScanDefaulter()
+	require.NoError(t, defaulter.Default(context.Background(), nodeScan))
+
+	// The controller reports the missing policy, the defaults are recorded
+	assert.Equal(t, DefaultScanPaths, nodeScan.Spec.Paths)
+	params := resolvedSpecAnnotation(t, nodeScan)
+	assert.Equal(t, "missing", params.ScanPolicy)
+	assert.Equal(t, DefaultScanPaths, params.Paths)
+
+	// Updates after the policy is created keep the resolved spec
+	scanPolicy := &ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"},
+		Spec:       ScanPolicySpec{Paths: []string{"/host/etc"}, MaxConcurrent: 10},
+	}
+	require.NoError(t, defaulter.reader.(client.Client).Create(context.Background(), scanPolicy))
+	annotation := nodeScan.Annotations[ResolvedSpecAnnotation]
+	nodeScan.Labels = map[string]string{"team": "security"}
+
+	require.NoError(t, defaulter.Default(context.Background(), nodeScan))
+	assert.Equal(t, DefaultScanPaths, nodeScan.Spec.Paths)
+	assert.Equal(t, int32(DefaultMaxConcurrent), nodeScan.Spec.MaxConcurrent)
+	assert.Equal(t, annotation, nodeScan.Annotations[ResolvedSpecAnnotation])
+}
+
+func TestNodeScan_Default_WithClusterPolicy(t *testing.T) {
+	clusterScanPolicy := &ClusterScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+		Spec:       ScanPolicySpec{Paths: []string{"/host/etc"}, MaxConcurrent: 4},
+	}
+	scanPolicy := &ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "default"},
+		Spec:       ScanPolicySpec{MaxConcurrent: 8},
+	}
+	nodeScan := &NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan", Namespace: "default"},
+		Spec:       NodeScanSpec{NodeName: "node-1", ScanPolicy: "team", ClusterScanPolicy: "baseline"},
+	}
+	defaulter := newTestNodeScanDefaulter(clusterScanPolicy, scanPolicy)
+
+	require.NoError(t, defaulter.Default(context.Background(), nodeScan))
+
+	assert.Equal(t, []string{"/host/etc"}, nodeScan.Spec.Paths)
+	assert.Equal(t, int32(8), nodeScan.Spec.MaxConcurrent)
+
+	params, ok := nodeScan.ResolvedParameters()
+	require.True(t, ok)
+	assert.Equal(t, "ClusterScanPolicy/baseline", params.Sources["paths"])
+	assert.Equal(t, "ScanPolicy/team", params.Sources["maxConcurrent"])
+
+	// The sources recorded at creation are kept on update
+	require.NoError(t, defaulter.Default(context.Background(), nodeScan))
+	params, _ = nodeScan.ResolvedParameters()
+	assert.Equal(t, "ClusterScanPolicy/baseline", params.Sources["paths"])
+}
+
+func TestNodeScan_Default_Workloads(t *testing.T) {
+	scanPolicy := &ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "default"},
+		Spec:       ScanPolicySpec{Paths: []string{"/host/etc"}},
+	}
+	nodeScan := &NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan", Namespace: "default"},
+		Spec: NodeScanSpec{
+			NodeName:   "node-1",
+			ScanPolicy: "strict",
+			Workloads:  &WorkloadTarget{Namespaces: []string{"shop"}},
+		},
+	}
+
+	require.NoError(t, newTestNodeScanDefaulter(scanPolicy).Default(context.Background(), nodeScan))
+
+	// The container filesystems replace the policy and default paths
+	assert.Empty(t, nodeScan.Spec.Paths)
+	params := resolvedSpecAnnotation(t, nodeScan)
+	assert.Equal(t, ParameterSourceWorkloads, params.Sources["paths"])
+	_, err := nodeScan.ValidateCreate()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Sources of the effective scan parameters, besides "ScanPolicy/<name>" and
+// "ClusterScanPolicy/<name>"
+const (
+	// ParameterSourceNodeScan means the value is set on the NodeScan itself
+	ParameterSourceNodeScan = "NodeScan"
+	// ParameterSourceDefault means the operator default is used
+	ParameterSourceDefault = "Default"
+	// ParameterSourceWorkloads means the paths are the container filesystems
+	// selected by spec.workloads
+	ParameterSourceWorkloads = "Workloads"
+	// ParameterSourceVolumeScan means the value is set on the VolumeScan itself
+	ParameterSourceVolumeScan = "VolumeScan"
+)
+
+// ScanPolicies are the policies referenced by a NodeScan. Either may be nil.
+// +kubebuilder:object:generate=false
+type ScanPolicies struct {
+	Cluster   *ClusterScanPolicy
+	Namespace *ScanPolicy
+}
+
+// Merge layers the ScanPolicy over the ClusterScanPolicy. It returns nil when no
+// policy is referenced, along with the policy each set field was taken from.
+func (p ScanPolicies) Merge() (*ScanPolicySpec, map[string]string) {
+	sources := map[string]string{}
+	if p.Cluster == nil && p.Namespace == nil {
+		return nil, sources
+	}
+
+	merged := &ScanPolicySpec{}
+	merge := func(spec *ScanPolicySpec, source string) {
+		if len(spec.Paths) > 0 {
+			merged.Paths = spec.Paths
+			sources["paths"] = source
+		}
+		if len(spec.ExcludePatterns) > 0 {
+			merged.ExcludePatterns = spec.ExcludePatterns
+			sources["excludePatterns"] = source
+		}
+		if spec.MaxConcurrent != 0 {
+			merged.MaxConcurrent = spec.MaxConcurrent
+			sources["maxConcurrent"] = source
+		}
+		if spec.FileTimeout != 0 {
+			merged.FileTimeout = spec.FileTimeout
+			sources["fileTimeout"] = source
+		}
+		if spec.MaxFileSize != 0 {
+			merged.MaxFileSize = spec.MaxFileSize
+			sources["maxFileSize"] = source
+		}
+		if spec.ConnectTimeout > 0 {
+			merged.ConnectTimeout = spec.ConnectTimeout
+			sources["connectTimeout"] = source
+		}
+		if spec.Resources != nil {
+			merged.Resources = spec.Resources
+			sources["resources"] = source
+		}
+		if spec.Notifications != nil {
+			merged.Notifications = spec.Notifications
+			sources["notifications"] = source
+		}
+		if spec.Quarantine != nil {
+			merged.Quarantine = spec.Quarantine
+			sources["quarantine"] = source
+		}
+		if spec.NodeReporting != nil {
+			merged.NodeReporting = spec.NodeReporting
+			sources["nodeReporting"] = source
+		}
+		if spec.ResponseActions != nil {
+			merged.ResponseActions = spec.ResponseActions
+			sources["responseActions"] = source
+		}
+	}
+
+	// Lowest precedence first
+	if p.Cluster != nil {
+		merge(&p.Cluster.Spec, "ClusterScanPolicy/"+p.Cluster.Name)
+	}
+	if p.Namespace != nil {
+		merge(&p.Namespace.Spec, "ScanPolicy/"+p.Namespace.Name)
+	}
+
+	return merged.DeepCopy(), sources
+}
+
+// ResolvedScanParameters are the effective parameters of a NodeScan once its
+// policies and the operator defaults have been applied
+type ResolvedScanParameters struct {
+	ClusterScanPolicy       string                      `json:"clusterScanPolicy,omitempty"`
+	ScanPolicy              string                      `json:"scanPolicy,omitempty"`
+	Priority                string                      `json:"priority,omitempty"`
+	Strategy                ScanStrategy                `json:"strategy,omitempty"`
+	Paths                   []string                    `json:"paths"`
+	MaxConcurrent           int32                       `json:"maxConcurrent"`
+	FileTimeout             int64                       `json:"fileTimeout"`
+	MaxFileSize             int64                       `json:"maxFileSize"`
+	ConnectTimeout          int64                       `json:"connectTimeout"`
+	Resources               corev1.ResourceRequirements `json:"resources"`
+	TTLSecondsAfterFinished int32                       `json:"ttlSecondsAfterFinished"`
+
+	// Sources maps each parameter to where its value comes from
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// ResolveScanParameters merges a NodeScan spec with its policies and the
+// operator defaults. Values set on the NodeScan take precedence over the
+// ScanPolicy, then the ClusterScanPolicy, then the defaults.
+func ResolveScanParameters(spec *NodeScanSpec, policies ScanPolicies) ResolvedScanParameters {
+	policy, policySources := policies.Merge()
+	if policy == nil {
+		policy = &ScanPolicySpec{}
+	}
+
+	resolved := ResolvedScanParameters{
+		ClusterScanPolicy: spec.ClusterScanPolicy,
+		ScanPolicy:        spec.ScanPolicy,
+		Priority:          spec.Priority,
+		Strategy:          spec.Strategy,
+		Sources:           map[string]string{},
+	}
+
+	// source records where field comes from given which layers set it
+	source := func(field string, fromNodeScan, fromPolicy bool) {
+		switch {
+		case fromNodeScan:
+			resolved.Sources[field] = ParameterSourceNodeScan
+		case fromPolicy:
+			resolved.Sources[field] = policySources[field]
+		default:
+			resolved.Sources[field] = ParameterSourceDefault
+		}
+	}
+
+	// Workload scans resolve their paths from the containers of the node
+	switch {
+	case spec.Workloads != nil:
+		resolved.Sources["paths"] = ParameterSourceWorkloads
+	case len(spec.Paths) > 0:
+		resolved.Paths = spec.Paths
+	case len(policy.Paths) > 0:
+		resolved.Paths = policy.Paths
+	default:
+		resolved.Paths = DefaultScanPaths
+	}
+	if spec.Workloads == nil {
+		source("paths", len(spec.Paths) > 0, len(policy.Paths) > 0)
+	}
+
+	switch {
+	case spec.MaxConcurrent != 0:
+		resolved.MaxConcurrent = spec.MaxConcurrent
+	case policy.MaxConcurrent != 0:
+		resolved.MaxConcurrent = policy.MaxConcurrent
+	default:
+		resolved.MaxConcurrent = DefaultMaxConcurrent
+	}
+	source("maxConcurrent", spec.MaxConcurrent != 0, policy.MaxConcurrent != 0)
+
+	switch {
+	case spec.FileTimeout != 0:
+		resolved.FileTimeout = spec.FileTimeout
+	case policy.FileTimeout != 0:
+		resolved.FileTimeout = policy.FileTimeout
+	default:
+		resolved.FileTimeout = DefaultFileTimeout
+	}
+	source("fileTimeout", spec.FileTimeout != 0, policy.FileTimeout != 0)
+
+	switch {
+	case spec.MaxFileSize != 0:
+		resolved.MaxFileSize = spec.MaxFileSize
+	case policy.MaxFileSize != 0:
+		resolved.MaxFileSize = policy.MaxFileSize
+	default:
+		resolved.MaxFileSize = DefaultMaxFileSize
+	}
+	source("maxFileSize", spec.MaxFileSize != 0, policy.MaxFileSize != 0)
+
+	// The connect timeout can only be set by policies
+	resolved.ConnectTimeout = DefaultConnectTimeout
+	if policy.ConnectTimeout > 0 {
+		resolved.ConnectTimeout = policy.ConnectTimeout
+	}
+	source("connectTimeout", false, policy.ConnectTimeout > 0)
+
+	// Resources: NodeScan, then policies, then priority-based defaults
+	switch {
+	case spec.Resources != nil:
+		resolved.Resources = *spec.Resources.DeepCopy()
+	case policy.Resources != nil:
+		resolved.Resources = *policy.Resources.DeepCopy()
+	default:
+		resolved.Resources = ResourcesForPriority(spec.Priority)
+	}
+	source("resources", spec.Resources != nil, policy.Resources != nil)
+
+	resolved.TTLSecondsAfterFinished = DefaultTTLSecondsAfterFinished
+	if spec.TTLSecondsAfterFinished != nil {
+		resolved.TTLSecondsAfterFinished = *spec.TTLSecondsAfterFinished
+	}
+	source("ttlSecondsAfterFinished", spec.TTLSecondsAfterFinished != nil, false)
+
+	// Notifications and quarantine only come from policies
+	for _, field := range []string{"notifications", "quarantine"} {
+		if s, ok := policySources[field]; ok {
+			resolved.Sources[field] = s
+		}
+	}
+
+	return resolved
+}
+
+// ResolveVolumeScanParameters merges a VolumeScan spec with its policies and
+// the operator defaults, like ResolveScanParameters. The paths of the policies
+// are node paths and do not apply: the paths are relative to the volume root,
+// which is scanned whole by default.
+func ResolveVolumeScanParameters(spec *VolumeScanSpec, policies ScanPolicies) ResolvedScanParameters {
+	paths := spec.Paths
+	if len(paths) == 0 {
+		paths = []string{"/"}
+	}
+
+	resolved := ResolveScanParameters(&NodeScanSpec{
+		Paths:                   paths,
+		ScanPolicy:              spec.ScanPolicy,
+		ClusterScanPolicy:       spec.ClusterScanPolicy,
+		Priority:                spec.Priority,
+		MaxConcurrent:           spec.MaxConcurrent,
+		FileTimeout:             spec.FileTimeout,
+		MaxFileSize:             spec.MaxFileSize,
+		Resources:               spec.Resources,
+		TTLSecondsAfterFinished: spec.TTLSecondsAfterFinished,
+	}, policies)
+
+	for field, source := range resolved.Sources {
+		if source == ParameterSourceNodeScan {
+			resolved.Sources[field] = ParameterSourceVolumeScan
+		}
+	}
+	if len(spec.Paths) == 0 {
+		resolved.Sources["paths"] = ParameterSourceDefault
+	}
+
+	// Notifications and quarantine act on nodes
+	delete(resolved.Sources, "notifications")
+	delete(resolved.Sources, "quarantine")
+
+	return resolved
+}
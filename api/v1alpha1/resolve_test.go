@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestScanPolicies() ScanPolicies {
+	return ScanPolicies{
+		Cluster: &ClusterScanPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+			Spec: ScanPolicySpec{
+				Paths:          []string{"/host/var/lib", "/host/etc"},
+				MaxConcurrent:  4,
+				FileTimeout:    120000,
+				ConnectTimeout: 30000,
+				Quarantine:     &QuarantineConfig{Enabled: true, Action: QuarantineActionMove},
+			},
+		},
+		Namespace: &ScanPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "team-a"},
+			Spec: ScanPolicySpec{
+				MaxConcurrent: 8,
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			},
+		},
+	}
+}
+
+func TestScanPolicies_Merge(t *testing.T) {
+	merged, sources := newTestScanPolicies().Merge()
+	require.NotNil(t, merged)
+
+	assert.Equal(t, []string{"/host/var/lib", "/host/etc"}, merged.Paths)
+	assert.Equal(t, int32(8), merged.MaxConcurrent)
+	assert.Equal(t, int64(120000), merged.FileTimeout)
+	assert.NotNil(t, merged.Resources)
+	assert.NotNil(t, merged.Quarantine)
+
+	assert.Equal(t, "ClusterScanPolicy/baseline", sources["paths"])
+	assert.Equal(t, "ScanPolicy/team", sources["maxConcurrent"])
+	assert.Equal(t, "ScanPolicy/team", sources["resources"])
+	assert.Equal(t, "ClusterScanPolicy/baseline", sources["quarantine"])
+	assert.NotContains(t, sources, "maxFileSize")
+
+	merged, sources = ScanPolicies{}.Merge()
+	assert.Nil(t, merged)
+	assert.Empty(t, sources)
+}
+
+func TestResolveScanParameters(t *testing.T) {
+	spec := &NodeScanSpec{
+		NodeName:          "node-1",
+		ScanPolicy:        "team",
+		ClusterScanPolicy: "baseline",
+		FileTimeout:       60000,
+	}
+
+	params := ResolveScanParameters(spec, newTestScanPolicies())
+
+	assert.Equal(t, []string{"/host/var/lib", "/host/etc"}, params.Paths)
+	assert.Equal(t, int32(8), params.MaxConcurrent)
+	assert.Equal(t, int64(60000), params.FileTimeout)
+	assert.Equal(t, int64(DefaultMaxFileSize), params.MaxFileSize)
+	assert.Equal(t, int64(30000), params.ConnectTimeout)
+	assert.Equal(t, "2Gi", params.Resources.Limits.Memory().String())
+
+	assert.Equal(t, map[string]string{
+		"paths":                   "ClusterScanPolicy/baseline",
+		"maxConcurrent":           "ScanPolicy/team",
+		"fileTimeout":             ParameterSourceNodeScan,
+		"maxFileSize":             ParameterSourceDefault,
+		"connectTimeout":          "ClusterScanPolicy/baseline",
+		"resources":               "ScanPolicy/team",
+		"ttlSecondsAfterFinished": ParameterSourceDefault,
+		"quarantine":              "ClusterScanPolicy/baseline",
+	}, params.Sources)
+}
+
+func TestResolveScanParameters_Defaults(t *testing.T) {
+	params := ResolveScanParameters(&NodeScanSpec{NodeName: "node-1", Priority: "low"}, ScanPolicies{})
+
+	assert.Equal(t, DefaultScanPaths, params.Paths)
+	assert.Equal(t, int32(DefaultMaxConcurrent), params.MaxConcurrent)
+	assert.Equal(t, int64(DefaultConnectTimeout), params.ConnectTimeout)
+	assert.True(t, LowPriorityScannerResources.Limits.Cpu().Equal(*params.Resources.Limits.Cpu()))
+	for field, source := range params.Sources {
+		assert.Equal(t, ParameterSourceDefault, source, field)
+	}
+}
+
+func TestResolveVolumeScanParameters(t *testing.T) {
+	spec := &VolumeScanSpec{
+		PersistentVolumeClaim: "uploads",
+		ScanPolicy:            "team",
+		ClusterScanPolicy:     "baseline",
+		FileTimeout:           60000,
+	}
+
+	params := ResolveVolumeScanParameters(spec, newTestScanPolicies())
+
+	// The node paths of the policies do not apply to volumes
+	assert.Equal(t, []string{"/"}, params.Paths)
+	assert.Equal(t, int32(8), params.MaxConcurrent)
+	assert.Equal(t, int64(60000), params.FileTimeout)
+	assert.Equal(t, int64(30000), params.ConnectTimeout)
+
+	assert.Equal(t, map[string]string{
+		"paths":                   ParameterSourceDefault,
+		"maxConcurrent":           "ScanPolicy/team",
+		"fileTimeout":             ParameterSourceVolumeScan,
+		"maxFileSize":             ParameterSourceDefault,
+		"connectTimeout":          "ClusterScanPolicy/baseline",
+		"resources":               "ScanPolicy/team",
+		"ttlSecondsAfterFinished": ParameterSourceDefault,
+	}, params.Sources)
+
+	spec.Paths = []string{"/media"}
+	params = ResolveVolumeScanParameters(spec, newTestScanPolicies())
+	assert.Equal(t, []string{"/media"}, params.Paths)
+	assert.Equal(t, ParameterSourceVolumeScan, params.Sources["paths"])
+}
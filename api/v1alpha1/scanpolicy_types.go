@@ -21,35 +21,34 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// ScanPolicySpec defines the desired state of ScanPolicy
+// ScanPolicySpec defines the desired state of ScanPolicy and ClusterScanPolicy.
+// Fields left unset are inherited: NodeScan values override the ScanPolicy,
+// which overrides the ClusterScanPolicy, which overrides the operator defaults.
 type ScanPolicySpec struct {
 	// Paths to scan on each node
-	// +kubebuilder:validation:MinItems=1
-	Paths []string `json:"paths"`
+	// If not specified, inherited from the ClusterScanPolicy or the defaults
+	// +optional
+	Paths []string `json:"paths,omitempty"`
 
 	// ExcludePatterns are regex patterns for paths to exclude from scanning
 	// +optional
 	ExcludePatterns []string `json:"excludePatterns,omitempty"`
 
-	// MaxConcurrent files to scan in parallel
+	// MaxConcurrent files to scan in parallel (default 5)
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=20
-	// +kubebuilder:default=5
 	// +optional
 	MaxConcurrent int32 `json:"maxConcurrent,omitempty"`
 
-	// FileTimeout in milliseconds for scanning each file
-	// +kubebuilder:default=300000
+	// FileTimeout in milliseconds for scanning each file (default 300000)
 	// +optional
 	FileTimeout int64 `json:"fileTimeout,omitempty"`
 
-	// MaxFileSize in bytes - files larger than this will be skipped
-	// +kubebuilder:default=104857600
+	// MaxFileSize in bytes - files larger than this will be skipped (default 104857600)
 	// +optional
 	MaxFileSize int64 `json:"maxFileSize,omitempty"`
 
-	// ConnectTimeout in milliseconds for connecting to ClamAV
-	// +kubebuilder:default=60000
+	// ConnectTimeout in milliseconds for connecting to ClamAV (default 60000)
 	// +optional
 	ConnectTimeout int64 `json:"connectTimeout,omitempty"`
 
@@ -64,6 +63,28 @@ type ScanPolicySpec struct {
 	// Quarantine configuration
 	// +optional
 	Quarantine *QuarantineConfig `json:"quarantine,omitempty"`
+
+	// NodeReporting publishes the scan results on the scanned Node
+	// +optional
+	NodeReporting *NodeReportingConfig `json:"nodeReporting,omitempty"`
+
+	// ResponseActions isolates the scanned Node when a completed scan
+	// confirms an infection
+	// +optional
+	ResponseActions *ResponseActionsConfig `json:"responseActions,omitempty"`
+}
+
+// NodeReportingConfig publishes the scan results on the scanned Node so that
+// they show in kubectl describe node and other automation can react to them.
+// Events are recorded on the Node whether or not it is set.
+type NodeReportingConfig struct {
+	// Condition sets the MalwareDetected condition of the Node status
+	// +optional
+	Condition bool `json:"condition,omitempty"`
+
+	// Label sets the clamav.io/malware-detected=true label on infected Nodes
+	// +optional
+	Label bool `json:"label,omitempty"`
 }
 
 // NotificationConfig defines notification settings
@@ -79,6 +100,114 @@ type NotificationConfig struct {
 	// Webhook notification settings
 	// +optional
 	Webhook *WebhookConfig `json:"webhook,omitempty"`
+
+	// Teams notification settings
+	// +optional
+	Teams *TeamsConfig `json:"teams,omitempty"`
+
+	// PagerDuty notification settings
+	// +optional
+	PagerDuty *PagerDutyConfig `json:"pagerDuty,omitempty"`
+
+	// Opsgenie notification settings
+	// +optional
+	Opsgenie *OpsgenieConfig `json:"opsgenie,omitempty"`
+
+	// Retry configures how failed deliveries are retried before being
+	// dead-lettered
+	// +optional
+	Retry *NotificationRetryPolicy `json:"retry,omitempty"`
+
+	// Deduplication suppresses the notification of infections that were
+	// already notified recently
+	// +optional
+	Deduplication *NotificationDeduplication `json:"deduplication,omitempty"`
+
+	// RateLimits caps the number of notifications sent on each channel
+	// +optional
+	RateLimits []NotificationRateLimit `json:"rateLimits,omitempty"`
+}
+
+// NotificationTrigger is an event that sends a notification on a channel
+// +kubebuilder:validation:Enum=Infection;JobFailed;ParseFailed;ScanOverdue;PartiallyCompleted;ScanStarted;ScanCompleted
+type NotificationTrigger string
+
+const (
+	// NotificationTriggerInfection notifies when malware is detected
+	NotificationTriggerInfection NotificationTrigger = "Infection"
+	// NotificationTriggerJobFailed notifies when a scan could not run, e.g.
+	// because the scanner Job failed or the node does not exist
+	NotificationTriggerJobFailed NotificationTrigger = "JobFailed"
+	// NotificationTriggerParseFailed notifies when the results of a scan
+	// could not be read and the scan completed with partial results
+	NotificationTriggerParseFailed NotificationTrigger = "ParseFailed"
+	// NotificationTriggerScanOverdue notifies when a ScanSchedule missed runs
+	NotificationTriggerScanOverdue NotificationTrigger = "ScanOverdue"
+	// NotificationTriggerPartiallyCompleted notifies when a ClusterScan
+	// finished with failed nodes
+	NotificationTriggerPartiallyCompleted NotificationTrigger = "PartiallyCompleted"
+	// NotificationTriggerScanStarted notifies when the scanner Job of a
+	// NodeScan is created
+	NotificationTriggerScanStarted NotificationTrigger = "ScanStarted"
+	// NotificationTriggerScanCompleted notifies when a scan completed, with or
+	// without infections
+	NotificationTriggerScanCompleted NotificationTrigger = "ScanCompleted"
+)
+
+// NotificationRetryPolicy defines the retry behavior of a notification channel.
+// Each channel is retried independently with exponential backoff.
+type NotificationRetryPolicy struct {
+	// MaxAttempts is the number of delivery attempts before a notification is
+	// dead-lettered (default 5)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// InitialBackoffSeconds is the delay before the first retry, doubled after
+	// each failed attempt (default 30)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InitialBackoffSeconds int32 `json:"initialBackoffSeconds,omitempty"`
+
+	// MaxBackoffSeconds caps the delay between two attempts (default 900)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBackoffSeconds int32 `json:"maxBackoffSeconds,omitempty"`
+}
+
+// NotificationDeduplication suppresses repeated infection notifications. An
+// infection is identified by its node, path and signature; a scan is only
+// notified of infections if one of them was not notified within the window.
+// The notified infections are recorded in the clamav-notification-state
+// ConfigMap of the namespace.
+type NotificationDeduplication struct {
+	// Enabled indicates if infection notifications are deduplicated
+	Enabled bool `json:"enabled"`
+
+	// WindowSeconds is how long a notified infection is not notified again
+	// (default 86400)
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+}
+
+// NotificationRateLimit caps the notifications sent on a channel from a
+// namespace. Notifications over the limit stay pending until the window
+// allows them.
+type NotificationRateLimit struct {
+	// Channel is the rate limited channel
+	// +kubebuilder:validation:Enum=slack;email;webhook;teams;pagerduty;opsgenie
+	Channel string `json:"channel"`
+
+	// MaxNotifications is the number of notifications sent per window
+	// +kubebuilder:validation:Minimum=1
+	MaxNotifications int32 `json:"maxNotifications"`
+
+	// WindowSeconds is the length of the window (default 3600)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
 }
 
 // SlackConfig defines Slack notification settings
@@ -103,6 +232,15 @@ type SlackConfig struct {
 	// +kubebuilder:default=true
 	// +optional
 	OnlyOnInfection bool `json:"onlyOnInfection,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+
+	// Template renders the message text instead of the default message
+	// +optional
+	Template *NotificationTemplate `json:"template,omitempty"`
 }
 
 // EmailConfig defines email notification settings
@@ -118,6 +256,17 @@ type EmailConfig struct {
 	// +optional
 	SMTPAuthSecretRef *corev1.SecretReference `json:"smtpAuthSecretRef,omitempty"`
 
+	// TLSMode secures the connection to the SMTP server. When unset, implicit
+	// TLS is tried first and the connection falls back to STARTTLS if the
+	// server offers it.
+	// +optional
+	TLSMode EmailTLSMode `json:"tlsMode,omitempty"`
+
+	// CASecretRef references a Secret key holding the PEM encoded CA
+	// certificates trusted for the SMTP server, instead of the system roots
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
 	// From is the sender email address
 	From string `json:"from"`
 
@@ -129,6 +278,55 @@ type EmailConfig struct {
 	// +kubebuilder:default=true
 	// +optional
 	OnlyOnInfection bool `json:"onlyOnInfection,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+
+	// SubjectTemplate renders the subject instead of the default subject
+	// +optional
+	SubjectTemplate string `json:"subjectTemplate,omitempty"`
+
+	// Template renders the body instead of the default body
+	// +optional
+	Template *NotificationTemplate `json:"template,omitempty"`
+
+	// HTML sends an HTML version of the default body along with the plain
+	// text one. Templated bodies are sent as plain text only.
+	// +optional
+	HTML bool `json:"html,omitempty"`
+
+	// AttachInfectedFiles attaches the infected files of the scan, with their
+	// signatures, as infected-files.json
+	// +optional
+	AttachInfectedFiles bool `json:"attachInfectedFiles,omitempty"`
+}
+
+// EmailTLSMode selects how the connection to the SMTP server is secured
+// +kubebuilder:validation:Enum=None;StartTLS;TLS
+type EmailTLSMode string
+
+const (
+	// EmailTLSModeNone sends emails over an unencrypted connection
+	EmailTLSModeNone EmailTLSMode = "None"
+	// EmailTLSModeStartTLS upgrades the connection with STARTTLS, usually on
+	// port 587. Delivery fails if the server does not offer it.
+	EmailTLSModeStartTLS EmailTLSMode = "StartTLS"
+	// EmailTLSModeTLS connects with implicit TLS, usually on port 465
+	EmailTLSModeTLS EmailTLSMode = "TLS"
+)
+
+// NotificationTemplate is a Go text/template rendering a notification, set
+// inline or read from a ConfigMap in the namespace of the policy
+type NotificationTemplate struct {
+	// Inline is the template text
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapRef references a ConfigMap key holding the template text
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
 }
 
 // WebhookConfig defines webhook notification settings
@@ -144,10 +342,182 @@ type WebhookConfig struct {
 	// +optional
 	SecretRef *corev1.SecretReference `json:"secretRef,omitempty"`
 
+	// SigningSecretRef references a Secret key holding the HMAC-SHA256 key
+	// used to sign request bodies. Signed requests carry the
+	// X-ClamAV-Timestamp and X-ClamAV-Signature headers.
+	// +optional
+	SigningSecretRef *corev1.SecretKeySelector `json:"signingSecretRef,omitempty"`
+
+	// TLS configures the client certificate and the CA bundle used to
+	// connect to the webhook
+	// +optional
+	TLS *WebhookTLSConfig `json:"tls,omitempty"`
+
+	// Template renders the JSON body instead of the default payload. It
+	// cannot be used with the cloudevents format.
+	// +optional
+	Template *NotificationTemplate `json:"template,omitempty"`
+
+	// Format of the payloads: json for the operator payloads or cloudevents
+	// for CloudEvents 1.0
+	// +kubebuilder:validation:Enum=json;cloudevents
+	// +kubebuilder:default=json
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// CloudEventsMode selects the HTTP content mode of CloudEvents: structured
+	// sends the event as an application/cloudevents+json body, binary sends
+	// the data as body and the attributes as ce- headers
+	// +kubebuilder:validation:Enum=structured;binary
+	// +kubebuilder:default=structured
+	// +optional
+	CloudEventsMode string `json:"cloudEventsMode,omitempty"`
+
 	// OnlyOnInfection sends webhooks only when malware is detected
 	// +kubebuilder:default=true
 	// +optional
 	OnlyOnInfection bool `json:"onlyOnInfection,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+}
+
+// Webhook payload formats supported by WebhookConfig
+const (
+	// WebhookFormatJSON sends the operator JSON payloads
+	WebhookFormatJSON = "json"
+	// WebhookFormatCloudEvents sends CloudEvents 1.0
+	WebhookFormatCloudEvents = "cloudevents"
+)
+
+// CloudEvents HTTP content modes supported by WebhookConfig
+const (
+	// CloudEventsModeStructured sends the whole event as the request body
+	CloudEventsModeStructured = "structured"
+	// CloudEventsModeBinary sends the event data as the request body and the
+	// attributes as headers
+	CloudEventsModeBinary = "binary"
+)
+
+// WebhookTLSConfig defines the TLS settings of webhook requests
+type WebhookTLSConfig struct {
+	// CASecretRef references a Secret key holding the PEM encoded CA
+	// certificates trusted for the webhook server, instead of the system roots
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ClientCertSecretRef references a Secret containing the client
+	// certificate presented to the webhook server
+	// Expected keys: tls.crt, tls.key
+	// +optional
+	ClientCertSecretRef *corev1.SecretReference `json:"clientCertSecretRef,omitempty"`
+}
+
+// TeamsConfig defines Microsoft Teams notification settings. Messages are
+// posted as Adaptive Cards to an incoming webhook or a Workflows webhook.
+type TeamsConfig struct {
+	// Enabled indicates if Teams notifications are enabled
+	Enabled bool `json:"enabled"`
+
+	// WebhookURL is the Teams webhook URL
+	// Should be stored in a Secret and referenced
+	// +optional
+	WebhookURL string `json:"webhookURL,omitempty"`
+
+	// WebhookSecretRef references a Secret containing the webhook URL
+	// +optional
+	WebhookSecretRef *corev1.SecretKeySelector `json:"webhookSecretRef,omitempty"`
+
+	// OnlyOnInfection sends notifications only when malware is detected
+	// +kubebuilder:default=true
+	// +optional
+	OnlyOnInfection bool `json:"onlyOnInfection,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+}
+
+// PagerDutyConfig defines PagerDuty notification settings. Events are sent to
+// the Events API v2 with one dedup key per node and signature, so that
+// repeated detections update the same incident.
+type PagerDutyConfig struct {
+	// Enabled indicates if PagerDuty notifications are enabled
+	Enabled bool `json:"enabled"`
+
+	// RoutingKeySecretRef references a Secret containing the integration key
+	// of the PagerDuty service
+	RoutingKeySecretRef *corev1.SecretKeySelector `json:"routingKeySecretRef"`
+
+	// EventsURL overrides the Events API v2 endpoint
+	// (default https://events.pagerduty.com/v2/enqueue)
+	// +optional
+	EventsURL string `json:"eventsURL,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+}
+
+// OpsgenieConfig defines Opsgenie notification settings. Alerts use one alias
+// per node and signature, so that repeated detections are deduplicated.
+type OpsgenieConfig struct {
+	// Enabled indicates if Opsgenie notifications are enabled
+	Enabled bool `json:"enabled"`
+
+	// APIKeySecretRef references a Secret containing the API key of an
+	// Opsgenie API integration
+	APIKeySecretRef *corev1.SecretKeySelector `json:"apiKeySecretRef"`
+
+	// APIURL is the Opsgenie API address, e.g. https://api.eu.opsgenie.com
+	// for the EU instance (default https://api.opsgenie.com)
+	// +optional
+	APIURL string `json:"apiURL,omitempty"`
+
+	// Tags added to the alerts
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Triggers selects the events notified on this channel. Defaults to
+	// Infection.
+	// +optional
+	Triggers []NotificationTrigger `json:"triggers,omitempty"`
+}
+
+// ResponseActionsConfig isolates infected Nodes so that they stop receiving
+// new workloads. The applied actions are recorded in the status of the
+// NodeScan and undone by annotating it with clamav.io/release-isolation=true.
+type ResponseActionsConfig struct {
+	// Cordon marks the infected Node unschedulable
+	// +optional
+	Cordon bool `json:"cordon,omitempty"`
+
+	// TaintEffect adds the clamav.io/malware-detected taint with this effect
+	// to the infected Node
+	// +kubebuilder:validation:Enum=NoSchedule;PreferNoSchedule;NoExecute
+	// +optional
+	TaintEffect corev1.TaintEffect `json:"taintEffect,omitempty"`
+
+	// Drain cordons the infected Node and evicts its pods, except DaemonSet
+	// and static pods. Evictions respect PodDisruptionBudgets and are retried
+	// until every pod is gone.
+	// +optional
+	Drain bool `json:"drain,omitempty"`
+
+	// MinInfectedFiles is the number of infected files from which the Node is
+	// isolated (default 1). Files only matching allowed signatures do not count.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinInfectedFiles int32 `json:"minInfectedFiles,omitempty"`
+
+	// AllowedSignatures are regex patterns of signatures that never isolate a
+	// Node, e.g. ^PUA\. for potentially unwanted applications
+	// +optional
+	AllowedSignatures []string `json:"allowedSignatures,omitempty"`
 }
 
 // QuarantineConfig defines quarantine settings for infected files
@@ -171,6 +541,16 @@ type QuarantineConfig struct {
 	NotifyAdmin bool `json:"notifyAdmin,omitempty"`
 }
 
+// Quarantine actions supported by QuarantineConfig
+const (
+	// QuarantineActionMove moves infected files into QuarantineDir
+	QuarantineActionMove = "move"
+	// QuarantineActionDelete deletes infected files from the node
+	QuarantineActionDelete = "delete"
+	// QuarantineActionAlertOnly only reports infected files
+	QuarantineActionAlertOnly = "alert-only"
+)
+
 // ScanPolicyStatus defines the observed state of ScanPolicy
 type ScanPolicyStatus struct {
 	// LastUsed is the last time this policy was used for a scan
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var scanpolicylog = logf.Log.WithName("scanpolicy-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *ScanPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ScanPolicy{}).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-scanpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=scanpolicies,verbs=create;update,versions=v1alpha1,name=mscanpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &ScanPolicy{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (r *ScanPolicy) Default(ctx context.Context, obj runtime.Object) error {
+	scanPolicy, ok := obj.(*ScanPolicy)
+	if !ok {
+		return fmt.Errorf("expected a ScanPolicy but got %T", obj)
+	}
+	scanpolicylog.Info("default", "name", scanPolicy.Name)
+
+	defaultScanPolicySpec(&scanPolicy.Spec)
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-scanpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=scanpolicies,verbs=create;update,versions=v1alpha1,name=vscanpolicy.kb.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &ScanPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanPolicy) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	scanPolicy, ok := obj.(*ScanPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanPolicy but got %T", obj)
+	}
+	scanpolicylog.Info("validate create", "name", scanPolicy.Name)
+
+	allErrs := scanPolicy.validateScanPolicy()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanPolicy) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	scanPolicy, ok := newObj.(*ScanPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanPolicy but got %T", newObj)
+	}
+	scanpolicylog.Info("validate update", "name", scanPolicy.Name)
+
+	allErrs := scanPolicy.validateScanPolicy()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanPolicy) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	// No validation needed for delete
+	return nil, nil
+}
+
+// defaultScanPolicySpec applies defaults to a ScanPolicy or ClusterScanPolicy spec.
+// Scan parameters are left unset so they can be inherited, the operator
+// defaults are applied when a NodeScan is resolved.
+func defaultScanPolicySpec(spec *ScanPolicySpec) {
+	if spec.Quarantine != nil && spec.Quarantine.Action == "" {
+		spec.Quarantine.Action = QuarantineActionAlertOnly
+	}
+}
+
+// validateScanPolicy performs comprehensive validation of ScanPolicy spec
+func (r *ScanPolicy) validateScanPolicy() field.ErrorList {
+	return validateScanPolicySpec(&r.Spec, field.NewPath("spec"))
+}
+
+// validateScanPolicySpec validates a ScanPolicy or ClusterScanPolicy spec
+func validateScanPolicySpec(spec *ScanPolicySpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Validate paths
+	allErrs = append(allErrs, ValidatePaths(spec.Paths, specPath.Child("paths"))...)
+
+	// Validate exclude patterns
+	allErrs = append(allErrs, ValidateExcludePatterns(spec.ExcludePatterns, specPath.Child("excludePatterns"))...)
+
+	// Validate scan parameters
+	allErrs = append(allErrs, ValidateNodeScanConcurrent(spec.MaxConcurrent, specPath.Child("maxConcurrent"))...)
+	allErrs = append(allErrs, ValidateFileTimeout(spec.FileTimeout, specPath.Child("fileTimeout"))...)
+	allErrs = append(allErrs, ValidateMaxFileSize(spec.MaxFileSize, specPath.Child("maxFileSize"))...)
+	if spec.ConnectTimeout < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("connectTimeout"), spec.ConnectTimeout, "must be non-negative"))
+	}
+
+	// Validate resources if specified
+	if spec.Resources != nil {
+		allErrs = append(allErrs, validateResources(spec.Resources, specPath.Child("resources"))...)
+	}
+
+	// Validate notifications, quarantine and response actions
+	allErrs = append(allErrs, ValidateNotifications(spec.Notifications, specPath.Child("notifications"))...)
+	allErrs = append(allErrs, ValidateQuarantine(spec.Quarantine, specPath.Child("quarantine"))...)
+	allErrs = append(allErrs, ValidateResponseActions(spec.ResponseActions, specPath.Child("responseActions"))...)
+
+	return allErrs
+}
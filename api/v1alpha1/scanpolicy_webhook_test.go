@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanPolicy_Default(t *testing.T) {
+	scanPolicy := &ScanPolicy{Spec: ScanPolicySpec{
+		Paths:      []string{"/host/var/lib"},
+		Quarantine: &QuarantineConfig{Enabled: true},
+	}}
+
+	require.NoError(t, (&ScanPolicy{}).Default(context.Background(), scanPolicy))
+
+	// Scan parameters are left unset so they can be inherited from a ClusterScanPolicy
+	assert.Zero(t, scanPolicy.Spec.MaxConcurrent)
+	assert.Zero(t, scanPolicy.Spec.FileTimeout)
+	assert.Zero(t, scanPolicy.Spec.MaxFileSize)
+	assert.Zero(t, scanPolicy.Spec.ConnectTimeout)
+	assert.Equal(t, QuarantineActionAlertOnly, scanPolicy.Spec.Quarantine.Action)
+}
+
+func TestScanPolicy_ValidateCreate(t *testing.T) {
+	valid := &ScanPolicy{Spec: ScanPolicySpec{
+		Paths:           []string{"/host/var/lib"},
+		ExcludePatterns: []string{"*.log"},
+	}}
+	_, err := (&ScanPolicy{}).ValidateCreate(context.Background(), valid)
+	assert.NoError(t, err)
+
+	invalid := valid.DeepCopy()
+	invalid.Spec.ExcludePatterns = []string{"^/tmp/(.*$"}
+	invalid.Spec.Notifications = &NotificationConfig{Webhook: &WebhookConfig{URL: "http://example.com"}}
+	_, err = (&ScanPolicy{}).ValidateCreate(context.Background(), invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.excludePatterns[0]")
+	assert.Contains(t, err.Error(), "spec.notifications.webhook.url")
+}
+
+func TestScanSchedule_Default(t *testing.T) {
+	scanSchedule := &ScanSchedule{Spec: ScanScheduleSpec{Schedule: "0 2 * * *"}}
+
+	require.NoError(t, (&ScanSchedule{}).Default(context.Background(), scanSchedule))
+
+	assert.Equal(t, DefaultConcurrencyPolicy, scanSchedule.Spec.ConcurrencyPolicy)
+	require.NotNil(t, scanSchedule.Spec.SuccessfulScansHistoryLimit)
+	assert.Equal(t, int32(DefaultSuccessfulScansHistoryLimit), *scanSchedule.Spec.SuccessfulScansHistoryLimit)
+	require.NotNil(t, scanSchedule.Spec.FailedScansHistoryLimit)
+	assert.Equal(t, int32(DefaultFailedScansHistoryLimit), *scanSchedule.Spec.FailedScansHistoryLimit)
+}
+
+func TestScanSchedule_ValidateCreate(t *testing.T) {
+	valid := &ScanSchedule{Spec: ScanScheduleSpec{Schedule: "0 2 * * *"}}
+	_, err := (&ScanSchedule{}).ValidateCreate(context.Background(), valid)
+	assert.NoError(t, err)
+
+	invalid := valid.DeepCopy()
+	invalid.Spec.Schedule = "every night"
+	invalid.Spec.ClusterScan = &ClusterScanSpec{Concurrent: 100}
+	_, err = (&ScanSchedule{}).ValidateCreate(context.Background(), invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.schedule")
+	assert.Contains(t, err.Error(), "spec.clusterScan.concurrent")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScanReportSpec holds one chunk of the complete findings of a NodeScan
+type ScanReportSpec struct {
+	// NodeScan is the name of the NodeScan this report belongs to
+	// +kubebuilder:validation:Required
+	NodeScan string `json:"nodeScan"`
+
+	// NodeName is the node that was scanned
+	NodeName string `json:"nodeName"`
+
+	// Chunk is the index of this report among the reports of the NodeScan (0-based)
+	// +kubebuilder:validation:Minimum=0
+	Chunk int32 `json:"chunk"`
+
+	// TotalChunks is the number of reports holding the findings of the NodeScan
+	// +kubebuilder:validation:Minimum=1
+	TotalChunks int32 `json:"totalChunks"`
+
+	// CompletionTime is when the scan completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// InfectedFiles found by the scan
+	// +optional
+	InfectedFiles []InfectedFile `json:"infectedFiles,omitempty"`
+
+	// Errors are the files that could not be scanned
+	// +optional
+	Errors []ScanError `json:"errors,omitempty"`
+
+	// SkippedFiles are the files that were not scanned, with the reason
+	// +optional
+	SkippedFiles []SkippedFile `json:"skippedFiles,omitempty"`
+
+	// Truncated is true when the scanner could not publish every finding. The
+	// reports of the NodeScan then hold the first findings only.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Omitted counts the findings of the scan missing from all the reports of
+	// the NodeScan
+	// +optional
+	Omitted *OmittedFindings `json:"omitted,omitempty"`
+}
+
+// OmittedFindings counts the findings of a scan that are not listed in its reports
+type OmittedFindings struct {
+	// InfectedFiles is the number of infected files not listed
+	// +optional
+	InfectedFiles int64 `json:"infectedFiles,omitempty"`
+
+	// Errors is the number of scan errors not listed
+	// +optional
+	Errors int64 `json:"errors,omitempty"`
+
+	// SkippedFiles is the number of skipped files not listed
+	// +optional
+	SkippedFiles int64 `json:"skippedFiles,omitempty"`
+}
+
+// ScanError describes a file that could not be scanned
+type ScanError struct {
+	// Path to the file on the node
+	Path string `json:"path"`
+
+	// Error returned while scanning the file
+	Error string `json:"error"`
+}
+
+// SkippedFile describes a file that was not scanned
+type SkippedFile struct {
+	// Path to the file on the node
+	Path string `json:"path"`
+
+	// Reason the file was skipped (e.g. too_large, excluded, empty_file)
+	Reason string `json:"reason"`
+}
+
+// ScanReportReference links a NodeScan to the ScanReports holding its complete findings
+type ScanReportReference struct {
+	// Names of the ScanReport chunks, in order
+	Names []string `json:"names"`
+
+	// InfectedFiles is the number of infected files across all chunks
+	// +optional
+	InfectedFiles int64 `json:"infectedFiles,omitempty"`
+
+	// Errors is the number of scan errors across all chunks
+	// +optional
+	Errors int64 `json:"errors,omitempty"`
+
+	// SkippedFiles is the number of skipped files across all chunks
+	// +optional
+	SkippedFiles int64 `json:"skippedFiles,omitempty"`
+
+	// Truncated is true when the scanner could not publish every finding
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Omitted counts the findings of the scan missing from the reports
+	// +optional
+	Omitted *OmittedFindings `json:"omitted,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=sr;scanreport
+// +kubebuilder:printcolumn:name="NodeScan",type=string,JSONPath=`.spec.nodeScan`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+// +kubebuilder:printcolumn:name="Chunk",type=integer,JSONPath=`.spec.chunk`
+// +kubebuilder:printcolumn:name="Chunks",type=integer,JSONPath=`.spec.totalChunks`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ScanReport is the Schema for the scanreports API
+type ScanReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScanReportSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScanReportList contains a list of ScanReport
+type ScanReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScanReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScanReport{}, &ScanReportList{})
+}
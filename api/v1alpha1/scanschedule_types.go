@@ -28,9 +28,26 @@ type ScanScheduleSpec struct {
 	// +kubebuilder:validation:Required
 	Schedule string `json:"schedule"`
 
+	// TimeZone is the IANA time zone the schedule is evaluated in
+	// (e.g. "Europe/Paris"). Defaults to the time zone of the operator.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// MaintenanceWindows restricts when scheduled runs start. A run that falls
+	// outside the windows starts when the next window opens, within
+	// StartingDeadlineSeconds. Created ClusterScans inherit the windows unless
+	// their template defines its own. Windows default to TimeZone.
+	// +optional
+	MaintenanceWindows *MaintenanceWindows `json:"maintenanceWindows,omitempty"`
+
 	// ClusterScan template for scheduled scans
-	// +kubebuilder:validation:Required
-	ClusterScan ClusterScanSpec `json:"clusterScan"`
+	// +optional
+	ClusterScan *ClusterScanSpec `json:"clusterScan,omitempty"`
+
+	// VolumeScan template for scheduled scans of a PersistentVolumeClaim.
+	// When set, the schedule creates VolumeScans and ClusterScan must not be set.
+	// +optional
+	VolumeScan *VolumeScanSpec `json:"volumeScan,omitempty"`
 
 	// Suspend tells the controller to suspend subsequent executions
 	// Defaults to false
@@ -58,7 +75,9 @@ type ScanScheduleSpec struct {
 	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
 
 	// StartingDeadlineSeconds is optional deadline in seconds for starting
-	// the scan if it misses scheduled time for any reason
+	// the scan if it misses scheduled time for any reason. Missed runs older
+	// than the deadline are skipped and reported as missed.
+	// +kubebuilder:validation:Minimum=0
 	// +optional
 	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
 }
@@ -77,6 +96,11 @@ type ScanScheduleStatus struct {
 	// +optional
 	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
 
+	// LastMissedScheduleTime is the most recent schedule time that was missed,
+	// or the time the runs were skipped after too many were missed
+	// +optional
+	LastMissedScheduleTime *metav1.Time `json:"lastMissedScheduleTime,omitempty"`
+
 	// NextScheduleTime is the next time a scan is scheduled to run
 	// +optional
 	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
@@ -85,6 +109,14 @@ type ScanScheduleStatus struct {
 	// +optional
 	LastClusterScan string `json:"lastClusterScan,omitempty"`
 
+	// LastVolumeScan is the name of the last created VolumeScan
+	// +optional
+	LastVolumeScan string `json:"lastVolumeScan,omitempty"`
+
+	// Notifications tracks the delivery of ScanOverdue notifications
+	// +optional
+	Notifications []NotificationStatus `json:"notifications,omitempty"`
+
 	// Conditions represent the latest available observations
 	// +optional
 	// +patchMergeKey=type
@@ -96,6 +128,7 @@ type ScanScheduleStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=ss;scanschedule
 // +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="TimeZone",type=string,JSONPath=`.spec.timeZone`,priority=1
 // +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
 // +kubebuilder:printcolumn:name="Active",type=integer,JSONPath=`.status.active`
 // +kubebuilder:printcolumn:name="LastSchedule",type=date,JSONPath=`.status.lastScheduleTime`
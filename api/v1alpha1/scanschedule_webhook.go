@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var scanschedulelog = logf.Log.WithName("scanschedule-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *ScanSchedule) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ScanSchedule{}).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-scanschedule,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=scanschedules,verbs=create;update,versions=v1alpha1,name=mscanschedule.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &ScanSchedule{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (r *ScanSchedule) Default(ctx context.Context, obj runtime.Object) error {
+	scanSchedule, ok := obj.(*ScanSchedule)
+	if !ok {
+		return fmt.Errorf("expected a ScanSchedule but got %T", obj)
+	}
+	scanschedulelog.Info("default", "name", scanSchedule.Name)
+
+	spec := &scanSchedule.Spec
+	if spec.ConcurrencyPolicy == "" {
+		spec.ConcurrencyPolicy = DefaultConcurrencyPolicy
+	}
+	if spec.SuccessfulScansHistoryLimit == nil {
+		limit := int32(DefaultSuccessfulScansHistoryLimit)
+		spec.SuccessfulScansHistoryLimit = &limit
+	}
+	if spec.FailedScansHistoryLimit == nil {
+		limit := int32(DefaultFailedScansHistoryLimit)
+		spec.FailedScansHistoryLimit = &limit
+	}
+	if spec.VolumeScan != nil {
+		defaultVolumeScanSpec(spec.VolumeScan)
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-scanschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=scanschedules,verbs=create;update,versions=v1alpha1,name=vscanschedule.kb.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &ScanSchedule{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanSchedule) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	scanSchedule, ok := obj.(*ScanSchedule)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSchedule but got %T", obj)
+	}
+	scanschedulelog.Info("validate create", "name", scanSchedule.Name)
+
+	allErrs := scanSchedule.validateScanSchedule()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanSchedule) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	scanSchedule, ok := newObj.(*ScanSchedule)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSchedule but got %T", newObj)
+	}
+	scanschedulelog.Info("validate update", "name", scanSchedule.Name)
+
+	allErrs := scanSchedule.validateScanSchedule()
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ScanSchedule) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	// No validation needed for delete
+	return nil, nil
+}
+
+// validateScanSchedule performs validation of the ScanSchedule spec
+func (r *ScanSchedule) validateScanSchedule() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// Validate the cron expression and its time zone
+	allErrs = append(allErrs, ValidateSchedule(r.Spec.Schedule, r.Spec.TimeZone, specPath)...)
+
+	// Validate maintenance windows
+	allErrs = append(allErrs, ValidateMaintenanceWindows(r.Spec.MaintenanceWindows, specPath.Child("maintenanceWindows"))...)
+
+	// Validate the template of the scheduled scans
+	if r.Spec.VolumeScan != nil {
+		allErrs = append(allErrs, validateVolumeScanSpec(r.Spec.VolumeScan, specPath.Child("volumeScan"))...)
+		if r.Spec.ClusterScan != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("clusterScan"),
+				"must not be set together with volumeScan"))
+		}
+	} else if r.Spec.ClusterScan != nil {
+		allErrs = append(allErrs, validateClusterScanSpec(r.Spec.ClusterScan, specPath.Child("clusterScan"))...)
+	}
+
+	// Validate concurrency policy
+	switch r.Spec.ConcurrencyPolicy {
+	case "", "Allow", "Forbid", "Replace":
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("concurrencyPolicy"),
+			r.Spec.ConcurrencyPolicy, []string{"Allow", "Forbid", "Replace"}))
+	}
+
+	// Validate history limits and starting deadline
+	if r.Spec.SuccessfulScansHistoryLimit != nil && *r.Spec.SuccessfulScansHistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("successfulScansHistoryLimit"),
+			*r.Spec.SuccessfulScansHistoryLimit, "must be non-negative"))
+	}
+	if r.Spec.FailedScansHistoryLimit != nil && *r.Spec.FailedScansHistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("failedScansHistoryLimit"),
+			*r.Spec.FailedScansHistoryLimit, "must be non-negative"))
+	}
+	if r.Spec.StartingDeadlineSeconds != nil && *r.Spec.StartingDeadlineSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("startingDeadlineSeconds"),
+			*r.Spec.StartingDeadlineSeconds, "must be non-negative"))
+	}
+
+	return allErrs
+}
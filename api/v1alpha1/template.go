@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"strings"
+	"text/template"
+)
+
+// notificationTemplateFuncs are the functions available to notification
+// templates in addition to the text/template builtins
+var notificationTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	// toJSON quotes a value for JSON payloads
+	"toJSON": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"default": func(def, v interface{}) interface{} {
+		if s, ok := v.(string); v == nil || ok && s == "" {
+			return def
+		}
+		return v
+	},
+}
+
+// ParseNotificationTemplate parses the text of a notification template. The
+// webhook validates inline templates with it, the controllers parse both
+// inline and ConfigMap templates before rendering them.
+func ParseNotificationTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(notificationTemplateFuncs).Option("missingkey=error").Parse(text)
+}
@@ -18,11 +18,17 @@ package v1alpha1
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -269,6 +275,443 @@ func ValidateMaxFileSize(size int64, fldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// ValidateSchedule validates a cron schedule and the time zone it is evaluated in
+func ValidateSchedule(schedule, timeZone string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), schedule,
+			fmt.Sprintf("invalid cron schedule: %v", err)))
+	}
+
+	if timeZone == "" {
+		return allErrs
+	}
+
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), schedule,
+			"schedule cannot specify TZ or CRON_TZ when timeZone is set"))
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "Local" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), timeZone,
+			"must be a valid IANA time zone name (e.g. Europe/Paris)"))
+	}
+
+	return allErrs
+}
+
+// ValidateHTTPSURL validates that a notification endpoint is an absolute HTTPS URL
+func ValidateHTTPSURL(rawURL string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, rawURL, "must be an absolute URL"))
+		return allErrs
+	}
+	if u.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath, rawURL, "must use https"))
+	}
+
+	return allErrs
+}
+
+// ValidateNotificationTriggers validates the triggers of a notification channel
+func ValidateNotificationTriggers(triggers []NotificationTrigger, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	validTriggers := map[NotificationTrigger]bool{
+		NotificationTriggerInfection:          true,
+		NotificationTriggerJobFailed:          true,
+		NotificationTriggerParseFailed:        true,
+		NotificationTriggerScanOverdue:        true,
+		NotificationTriggerPartiallyCompleted: true,
+		NotificationTriggerScanStarted:        true,
+		NotificationTriggerScanCompleted:      true,
+	}
+	seen := map[NotificationTrigger]bool{}
+	for i, trigger := range triggers {
+		switch {
+		case !validTriggers[trigger]:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), trigger, []string{
+				string(NotificationTriggerInfection), string(NotificationTriggerJobFailed),
+				string(NotificationTriggerParseFailed), string(NotificationTriggerScanOverdue),
+				string(NotificationTriggerPartiallyCompleted), string(NotificationTriggerScanStarted),
+				string(NotificationTriggerScanCompleted),
+			}))
+		case seen[trigger]:
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), trigger))
+		}
+		seen[trigger] = true
+	}
+
+	return allErrs
+}
+
+// ValidateNotificationTemplate validates that a notification template has
+// exactly one source and that an inline template parses. ConfigMap templates
+// are parsed when they are rendered.
+func ValidateNotificationTemplate(tmpl *NotificationTemplate, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if tmpl == nil {
+		return allErrs
+	}
+
+	switch {
+	case tmpl.Inline == "" && tmpl.ConfigMapRef == nil:
+		allErrs = append(allErrs, field.Required(fldPath, "inline or configMapRef is required"))
+	case tmpl.Inline != "" && tmpl.ConfigMapRef != nil:
+		allErrs = append(allErrs, field.Invalid(fldPath, "inline, configMapRef", "inline and configMapRef are mutually exclusive"))
+	case tmpl.Inline != "":
+		if _, err := ParseNotificationTemplate(fldPath.String(), tmpl.Inline); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("inline"), tmpl.Inline, fmt.Sprintf("invalid template: %v", err)))
+		}
+	default:
+		if tmpl.ConfigMapRef.Name == "" || tmpl.ConfigMapRef.Key == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("configMapRef"), "name and key are required"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateNotifications validates a notification configuration
+func ValidateNotifications(notifications *NotificationConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if notifications == nil {
+		return allErrs
+	}
+
+	if slack := notifications.Slack; slack != nil && slack.Enabled {
+		slackPath := fldPath.Child("slack")
+		if slack.WebhookURL == "" && slack.WebhookSecretRef == nil {
+			allErrs = append(allErrs, field.Required(slackPath.Child("webhookURL"),
+				"webhookURL or webhookSecretRef is required when Slack notifications are enabled"))
+		}
+		if slack.WebhookURL != "" {
+			allErrs = append(allErrs, ValidateHTTPSURL(slack.WebhookURL, slackPath.Child("webhookURL"))...)
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(slack.Triggers, slackPath.Child("triggers"))...)
+		allErrs = append(allErrs, ValidateNotificationTemplate(slack.Template, slackPath.Child("template"))...)
+	}
+
+	if email := notifications.Email; email != nil && email.Enabled {
+		emailPath := fldPath.Child("email")
+		if _, port, err := net.SplitHostPort(email.SMTPServer); err != nil || port == "" {
+			allErrs = append(allErrs, field.Invalid(emailPath.Child("smtpServer"), email.SMTPServer,
+				"must be in host:port format"))
+		}
+		if _, err := mail.ParseAddress(email.From); err != nil {
+			allErrs = append(allErrs, field.Invalid(emailPath.Child("from"), email.From,
+				fmt.Sprintf("invalid email address: %v", err)))
+		}
+		if len(email.Recipients) == 0 {
+			allErrs = append(allErrs, field.Required(emailPath.Child("recipients"), "at least one recipient is required"))
+		}
+		for i, recipient := range email.Recipients {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				allErrs = append(allErrs, field.Invalid(emailPath.Child("recipients").Index(i), recipient,
+					fmt.Sprintf("invalid email address: %v", err)))
+			}
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(email.Triggers, emailPath.Child("triggers"))...)
+		if email.SubjectTemplate != "" {
+			if _, err := ParseNotificationTemplate("subjectTemplate", email.SubjectTemplate); err != nil {
+				allErrs = append(allErrs, field.Invalid(emailPath.Child("subjectTemplate"), email.SubjectTemplate,
+					fmt.Sprintf("invalid template: %v", err)))
+			}
+		}
+		allErrs = append(allErrs, ValidateNotificationTemplate(email.Template, emailPath.Child("template"))...)
+		if email.TLSMode == EmailTLSModeNone && email.SMTPAuthSecretRef != nil {
+			allErrs = append(allErrs, field.Invalid(emailPath.Child("tlsMode"), email.TLSMode,
+				"SMTP credentials cannot be sent over an unencrypted connection"))
+		}
+	}
+
+	if webhook := notifications.Webhook; webhook != nil {
+		webhookPath := fldPath.Child("webhook")
+		if webhook.URL == "" {
+			allErrs = append(allErrs, field.Required(webhookPath.Child("url"), "url is required"))
+		} else {
+			allErrs = append(allErrs, ValidateHTTPSURL(webhook.URL, webhookPath.Child("url"))...)
+		}
+		for name := range webhook.Headers {
+			if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " :\r\n") {
+				allErrs = append(allErrs, field.Invalid(webhookPath.Child("headers"), name, "invalid header name"))
+			}
+		}
+		switch webhook.Format {
+		case "", WebhookFormatJSON, WebhookFormatCloudEvents:
+		default:
+			allErrs = append(allErrs, field.NotSupported(webhookPath.Child("format"), webhook.Format,
+				[]string{WebhookFormatJSON, WebhookFormatCloudEvents}))
+		}
+		switch webhook.CloudEventsMode {
+		case "", CloudEventsModeStructured, CloudEventsModeBinary:
+		default:
+			allErrs = append(allErrs, field.NotSupported(webhookPath.Child("cloudEventsMode"), webhook.CloudEventsMode,
+				[]string{CloudEventsModeStructured, CloudEventsModeBinary}))
+		}
+		if webhook.SigningSecretRef != nil && webhook.SigningSecretRef.Name == "" {
+			allErrs = append(allErrs, field.Required(webhookPath.Child("signingSecretRef", "name"), "secret name is required"))
+		}
+		if tls := webhook.TLS; tls != nil {
+			tlsPath := webhookPath.Child("tls")
+			if tls.CASecretRef != nil && tls.CASecretRef.Name == "" {
+				allErrs = append(allErrs, field.Required(tlsPath.Child("caSecretRef", "name"), "secret name is required"))
+			}
+			if tls.ClientCertSecretRef != nil && tls.ClientCertSecretRef.Name == "" {
+				allErrs = append(allErrs, field.Required(tlsPath.Child("clientCertSecretRef", "name"), "secret name is required"))
+			}
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(webhook.Triggers, webhookPath.Child("triggers"))...)
+		if webhook.Template != nil && webhook.Format == WebhookFormatCloudEvents {
+			allErrs = append(allErrs, field.Forbidden(webhookPath.Child("template"),
+				"template cannot be used with the cloudevents format"))
+		}
+		allErrs = append(allErrs, ValidateNotificationTemplate(webhook.Template, webhookPath.Child("template"))...)
+	}
+
+	if teams := notifications.Teams; teams != nil && teams.Enabled {
+		teamsPath := fldPath.Child("teams")
+		if teams.WebhookURL == "" && teams.WebhookSecretRef == nil {
+			allErrs = append(allErrs, field.Required(teamsPath.Child("webhookURL"),
+				"webhookURL or webhookSecretRef is required when Teams notifications are enabled"))
+		}
+		if teams.WebhookURL != "" {
+			allErrs = append(allErrs, ValidateHTTPSURL(teams.WebhookURL, teamsPath.Child("webhookURL"))...)
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(teams.Triggers, teamsPath.Child("triggers"))...)
+	}
+
+	if pagerDuty := notifications.PagerDuty; pagerDuty != nil && pagerDuty.Enabled {
+		pagerDutyPath := fldPath.Child("pagerDuty")
+		if pagerDuty.RoutingKeySecretRef == nil {
+			allErrs = append(allErrs, field.Required(pagerDutyPath.Child("routingKeySecretRef"),
+				"routingKeySecretRef is required when PagerDuty notifications are enabled"))
+		}
+		if pagerDuty.EventsURL != "" {
+			allErrs = append(allErrs, ValidateHTTPSURL(pagerDuty.EventsURL, pagerDutyPath.Child("eventsURL"))...)
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(pagerDuty.Triggers, pagerDutyPath.Child("triggers"))...)
+	}
+
+	if opsgenie := notifications.Opsgenie; opsgenie != nil && opsgenie.Enabled {
+		opsgeniePath := fldPath.Child("opsgenie")
+		if opsgenie.APIKeySecretRef == nil {
+			allErrs = append(allErrs, field.Required(opsgeniePath.Child("apiKeySecretRef"),
+				"apiKeySecretRef is required when Opsgenie notifications are enabled"))
+		}
+		if opsgenie.APIURL != "" {
+			allErrs = append(allErrs, ValidateHTTPSURL(opsgenie.APIURL, opsgeniePath.Child("apiURL"))...)
+		}
+		allErrs = append(allErrs, ValidateNotificationTriggers(opsgenie.Triggers, opsgeniePath.Child("triggers"))...)
+	}
+
+	if retry := notifications.Retry; retry != nil {
+		retryPath := fldPath.Child("retry")
+		if retry.MaxAttempts < 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxAttempts"), retry.MaxAttempts, "must be non-negative"))
+		}
+		if retry.InitialBackoffSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("initialBackoffSeconds"), retry.InitialBackoffSeconds, "must be non-negative"))
+		}
+		if retry.MaxBackoffSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxBackoffSeconds"), retry.MaxBackoffSeconds, "must be non-negative"))
+		}
+		if retry.MaxBackoffSeconds > 0 && retry.MaxBackoffSeconds < retry.InitialBackoffSeconds {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxBackoffSeconds"), retry.MaxBackoffSeconds,
+				"must be greater than or equal to initialBackoffSeconds"))
+		}
+	}
+
+	if dedup := notifications.Deduplication; dedup != nil && dedup.WindowSeconds != 0 && dedup.WindowSeconds < 60 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("deduplication", "windowSeconds"), dedup.WindowSeconds,
+			"must be at least 60"))
+	}
+
+	channels := []string{
+		NotificationChannelSlack, NotificationChannelEmail, NotificationChannelWebhook,
+		NotificationChannelTeams, NotificationChannelPagerDuty, NotificationChannelOpsgenie,
+	}
+	limited := map[string]bool{}
+	for i, limit := range notifications.RateLimits {
+		limitPath := fldPath.Child("rateLimits").Index(i)
+		switch limit.Channel {
+		case NotificationChannelSlack, NotificationChannelEmail, NotificationChannelWebhook,
+			NotificationChannelTeams, NotificationChannelPagerDuty, NotificationChannelOpsgenie:
+			if limited[limit.Channel] {
+				allErrs = append(allErrs, field.Duplicate(limitPath.Child("channel"), limit.Channel))
+			}
+			limited[limit.Channel] = true
+		default:
+			allErrs = append(allErrs, field.NotSupported(limitPath.Child("channel"), limit.Channel, channels))
+		}
+		if limit.MaxNotifications < 1 {
+			allErrs = append(allErrs, field.Invalid(limitPath.Child("maxNotifications"), limit.MaxNotifications, "must be at least 1"))
+		}
+		if limit.WindowSeconds < 0 || limit.WindowSeconds > 86400 {
+			allErrs = append(allErrs, field.Invalid(limitPath.Child("windowSeconds"), limit.WindowSeconds,
+				"must be between 1 and 86400"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateQuarantine validates a quarantine configuration
+func ValidateQuarantine(quarantine *QuarantineConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if quarantine == nil {
+		return allErrs
+	}
+
+	switch quarantine.Action {
+	case QuarantineActionMove, QuarantineActionDelete, QuarantineActionAlertOnly:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("action"), quarantine.Action,
+			[]string{QuarantineActionMove, QuarantineActionDelete, QuarantineActionAlertOnly}))
+	}
+
+	if dir := quarantine.QuarantineDir; dir != "" {
+		dirPath := fldPath.Child("quarantineDir")
+		if !strings.HasPrefix(dir, "/") {
+			allErrs = append(allErrs, field.Invalid(dirPath, dir, "path must be absolute (start with /)"))
+		}
+		if strings.Contains(dir, "..") {
+			allErrs = append(allErrs, field.Invalid(dirPath, dir, "path cannot contain '..' (path traversal)"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateResponseActions validates the response actions of a policy
+func ValidateResponseActions(actions *ResponseActionsConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if actions == nil {
+		return allErrs
+	}
+
+	switch actions.TaintEffect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("taintEffect"), actions.TaintEffect,
+			[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+	}
+
+	if actions.MinInfectedFiles < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minInfectedFiles"), actions.MinInfectedFiles, "must be non-negative"))
+	}
+
+	for i, pattern := range actions.AllowedSignatures {
+		if _, err := regexp.Compile(pattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("allowedSignatures").Index(i), pattern,
+				fmt.Sprintf("invalid regex pattern: %v", err)))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateWorkloadTarget validates the workloads of a NodeScan, which replace
+// its paths
+func ValidateWorkloadTarget(target *WorkloadTarget, paths []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if target == nil {
+		return allErrs
+	}
+
+	if len(paths) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "workloads and paths are mutually exclusive"))
+	}
+
+	for i, namespace := range target.Namespaces {
+		if !isValidDNS1123Name(namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaces").Index(i), namespace,
+				"must be a valid namespace name"))
+		}
+	}
+
+	if target.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(target.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespaceSelector"), target.NamespaceSelector, err.Error()))
+		}
+	}
+	if target.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(target.PodSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("podSelector"), target.PodSelector, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// imageReferenceRegex matches an image reference: an optional registry host,
+// a repository path, an optional tag and an optional sha256 digest
+var imageReferenceRegex = regexp.MustCompile(`^((?:[a-zA-Z0-9-]+\.)*[a-zA-Z0-9-]+(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::\w[\w.-]{0,127})?(?:@sha256:[0-9a-f]{64})?$`)
+
+// ValidateImageReference validates the reference of a container image
+func ValidateImageReference(image string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(image) > 255 || !imageReferenceRegex.MatchString(image) {
+		allErrs = append(allErrs, field.Invalid(fldPath, image, "must be a valid image reference"))
+	}
+
+	return allErrs
+}
+
+// clockRegex matches a time of day in HH:MM format
+var clockRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// ValidateMaintenanceWindows validates maintenance windows
+func ValidateMaintenanceWindows(windows *MaintenanceWindows, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if windows == nil {
+		return allErrs
+	}
+
+	if windows.TimeZone != "" {
+		if _, err := time.LoadLocation(windows.TimeZone); err != nil || windows.TimeZone == "Local" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), windows.TimeZone,
+				"must be a valid IANA time zone name (e.g. Europe/Paris)"))
+		}
+	}
+
+	validateWindows := func(list []TimeWindow, listPath *field.Path) {
+		for i, w := range list {
+			windowPath := listPath.Index(i)
+			if !clockRegex.MatchString(w.Start) {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), w.Start, "must be in HH:MM format"))
+			}
+			if !clockRegex.MatchString(w.End) {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("end"), w.End, "must be in HH:MM format"))
+			}
+			for j, day := range w.Days {
+				switch day {
+				case "Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun":
+				default:
+					allErrs = append(allErrs, field.NotSupported(windowPath.Child("days").Index(j), day,
+						[]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}))
+				}
+			}
+		}
+	}
+	validateWindows(windows.Allowed, fldPath.Child("allowed"))
+	validateWindows(windows.Forbidden, fldPath.Child("forbidden"))
+
+	return allErrs
+}
+
 // isValidDNS1123Name checks if a string is a valid DNS-1123 subdomain name
 func isValidDNS1123Name(name string) bool {
 	if len(name) == 0 || len(name) > 253 {
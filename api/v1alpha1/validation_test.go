@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -437,3 +438,329 @@ func TestIsValidDNS1123Name(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    string
+		timeZone    string
+		expectError bool
+	}{
+		{name: "valid schedule", schedule: "0 2 * * *"},
+		{name: "valid schedule with time zone", schedule: "0 2 * * *", timeZone: "Europe/Paris"},
+		{name: "invalid schedule", schedule: "not a cron", expectError: true},
+		{name: "unknown time zone", schedule: "0 2 * * *", timeZone: "Mars/Olympus", expectError: true},
+		{name: "local time zone", schedule: "0 2 * * *", timeZone: "Local", expectError: true},
+		{name: "time zone in schedule", schedule: "CRON_TZ=UTC 0 2 * * *", timeZone: "Europe/Paris", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateSchedule(tt.schedule, tt.timeZone, field.NewPath("spec"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	tests := []struct {
+		name        string
+		windows     *MaintenanceWindows
+		expectError bool
+	}{
+		{name: "nil", windows: nil},
+		{name: "valid", windows: &MaintenanceWindows{
+			TimeZone:  "Europe/Paris",
+			Allowed:   []TimeWindow{{Days: []Weekday{"Mon", "Fri"}, Start: "22:00", End: "06:00"}},
+			Forbidden: []TimeWindow{{Start: "02:00", End: "03:00"}},
+		}},
+		{name: "invalid time zone", windows: &MaintenanceWindows{
+			TimeZone: "Mars/Olympus",
+			Allowed:  []TimeWindow{{Start: "22:00", End: "06:00"}},
+		}, expectError: true},
+		{name: "invalid start", windows: &MaintenanceWindows{
+			Allowed: []TimeWindow{{Start: "24:00", End: "06:00"}},
+		}, expectError: true},
+		{name: "invalid end", windows: &MaintenanceWindows{
+			Forbidden: []TimeWindow{{Start: "08:00", End: "6pm"}},
+		}, expectError: true},
+		{name: "invalid day", windows: &MaintenanceWindows{
+			Allowed: []TimeWindow{{Days: []Weekday{"Monday"}, Start: "22:00", End: "06:00"}},
+		}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateMaintenanceWindows(tt.windows, field.NewPath("spec").Child("maintenanceWindows"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidateNotifications(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications *NotificationConfig
+		expectError   bool
+	}{
+		{name: "nil", notifications: nil},
+		{name: "valid", notifications: &NotificationConfig{
+			Slack:   &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/services/T/B/X"},
+			Email:   &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com:587", From: "clamav@example.com", Recipients: []string{"sec@example.com"}},
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events", Headers: map[string]string{"X-Token": "t"}},
+		}},
+		{name: "disabled slack is not validated", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: false, WebhookURL: "http://insecure"},
+		}},
+		{name: "slack without url", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true},
+		}, expectError: true},
+		{name: "non-https webhook", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "http://siem.example.com/events"},
+		}, expectError: true},
+		{name: "relative webhook url", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "/events"},
+		}, expectError: true},
+		{name: "invalid header name", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events", Headers: map[string]string{"X Token": "t"}},
+		}, expectError: true},
+		{name: "bad recipient", notifications: &NotificationConfig{
+			Email: &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com:587", From: "clamav@example.com", Recipients: []string{"not-an-email"}},
+		}, expectError: true},
+		{name: "smtp server without port", notifications: &NotificationConfig{
+			Email: &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com", From: "clamav@example.com", Recipients: []string{"sec@example.com"}},
+		}, expectError: true},
+		{name: "valid retry policy", notifications: &NotificationConfig{
+			Retry: &NotificationRetryPolicy{MaxAttempts: 3, InitialBackoffSeconds: 10, MaxBackoffSeconds: 300},
+		}},
+		{name: "max backoff below initial backoff", notifications: &NotificationConfig{
+			Retry: &NotificationRetryPolicy{InitialBackoffSeconds: 600, MaxBackoffSeconds: 60},
+		}, expectError: true},
+		{name: "valid triggers", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events", Triggers: []NotificationTrigger{
+				NotificationTriggerInfection, NotificationTriggerJobFailed, NotificationTriggerScanOverdue,
+			}},
+		}},
+		{name: "unknown trigger", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/services/T/B/X",
+				Triggers: []NotificationTrigger{"NodeRebooted"}},
+		}, expectError: true},
+		{name: "duplicate trigger", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events", Triggers: []NotificationTrigger{
+				NotificationTriggerJobFailed, NotificationTriggerJobFailed,
+			}},
+		}, expectError: true},
+		{name: "valid incident channels", notifications: &NotificationConfig{
+			Teams: &TeamsConfig{Enabled: true, WebhookSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "teams"}, Key: "url"}},
+			PagerDuty: &PagerDutyConfig{Enabled: true, RoutingKeySecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "pagerduty"}, Key: "routingKey"}},
+			Opsgenie: &OpsgenieConfig{Enabled: true, APIURL: "https://api.eu.opsgenie.com", APIKeySecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "opsgenie"}, Key: "apiKey"}},
+		}},
+		{name: "signed webhook with client certificate", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events",
+				SigningSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-signing"}, Key: "key"},
+				TLS: &WebhookTLSConfig{
+					CASecretRef:         &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "siem-ca"}, Key: "ca.crt"},
+					ClientCertSecretRef: &corev1.SecretReference{Name: "siem-client"},
+				}},
+		}},
+		{name: "webhook client certificate without name", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events",
+				TLS: &WebhookTLSConfig{ClientCertSecretRef: &corev1.SecretReference{}}},
+		}, expectError: true},
+		{name: "cloudevents webhook", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://events.example.com", Format: WebhookFormatCloudEvents,
+				CloudEventsMode: CloudEventsModeBinary,
+				Triggers:        []NotificationTrigger{NotificationTriggerScanStarted, NotificationTriggerScanCompleted}},
+		}},
+		{name: "unknown webhook format", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://events.example.com", Format: "xml"},
+		}, expectError: true},
+		{name: "inline templates", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/x",
+				Template: &NotificationTemplate{Inline: `{{ .Title }} sur {{ .Fields.node | upper }}`}},
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events",
+				Template: &NotificationTemplate{Inline: `{"event": {{ toJSON .Event }}, "team": "secops"}`}},
+		}},
+		{name: "configmap template", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/x",
+				Template: &NotificationTemplate{ConfigMapRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "slack.tmpl"}}},
+		}},
+		{name: "template that does not parse", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/x",
+				Template: &NotificationTemplate{Inline: `{{ .Title `}},
+		}, expectError: true},
+		{name: "template with unknown function", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://siem.example.com/events",
+				Template: &NotificationTemplate{Inline: `{{ sha256 .Title }}`}},
+		}, expectError: true},
+		{name: "empty template", notifications: &NotificationConfig{
+			Slack: &SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/x", Template: &NotificationTemplate{}},
+		}, expectError: true},
+		{name: "invalid email subject template", notifications: &NotificationConfig{
+			Email: &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com:587", From: "clamav@example.com",
+				Recipients: []string{"secops@example.com"}, SubjectTemplate: "{{ end }}"},
+		}, expectError: true},
+		{name: "template with cloudevents", notifications: &NotificationConfig{
+			Webhook: &WebhookConfig{URL: "https://events.example.com", Format: WebhookFormatCloudEvents,
+				Template: &NotificationTemplate{Inline: `{}`}},
+		}, expectError: true},
+		{name: "deduplication and rate limits", notifications: &NotificationConfig{
+			Webhook:       &WebhookConfig{URL: "https://siem.example.com/events"},
+			Deduplication: &NotificationDeduplication{Enabled: true, WindowSeconds: 7 * 86400},
+			RateLimits: []NotificationRateLimit{
+				{Channel: NotificationChannelWebhook, MaxNotifications: 10},
+				{Channel: NotificationChannelPagerDuty, MaxNotifications: 5, WindowSeconds: 600},
+			},
+		}},
+		{name: "deduplication window too short", notifications: &NotificationConfig{
+			Deduplication: &NotificationDeduplication{Enabled: true, WindowSeconds: 10},
+		}, expectError: true},
+		{name: "rate limit of unknown channel", notifications: &NotificationConfig{
+			RateLimits: []NotificationRateLimit{{Channel: "sms", MaxNotifications: 1}},
+		}, expectError: true},
+		{name: "duplicate rate limit", notifications: &NotificationConfig{
+			RateLimits: []NotificationRateLimit{
+				{Channel: NotificationChannelSlack, MaxNotifications: 1},
+				{Channel: NotificationChannelSlack, MaxNotifications: 2},
+			},
+		}, expectError: true},
+		{name: "email with implicit TLS and attachments", notifications: &NotificationConfig{
+			Email: &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com:465", From: "clamav@example.com",
+				Recipients: []string{"secops@example.com"}, TLSMode: EmailTLSModeTLS, HTML: true, AttachInfectedFiles: true,
+				SMTPAuthSecretRef: &corev1.SecretReference{Name: "smtp"}},
+		}},
+		{name: "email credentials without TLS", notifications: &NotificationConfig{
+			Email: &EmailConfig{Enabled: true, SMTPServer: "smtp.example.com:25", From: "clamav@example.com",
+				Recipients: []string{"secops@example.com"}, TLSMode: EmailTLSModeNone,
+				SMTPAuthSecretRef: &corev1.SecretReference{Name: "smtp"}},
+		}, expectError: true},
+		{name: "rate limit without notifications", notifications: &NotificationConfig{
+			RateLimits: []NotificationRateLimit{{Channel: NotificationChannelSlack}},
+		}, expectError: true},
+		{name: "teams without url", notifications: &NotificationConfig{
+			Teams: &TeamsConfig{Enabled: true},
+		}, expectError: true},
+		{name: "pagerduty without routing key", notifications: &NotificationConfig{
+			PagerDuty: &PagerDutyConfig{Enabled: true},
+		}, expectError: true},
+		{name: "non-https opsgenie api url", notifications: &NotificationConfig{
+			Opsgenie: &OpsgenieConfig{Enabled: true, APIURL: "http://api.opsgenie.com", APIKeySecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "opsgenie"}, Key: "apiKey"}},
+		}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateNotifications(tt.notifications, field.NewPath("spec").Child("notifications"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidateQuarantine(t *testing.T) {
+	tests := []struct {
+		name        string
+		quarantine  *QuarantineConfig
+		expectError bool
+	}{
+		{name: "nil", quarantine: nil},
+		{name: "move", quarantine: &QuarantineConfig{Enabled: true, Action: QuarantineActionMove, QuarantineDir: "/var/lib/quarantine"}},
+		{name: "unknown action", quarantine: &QuarantineConfig{Enabled: true, Action: "shred"}, expectError: true},
+		{name: "relative dir", quarantine: &QuarantineConfig{Enabled: true, Action: QuarantineActionMove, QuarantineDir: "quarantine"}, expectError: true},
+		{name: "dir with traversal", quarantine: &QuarantineConfig{Enabled: true, Action: QuarantineActionMove, QuarantineDir: "/var/../etc"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateQuarantine(tt.quarantine, field.NewPath("spec").Child("quarantine"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidateResponseActions(t *testing.T) {
+	tests := []struct {
+		name        string
+		actions     *ResponseActionsConfig
+		expectError bool
+	}{
+		{name: "nil", actions: nil},
+		{name: "cordon and taint", actions: &ResponseActionsConfig{Cordon: true, TaintEffect: corev1.TaintEffectNoExecute, MinInfectedFiles: 2}},
+		{name: "allowed signatures", actions: &ResponseActionsConfig{Drain: true, AllowedSignatures: []string{`^PUA\.`, "Eicar"}}},
+		{name: "unknown taint effect", actions: &ResponseActionsConfig{TaintEffect: "Evict"}, expectError: true},
+		{name: "negative threshold", actions: &ResponseActionsConfig{Cordon: true, MinInfectedFiles: -1}, expectError: true},
+		{name: "invalid signature pattern", actions: &ResponseActionsConfig{Cordon: true, AllowedSignatures: []string{"PUA.("}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateResponseActions(tt.actions, field.NewPath("spec").Child("responseActions"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestValidateWorkloadTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      *WorkloadTarget
+		paths       []string
+		expectError bool
+	}{
+		{name: "nil", target: nil, paths: []string{"/host/var/lib"}},
+		{name: "all pods", target: &WorkloadTarget{}},
+		{name: "namespaces and selectors", target: &WorkloadTarget{
+			Namespaces:        []string{"shop"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+			PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web", "api"}},
+			}},
+		}},
+		{name: "combined with paths", target: &WorkloadTarget{}, paths: []string{"/host/var/lib"}, expectError: true},
+		{name: "invalid namespace", target: &WorkloadTarget{Namespaces: []string{"Shop_1"}}, expectError: true},
+		{name: "invalid pod selector", target: &WorkloadTarget{PodSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Matches"}},
+		}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateWorkloadTarget(tt.target, tt.paths, field.NewPath("spec").Child("workloads"))
+
+			if tt.expectError {
+				assert.NotEmpty(t, errs)
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
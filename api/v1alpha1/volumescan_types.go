@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeSnapshotMode tells whether a VolumeScan scans a snapshot of the volume
+// +kubebuilder:validation:Enum=Auto;Always;Never
+type VolumeSnapshotMode string
+
+const (
+	// VolumeSnapshotAuto scans a snapshot when a VolumeSnapshotClass exists
+	// for the CSI driver of the volume, and the volume itself otherwise
+	VolumeSnapshotAuto VolumeSnapshotMode = "Auto"
+	// VolumeSnapshotAlways scans a snapshot and fails if none can be taken
+	VolumeSnapshotAlways VolumeSnapshotMode = "Always"
+	// VolumeSnapshotNever scans the volume itself
+	VolumeSnapshotNever VolumeSnapshotMode = "Never"
+)
+
+// VolumeScanSpec defines the desired state of VolumeScan
+type VolumeScanSpec struct {
+	// PersistentVolumeClaim is the name of the claim to scan, in the
+	// namespace of the VolumeScan
+	// +kubebuilder:validation:Required
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// Snapshot tells whether the scan reads a snapshot of the volume, which
+	// is restored to a temporary claim, or mounts the claim itself read-only
+	// +kubebuilder:default=Auto
+	// +optional
+	Snapshot VolumeSnapshotMode `json:"snapshot,omitempty"`
+
+	// VolumeSnapshotClassName is the class of the snapshot. Defaults to a
+	// class of the CSI driver of the volume, preferring the default class.
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Paths to scan, relative to the root of the volume
+	// If not specified, the whole volume is scanned
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// ScanPolicy is the name of the ScanPolicy to use. Only its scan
+	// parameters apply: its paths, notifications and remediation are node
+	// scan settings.
+	// +optional
+	ScanPolicy string `json:"scanPolicy,omitempty"`
+
+	// ClusterScanPolicy is the name of the ClusterScanPolicy to use. The
+	// ScanPolicy takes precedence over it.
+	// +optional
+	ClusterScanPolicy string `json:"clusterScanPolicy,omitempty"`
+
+	// Priority of the scan (high, medium, low)
+	// +kubebuilder:validation:Enum=high;medium;low
+	// +kubebuilder:default=medium
+	// +optional
+	Priority string `json:"priority,omitempty"`
+
+	// MaxConcurrent is the maximum number of files to scan in parallel
+	// Defaults to the policies, then to 5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	// +optional
+	MaxConcurrent int32 `json:"maxConcurrent,omitempty"`
+
+	// FileTimeout in milliseconds
+	// Defaults to the policies, then to 300000
+	// +optional
+	FileTimeout int64 `json:"fileTimeout,omitempty"`
+
+	// MaxFileSize in bytes - files larger than this will be skipped
+	// Defaults to the policies, then to 104857600
+	// +optional
+	MaxFileSize int64 `json:"maxFileSize,omitempty"`
+
+	// Resources for the scan job
+	// If not specified, uses the policies, then the defaults of the priority
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of the scan Job once it
+	// finished. Defaults to 86400.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// VolumeScanStatus defines the observed state of VolumeScan
+type VolumeScanStatus struct {
+	// Phase of the scan
+	// +optional
+	Phase NodeScanPhase `json:"phase,omitempty"`
+
+	// StartTime of the scan
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime of the scan
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Duration of the scan in seconds
+	// +optional
+	Duration int64 `json:"duration,omitempty"`
+
+	// FilesScanned is the total number of files scanned
+	// +optional
+	FilesScanned int64 `json:"filesScanned,omitempty"`
+
+	// FilesInfected is the number of infected files found
+	// +optional
+	FilesInfected int64 `json:"filesInfected,omitempty"`
+
+	// FilesSkipped is the number of files skipped
+	// +optional
+	FilesSkipped int64 `json:"filesSkipped,omitempty"`
+
+	// ErrorCount is the number of errors encountered during scan
+	// +optional
+	ErrorCount int64 `json:"errorCount,omitempty"`
+
+	// InfectedFiles contains details of infected files, with paths relative
+	// to the root of the volume
+	// Limited to first 100 for performance
+	// +optional
+	InfectedFiles []InfectedFile `json:"infectedFiles,omitempty"`
+
+	// JobRef is a reference to the created Job
+	// +optional
+	JobRef *corev1.ObjectReference `json:"jobRef,omitempty"`
+
+	// NodeName is the node the volume was scanned on
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// SnapshotName is the VolumeSnapshot taken for the scan
+	// +optional
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// ClaimName is the claim mounted by the scan Job: the scanned claim, or
+	// the temporary claim restored from the snapshot
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// ParameterSources reports where each effective scan parameter comes from:
+	// VolumeScan, ScanPolicy/<name>, ClusterScanPolicy/<name> or Default
+	// +optional
+	ParameterSources map[string]string `json:"parameterSources,omitempty"`
+
+	// Conditions represent the latest available observations of the VolumeScan's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=vscan;volumescan
+// +kubebuilder:printcolumn:name="Claim",type=string,JSONPath=`.spec.persistentVolumeClaim`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.status.snapshotName`,priority=1
+// +kubebuilder:printcolumn:name="Scanned",type=integer,JSONPath=`.status.filesScanned`
+// +kubebuilder:printcolumn:name="Infected",type=integer,JSONPath=`.status.filesInfected`
+// +kubebuilder:printcolumn:name="Duration",type=integer,JSONPath=`.status.duration`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VolumeScan is the Schema for the volumescans API
+type VolumeScan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeScanSpec   `json:"spec,omitempty"`
+	Status VolumeScanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VolumeScanList contains a list of VolumeScan
+type VolumeScanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VolumeScan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VolumeScan{}, &VolumeScanList{})
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var volumescanlog = logf.Log.WithName("volumescan-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *VolumeScan) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&VolumeScan{}).
+		WithDefaulter(r).
+		WithValidator(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-clamav-io-v1alpha1-volumescan,mutating=true,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=volumescans,verbs=create;update,versions=v1alpha1,name=mvolumescan.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &VolumeScan{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type
+func (r *VolumeScan) Default(ctx context.Context, obj runtime.Object) error {
+	volumeScan, ok := obj.(*VolumeScan)
+	if !ok {
+		return fmt.Errorf("expected a VolumeScan but got %T", obj)
+	}
+	volumescanlog.Info("default", "name", volumeScan.Name)
+
+	defaultVolumeScanSpec(&volumeScan.Spec)
+
+	return nil
+}
+
+// defaultVolumeScanSpec applies the defaults of a VolumeScan spec. The scan
+// parameters are left to the policies, which are resolved by the controller.
+func defaultVolumeScanSpec(spec *VolumeScanSpec) {
+	if spec.Snapshot == "" {
+		spec.Snapshot = VolumeSnapshotAuto
+	}
+	if spec.Priority == "" {
+		spec.Priority = "medium"
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-clamav-io-v1alpha1-volumescan,mutating=false,failurePolicy=fail,sideEffects=None,groups=clamav.io,resources=volumescans,verbs=create;update,versions=v1alpha1,name=vvolumescan.kb.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &VolumeScan{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *VolumeScan) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	volumeScan, ok := obj.(*VolumeScan)
+	if !ok {
+		return nil, fmt.Errorf("expected a VolumeScan but got %T", obj)
+	}
+	volumescanlog.Info("validate create", "name", volumeScan.Name)
+
+	allErrs := validateVolumeScanSpec(&volumeScan.Spec, field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *VolumeScan) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	volumeScan, ok := newObj.(*VolumeScan)
+	if !ok {
+		return nil, fmt.Errorf("expected a VolumeScan but got %T", newObj)
+	}
+	volumescanlog.Info("validate update", "name", volumeScan.Name)
+
+	allErrs := validateVolumeScanSpec(&volumeScan.Spec, field.NewPath("spec"))
+
+	if len(allErrs) > 0 {
+		return nil, allErrs.ToAggregate()
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *VolumeScan) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	// No validation needed for delete
+	return nil, nil
+}
+
+// validateVolumeScanSpec validates a VolumeScan spec, also used as the
+// template of ScanSchedules
+func validateVolumeScanSpec(spec *VolumeScanSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.PersistentVolumeClaim == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("persistentVolumeClaim"), "claim name is required"))
+	} else if !isValidDNS1123Name(spec.PersistentVolumeClaim) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("persistentVolumeClaim"),
+			spec.PersistentVolumeClaim, "must be a valid claim name"))
+	}
+
+	switch spec.Snapshot {
+	case "", VolumeSnapshotAuto, VolumeSnapshotAlways, VolumeSnapshotNever:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("snapshot"), spec.Snapshot,
+			[]string{string(VolumeSnapshotAuto), string(VolumeSnapshotAlways), string(VolumeSnapshotNever)}))
+	}
+
+	if spec.VolumeSnapshotClassName != "" {
+		if spec.Snapshot == VolumeSnapshotNever {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("volumeSnapshotClassName"),
+				"the volume is not snapshotted when snapshot is Never"))
+		} else if !isValidDNS1123Name(spec.VolumeSnapshotClassName) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("volumeSnapshotClassName"),
+				spec.VolumeSnapshotClassName, "must be a valid class name"))
+		}
+	}
+
+	// Paths are relative to the volume root but written as absolute paths
+	if len(spec.Paths) > 0 {
+		allErrs = append(allErrs, ValidatePaths(spec.Paths, specPath.Child("paths"))...)
+	}
+
+	allErrs = append(allErrs, ValidatePriority(spec.Priority, specPath.Child("priority"))...)
+	allErrs = append(allErrs, ValidateNodeScanConcurrent(spec.MaxConcurrent, specPath.Child("maxConcurrent"))...)
+	allErrs = append(allErrs, ValidateFileTimeout(spec.FileTimeout, specPath.Child("fileTimeout"))...)
+	allErrs = append(allErrs, ValidateMaxFileSize(spec.MaxFileSize, specPath.Child("maxFileSize"))...)
+
+	if spec.Resources != nil {
+		allErrs = append(allErrs, validateResources(spec.Resources, specPath.Child("resources"))...)
+	}
+
+	if spec.TTLSecondsAfterFinished != nil && *spec.TTLSecondsAfterFinished < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("ttlSecondsAfterFinished"),
+			*spec.TTLSecondsAfterFinished, "must be non-negative"))
+	}
+
+	return allErrs
+}
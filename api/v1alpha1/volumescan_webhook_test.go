@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeScan_Default(t *testing.T) {
+	volumeScan := &VolumeScan{Spec: VolumeScanSpec{PersistentVolumeClaim: "uploads"}}
+
+	require.NoError(t, (&VolumeScan{}).Default(context.Background(), volumeScan))
+
+	assert.Equal(t, VolumeSnapshotAuto, volumeScan.Spec.Snapshot)
+	assert.Equal(t, "medium", volumeScan.Spec.Priority)
+	assert.Zero(t, volumeScan.Spec.MaxConcurrent, "scan parameters are resolved from the policies")
+}
+
+func TestVolumeScan_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    VolumeScanSpec
+		wantErr string
+	}{
+		{name: "claim", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads"}},
+		{name: "snapshot class and paths", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads",
+			Snapshot: VolumeSnapshotAlways, VolumeSnapshotClassName: "csi-snapclass", Paths: []string{"/media"}}},
+		{name: "missing claim", spec: VolumeScanSpec{}, wantErr: "spec.persistentVolumeClaim"},
+		{name: "invalid claim", spec: VolumeScanSpec{PersistentVolumeClaim: "Uploads"}, wantErr: "spec.persistentVolumeClaim"},
+		{name: "invalid snapshot mode", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads", Snapshot: "Sometimes"},
+			wantErr: "spec.snapshot"},
+		{name: "class without snapshot", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads",
+			Snapshot: VolumeSnapshotNever, VolumeSnapshotClassName: "csi-snapclass"}, wantErr: "spec.volumeSnapshotClassName"},
+		{name: "relative path", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads", Paths: []string{"media"}},
+			wantErr: "spec.paths[0]"},
+		{name: "path traversal", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads", Paths: []string{"/../etc"}},
+			wantErr: "spec.paths[0]"},
+		{name: "maxConcurrent out of range", spec: VolumeScanSpec{PersistentVolumeClaim: "uploads", MaxConcurrent: 50},
+			wantErr: "spec.maxConcurrent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&VolumeScan{}).ValidateCreate(context.Background(), &VolumeScan{Spec: tt.spec})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestScanSchedule_VolumeScanTemplate(t *testing.T) {
+	scanSchedule := &ScanSchedule{Spec: ScanScheduleSpec{
+		Schedule:   "0 2 * * *",
+		VolumeScan: &VolumeScanSpec{PersistentVolumeClaim: "uploads"},
+	}}
+
+	require.NoError(t, (&ScanSchedule{}).Default(context.Background(), scanSchedule))
+	assert.Equal(t, VolumeSnapshotAuto, scanSchedule.Spec.VolumeScan.Snapshot)
+
+	_, err := (&ScanSchedule{}).ValidateCreate(context.Background(), scanSchedule)
+	assert.NoError(t, err)
+
+	invalid := scanSchedule.DeepCopy()
+	invalid.Spec.VolumeScan.PersistentVolumeClaim = ""
+	_, err = (&ScanSchedule{}).ValidateCreate(context.Background(), invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.volumeScan.persistentVolumeClaim")
+
+	// Any ClusterScan template conflicts, even one holding only the defaults
+	both := scanSchedule.DeepCopy()
+	both.Spec.ClusterScan = &ClusterScanSpec{Concurrent: 3, Priority: "medium"}
+	_, err = (&ScanSchedule{}).ValidateCreate(context.Background(), both)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.clusterScan: Forbidden")
+}
@@ -85,6 +85,81 @@ func (in *ClusterScanList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScanNotifications) DeepCopyInto(out *ClusterScanNotifications) {
+	*out = *in
+	in.NotificationConfig.DeepCopyInto(&out.NotificationConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScanNotifications.
+func (in *ClusterScanNotifications) DeepCopy() *ClusterScanNotifications {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScanNotifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScanPolicy) DeepCopyInto(out *ClusterScanPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScanPolicy.
+func (in *ClusterScanPolicy) DeepCopy() *ClusterScanPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScanPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterScanPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScanPolicyList) DeepCopyInto(out *ClusterScanPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterScanPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScanPolicyList.
+func (in *ClusterScanPolicyList) DeepCopy() *ClusterScanPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScanPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterScanPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterScanSpec) DeepCopyInto(out *ClusterScanSpec) {
 	*out = *in
@@ -98,6 +173,16 @@ func (in *ClusterScanSpec) DeepCopyInto(out *ClusterScanSpec) {
 		*out = new(NodeScanSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = new(MaintenanceWindows)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(ClusterScanNotifications)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScanSpec.
@@ -121,6 +206,10 @@ func (in *ClusterScanStatus) DeepCopyInto(out *ClusterScanStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextWindowTime != nil {
+		in, out := &in.NextWindowTime, &out.NextWindowTime
+		*out = (*in).DeepCopy()
+	}
 	if in.NodeScans != nil {
 		in, out := &in.NodeScans, &out.NodeScans
 		*out = make([]NodeScanReference, len(*in))
@@ -128,6 +217,13 @@ func (in *ClusterScanStatus) DeepCopyInto(out *ClusterScanStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -155,11 +251,26 @@ func (in *EmailConfig) DeepCopyInto(out *EmailConfig) {
 		*out = new(corev1.SecretReference)
 		**out = **in
 	}
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Recipients != nil {
 		in, out := &in.Recipients, &out.Recipients
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(NotificationTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailConfig.
@@ -173,58 +284,73 @@ func (in *EmailConfig) DeepCopy() *EmailConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FileMetadata) DeepCopyInto(out *FileMetadata) {
+func (in *FileAttribution) DeepCopyInto(out *FileAttribution) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileMetadata.
-func (in *FileMetadata) DeepCopy() *FileMetadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileAttribution.
+func (in *FileAttribution) DeepCopy() *FileAttribution {
 	if in == nil {
 		return nil
 	}
-	out := new(FileMetadata)
+	out := new(FileAttribution)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IncrementalScanConfig) DeepCopyInto(out *IncrementalScanConfig) {
+func (in *FileMetadata) DeepCopyInto(out *FileMetadata) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncrementalScanConfig.
-func (in *IncrementalScanConfig) DeepCopy() *IncrementalScanConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileMetadata.
+func (in *FileMetadata) DeepCopy() *FileMetadata {
 	if in == nil {
 		return nil
 	}
-	out := new(IncrementalScanConfig)
+	out := new(FileMetadata)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfectedFile) DeepCopyInto(out *InfectedFile) {
+func (in *ImageResult) DeepCopyInto(out *ImageResult) {
 	*out = *in
-	if in.Viruses != nil {
-		in, out := &in.Viruses, &out.Viruses
+	if in.References != nil {
+		in, out := &in.References, &out.References
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InfectedLayers != nil {
+		in, out := &in.InfectedLayers, &out.InfectedLayers
+		*out = make([]InfectedLayer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfectedFile.
-func (in *InfectedFile) DeepCopy() *InfectedFile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageResult.
+func (in *ImageResult) DeepCopy() *ImageResult {
 	if in == nil {
 		return nil
 	}
-	out := new(InfectedFile)
+	out := new(ImageResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeScan) DeepCopyInto(out *NodeScan) {
+func (in *ImageScan) DeepCopyInto(out *ImageScan) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -232,18 +358,18 @@ func (in *NodeScan) DeepCopyInto(out *NodeScan) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScan.
-func (in *NodeScan) DeepCopy() *NodeScan {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScan.
+func (in *ImageScan) DeepCopy() *ImageScan {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeScan)
+	out := new(ImageScan)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodeScan) DeepCopyObject() runtime.Object {
+func (in *ImageScan) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -251,31 +377,31 @@ func (in *NodeScan) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeScanList) DeepCopyInto(out *NodeScanList) {
+func (in *ImageScanList) DeepCopyInto(out *ImageScanList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NodeScan, len(*in))
+		*out = make([]ImageScan, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanList.
-func (in *NodeScanList) DeepCopy() *NodeScanList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScanList.
+func (in *ImageScanList) DeepCopy() *ImageScanList {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeScanList)
+	out := new(ImageScanList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodeScanList) DeepCopyObject() runtime.Object {
+func (in *ImageScanList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -283,39 +409,16 @@ func (in *NodeScanList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeScanReference) DeepCopyInto(out *NodeScanReference) {
-	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanReference.
-func (in *NodeScanReference) DeepCopy() *NodeScanReference {
-	if in == nil {
-		return nil
-	}
-	out := new(NodeScanReference)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeScanSpec) DeepCopyInto(out *NodeScanSpec) {
+func (in *ImageScanSpec) DeepCopyInto(out *ImageScanSpec) {
 	*out = *in
-	if in.Paths != nil {
-		in, out := &in.Paths, &out.Paths
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.RunningImages != nil {
+		in, out := &in.RunningImages, &out.RunningImages
+		*out = new(WorkloadTarget)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.ExcludePatterns != nil {
-		in, out := &in.ExcludePatterns, &out.ExcludePatterns
-		*out = make([]string, len(*in))
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.Resources != nil {
@@ -330,18 +433,18 @@ func (in *NodeScanSpec) DeepCopyInto(out *NodeScanSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanSpec.
-func (in *NodeScanSpec) DeepCopy() *NodeScanSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScanSpec.
+func (in *ImageScanSpec) DeepCopy() *ImageScanSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeScanSpec)
+	out := new(ImageScanSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeScanStatus) DeepCopyInto(out *NodeScanStatus) {
+func (in *ImageScanStatus) DeepCopyInto(out *ImageScanStatus) {
 	*out = *in
 	if in.StartTime != nil {
 		in, out := &in.StartTime, &out.StartTime
@@ -351,139 +454,754 @@ func (in *NodeScanStatus) DeepCopyInto(out *NodeScanStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
-	if in.InfectedFiles != nil {
-		in, out := &in.InfectedFiles, &out.InfectedFiles
-		*out = make([]InfectedFile, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.JobRef != nil {
-		in, out := &in.JobRef, &out.JobRef
-		*out = new(corev1.ObjectReference)
-		**out = **in
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]ImageResult, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LastTransitionTime != nil {
-		in, out := &in.LastTransitionTime, &out.LastTransitionTime
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanStatus.
-func (in *NodeScanStatus) DeepCopy() *NodeScanStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageScanStatus.
+func (in *ImageScanStatus) DeepCopy() *ImageScanStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeScanStatus)
+	out := new(ImageScanStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotificationConfig) DeepCopyInto(out *NotificationConfig) {
+func (in *IncrementalScanConfig) DeepCopyInto(out *IncrementalScanConfig) {
 	*out = *in
-	if in.Slack != nil {
-		in, out := &in.Slack, &out.Slack
-		*out = new(SlackConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Email != nil {
-		in, out := &in.Email, &out.Email
-		*out = new(EmailConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Webhook != nil {
-		in, out := &in.Webhook, &out.Webhook
-		*out = new(WebhookConfig)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationConfig.
-func (in *NotificationConfig) DeepCopy() *NotificationConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncrementalScanConfig.
+func (in *IncrementalScanConfig) DeepCopy() *IncrementalScanConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(NotificationConfig)
+	out := new(IncrementalScanConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuarantineConfig) DeepCopyInto(out *QuarantineConfig) {
+func (in *InfectedFile) DeepCopyInto(out *InfectedFile) {
 	*out = *in
+	if in.Viruses != nil {
+		in, out := &in.Viruses, &out.Viruses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
+	if in.Attribution != nil {
+		in, out := &in.Attribution, &out.Attribution
+		*out = new(FileAttribution)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantineConfig.
-func (in *QuarantineConfig) DeepCopy() *QuarantineConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfectedFile.
+func (in *InfectedFile) DeepCopy() *InfectedFile {
 	if in == nil {
 		return nil
 	}
-	out := new(QuarantineConfig)
+	out := new(InfectedFile)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScanCache) DeepCopyInto(out *ScanCache) {
+func (in *InfectedLayer) DeepCopyInto(out *InfectedLayer) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]FileMetadata, len(*in))
+	if in.Viruses != nil {
+		in, out := &in.Viruses, &out.Viruses
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanCache.
-func (in *ScanCache) DeepCopy() *ScanCache {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfectedLayer.
+func (in *InfectedLayer) DeepCopy() *InfectedLayer {
 	if in == nil {
 		return nil
 	}
-	out := new(ScanCache)
+	out := new(InfectedLayer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScanCacheResource) DeepCopyInto(out *ScanCacheResource) {
+func (in *MaintenanceWindows) DeepCopyInto(out *MaintenanceWindows) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]TimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Forbidden != nil {
+		in, out := &in.Forbidden, &out.Forbidden
+		*out = make([]TimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanCacheResource.
-func (in *ScanCacheResource) DeepCopy() *ScanCacheResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindows.
+func (in *MaintenanceWindows) DeepCopy() *MaintenanceWindows {
 	if in == nil {
 		return nil
 	}
-	out := new(ScanCacheResource)
+	out := new(MaintenanceWindows)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ScanCacheResource) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ScanCacheResourceList) DeepCopyInto(out *ScanCacheResourceList) {
+func (in *NodeIsolationStatus) DeepCopyInto(out *NodeIsolationStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
+	if in.Taint != nil {
+		in, out := &in.Taint, &out.Taint
+		*out = new(corev1.Taint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictedPods != nil {
+		in, out := &in.EvictedPods, &out.EvictedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IsolationTime != nil {
+		in, out := &in.IsolationTime, &out.IsolationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ReleaseTime != nil {
+		in, out := &in.ReleaseTime, &out.ReleaseTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeIsolationStatus.
+func (in *NodeIsolationStatus) DeepCopy() *NodeIsolationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeIsolationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeReportingConfig) DeepCopyInto(out *NodeReportingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeReportingConfig.
+func (in *NodeReportingConfig) DeepCopy() *NodeReportingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeReportingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScan) DeepCopyInto(out *NodeScan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScan.
+func (in *NodeScan) DeepCopy() *NodeScan {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeScan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScanList) DeepCopyInto(out *NodeScanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeScan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanList.
+func (in *NodeScanList) DeepCopy() *NodeScanList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeScanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScanReference) DeepCopyInto(out *NodeScanReference) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanReference.
+func (in *NodeScanReference) DeepCopy() *NodeScanReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScanReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScanSpec) DeepCopyInto(out *NodeScanSpec) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludePatterns != nil {
+		in, out := &in.ExcludePatterns, &out.ExcludePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IncrementalConfig != nil {
+		in, out := &in.IncrementalConfig, &out.IncrementalConfig
+		*out = new(IncrementalScanConfig)
+		**out = **in
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = new(WorkloadTarget)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanSpec.
+func (in *NodeScanSpec) DeepCopy() *NodeScanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScanStatus) DeepCopyInto(out *NodeScanStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.InfectedFiles != nil {
+		in, out := &in.InfectedFiles, &out.InfectedFiles
+		*out = make([]InfectedFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JobRef != nil {
+		in, out := &in.JobRef, &out.JobRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(ScanReportReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ParameterSources != nil {
+		in, out := &in.ParameterSources, &out.ParameterSources
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Quarantine != nil {
+		in, out := &in.Quarantine, &out.Quarantine
+		*out = new(QuarantineStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Isolation != nil {
+		in, out := &in.Isolation, &out.Isolation
+		*out = new(NodeIsolationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadScanStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanStatus.
+func (in *NodeScanStatus) DeepCopy() *NodeScanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationConfig) DeepCopyInto(out *NotificationConfig) {
+	*out = *in
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Teams != nil {
+		in, out := &in.Teams, &out.Teams
+		*out = new(TeamsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Opsgenie != nil {
+		in, out := &in.Opsgenie, &out.Opsgenie
+		*out = new(OpsgenieConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(NotificationRetryPolicy)
+		**out = **in
+	}
+	if in.Deduplication != nil {
+		in, out := &in.Deduplication, &out.Deduplication
+		*out = new(NotificationDeduplication)
+		**out = **in
+	}
+	if in.RateLimits != nil {
+		in, out := &in.RateLimits, &out.RateLimits
+		*out = make([]NotificationRateLimit, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationConfig.
+func (in *NotificationConfig) DeepCopy() *NotificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationDeduplication) DeepCopyInto(out *NotificationDeduplication) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationDeduplication.
+func (in *NotificationDeduplication) DeepCopy() *NotificationDeduplication {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationDeduplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationRateLimit) DeepCopyInto(out *NotificationRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationRateLimit.
+func (in *NotificationRateLimit) DeepCopy() *NotificationRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationRetryPolicy) DeepCopyInto(out *NotificationRetryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationRetryPolicy.
+func (in *NotificationRetryPolicy) DeepCopy() *NotificationRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationStatus) DeepCopyInto(out *NotificationStatus) {
+	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SentTime != nil {
+		in, out := &in.SentTime, &out.SentTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationStatus.
+func (in *NotificationStatus) DeepCopy() *NotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationTemplate) DeepCopyInto(out *NotificationTemplate) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationTemplate.
+func (in *NotificationTemplate) DeepCopy() *NotificationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OmittedFindings) DeepCopyInto(out *OmittedFindings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OmittedFindings.
+func (in *OmittedFindings) DeepCopy() *OmittedFindings {
+	if in == nil {
+		return nil
+	}
+	out := new(OmittedFindings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpsgenieConfig) DeepCopyInto(out *OpsgenieConfig) {
+	*out = *in
+	if in.APIKeySecretRef != nil {
+		in, out := &in.APIKeySecretRef, &out.APIKeySecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpsgenieConfig.
+func (in *OpsgenieConfig) DeepCopy() *OpsgenieConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OpsgenieConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyConfig) DeepCopyInto(out *PagerDutyConfig) {
+	*out = *in
+	if in.RoutingKeySecretRef != nil {
+		in, out := &in.RoutingKeySecretRef, &out.RoutingKeySecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyConfig.
+func (in *PagerDutyConfig) DeepCopy() *PagerDutyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuarantineConfig) DeepCopyInto(out *QuarantineConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantineConfig.
+func (in *QuarantineConfig) DeepCopy() *QuarantineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuarantineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuarantineStatus) DeepCopyInto(out *QuarantineStatus) {
+	*out = *in
+	if in.JobRef != nil {
+		in, out := &in.JobRef, &out.JobRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]QuarantinedFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantineStatus.
+func (in *QuarantineStatus) DeepCopy() *QuarantineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuarantineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuarantinedFile) DeepCopyInto(out *QuarantinedFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuarantinedFile.
+func (in *QuarantinedFile) DeepCopy() *QuarantinedFile {
+	if in == nil {
+		return nil
+	}
+	out := new(QuarantinedFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedScanParameters) DeepCopyInto(out *ResolvedScanParameters) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedScanParameters.
+func (in *ResolvedScanParameters) DeepCopy() *ResolvedScanParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedScanParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseActionsConfig) DeepCopyInto(out *ResponseActionsConfig) {
+	*out = *in
+	if in.AllowedSignatures != nil {
+		in, out := &in.AllowedSignatures, &out.AllowedSignatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseActionsConfig.
+func (in *ResponseActionsConfig) DeepCopy() *ResponseActionsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseActionsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanCache) DeepCopyInto(out *ScanCache) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]FileMetadata, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanCache.
+func (in *ScanCache) DeepCopy() *ScanCache {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanCacheResource) DeepCopyInto(out *ScanCacheResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanCacheResource.
+func (in *ScanCacheResource) DeepCopy() *ScanCacheResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanCacheResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScanCacheResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanCacheResourceList) DeepCopyInto(out *ScanCacheResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
 		in, out := &in.Items, &out.Items
 		*out = make([]ScanCacheResource, len(*in))
 		for i := range *in {
@@ -526,6 +1244,21 @@ func (in *ScanCacheStatus) DeepCopy() *ScanCacheStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanError) DeepCopyInto(out *ScanError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanError.
+func (in *ScanError) DeepCopy() *ScanError {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanError)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScanPolicy) DeepCopyInto(out *ScanPolicy) {
 	*out = *in
@@ -613,6 +1346,16 @@ func (in *ScanPolicySpec) DeepCopyInto(out *ScanPolicySpec) {
 		*out = new(QuarantineConfig)
 		**out = **in
 	}
+	if in.NodeReporting != nil {
+		in, out := &in.NodeReporting, &out.NodeReporting
+		*out = new(NodeReportingConfig)
+		**out = **in
+	}
+	if in.ResponseActions != nil {
+		in, out := &in.ResponseActions, &out.ResponseActions
+		*out = new(ResponseActionsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanPolicySpec.
@@ -632,21 +1375,145 @@ func (in *ScanPolicyStatus) DeepCopyInto(out *ScanPolicyStatus) {
 		in, out := &in.LastUsed, &out.LastUsed
 		*out = (*in).DeepCopy()
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanPolicyStatus.
+func (in *ScanPolicyStatus) DeepCopy() *ScanPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanReport) DeepCopyInto(out *ScanReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanReport.
+func (in *ScanReport) DeepCopy() *ScanReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScanReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanReportList) DeepCopyInto(out *ScanReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScanReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanReportList.
+func (in *ScanReportList) DeepCopy() *ScanReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScanReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanReportReference) DeepCopyInto(out *ScanReportReference) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Omitted != nil {
+		in, out := &in.Omitted, &out.Omitted
+		*out = new(OmittedFindings)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanReportReference.
+func (in *ScanReportReference) DeepCopy() *ScanReportReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanReportReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanReportSpec) DeepCopyInto(out *ScanReportSpec) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.InfectedFiles != nil {
+		in, out := &in.InfectedFiles, &out.InfectedFiles
+		*out = make([]InfectedFile, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]ScanError, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedFiles != nil {
+		in, out := &in.SkippedFiles, &out.SkippedFiles
+		*out = make([]SkippedFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.Omitted != nil {
+		in, out := &in.Omitted, &out.Omitted
+		*out = new(OmittedFindings)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanPolicyStatus.
-func (in *ScanPolicyStatus) DeepCopy() *ScanPolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanReportSpec.
+func (in *ScanReportSpec) DeepCopy() *ScanReportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ScanPolicyStatus)
+	out := new(ScanReportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -713,7 +1580,21 @@ func (in *ScanScheduleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScanScheduleSpec) DeepCopyInto(out *ScanScheduleSpec) {
 	*out = *in
-	in.ClusterScan.DeepCopyInto(&out.ClusterScan)
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = new(MaintenanceWindows)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterScan != nil {
+		in, out := &in.ClusterScan, &out.ClusterScan
+		*out = new(ClusterScanSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeScan != nil {
+		in, out := &in.VolumeScan, &out.VolumeScan
+		*out = new(VolumeScanSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SuccessfulScansHistoryLimit != nil {
 		in, out := &in.SuccessfulScansHistoryLimit, &out.SuccessfulScansHistoryLimit
 		*out = new(int32)
@@ -757,10 +1638,21 @@ func (in *ScanScheduleStatus) DeepCopyInto(out *ScanScheduleStatus) {
 		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastMissedScheduleTime != nil {
+		in, out := &in.LastMissedScheduleTime, &out.LastMissedScheduleTime
+		*out = (*in).DeepCopy()
+	}
 	if in.NextScheduleTime != nil {
 		in, out := &in.NextScheduleTime, &out.NextScheduleTime
 		*out = (*in).DeepCopy()
 	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -780,6 +1672,21 @@ func (in *ScanScheduleStatus) DeepCopy() *ScanScheduleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedFile) DeepCopyInto(out *SkippedFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedFile.
+func (in *SkippedFile) DeepCopy() *SkippedFile {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SlackConfig) DeepCopyInto(out *SlackConfig) {
 	*out = *in
@@ -788,6 +1695,16 @@ func (in *SlackConfig) DeepCopyInto(out *SlackConfig) {
 		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(NotificationTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackConfig.
@@ -800,6 +1717,51 @@ func (in *SlackConfig) DeepCopy() *SlackConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TeamsConfig) DeepCopyInto(out *TeamsConfig) {
+	*out = *in
+	if in.WebhookSecretRef != nil {
+		in, out := &in.WebhookSecretRef, &out.WebhookSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TeamsConfig.
+func (in *TeamsConfig) DeepCopy() *TeamsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TeamsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
 	*out = *in
@@ -815,6 +1777,26 @@ func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
 		*out = new(corev1.SecretReference)
 		**out = **in
 	}
+	if in.SigningSecretRef != nil {
+		in, out := &in.SigningSecretRef, &out.SigningSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(WebhookTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(NotificationTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfig.
@@ -826,3 +1808,216 @@ func (in *WebhookConfig) DeepCopy() *WebhookConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeScan) DeepCopyInto(out *VolumeScan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeScan.
+func (in *VolumeScan) DeepCopy() *VolumeScan {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeScan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeScan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeScanList) DeepCopyInto(out *VolumeScanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeScan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeScanList.
+func (in *VolumeScanList) DeepCopy() *VolumeScanList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeScanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeScanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeScanSpec) DeepCopyInto(out *VolumeScanSpec) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeScanSpec.
+func (in *VolumeScanSpec) DeepCopy() *VolumeScanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeScanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeScanStatus) DeepCopyInto(out *VolumeScanStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.InfectedFiles != nil {
+		in, out := &in.InfectedFiles, &out.InfectedFiles
+		*out = make([]InfectedFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JobRef != nil {
+		in, out := &in.JobRef, &out.JobRef
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.ParameterSources != nil {
+		in, out := &in.ParameterSources, &out.ParameterSources
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeScanStatus.
+func (in *VolumeScanStatus) DeepCopy() *VolumeScanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeScanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookTLSConfig) DeepCopyInto(out *WebhookTLSConfig) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertSecretRef != nil {
+		in, out := &in.ClientCertSecretRef, &out.ClientCertSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookTLSConfig.
+func (in *WebhookTLSConfig) DeepCopy() *WebhookTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadScanStatus) DeepCopyInto(out *WorkloadScanStatus) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadScanStatus.
+func (in *WorkloadScanStatus) DeepCopy() *WorkloadScanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadScanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadTarget) DeepCopyInto(out *WorkloadTarget) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTarget.
+func (in *WorkloadTarget) DeepCopy() *WorkloadTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadTarget)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,6 +21,10 @@ import (
 	"os"
 	"strings"
 
+	// Embed the time zone database so ScanSchedule time zones resolve in
+	// images without tzdata.
+	_ "time/tzdata"
+
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -67,6 +71,7 @@ func main() {
 	var clamavPort int
 	var skipStartupChecks bool
 	var scannerServiceAccount string
+	var clusterName string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -83,6 +88,8 @@ func main() {
 		"Skip startup validation checks (not recommended for production)")
 	flag.StringVar(&scannerServiceAccount, "scanner-service-account", "clamav-scanner",
 		"Name of the ServiceAccount used by scanner jobs")
+	flag.StringVar(&clusterName, "cluster-name", "",
+		"Name of the cluster, available to notification templates as .Cluster.Name")
 
 	opts := zap.Options{
 		Development: true,
@@ -160,29 +167,57 @@ func main() {
 		ScannerImage: scannerImage,
 		ClamavHost:   clamavHost,
 		ClamavPort:   clamavPort,
+		ClusterName:  clusterName,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NodeScan")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.ClusterScanReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("clusterscan-controller"),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("clusterscan-controller"),
+		ClusterName: clusterName,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterScan")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.ScanScheduleReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("scanschedule-controller"),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("scanschedule-controller"),
+		ClusterName: clusterName,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScanSchedule")
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ImageScanReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("imagescan-controller"),
+		Clientset:    clientset,
+		ScannerImage: scannerImage,
+		ClamavHost:   clamavHost,
+		ClamavPort:   clamavPort,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ImageScan")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.VolumeScanReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("volumescan-controller"),
+		ScannerImage: scannerImage,
+		ClamavHost:   clamavHost,
+		ClamavPort:   clamavPort,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeScan")
+		os.Exit(1)
+	}
+
 	// Setup webhooks
 	if err = (&clamavv1alpha1.NodeScan{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "NodeScan")
@@ -192,6 +227,26 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterScan")
 		os.Exit(1)
 	}
+	if err = (&clamavv1alpha1.ScanPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ScanPolicy")
+		os.Exit(1)
+	}
+	if err = (&clamavv1alpha1.ClusterScanPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterScanPolicy")
+		os.Exit(1)
+	}
+	if err = (&clamavv1alpha1.ScanSchedule{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ScanSchedule")
+		os.Exit(1)
+	}
+	if err = (&clamavv1alpha1.ImageScan{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ImageScan")
+		os.Exit(1)
+	}
+	if err = (&clamavv1alpha1.VolumeScan{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "VolumeScan")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,351 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// CloudEvents types emitted by the operator. The version suffix changes when
+// the data of an event changes incompatibly.
+const (
+	cloudEventNodeScanStarted      = "io.clamav.nodescan.started.v1"
+	cloudEventNodeScanCompleted    = "io.clamav.nodescan.completed.v1"
+	cloudEventNodeScanFailed       = "io.clamav.nodescan.failed.v1"
+	cloudEventNodeScanParseFailed  = "io.clamav.nodescan.parsefailed.v1"
+	cloudEventInfectionDetected    = "io.clamav.infection.detected.v1"
+	cloudEventQuarantineCompleted  = "io.clamav.quarantine.completed.v1"
+	cloudEventClusterScanCompleted = "io.clamav.clusterscan.completed.v1"
+	cloudEventScanScheduleOverdue  = "io.clamav.scanschedule.overdue.v1"
+)
+
+// Schemas of the event data
+const (
+	cloudEventSchemaNodeScan    = "urn:clamav.io:schema:nodescan:v1"
+	cloudEventSchemaInfection   = "urn:clamav.io:schema:infection:v1"
+	cloudEventSchemaQuarantine  = "urn:clamav.io:schema:quarantine:v1"
+	cloudEventSchemaClusterScan = "urn:clamav.io:schema:clusterscan:v1"
+	cloudEventSchemaSchedule    = "urn:clamav.io:schema:scanschedule:v1"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	// cloudEventsContentType is the content type of structured mode requests
+	cloudEventsContentType = "application/cloudevents+json"
+)
+
+// cloudEvent is a CloudEvents 1.0 event with JSON data
+type cloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            string      `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	DataSchema      string      `json:"dataschema"`
+	Data            interface{} `json:"data"`
+}
+
+// nodeScanEventData is the data of the NodeScan lifecycle events
+type nodeScanEventData struct {
+	Name           string       `json:"name"`
+	Namespace      string       `json:"namespace"`
+	Node           string       `json:"node"`
+	Phase          string       `json:"phase"`
+	FilesScanned   int64        `json:"filesScanned"`
+	FilesInfected  int64        `json:"filesInfected"`
+	FilesSkipped   int64        `json:"filesSkipped"`
+	ErrorCount     int64        `json:"errorCount"`
+	Duration       int64        `json:"duration"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	Message        string       `json:"message,omitempty"`
+}
+
+// infectionEventData is the data of infection.detected events
+type infectionEventData struct {
+	Name          string             `json:"name"`
+	Namespace     string             `json:"namespace"`
+	Node          string             `json:"node"`
+	FilesInfected int64              `json:"filesInfected"`
+	Findings      []infectionFinding `json:"findings"`
+}
+
+// infectionFinding is a signature and the files it was found in
+type infectionFinding struct {
+	Signature string   `json:"signature"`
+	Paths     []string `json:"paths"`
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// quarantineEventData is the data of quarantine.completed events
+type quarantineEventData struct {
+	Name             string `json:"name"`
+	Namespace        string `json:"namespace"`
+	Node             string `json:"node"`
+	Action           string `json:"action"`
+	FilesQuarantined int64  `json:"filesQuarantined"`
+	FilesDeleted     int64  `json:"filesDeleted"`
+	FilesFailed      int64  `json:"filesFailed"`
+}
+
+// clusterScanEventData is the data of clusterscan.completed events
+type clusterScanEventData struct {
+	Name               string                 `json:"name"`
+	Namespace          string                 `json:"namespace"`
+	Phase              string                 `json:"phase"`
+	TotalNodes         int32                  `json:"totalNodes"`
+	CompletedNodes     int32                  `json:"completedNodes"`
+	FailedNodes        int32                  `json:"failedNodes"`
+	InfectedNodes      int32                  `json:"infectedNodes"`
+	TotalFilesScanned  int64                  `json:"totalFilesScanned"`
+	TotalFilesInfected int64                  `json:"totalFilesInfected"`
+	Infected           []clusterScanEventNode `json:"infected"`
+	Failed             []string               `json:"failed"`
+}
+
+// clusterScanEventNode is an infected node of a ClusterScan
+type clusterScanEventNode struct {
+	Node          string   `json:"node"`
+	NodeScan      string   `json:"nodeScan"`
+	FilesInfected int64    `json:"filesInfected"`
+	Signatures    []string `json:"signatures"`
+}
+
+// scanScheduleEventData is the data of scanschedule.overdue events
+type scanScheduleEventData struct {
+	Name                   string       `json:"name"`
+	Namespace              string       `json:"namespace"`
+	Schedule               string       `json:"schedule"`
+	LastScheduleTime       *metav1.Time `json:"lastScheduleTime,omitempty"`
+	LastMissedScheduleTime *metav1.Time `json:"lastMissedScheduleTime,omitempty"`
+	Message                string       `json:"message,omitempty"`
+}
+
+// newCloudEvent returns an event about an object of the resource. The id is
+// derived from the object UID and the event type, so that retried deliveries
+// carry the same id and receivers can drop duplicates.
+func newCloudEvent(obj metav1.Object, resource, eventType, schema string, at *metav1.Time, data interface{}) cloudEvent {
+	t := time.Now()
+	if at != nil {
+		t = at.Time
+	}
+	return cloudEvent{
+		SpecVersion: cloudEventsSpecVersion,
+		ID:          fmt.Sprintf("%s/%s", obj.GetUID(), eventType),
+		Source: fmt.Sprintf("/apis/%s/namespaces/%s/%s/%s",
+			clamavv1alpha1.GroupVersion.String(), obj.GetNamespace(), resource, obj.GetName()),
+		Type:            eventType,
+		Time:            t.UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		DataSchema:      schema,
+		Data:            data,
+	}
+}
+
+// nodeScanCloudEvents returns the events of a NodeScan notification. A
+// completed scan that found malware is followed by an infection.detected event.
+func nodeScanCloudEvents(nodeScan *clamavv1alpha1.NodeScan, event string) ([]cloudEvent, error) {
+	status := nodeScan.Status
+	data := nodeScanEventData{
+		Name:           nodeScan.Name,
+		Namespace:      nodeScan.Namespace,
+		Node:           nodeScan.Spec.NodeName,
+		Phase:          string(status.Phase),
+		FilesScanned:   status.FilesScanned,
+		FilesInfected:  status.FilesInfected,
+		FilesSkipped:   status.FilesSkipped,
+		ErrorCount:     status.ErrorCount,
+		Duration:       status.Duration,
+		StartTime:      status.StartTime,
+		CompletionTime: status.CompletionTime,
+	}
+
+	var events []cloudEvent
+	switch event {
+	case clamavv1alpha1.NotificationEventScanStarted:
+		events = append(events, newCloudEvent(nodeScan, "nodescans", cloudEventNodeScanStarted,
+			cloudEventSchemaNodeScan, status.StartTime, data))
+	case clamavv1alpha1.NotificationEventScanCompleted:
+		events = append(events, newCloudEvent(nodeScan, "nodescans", cloudEventNodeScanCompleted,
+			cloudEventSchemaNodeScan, status.CompletionTime, data))
+		if status.FilesInfected > 0 {
+			events = append(events, newCloudEvent(nodeScan, "nodescans", cloudEventInfectionDetected,
+				cloudEventSchemaInfection, status.CompletionTime, infectionData(nodeScan)))
+		}
+	case clamavv1alpha1.NotificationEventScanFailed, clamavv1alpha1.NotificationEventParseFailed:
+		eventType := cloudEventNodeScanFailed
+		if event == clamavv1alpha1.NotificationEventParseFailed {
+			eventType = cloudEventNodeScanParseFailed
+		}
+		data.Message = nodeScanAlert(nodeScan, event).message
+		events = append(events, newCloudEvent(nodeScan, "nodescans", eventType,
+			cloudEventSchemaNodeScan, status.CompletionTime, data))
+	case clamavv1alpha1.NotificationEventQuarantineCompleted:
+		quarantine := status.Quarantine
+		if quarantine == nil {
+			return nil, fmt.Errorf("no quarantine to report")
+		}
+		events = append(events, newCloudEvent(nodeScan, "nodescans", cloudEventQuarantineCompleted,
+			cloudEventSchemaQuarantine, quarantine.CompletionTime, quarantineEventData{
+				Name:             nodeScan.Name,
+				Namespace:        nodeScan.Namespace,
+				Node:             nodeScan.Spec.NodeName,
+				Action:           quarantine.Action,
+				FilesQuarantined: quarantine.FilesQuarantined,
+				FilesDeleted:     quarantine.FilesDeleted,
+				FilesFailed:      quarantine.FilesFailed,
+			}))
+	default:
+		return nil, fmt.Errorf("unknown notification event %q", event)
+	}
+
+	for i := range events {
+		events[i].Subject = nodeScan.Spec.NodeName
+	}
+	return events, nil
+}
+
+// infectionData groups the infected files of a NodeScan by signature
+func infectionData(nodeScan *clamavv1alpha1.NodeScan) infectionEventData {
+	paths := map[string][]string{}
+	workloads := map[string][]string{}
+	for _, f := range nodeScan.Status.InfectedFiles {
+		for _, virus := range f.Viruses {
+			paths[virus] = append(paths[virus], f.Path)
+			workloads[virus] = appendUnique(workloads[virus], attributionWorkload(f.Attribution))
+		}
+	}
+	data := infectionEventData{
+		Name:          nodeScan.Name,
+		Namespace:     nodeScan.Namespace,
+		Node:          nodeScan.Spec.NodeName,
+		FilesInfected: nodeScan.Status.FilesInfected,
+		Findings:      []infectionFinding{},
+	}
+	for virus, files := range paths {
+		data.Findings = append(data.Findings, infectionFinding{Signature: virus, Paths: files, Workloads: workloads[virus]})
+	}
+	sort.Slice(data.Findings, func(i, j int) bool { return data.Findings[i].Signature < data.Findings[j].Signature })
+	return data
+}
+
+// clusterScanCloudEvent returns the clusterscan.completed event of a ClusterScan
+func clusterScanCloudEvent(clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest) cloudEvent {
+	status := clusterScan.Status
+	data := clusterScanEventData{
+		Name:               clusterScan.Name,
+		Namespace:          clusterScan.Namespace,
+		Phase:              string(status.Phase),
+		TotalNodes:         status.TotalNodes,
+		CompletedNodes:     status.CompletedNodes,
+		FailedNodes:        status.FailedNodes,
+		InfectedNodes:      status.InfectedNodes,
+		TotalFilesScanned:  status.TotalFilesScanned,
+		TotalFilesInfected: status.TotalFilesInfected,
+		Infected:           []clusterScanEventNode{},
+		Failed:             []string{},
+	}
+	for _, n := range digest.infectedNodes {
+		data.Infected = append(data.Infected, clusterScanEventNode{
+			Node:          n.node,
+			NodeScan:      n.nodeScan,
+			FilesInfected: n.filesInfected,
+			Signatures:    n.signatures,
+		})
+	}
+	data.Failed = append(data.Failed, digest.failedNodes...)
+
+	return newCloudEvent(clusterScan, "clusterscans", cloudEventClusterScanCompleted,
+		cloudEventSchemaClusterScan, status.CompletionTime, data)
+}
+
+// scanScheduleCloudEvent returns the scanschedule.overdue event of a
+// ScanSchedule. A schedule can be overdue many times, so the id includes the
+// missed run.
+func scanScheduleCloudEvent(scanSchedule *clamavv1alpha1.ScanSchedule) cloudEvent {
+	status := scanSchedule.Status
+	data := scanScheduleEventData{
+		Name:                   scanSchedule.Name,
+		Namespace:              scanSchedule.Namespace,
+		Schedule:               scanSchedule.Spec.Schedule,
+		LastScheduleTime:       status.LastScheduleTime,
+		LastMissedScheduleTime: status.LastMissedScheduleTime,
+	}
+	if c := meta.FindStatusCondition(status.Conditions, scheduleConditionMissed); c != nil {
+		data.Message = c.Message
+	}
+
+	event := newCloudEvent(scanSchedule, "scanschedules", cloudEventScanScheduleOverdue,
+		cloudEventSchemaSchedule, status.LastMissedScheduleTime, data)
+	if t := status.LastMissedScheduleTime; t != nil {
+		event.ID = fmt.Sprintf("%s/%d", event.ID, t.Unix())
+	}
+	return event
+}
+
+// usesCloudEvents reports whether the channel sends CloudEvents
+func usesCloudEvents(notifications *clamavv1alpha1.NotificationConfig, channel string) bool {
+	return channel == clamavv1alpha1.NotificationChannelWebhook && notifications != nil &&
+		notifications.Webhook != nil && notifications.Webhook.Format == clamavv1alpha1.WebhookFormatCloudEvents
+}
+
+// sendCloudEvents posts each event to the webhook in the configured HTTP
+// content mode. Binary mode carries the attributes in ce- headers.
+func sendCloudEvents(ctx context.Context, c client.Reader, config *clamavv1alpha1.WebhookConfig, namespace string, events []cloudEvent) error {
+	for _, event := range events {
+		var body []byte
+		var err error
+		headers := map[string]string{}
+
+		if config.CloudEventsMode == clamavv1alpha1.CloudEventsModeBinary {
+			body, err = json.Marshal(event.Data)
+			headers["Content-Type"] = event.DataContentType
+			headers["ce-specversion"] = event.SpecVersion
+			headers["ce-id"] = event.ID
+			headers["ce-source"] = event.Source
+			headers["ce-type"] = event.Type
+			headers["ce-time"] = event.Time
+			headers["ce-dataschema"] = event.DataSchema
+			if event.Subject != "" {
+				headers["ce-subject"] = event.Subject
+			}
+		} else {
+			body, err = json.Marshal(event)
+			headers["Content-Type"] = cloudEventsContentType
+		}
+		if err != nil {
+			return fmt.Errorf("failed to marshal event %s: %w", event.Type, err)
+		}
+
+		if err := sendWebhookRequest(ctx, c, config, namespace, body, headers); err != nil {
+			return fmt.Errorf("failed to send event %s: %w", event.Type, err)
+		}
+	}
+	return nil
+}
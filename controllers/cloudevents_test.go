@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func TestNodeScanCloudEvents(t *testing.T) {
+	nodeScan := newTestInfectedNodeScan()
+	nodeScan.UID = "1234"
+
+	events, err := nodeScanCloudEvents(nodeScan, clamavv1alpha1.NotificationEventScanCompleted)
+	require.NoError(t, err)
+
+	// An infected scan is followed by infection.detected
+	require.Len(t, events, 2)
+	assert.Equal(t, cloudEventNodeScanCompleted, events[0].Type)
+	assert.Equal(t, "1234/"+cloudEventNodeScanCompleted, events[0].ID)
+	assert.Equal(t, "/apis/clamav.io/v1alpha1/namespaces/default/nodescans/test-scan", events[0].Source)
+	assert.Equal(t, "worker-1", events[0].Subject)
+	assert.Equal(t, cloudEventSchemaNodeScan, events[0].DataSchema)
+	assert.Equal(t, cloudEventInfectionDetected, events[1].Type)
+	data := events[1].Data.(infectionEventData)
+	require.Len(t, data.Findings, 2)
+	assert.Equal(t, infectionFinding{Signature: "Eicar-Test-Signature", Paths: []string{"/host/opt/a", "/host/opt/b"}}, data.Findings[0])
+
+	nodeScan.Status.FilesInfected = 0
+	events, err = nodeScanCloudEvents(nodeScan, clamavv1alpha1.NotificationEventScanCompleted)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	_, err = nodeScanCloudEvents(nodeScan, clamavv1alpha1.NotificationEventQuarantineCompleted)
+	assert.Error(t, err)
+}
+
+func TestSendCloudEvents_Structured(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusAccepted)
+	r := newTestNodeScanReconciler()
+	config := &clamavv1alpha1.WebhookConfig{URL: receiver.URL, Format: clamavv1alpha1.WebhookFormatCloudEvents}
+
+	events, err := nodeScanCloudEvents(newTestInfectedNodeScan(), clamavv1alpha1.NotificationEventScanCompleted)
+	require.NoError(t, err)
+	require.NoError(t, sendCloudEvents(context.Background(), r.Client, config, "default", events))
+
+	require.Len(t, receiver.requests, 2)
+	assert.Equal(t, "application/cloudevents+json", receiver.requests[0].Header.Get("Content-Type"))
+	event := receiver.payloads[0]
+	assert.Equal(t, "1.0", event["specversion"])
+	assert.Equal(t, cloudEventNodeScanCompleted, event["type"])
+	assert.Equal(t, "application/json", event["datacontenttype"])
+	assert.Equal(t, "worker-1", event["data"].(map[string]interface{})["node"])
+	assert.Equal(t, cloudEventInfectionDetected, receiver.payloads[1]["type"])
+}
+
+func TestSendCloudEvents_Binary(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusAccepted)
+	r := newTestNodeScanReconciler()
+	config := &clamavv1alpha1.WebhookConfig{
+		URL:             receiver.URL,
+		Format:          clamavv1alpha1.WebhookFormatCloudEvents,
+		CloudEventsMode: clamavv1alpha1.CloudEventsModeBinary,
+	}
+
+	events, err := nodeScanCloudEvents(newTestInfectedNodeScan(), clamavv1alpha1.NotificationEventScanStarted)
+	require.NoError(t, err)
+	require.NoError(t, sendCloudEvents(context.Background(), r.Client, config, "default", events))
+
+	require.Len(t, receiver.requests, 1)
+	header := receiver.requests[0].Header
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "1.0", header.Get("ce-specversion"))
+	assert.Equal(t, cloudEventNodeScanStarted, header.Get("ce-type"))
+	assert.Equal(t, "worker-1", header.Get("ce-subject"))
+	assert.Equal(t, cloudEventSchemaNodeScan, header.Get("ce-dataschema"))
+	// The body is the event data
+	assert.Equal(t, "test-scan", receiver.payloads[0]["name"])
+	assert.NotContains(t, receiver.payloads[0], "specversion")
+}
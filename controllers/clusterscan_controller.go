@@ -11,6 +11,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -31,6 +32,8 @@ type ClusterScanReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// ClusterName identifies the cluster in notification templates
+	ClusterName string
 }
 
 // +kubebuilder:rbac:groups=clamav.io,resources=clusterscans,verbs=get;list;watch;create;update;patch;delete
@@ -38,6 +41,7 @@ type ClusterScanReconciler struct {
 // +kubebuilder:rbac:groups=clamav.io,resources=clusterscans/finalizers,verbs=update
 // +kubebuilder:rbac:groups=clamav.io,resources=nodescans,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *ClusterScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -129,13 +133,17 @@ func (r *ClusterScanReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		})
 	}
 
+	// New NodeScans only start inside a maintenance window
+	now := time.Now()
+	windowOpen, nextWindow := r.checkMaintenanceWindows(&clusterScan, now)
+
 	// Create NodeScans for nodes that don't have one yet
 	concurrent := clusterScan.Spec.Concurrent
 	if concurrent == 0 {
 		concurrent = 3
 	}
 
-	if running < concurrent {
+	if windowOpen && running < concurrent {
 		for _, node := range nodes {
 			// Check if NodeScan already exists for this node
 			exists := false
@@ -174,11 +182,37 @@ func (r *ClusterScanReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		} else {
 			clusterScan.Status.Phase = clamavv1alpha1.ClusterScanPhaseFailed
 		}
-		now := metav1.Now()
-		clusterScan.Status.CompletionTime = &now
-		
-		// Record metrics
-		recordClusterScanMetrics(&clusterScan, clusterScan.Status.Phase)
+
+		// Digest retries and NodeScan updates reconcile the finished scan again,
+		// it is only completed once
+		if clusterScan.Status.CompletionTime == nil {
+			now := metav1.Now()
+			clusterScan.Status.CompletionTime = &now
+			clusterScan.Status.NextWindowTime = nil
+
+			// Record metrics
+			recordClusterScanMetrics(&clusterScan, clusterScan.Status.Phase)
+
+			// Queue the digest, it is delivered once the status is stored.
+			// Infections notified recently are reported like a clean run.
+			infected := clusterScan.Status.TotalFilesInfected > 0
+			if infected && clusterScan.Spec.Notifications != nil {
+				keys, complete := nodeScanFindingKeys(existingNodeScans.Items...)
+				known, err := recordFindings(ctx, r.Client, clusterScan.Namespace, clusterScan.UID,
+					clusterScan.Spec.Notifications.Deduplication, keys)
+				if err != nil {
+					// Without the state the infections are notified again
+					log.Error(err, "failed to record notified infections")
+					r.Recorder.Event(&clusterScan, corev1.EventTypeWarning, "NotificationStateFailed",
+						fmt.Sprintf("Failed to record notified infections, deduplication is skipped: %v", err))
+				}
+				infected = !known || !complete || err != nil
+			}
+			enqueueDigest(&clusterScan, infected)
+		}
+	} else if len(existingNodeScans.Items) == 0 && !windowOpen {
+		// Nothing has started yet
+		clusterScan.Status.Phase = clamavv1alpha1.ClusterScanPhasePending
 	} else {
 		clusterScan.Status.Phase = clamavv1alpha1.ClusterScanPhaseRunning
 	}
@@ -187,12 +221,87 @@ func (r *ClusterScanReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	var requeueAfter time.Duration
 	// Requeue if still running
 	if clusterScan.Status.Phase == clamavv1alpha1.ClusterScanPhaseRunning {
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		requeueAfter = 30 * time.Second
+	}
+	// Resume the rollout when the next window opens
+	if !windowOpen && clusterScan.Status.CompletionTime == nil {
+		wait := time.Hour
+		if !nextWindow.IsZero() {
+			wait = nextWindow.Sub(now)
+		}
+		if requeueAfter == 0 || wait < requeueAfter {
+			requeueAfter = wait
+		}
+	}
+
+	// Deliver the pending digest and schedule its retries
+	notificationResult, err := r.reconcileNotifications(ctx, &clusterScan, existingNodeScans.Items)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return earliestRequeue(ctrl.Result{RequeueAfter: requeueAfter}, notificationResult), nil
+}
+
+// checkMaintenanceWindows returns whether new NodeScans may start at now and, if
+// not, when the next maintenance window opens. It updates the MaintenanceWindow
+// condition and emits an event when the rollout is paused or resumed.
+func (r *ClusterScanReconciler) checkMaintenanceWindows(clusterScan *clamavv1alpha1.ClusterScan, now time.Time) (bool, time.Time) {
+	if clusterScan.Spec.MaintenanceWindows == nil {
+		return true, time.Time{}
+	}
+
+	condition := metav1.Condition{
+		Type:   conditionMaintenanceWindow,
+		Status: metav1.ConditionTrue,
+		Reason: reasonWindowOpen,
+	}
+	open := true
+	var next time.Time
+
+	windows, err := parseMaintenanceWindows(clusterScan.Spec.MaintenanceWindows, "")
+	switch {
+	case err != nil:
+		open = false
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonInvalidWindowConfig
+		condition.Message = err.Error()
+	case !windows.isOpen(now):
+		open = false
+		next = windows.nextOpen(now)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonWaitingForWindow
+		condition.Message = "Waiting for a maintenance window to start new node scans"
+		if !next.IsZero() {
+			condition.Message = fmt.Sprintf("Waiting for the maintenance window opening at %s", next.Format(time.RFC3339))
+		}
+	default:
+		condition.Message = "Node scans may start"
+	}
+
+	clusterScan.Status.NextWindowTime = nil
+	if !next.IsZero() {
+		clusterScan.Status.NextWindowTime = &metav1.Time{Time: next}
+	}
+
+	// Report rollout transitions, but not a rollout that starts inside a window
+	previous := meta.FindStatusCondition(clusterScan.Status.Conditions, conditionMaintenanceWindow)
+	if previous == nil || previous.Status != condition.Status || previous.Reason != condition.Reason {
+		switch {
+		case err != nil:
+			r.Recorder.Event(clusterScan, corev1.EventTypeWarning, "RolloutPaused", condition.Message)
+		case !open:
+			r.Recorder.Event(clusterScan, corev1.EventTypeNormal, "RolloutPaused", condition.Message)
+		case previous != nil:
+			r.Recorder.Event(clusterScan, corev1.EventTypeNormal, "RolloutResumed", condition.Message)
+		}
+	}
+	meta.SetStatusCondition(&clusterScan.Status.Conditions, condition)
+
+	return open, next
 }
 
 func (r *ClusterScanReconciler) getNodesForScan(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan) ([]corev1.Node, error) {
@@ -226,9 +335,13 @@ func (r *ClusterScanReconciler) createNodeScanForNode(ctx context.Context, clust
 			},
 		},
 		Spec: clamavv1alpha1.NodeScanSpec{
-			NodeName:   nodeName,
-			ScanPolicy: clusterScan.Spec.ScanPolicy,
-			Priority:   clusterScan.Spec.Priority,
+			NodeName:          nodeName,
+			ScanPolicy:        clusterScan.Spec.ScanPolicy,
+			ClusterScanPolicy: clusterScan.Spec.ClusterScanPolicy,
+			Priority:          clusterScan.Spec.Priority,
+			// The digest replaces per-node notifications unless they are kept
+			SuppressNotifications: clusterScan.Spec.Notifications != nil &&
+				!clusterScan.Spec.Notifications.PerNodeNotifications,
 		},
 	}
 
@@ -255,6 +368,9 @@ func (r *ClusterScanReconciler) createNodeScanForNode(ctx context.Context, clust
 		if clusterScan.Spec.NodeScanTemplate.ForceFullScan {
 			nodeScan.Spec.ForceFullScan = clusterScan.Spec.NodeScanTemplate.ForceFullScan
 		}
+		if clusterScan.Spec.NodeScanTemplate.Workloads != nil {
+			nodeScan.Spec.Workloads = clusterScan.Spec.NodeScanTemplate.Workloads.DeepCopy()
+		}
 	}
 
 	if err := controllerutil.SetControllerReference(clusterScan, nodeScan, r.Scheme); err != nil {
@@ -230,6 +230,68 @@ func TestClusterScanReconciler_Reconcile_Completion(t *testing.T) {
 	assert.Equal(t, clamavv1alpha1.ClusterScanPhaseCompleted, updatedScan.Status.Phase)
 }
 
+func TestClusterScanReconciler_Reconcile_CompletesOnce(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+	}
+
+	completionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	clusterScan := &clamavv1alpha1.ClusterScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-scan",
+			Namespace: "default",
+		},
+		Spec: clamavv1alpha1.ClusterScanSpec{
+			Concurrent: 1,
+		},
+		Status: clamavv1alpha1.ClusterScanStatus{
+			Phase:          clamavv1alpha1.ClusterScanPhaseCompleted,
+			TotalNodes:     1,
+			CompletionTime: &completionTime,
+		},
+	}
+
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-scan-node-1",
+			Namespace: "default",
+			Labels: map[string]string{
+				"clamav.io/clusterscan": "test-cluster-scan",
+			},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName: "node-1",
+		},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:        clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesScanned: 1000,
+		},
+	}
+
+	r := newTestClusterScanReconciler(node, clusterScan, nodeScan)
+
+	// A later reconcile of the finished scan keeps its completion time
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-cluster-scan",
+			Namespace: "default",
+		},
+	})
+	require.NoError(t, err)
+
+	var updatedScan clamavv1alpha1.ClusterScan
+	err = r.Get(context.Background(), types.NamespacedName{
+		Name:      "test-cluster-scan",
+		Namespace: "default",
+	}, &updatedScan)
+	require.NoError(t, err)
+	assert.Equal(t, clamavv1alpha1.ClusterScanPhaseCompleted, updatedScan.Status.Phase)
+	require.NotNil(t, updatedScan.Status.CompletionTime)
+	assert.True(t, completionTime.Equal(updatedScan.Status.CompletionTime))
+}
+
 func TestClusterScanReconciler_Reconcile_ConcurrencyLimit(t *testing.T) {
 	nodes := []client.Object{
 		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
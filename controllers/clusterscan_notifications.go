@@ -0,0 +1,539 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// digestListLimit caps the nodes and signatures listed in chat digests
+const digestListLimit = 10
+
+// clusterScanDigest groups the findings of the NodeScans of a ClusterScan
+type clusterScanDigest struct {
+	infectedNodes []digestNode
+	signatures    []digestSignature
+	failedNodes   []string
+	// infectedFiles are the infected files of all nodes
+	infectedFiles []reportedFile
+}
+
+// digestNode summarizes the infections found on a node
+type digestNode struct {
+	node          string
+	nodeScan      string
+	filesInfected int64
+	signatures    []string
+	// paths are the infected files by signature
+	paths map[string][]string
+	// workloads are the pods and containers of the infected files by signature
+	workloads map[string][]string
+}
+
+// digestSignature summarizes where a signature was found
+type digestSignature struct {
+	name  string
+	files int
+	nodes []string
+}
+
+// buildClusterScanDigest groups the infected nodes and signatures of the NodeScans.
+// Nodes are sorted by infected files and signatures by the number of nodes they hit.
+func buildClusterScanDigest(nodeScans []clamavv1alpha1.NodeScan) clusterScanDigest {
+	var digest clusterScanDigest
+	signatures := map[string]*digestSignature{}
+
+	for _, ns := range nodeScans {
+		switch ns.Status.Phase {
+		case clamavv1alpha1.NodeScanPhaseFailed:
+			digest.failedNodes = append(digest.failedNodes, ns.Spec.NodeName)
+			continue
+		case clamavv1alpha1.NodeScanPhaseCompleted:
+		default:
+			continue
+		}
+		if ns.Status.FilesInfected == 0 {
+			continue
+		}
+
+		node := digestNode{
+			node:          ns.Spec.NodeName,
+			nodeScan:      ns.Name,
+			filesInfected: ns.Status.FilesInfected,
+			paths:         map[string][]string{},
+			workloads:     map[string][]string{},
+		}
+		seen := map[string]bool{}
+		for _, f := range ns.Status.InfectedFiles {
+			for _, virus := range f.Viruses {
+				node.paths[virus] = append(node.paths[virus], f.Path)
+				node.workloads[virus] = appendUnique(node.workloads[virus], attributionWorkload(f.Attribution))
+				sig, ok := signatures[virus]
+				if !ok {
+					sig = &digestSignature{name: virus}
+					signatures[virus] = sig
+				}
+				sig.files++
+				if !seen[virus] {
+					seen[virus] = true
+					sig.nodes = append(sig.nodes, ns.Spec.NodeName)
+					node.signatures = append(node.signatures, virus)
+				}
+			}
+		}
+		sort.Strings(node.signatures)
+		digest.infectedNodes = append(digest.infectedNodes, node)
+		digest.infectedFiles = append(digest.infectedFiles, nodeScanReportedFiles(&ns)...)
+	}
+
+	for _, sig := range signatures {
+		sort.Strings(sig.nodes)
+		digest.signatures = append(digest.signatures, *sig)
+	}
+	sort.Slice(digest.signatures, func(i, j int) bool {
+		a, b := digest.signatures[i], digest.signatures[j]
+		if len(a.nodes) != len(b.nodes) {
+			return len(a.nodes) > len(b.nodes)
+		}
+		if a.files != b.files {
+			return a.files > b.files
+		}
+		return a.name < b.name
+	})
+	sort.Slice(digest.infectedNodes, func(i, j int) bool {
+		a, b := digest.infectedNodes[i], digest.infectedNodes[j]
+		if a.filesInfected != b.filesInfected {
+			return a.filesInfected > b.filesInfected
+		}
+		return a.node < b.node
+	})
+	sort.Strings(digest.failedNodes)
+
+	return digest
+}
+
+// onlyOnInfection reports whether the channel only notifies about infections.
+// Incident channels never report clean scans.
+func onlyOnInfection(notifications *clamavv1alpha1.NotificationConfig, channel string) bool {
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		return notifications.Slack.OnlyOnInfection
+	case clamavv1alpha1.NotificationChannelEmail:
+		return notifications.Email.OnlyOnInfection
+	case clamavv1alpha1.NotificationChannelWebhook:
+		return notifications.Webhook.OnlyOnInfection
+	case clamavv1alpha1.NotificationChannelTeams:
+		return notifications.Teams.OnlyOnInfection
+	}
+	return true
+}
+
+// enqueueDigest records a pending digest for every enabled channel of a
+// ClusterScan that reached a terminal phase. A channel receives the digest if
+// it is notified of infections and infected reports new malware, if it is notified of
+// partial completions and nodes failed, or if it reports every scan or is
+// notified of scan completions.
+func enqueueDigest(clusterScan *clamavv1alpha1.ClusterScan, infected bool) {
+	if clusterScan.Spec.Notifications == nil {
+		return
+	}
+	notifications := &clusterScan.Spec.Notifications.NotificationConfig
+	partial := clusterScan.Status.Phase == clamavv1alpha1.ClusterScanPhasePartiallyComplete ||
+		clusterScan.Status.Phase == clamavv1alpha1.ClusterScanPhaseFailed
+
+	for _, channel := range enabledNotificationChannels(notifications) {
+		notify := !onlyOnInfection(notifications, channel) ||
+			notifiesOn(notifications, channel, clamavv1alpha1.NotificationTriggerScanCompleted) ||
+			(infected && notifiesOn(notifications, channel, clamavv1alpha1.NotificationTriggerInfection)) ||
+			(partial && notifiesOn(notifications, channel, clamavv1alpha1.NotificationTriggerPartiallyCompleted))
+		if !notify {
+			continue
+		}
+		if findNotification(clusterScan.Status.Notifications, channel, clamavv1alpha1.NotificationEventClusterScanCompleted) != nil {
+			continue
+		}
+		clusterScan.Status.Notifications = append(clusterScan.Status.Notifications, clamavv1alpha1.NotificationStatus{
+			Channel: channel,
+			Event:   clamavv1alpha1.NotificationEventClusterScanCompleted,
+			State:   clamavv1alpha1.NotificationStatePending,
+		})
+	}
+}
+
+// reconcileNotifications delivers the pending digests of a ClusterScan whose
+// retry time has come
+func (r *ClusterScanReconciler) reconcileNotifications(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan, nodeScans []clamavv1alpha1.NodeScan) (ctrl.Result, error) {
+	var notifications *clamavv1alpha1.NotificationConfig
+	if clusterScan.Spec.Notifications != nil {
+		notifications = &clusterScan.Spec.Notifications.NotificationConfig
+	}
+	queue := &notificationQueue{
+		client:        r.Client,
+		scheme:        r.Scheme,
+		recorder:      r.Recorder,
+		retry:         retryPolicyFor(notifications),
+		notifications: notifications,
+		owner:         clusterScan,
+		kind:          "clusterScan",
+		labels: map[string]string{
+			"clamav.io/clusterscan": clusterScan.Name,
+		},
+		deliver: func(ctx context.Context, channel, event string) error {
+			return r.deliverDigest(ctx, clusterScan, buildClusterScanDigest(nodeScans), channel, event)
+		},
+	}
+
+	if err := queue.replay(ctx, &clusterScan.Status.Notifications); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(clusterScan.Status.Notifications) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	changed, nextAttempt, err := queue.process(ctx, clusterScan.Status.Notifications)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err := r.Status().Update(ctx, clusterScan); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if nextAttempt.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Until(nextAttempt)}, nil
+}
+
+// deliverDigest sends the digest of a ClusterScan on the channel
+func (r *ClusterScanReconciler) deliverDigest(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest, channel, event string) error {
+	if event != clamavv1alpha1.NotificationEventClusterScanCompleted {
+		return fmt.Errorf("unknown notification event %q", event)
+	}
+	if clusterScan.Spec.Notifications == nil {
+		return fmt.Errorf("notifications are no longer configured")
+	}
+	if err := checkNotificationChannel(&clusterScan.Spec.Notifications.NotificationConfig, channel); err != nil {
+		return err
+	}
+
+	if usesCloudEvents(&clusterScan.Spec.Notifications.NotificationConfig, channel) {
+		return sendCloudEvents(ctx, r.Client, clusterScan.Spec.Notifications.Webhook, clusterScan.Namespace,
+			[]cloudEvent{clusterScanCloudEvent(clusterScan, digest)})
+	}
+
+	if hasNotificationTemplate(&clusterScan.Spec.Notifications.NotificationConfig, channel) {
+		a := clusterScanDigestAlert(clusterScan, digest)
+		data := newNotificationTemplateData(event, a, r.ClusterName)
+		data.ClusterScan = clusterScan
+		return sendTemplated(ctx, r.Client, &clusterScan.Spec.Notifications.NotificationConfig, clusterScan.Namespace,
+			channel, a, data)
+	}
+
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		return r.sendSlackDigest(ctx, clusterScan, digest)
+	case clamavv1alpha1.NotificationChannelEmail:
+		return r.sendEmailDigest(ctx, clusterScan, digest)
+	case clamavv1alpha1.NotificationChannelWebhook:
+		return r.sendWebhookDigest(ctx, clusterScan, digest)
+	default:
+		return sendAlert(ctx, r.Client, &clusterScan.Spec.Notifications.NotificationConfig, clusterScan.Namespace,
+			channel, clusterScanDigestAlert(clusterScan, digest))
+	}
+}
+
+// clusterScanDigestAlert describes the digest of a ClusterScan with one
+// finding per infected node and signature
+func clusterScanDigestAlert(clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest) alert {
+	status := clusterScan.Status
+	a := alert{
+		title:     "ClamAV Cluster Scan Completed",
+		eventType: "clamav.clusterscan.completed",
+		severity:  "info",
+		message: fmt.Sprintf("%d of %d nodes scanned, no malware detected",
+			status.CompletedNodes, status.TotalNodes),
+		source:   clusterScan.Name,
+		dedupKey: fmt.Sprintf("clamav/clusterscan/%s/%s", clusterScan.Namespace, clusterScan.Name),
+		fields: []alertField{
+			{key: "name", title: "Cluster Scan", value: clusterScan.Name},
+			{key: "namespace", title: "Namespace", value: clusterScan.Namespace},
+			{key: "phase", title: "Status", value: string(status.Phase)},
+			{key: "nodesScanned", title: "Nodes Scanned", value: fmt.Sprintf("%d/%d", status.CompletedNodes, status.TotalNodes)},
+			{key: "failedNodes", title: "Nodes Failed", value: fmt.Sprintf("%d", status.FailedNodes)},
+			{key: "infectedNodes", title: "Nodes Infected", value: fmt.Sprintf("%d", status.InfectedNodes)},
+			{key: "filesInfected", title: "Files Infected", value: fmt.Sprintf("%d of %d scanned", status.TotalFilesInfected, status.TotalFilesScanned)},
+		},
+	}
+
+	if status.FailedNodes > 0 {
+		a.title = "ClamAV Cluster Scan Partially Completed"
+		a.severity = "warning"
+		a.message = fmt.Sprintf("%d of %d nodes failed: %s", status.FailedNodes, status.TotalNodes,
+			strings.Join(digest.failedNodes, ", "))
+	}
+	if status.TotalFilesInfected > 0 {
+		a.title = "ClamAV Malware Detected"
+		a.severity = "critical"
+		a.message = fmt.Sprintf("Malware detected on %d of %d nodes, %d nodes failed",
+			status.InfectedNodes, status.TotalNodes, status.FailedNodes)
+	}
+
+	for _, node := range digest.infectedNodes {
+		for _, sig := range node.signatures {
+			a.findings = append(a.findings, alertFinding{node: node.node, signature: sig,
+				paths: node.paths[sig], workloads: node.workloads[sig]})
+		}
+	}
+	a.infectedFiles = digest.infectedFiles
+	return a
+}
+
+// sendSlackDigest posts the digest of a ClusterScan to Slack
+func (r *ClusterScanReconciler) sendSlackDigest(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest) error {
+	config := clusterScan.Spec.Notifications.Slack
+	status := clusterScan.Status
+
+	color := "good"
+	icon := "✅"
+	if status.TotalFilesInfected > 0 {
+		color = "danger"
+		icon = "🚨"
+	} else if status.FailedNodes > 0 {
+		color = "warning"
+		icon = "⚠️"
+	}
+
+	fields := []map[string]interface{}{
+		{
+			"title": "Cluster Scan",
+			"value": clusterScan.Name,
+			"short": true,
+		},
+		{
+			"title": "Status",
+			"value": string(status.Phase),
+			"short": true,
+		},
+		{
+			"title": "Nodes Scanned",
+			"value": fmt.Sprintf("%d/%d", status.CompletedNodes, status.TotalNodes),
+			"short": true,
+		},
+		{
+			"title": "Nodes Failed",
+			"value": fmt.Sprintf("%d", status.FailedNodes),
+			"short": true,
+		},
+		{
+			"title": "Nodes Infected",
+			"value": fmt.Sprintf("%d", status.InfectedNodes),
+			"short": true,
+		},
+		{
+			"title": "Files Infected",
+			"value": fmt.Sprintf("%d of %d scanned", status.TotalFilesInfected, status.TotalFilesScanned),
+			"short": true,
+		},
+	}
+
+	if len(digest.signatures) > 0 {
+		var lines []string
+		for i, sig := range digest.signatures {
+			if i >= digestListLimit {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(digest.signatures)-digestListLimit))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• `%s` - %d files on %d nodes", sig.name, sig.files, len(sig.nodes)))
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": "Signatures",
+			"value": strings.Join(lines, "\n"),
+			"short": false,
+		})
+	}
+
+	if len(digest.infectedNodes) > 0 {
+		var lines []string
+		for i, node := range digest.infectedNodes {
+			if i >= digestListLimit {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(digest.infectedNodes)-digestListLimit))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• %s - %d files: %s", node.node, node.filesInfected, strings.Join(node.signatures, ", ")))
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": "Infected Nodes",
+			"value": strings.Join(lines, "\n"),
+			"short": false,
+		})
+	}
+
+	message := map[string]interface{}{
+		"channel":    config.Channel,
+		"username":   "ClamAV Operator",
+		"icon_emoji": ":shield:",
+		"text":       fmt.Sprintf("%s ClamAV Cluster Scan Completed", icon),
+		"attachments": []map[string]interface{}{
+			{
+				"color":  color,
+				"fields": fields,
+				"footer": "ClamAV Operator",
+				"ts":     time.Now().Unix(),
+			},
+		},
+	}
+
+	return postSlackMessage(ctx, r.Client, config, clusterScan.Namespace, message)
+}
+
+// sendEmailDigest emails the digest of a ClusterScan
+func (r *ClusterScanReconciler) sendEmailDigest(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest) error {
+	config := clusterScan.Spec.Notifications.Email
+	status := clusterScan.Status
+
+	subject := fmt.Sprintf("ClamAV Cluster Scan %s Completed", clusterScan.Name)
+	if status.TotalFilesInfected > 0 {
+		subject = fmt.Sprintf("🚨 ALERT: Malware Detected on %d Nodes by ClamAV", status.InfectedNodes)
+	} else if status.FailedNodes > 0 {
+		subject = fmt.Sprintf("⚠️ ClamAV Cluster Scan %s: %d Nodes Failed", clusterScan.Name, status.FailedNodes)
+	}
+
+	var body strings.Builder
+	body.WriteString("================================================================================\n")
+	body.WriteString("                      ClamAV CLUSTER SCAN REPORT\n")
+	body.WriteString("================================================================================\n\n")
+
+	body.WriteString(fmt.Sprintf("Cluster Scan:      %s/%s\n", clusterScan.Namespace, clusterScan.Name))
+	body.WriteString(fmt.Sprintf("Status:            %s\n", status.Phase))
+	if status.StartTime != nil {
+		body.WriteString(fmt.Sprintf("Scan Date:         %s\n", status.StartTime.Format(time.RFC3339)))
+	}
+	if status.CompletionTime != nil {
+		body.WriteString(fmt.Sprintf("Completed:         %s\n", status.CompletionTime.Format(time.RFC3339)))
+	}
+	body.WriteString("\n")
+
+	body.WriteString("STATISTICS:\n")
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	body.WriteString(fmt.Sprintf("Nodes Scanned:     %d/%d\n", status.CompletedNodes, status.TotalNodes))
+	body.WriteString(fmt.Sprintf("Nodes Failed:      %d\n", status.FailedNodes))
+	body.WriteString(fmt.Sprintf("Infected Nodes:    %d\n", status.InfectedNodes))
+	body.WriteString(fmt.Sprintf("Files Scanned:     %d\n", status.TotalFilesScanned))
+	body.WriteString(fmt.Sprintf("Files Infected:    %d\n", status.TotalFilesInfected))
+	body.WriteString("\n")
+
+	if len(digest.signatures) > 0 {
+		body.WriteString("⚠️  SIGNATURES DETECTED:\n")
+		body.WriteString("================================================================================\n\n")
+		for i, sig := range digest.signatures {
+			body.WriteString(fmt.Sprintf("%d. %s\n", i+1, sig.name))
+			body.WriteString(fmt.Sprintf("   Files: %d\n", sig.files))
+			body.WriteString(fmt.Sprintf("   Nodes: %s\n", strings.Join(sig.nodes, ", ")))
+			body.WriteString("\n")
+		}
+
+		body.WriteString("INFECTED NODES:\n")
+		body.WriteString("--------------------------------------------------------------------------------\n")
+		for _, node := range digest.infectedNodes {
+			body.WriteString(fmt.Sprintf("%s (NodeScan %s): %d files - %s\n",
+				node.node, node.nodeScan, node.filesInfected, strings.Join(node.signatures, ", ")))
+		}
+		body.WriteString("\n")
+	} else {
+		body.WriteString("✅ NO MALWARE DETECTED\n")
+		body.WriteString("\n")
+	}
+
+	if len(digest.failedNodes) > 0 {
+		body.WriteString("FAILED NODES:\n")
+		body.WriteString("--------------------------------------------------------------------------------\n")
+		body.WriteString(strings.Join(digest.failedNodes, "\n"))
+		body.WriteString("\n\n")
+	}
+
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	body.WriteString("This is an automated message from ClamAV Operator.\n")
+	body.WriteString("Per-file details are available in the status of each NodeScan.\n")
+	body.WriteString("================================================================================\n")
+
+	return deliverEmail(ctx, r.Client, config, clusterScan.Namespace,
+		alertEmail(subject, body.String(), clusterScanDigestAlert(clusterScan, digest)))
+}
+
+// sendWebhookDigest posts the digest of a ClusterScan to the webhook
+func (r *ClusterScanReconciler) sendWebhookDigest(ctx context.Context, clusterScan *clamavv1alpha1.ClusterScan, digest clusterScanDigest) error {
+	config := clusterScan.Spec.Notifications.Webhook
+	status := clusterScan.Status
+
+	signatures := []map[string]interface{}{}
+	for _, sig := range digest.signatures {
+		signatures = append(signatures, map[string]interface{}{
+			"name":  sig.name,
+			"files": sig.files,
+			"nodes": sig.nodes,
+		})
+	}
+	infectedNodes := []map[string]interface{}{}
+	for _, node := range digest.infectedNodes {
+		infectedNodes = append(infectedNodes, map[string]interface{}{
+			"node":          node.node,
+			"nodeScan":      node.nodeScan,
+			"filesInfected": node.filesInfected,
+			"signatures":    node.signatures,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"type":      "clamav.clusterscan.completed",
+		"timestamp": time.Now().Format(time.RFC3339),
+		"clusterScan": map[string]interface{}{
+			"name":           clusterScan.Name,
+			"namespace":      clusterScan.Namespace,
+			"phase":          status.Phase,
+			"totalNodes":     status.TotalNodes,
+			"completedNodes": status.CompletedNodes,
+			"failedNodes":    status.FailedNodes,
+			"infectedNodes":  status.InfectedNodes,
+			"filesScanned":   status.TotalFilesScanned,
+			"filesInfected":  status.TotalFilesInfected,
+			"startTime":      status.StartTime,
+			"completionTime": status.CompletionTime,
+		},
+		"signatures":    signatures,
+		"infectedNodes": infectedNodes,
+		"failedNodes":   digest.failedNodes,
+		"severity":      "info",
+	}
+	if status.TotalFilesInfected > 0 {
+		payload["severity"] = "critical"
+	} else if status.FailedNodes > 0 {
+		payload["severity"] = "warning"
+	}
+
+	return postWebhook(ctx, r.Client, config, clusterScan.Namespace, payload)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestDigestNodeScan(node string, phase clamavv1alpha1.NodeScanPhase, viruses ...string) *clamavv1alpha1.NodeScan {
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-scan-" + node,
+			Namespace: "default",
+			Labels: map[string]string{
+				"clamav.io/clusterscan": "test-cluster-scan",
+			},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{NodeName: node},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         phase,
+			FilesScanned:  100,
+			FilesInfected: int64(len(viruses)),
+		},
+	}
+	for i, virus := range viruses {
+		nodeScan.Status.InfectedFiles = append(nodeScan.Status.InfectedFiles, clamavv1alpha1.InfectedFile{
+			Path:    fmt.Sprintf("/host/var/lib/file-%d", i),
+			Viruses: []string{virus},
+		})
+	}
+	return nodeScan
+}
+
+func TestBuildClusterScanDigest(t *testing.T) {
+	digest := buildClusterScanDigest([]clamavv1alpha1.NodeScan{
+		*newTestDigestNodeScan("node-a", clamavv1alpha1.NodeScanPhaseCompleted, "Eicar-Signature"),
+		*newTestDigestNodeScan("node-b", clamavv1alpha1.NodeScanPhaseCompleted, "Eicar-Signature", "Eicar-Signature", "Win.Trojan.Agent"),
+		*newTestDigestNodeScan("node-c", clamavv1alpha1.NodeScanPhaseCompleted),
+		*newTestDigestNodeScan("node-d", clamavv1alpha1.NodeScanPhaseFailed),
+		*newTestDigestNodeScan("node-e", clamavv1alpha1.NodeScanPhaseRunning),
+	})
+
+	require.Len(t, digest.signatures, 2)
+	assert.Equal(t, "Eicar-Signature", digest.signatures[0].name)
+	assert.Equal(t, 3, digest.signatures[0].files)
+	assert.Equal(t, []string{"node-a", "node-b"}, digest.signatures[0].nodes)
+	assert.Equal(t, "Win.Trojan.Agent", digest.signatures[1].name)
+	assert.Equal(t, []string{"node-b"}, digest.signatures[1].nodes)
+
+	require.Len(t, digest.infectedNodes, 2)
+	assert.Equal(t, "node-b", digest.infectedNodes[0].node)
+	assert.Equal(t, int64(3), digest.infectedNodes[0].filesInfected)
+	assert.Equal(t, []string{"Eicar-Signature", "Win.Trojan.Agent"}, digest.infectedNodes[0].signatures)
+	assert.Equal(t, "node-a", digest.infectedNodes[1].node)
+
+	assert.Equal(t, []string{"node-d"}, digest.failedNodes)
+}
+
+func TestEnqueueDigest(t *testing.T) {
+	clusterScan := &clamavv1alpha1.ClusterScan{
+		Spec: clamavv1alpha1.ClusterScanSpec{
+			Notifications: &clamavv1alpha1.ClusterScanNotifications{
+				NotificationConfig: clamavv1alpha1.NotificationConfig{
+					Slack:   &clamavv1alpha1.SlackConfig{Enabled: true, OnlyOnInfection: true},
+					Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com/hook"},
+				},
+			},
+		},
+	}
+
+	// Clean scans only notify channels that report every scan
+	enqueueDigest(clusterScan, false)
+	require.Len(t, clusterScan.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationChannelWebhook, clusterScan.Status.Notifications[0].Channel)
+
+	clusterScan.Status.TotalFilesInfected = 1
+	enqueueDigest(clusterScan, true)
+	enqueueDigest(clusterScan, true)
+	require.Len(t, clusterScan.Status.Notifications, 2)
+	assert.Equal(t, clamavv1alpha1.NotificationChannelSlack, clusterScan.Status.Notifications[1].Channel)
+	assert.Equal(t, clamavv1alpha1.NotificationEventClusterScanCompleted, clusterScan.Status.Notifications[1].Event)
+}
+
+func TestEnqueueDigest_PartiallyCompleted(t *testing.T) {
+	clusterScan := &clamavv1alpha1.ClusterScan{
+		Spec: clamavv1alpha1.ClusterScanSpec{
+			Notifications: &clamavv1alpha1.ClusterScanNotifications{
+				NotificationConfig: clamavv1alpha1.NotificationConfig{
+					Slack: &clamavv1alpha1.SlackConfig{Enabled: true, OnlyOnInfection: true},
+					Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com/hook", OnlyOnInfection: true,
+						Triggers: []clamavv1alpha1.NotificationTrigger{clamavv1alpha1.NotificationTriggerPartiallyCompleted}},
+				},
+			},
+		},
+		Status: clamavv1alpha1.ClusterScanStatus{
+			Phase:       clamavv1alpha1.ClusterScanPhasePartiallyComplete,
+			FailedNodes: 1,
+		},
+	}
+
+	enqueueDigest(clusterScan, false)
+	require.Len(t, clusterScan.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationChannelWebhook, clusterScan.Status.Notifications[0].Channel)
+}
+
+func TestClusterScanReconciler_Reconcile_SendsDigest(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clusterScan := &clamavv1alpha1.ClusterScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-scan",
+			Namespace: "default",
+		},
+		Spec: clamavv1alpha1.ClusterScanSpec{
+			Concurrent: 2,
+			Notifications: &clamavv1alpha1.ClusterScanNotifications{
+				NotificationConfig: clamavv1alpha1.NotificationConfig{
+					Webhook: &clamavv1alpha1.WebhookConfig{URL: server.URL, OnlyOnInfection: true},
+				},
+			},
+		},
+		Status: clamavv1alpha1.ClusterScanStatus{
+			Phase:      clamavv1alpha1.ClusterScanPhaseRunning,
+			TotalNodes: 2,
+		},
+	}
+	r := newTestClusterScanReconciler(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		clusterScan,
+		newTestDigestNodeScan("node-1", clamavv1alpha1.NodeScanPhaseCompleted, "Eicar-Signature"),
+		newTestDigestNodeScan("node-2", clamavv1alpha1.NodeScanPhaseCompleted, "Eicar-Signature"),
+	)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-cluster-scan", Namespace: "default"}
+
+	// Later reconciles of the completed ClusterScan do not resend the digest
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+	}
+
+	require.Len(t, payloads, 1)
+	payload := payloads[0]
+	assert.Equal(t, "clamav.clusterscan.completed", payload["type"])
+	assert.Equal(t, "critical", payload["severity"])
+	signatures := payload["signatures"].([]interface{})
+	require.Len(t, signatures, 1)
+	signature := signatures[0].(map[string]interface{})
+	assert.Equal(t, "Eicar-Signature", signature["name"])
+	assert.ElementsMatch(t, []interface{}{"node-1", "node-2"}, signature["nodes"])
+	assert.Len(t, payload["infectedNodes"], 2)
+
+	var updated clamavv1alpha1.ClusterScan
+	require.NoError(t, r.Get(ctx, key, &updated))
+	require.Len(t, updated.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationStateSent, updated.Status.Notifications[0].State)
+}
+
+func TestClusterScanReconciler_Reconcile_PerNodeNotifications(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications *clamavv1alpha1.ClusterScanNotifications
+		wantSuppress  bool
+	}{
+		{
+			name: "no digest keeps per-node notifications",
+		},
+		{
+			name: "digest replaces per-node notifications",
+			notifications: &clamavv1alpha1.ClusterScanNotifications{
+				NotificationConfig: clamavv1alpha1.NotificationConfig{
+					Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com/hook"},
+				},
+			},
+			wantSuppress: true,
+		},
+		{
+			name: "digest with per-node notifications",
+			notifications: &clamavv1alpha1.ClusterScanNotifications{
+				NotificationConfig: clamavv1alpha1.NotificationConfig{
+					Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com/hook"},
+				},
+				PerNodeNotifications: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterScan := &clamavv1alpha1.ClusterScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-scan",
+					Namespace: "default",
+				},
+				Spec: clamavv1alpha1.ClusterScanSpec{
+					Concurrent:    1,
+					Notifications: tt.notifications,
+				},
+			}
+			r := newTestClusterScanReconciler(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}, clusterScan)
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-cluster-scan", Namespace: "default"},
+			})
+			require.NoError(t, err)
+
+			var nodeScans clamavv1alpha1.NodeScanList
+			require.NoError(t, r.List(context.Background(), &nodeScans, client.InNamespace("default")))
+			require.Len(t, nodeScans.Items, 1)
+			assert.Equal(t, tt.wantSuppress, nodeScans.Items[0].Spec.SuppressNotifications)
+		})
+	}
+}
@@ -18,7 +18,8 @@ package controllers
 
 import (
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
 )
 
 // Default resource limits and requests for scan jobs.
@@ -26,86 +27,55 @@ import (
 // in NodeScan, ClusterScan, or ScanPolicy resources.
 var (
 	// DefaultScannerResources defines the default resource requirements for scanner jobs.
-	// These values balance scan performance with cluster resource conservation.
-	DefaultScannerResources = corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			// CPU request: minimum CPU guaranteed for the scan job
-			corev1.ResourceCPU: resource.MustParse("100m"),
-			// Memory request: minimum memory guaranteed for the scan job
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
-		Limits: corev1.ResourceList{
-			// CPU limit: maximum CPU the scan job can use
-			// Set to 1 core to prevent scans from impacting node performance
-			corev1.ResourceCPU: resource.MustParse("1000m"),
-			// Memory limit: maximum memory the scan job can use
-			// Set to 512Mi to handle large file scanning without OOM
-			corev1.ResourceMemory: resource.MustParse("512Mi"),
-		},
-	}
+	DefaultScannerResources = clamavv1alpha1.DefaultScannerResources
 
 	// HighPriorityScannerResources defines resources for high-priority scans.
-	// Used when NodeScan.Spec.Priority is set to "high".
-	HighPriorityScannerResources = corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("500m"),
-			corev1.ResourceMemory: resource.MustParse("512Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("2000m"),
-			corev1.ResourceMemory: resource.MustParse("1Gi"),
-		},
-	}
+	HighPriorityScannerResources = clamavv1alpha1.HighPriorityScannerResources
 
 	// LowPriorityScannerResources defines resources for low-priority/background scans.
-	// Used when NodeScan.Spec.Priority is set to "low".
-	LowPriorityScannerResources = corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("50m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("500m"),
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
-	}
+	LowPriorityScannerResources = clamavv1alpha1.LowPriorityScannerResources
 )
 
 // Default scan configuration values
 const (
 	// DefaultMaxConcurrent is the default number of files to scan in parallel
-	DefaultMaxConcurrent = 5
+	DefaultMaxConcurrent = clamavv1alpha1.DefaultMaxConcurrent
 
 	// DefaultFileTimeout is the default timeout for scanning a single file (ms)
-	DefaultFileTimeout = 300000 // 5 minutes
+	DefaultFileTimeout = clamavv1alpha1.DefaultFileTimeout
 
 	// DefaultMaxFileSize is the default maximum file size to scan (bytes)
-	DefaultMaxFileSize = 104857600 // 100MB
+	DefaultMaxFileSize = clamavv1alpha1.DefaultMaxFileSize
 
 	// DefaultConnectTimeout is the default timeout for connecting to ClamAV (ms)
-	DefaultConnectTimeout = 60000 // 60 seconds
+	DefaultConnectTimeout = clamavv1alpha1.DefaultConnectTimeout
 
 	// DefaultTTLSecondsAfterFinished is the default TTL for completed jobs
-	DefaultTTLSecondsAfterFinished = 86400 // 24 hours
+	DefaultTTLSecondsAfterFinished = clamavv1alpha1.DefaultTTLSecondsAfterFinished
 
 	// DefaultConcurrentClusterScans is the default number of parallel node scans in ClusterScan
 	DefaultConcurrentClusterScans = 3
 )
 
+// Default incremental scan configuration values
+const (
+	// DefaultBaselineInterval is the number of incremental scans between two full scans
+	DefaultBaselineInterval = 7
+
+	// DefaultIncrementalMaxAge is the maximum file age (hours) for modified-only scans
+	DefaultIncrementalMaxAge = 24
+
+	// DefaultMinTimeBetweenScans is the minimum delay (hours) between two incremental scans
+	DefaultMinTimeBetweenScans = 6
+
+	// DefaultCacheExpiration is the scan cache validity (hours) before a full scan is forced
+	DefaultCacheExpiration = 168 // 7 days
+)
+
 // Default paths to scan if none specified
-var DefaultScanPaths = []string{
-	"/host/var/lib",
-	"/host/opt",
-}
+var DefaultScanPaths = clamavv1alpha1.DefaultScanPaths
 
 // GetResourcesForPriority returns the appropriate resource requirements based on scan priority.
 func GetResourcesForPriority(priority string) corev1.ResourceRequirements {
-	switch priority {
-	case "high":
-		return HighPriorityScannerResources
-	case "low":
-		return LowPriorityScannerResources
-	default:
-		return DefaultScannerResources
-	}
+	return clamavv1alpha1.ResourcesForPriority(priority)
 }
@@ -0,0 +1,350 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// overlayMountsContainer is the init container of scanner Jobs that
+	// prints the overlay mounts of the node
+	overlayMountsContainer = "overlay-mounts"
+
+	// overlayMountMarker prefixes the lines printed by overlayMountsScript
+	overlayMountMarker = "OVERLAY_MOUNT"
+
+	// kubeletPodsDir holds the volumes and files of the pods of a node, by pod UID
+	kubeletPodsDir = "/var/lib/kubelet/pods/"
+
+	// podLogsDir holds the container logs of the pods of a node
+	podLogsDir = "/var/log/pods/"
+)
+
+// overlayMountsScript prints one tab-separated line per overlay mount of the
+// host: marker, upper directory, mount point. Container runtimes mount the
+// writable layer of each container at a path named after its ID, which maps
+// containerd snapshots back to containers.
+const overlayMountsScript = `while read -r source target fstype options rest; do
+  [ "$fstype" = overlay ] || continue
+  upper=$(printf '%s' "$options" | tr ',' '\n' | sed -n 's/^upperdir=//p')
+  [ -n "$upper" ] && printf 'OVERLAY_MOUNT\t%s\t%s\n' "$upper" "$target"
+done < /proc/1/mounts
+exit 0
+`
+
+// containerDirPrefixes are the container runtime directories named after the
+// ID of a container
+var containerDirPrefixes = []string{
+	"/run/containerd/io.containerd.runtime.v2.task/k8s.io/",
+	"/run/containerd/io.containerd.runtime.v1.linux/k8s.io/",
+	"/var/lib/containerd/io.containerd.grpc.v1.cri/containers/",
+	"/var/lib/docker/containers/",
+	"/var/lib/containers/storage/overlay-containers/",
+	"/run/containers/storage/overlay-containers/",
+}
+
+// overlayMountsInitContainer returns the init container recording the overlay
+// mounts of the node before the scan starts
+func (r *NodeScanReconciler) overlayMountsInitContainer() corev1.Container {
+	return corev1.Container{
+		Name:            overlayMountsContainer,
+		Image:           r.ScannerImage,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/bin/sh", "-c", overlayMountsScript},
+		SecurityContext: &corev1.SecurityContext{
+			// Reading the mount table of the host init process
+			Privileged: ptr.To(true),
+		},
+	}
+}
+
+// containerRef is a container of a pod running on the scanned node
+type containerRef struct {
+	pod   *corev1.Pod
+	name  string
+	image string
+}
+
+// workloadIndex maps the container runtime and kubelet directories of a node
+// to its pods and containers
+type workloadIndex struct {
+	pods map[types.UID]*corev1.Pod
+	// containers by container ID, without the runtime prefix
+	containers map[string]containerRef
+	// upperDirs maps overlay upper directories to their mount point
+	upperDirs map[string]string
+}
+
+// newWorkloadIndex indexes the pods of a node and its overlay mounts
+func newWorkloadIndex(pods []corev1.Pod, upperDirs map[string]string) *workloadIndex {
+	idx := &workloadIndex{
+		pods:       map[types.UID]*corev1.Pod{},
+		containers: map[string]containerRef{},
+		upperDirs:  upperDirs,
+	}
+	for i := range pods {
+		pod := &pods[i]
+		idx.pods[pod.UID] = pod
+		statuses := append(append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+			pod.Status.ContainerStatuses...), pod.Status.EphemeralContainerStatuses...)
+		for _, status := range statuses {
+			if _, id, ok := strings.Cut(status.ContainerID, "://"); ok && id != "" {
+				idx.containers[id] = containerRef{pod: pod, name: status.Name, image: status.Image}
+			}
+		}
+	}
+	return idx
+}
+
+// attribute returns the pod and container an infected file belongs to, or nil
+// if the file is not stored under a known runtime or kubelet directory
+func (idx *workloadIndex) attribute(filePath string) *clamavv1alpha1.FileAttribution {
+	p := path.Clean(filePath)
+	if strings.HasPrefix(p, hostRootMount+"/") {
+		p = strings.TrimPrefix(p, hostRootMount)
+	}
+
+	// Files in the writable layer of a container are reached through the
+	// mount point of the layer, which is named after the container
+	for upper, target := range idx.upperDirs {
+		if p == upper || strings.HasPrefix(p, upper+"/") {
+			p = target
+			break
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(p, kubeletPodsDir); ok {
+		// <uid>/volumes/<plugin>/<volume>, <uid>/volume-subpaths/<volume>/<container>
+		// or <uid>/containers/<container>
+		parts := strings.Split(rest, "/")
+		pod := idx.pods[types.UID(parts[0])]
+		if pod == nil {
+			return nil
+		}
+		a := &clamavv1alpha1.FileAttribution{Namespace: pod.Namespace, Pod: pod.Name}
+		switch {
+		case len(parts) >= 4 && parts[1] == "volumes":
+			setVolumeAttribution(a, pod, parts[3], "")
+		case len(parts) >= 4 && parts[1] == "volume-subpaths":
+			setVolumeAttribution(a, pod, parts[2], parts[3])
+		case len(parts) >= 3 && parts[1] == "containers":
+			setContainerAttribution(a, pod, parts[2])
+		}
+		return a
+	}
+
+	if rest, ok := strings.CutPrefix(p, podLogsDir); ok {
+		// <namespace>_<pod>_<uid>/<container>/<restart>.log
+		parts := strings.Split(rest, "/")
+		pod := idx.pods[types.UID(parts[0][strings.LastIndex(parts[0], "_")+1:])]
+		if pod == nil {
+			return nil
+		}
+		a := &clamavv1alpha1.FileAttribution{Namespace: pod.Namespace, Pod: pod.Name}
+		if len(parts) >= 2 {
+			setContainerAttribution(a, pod, parts[1])
+		}
+		return a
+	}
+
+	for _, prefix := range containerDirPrefixes {
+		if rest, ok := strings.CutPrefix(p, prefix); ok {
+			id, _, _ := strings.Cut(rest, "/")
+			if c, ok := idx.containers[id]; ok {
+				return &clamavv1alpha1.FileAttribution{
+					Namespace: c.pod.Namespace,
+					Pod:       c.pod.Name,
+					Container: c.name,
+					Image:     c.image,
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// setVolumeAttribution records the pod volume holding a file. The container is
+// known for subpath mounts, or when a single container mounts the volume.
+func setVolumeAttribution(a *clamavv1alpha1.FileAttribution, pod *corev1.Pod, volume, container string) {
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == volume {
+			a.Volume = volume
+		}
+	}
+	if container != "" {
+		setContainerAttribution(a, pod, container)
+		return
+	}
+
+	var mountedBy []string
+	for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, m := range c.VolumeMounts {
+			if m.Name == volume {
+				mountedBy = append(mountedBy, c.Name)
+				break
+			}
+		}
+	}
+	if len(mountedBy) == 1 {
+		setContainerAttribution(a, pod, mountedBy[0])
+	}
+}
+
+// setContainerAttribution records the container of a file and its image
+func setContainerAttribution(a *clamavv1alpha1.FileAttribution, pod *corev1.Pod, container string) {
+	a.Container = container
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if status.Name == container && status.Image != "" {
+			a.Image = status.Image
+			return
+		}
+	}
+	for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if c.Name == container {
+			a.Image = c.Image
+			return
+		}
+	}
+}
+
+// attributeInfectedFiles records the pod and container of the infected files
+// of a scan result. Attribution is best effort: files of pods deleted since
+// the scan, and of runtimes whose layers are not named after containers, are
+// left unattributed.
+func (r *NodeScanReconciler) attributeInfectedFiles(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, job *batchv1.Job, result *scanResult) error {
+	if len(result.Infected) == 0 {
+		return nil
+	}
+
+	pods, err := r.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeScan.Spec.NodeName).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of node %s: %w", nodeScan.Spec.NodeName, err)
+	}
+	var nodePods []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == nodeScan.Spec.NodeName {
+			nodePods = append(nodePods, pod)
+		}
+	}
+
+	// Without the overlay mounts, files in container layers stay unattributed
+	upperDirs, err := r.readOverlayMounts(ctx, job)
+	if err != nil {
+		log.FromContext(ctx).Info("overlay mounts unavailable, container layers are not attributed", "error", err.Error())
+	}
+
+	idx := newWorkloadIndex(nodePods, upperDirs)
+	for i := range result.Infected {
+		result.Infected[i].Attribution = idx.attribute(result.Infected[i].Path)
+	}
+	return nil
+}
+
+// readOverlayMounts returns the overlay mount points of the node by upper
+// directory, as printed by the overlay-mounts init container of the Job
+func (r *NodeScanReconciler) readOverlayMounts(ctx context.Context, job *batchv1.Job) (map[string]string, error) {
+	if job.Spec.Selector == nil {
+		return nil, fmt.Errorf("job %s has no pod selector", job.Name)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels(job.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+
+	mounts := map[string]string{}
+	var lastErr error
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: overlayMountsContainer,
+		})
+		stream, err := req.Stream(ctx)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get pod logs: %w", err)
+			continue
+		}
+
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) != 3 || fields[0] != overlayMountMarker {
+				continue
+			}
+			mounts[path.Clean(fields[1])] = path.Clean(fields[2])
+		}
+		err = scanner.Err()
+		stream.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return mounts, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no completed pods found for job %s", job.Name)
+	}
+	return nil, lastErr
+}
+
+// attributionWorkload describes the workload of an attributed file as
+// namespace/pod/container
+func attributionWorkload(a *clamavv1alpha1.FileAttribution) string {
+	if a == nil {
+		return ""
+	}
+	workload := a.Namespace + "/" + a.Pod
+	if a.Container != "" {
+		workload += "/" + a.Container
+	}
+	return workload
+}
+
+// appendUnique appends the value to the list unless it is empty or listed
+func appendUnique(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// newAttributionTestPod returns a pod of worker-1 with an app container and a
+// log shipping sidecar sharing the cache volume
+func newAttributionTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f", Namespace: "shop", UID: "0f3c-uid"},
+		Spec: corev1.PodSpec{
+			NodeName: "worker-1",
+			Containers: []corev1.Container{
+				{Name: "app", Image: "registry.example.com/shop/web:1.2",
+					VolumeMounts: []corev1.VolumeMount{{Name: "uploads"}, {Name: "cache"}}},
+				{Name: "logs", Image: "fluent-bit:3", VolumeMounts: []corev1.VolumeMount{{Name: "cache"}}},
+			},
+			Volumes: []corev1.Volume{{Name: "uploads"}, {Name: "cache"}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", Image: "registry.example.com/shop/web:1.2", ContainerID: "containerd://a1b2c3"},
+			{Name: "logs", Image: "docker.io/library/fluent-bit:3", ContainerID: "containerd://d4e5f6"},
+		}},
+	}
+}
+
+func TestWorkloadIndex_Attribute(t *testing.T) {
+	snapshot := "/var/lib/containerd/io.containerd.snapshotter.v1.overlayfs/snapshots/42/fs"
+	idx := newWorkloadIndex([]corev1.Pod{*newAttributionTestPod()}, map[string]string{
+		snapshot: "/run/containerd/io.containerd.runtime.v2.task/k8s.io/d4e5f6/rootfs",
+	})
+	app := &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f", Container: "app",
+		Image: "registry.example.com/shop/web:1.2"}
+	logs := &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f", Container: "logs",
+		Image: "docker.io/library/fluent-bit:3"}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected *clamavv1alpha1.FileAttribution
+	}{
+		{
+			name: "volume mounted by one container",
+			path: "/host/var/lib/kubelet/pods/0f3c-uid/volumes/kubernetes.io~empty-dir/uploads/evil.php",
+			expected: &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f", Container: "app",
+				Image: "registry.example.com/shop/web:1.2", Volume: "uploads"},
+		},
+		{
+			name:     "volume shared by containers",
+			path:     "/host/var/lib/kubelet/pods/0f3c-uid/volumes/kubernetes.io~empty-dir/cache/evil.php",
+			expected: &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f", Volume: "cache"},
+		},
+		{
+			name: "volume subpath",
+			path: "/host/var/lib/kubelet/pods/0f3c-uid/volume-subpaths/cache/logs/0/evil.sh",
+			expected: &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f", Container: "logs",
+				Image: "docker.io/library/fluent-bit:3", Volume: "cache"},
+		},
+		{
+			name:     "persistent volume named after the PV",
+			path:     "/host/var/lib/kubelet/pods/0f3c-uid/volumes/kubernetes.io~csi/pvc-1234/mount/evil.sh",
+			expected: &clamavv1alpha1.FileAttribution{Namespace: "shop", Pod: "web-7d9f"},
+		},
+		{name: "container log", path: "/host/var/log/pods/shop_web-7d9f_0f3c-uid/app/0.log", expected: app},
+		{name: "container rootfs", path: "/host/run/containerd/io.containerd.runtime.v2.task/k8s.io/a1b2c3/rootfs/tmp/x", expected: app},
+		{name: "containerd snapshot", path: "/host" + snapshot + "/tmp/miner", expected: logs},
+		{name: "unknown container", path: "/host/var/lib/docker/containers/ffffff/evil", expected: nil},
+		{name: "deleted pod", path: "/host/var/lib/kubelet/pods/gone-uid/volumes/kubernetes.io~empty-dir/x/evil", expected: nil},
+		{name: "host file", path: "/host/tmp/eicar.com", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, idx.attribute(tt.path))
+		})
+	}
+}
+
+func TestAttributeInfectedFiles(t *testing.T) {
+	pod := newAttributionTestPod()
+	otherNode := newAttributionTestPod()
+	otherNode.Name, otherNode.UID, otherNode.Spec.NodeName = "web-other", "other-uid", "worker-2"
+	r := newTestNodeScanReconciler()
+	r.Clientset = fake.NewSimpleClientset(pod, otherNode)
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+	}
+	result := &scanResult{Infected: []scanResultInfectedFile{
+		{Path: "/host/var/log/pods/shop_web-7d9f_0f3c-uid/logs/0.log", Viruses: []string{"Eicar-Signature"}},
+		{Path: "/host/var/lib/kubelet/pods/other-uid/volumes/kubernetes.io~empty-dir/uploads/x", Viruses: []string{"Eicar-Signature"}},
+		{Path: "/host/tmp/eicar.com", Viruses: []string{"Eicar-Signature"}},
+	}}
+
+	// Jobs without overlay mounts still attribute kubelet and log paths
+	require.NoError(t, r.attributeInfectedFiles(context.Background(), nodeScan, &batchv1.Job{}, result))
+	require.NotNil(t, result.Infected[0].Attribution)
+	assert.Equal(t, "shop/web-7d9f/logs", attributionWorkload(result.Infected[0].Attribution))
+	assert.Nil(t, result.Infected[1].Attribution, "pods of other nodes are not attributed")
+	assert.Nil(t, result.Infected[2].Attribution)
+
+	// The attribution reaches the status and the notifications
+	applyScanResult(nodeScan, result)
+	nodeScan.Status.FilesInfected = 3
+	require.NotNil(t, nodeScan.Status.InfectedFiles[0].Attribution)
+	a := scanCompletedAlert(nodeScan)
+	require.Len(t, a.findings, 1)
+	assert.Equal(t, []string{"shop/web-7d9f/logs"}, a.findings[0].workloads)
+	assert.Equal(t, []string{"Eicar-Signature on worker-1 (3 files) in shop/web-7d9f/logs"}, findingLines(a.findings))
+}
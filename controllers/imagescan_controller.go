@@ -0,0 +1,818 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// imageLayersContainer is the init container of image scan Jobs that
+	// unpacks the layers of the image from the containerd content store
+	imageLayersContainer = "image-layers"
+
+	// Markers of the lines printed by imageLayersScript
+	imageDigestMarker       = "IMAGE_DIGEST"
+	imageLayerMarker        = "IMAGE_LAYER"
+	imageLayerSkippedMarker = "IMAGE_LAYER_SKIPPED"
+
+	// imageLayersDir holds the unpacked layers in the scan Job, one
+	// directory per layer digest
+	imageLayersDir = "/layers"
+
+	// maxImageWorkloads bounds the workloads listed for each image
+	maxImageWorkloads = 20
+
+	// registryAuthKey is the key of the registry credentials in the Secret
+	// of an image scan Job
+	registryAuthKey = "auth"
+)
+
+// imageLayersScript unpacks the layers of an image into /layers. Images given
+// by reference are first pulled by the container runtime of the node. crictl
+// reads the registry credentials from CRICTL_AUTH: its environment is only
+// readable by its user, while its arguments are visible to every user of the
+// node. The script then walks the manifests of the image from its digest in
+// the containerd content store: JSON blobs (index, manifests, config) are
+// searched for more digests, the other blobs are layers. Blobs of other
+// platforms are not in the store and are ignored. It prints one tab-separated
+// line for the image digest and for each layer: marker, digest, compressed
+// size.
+const imageLayersScript = `blobs=/host/var/lib/containerd/io.containerd.content.v1.content/blobs/sha256
+if [ -n "$IMAGE_REF" ]; then
+  CRICTL_AUTH="$REGISTRY_AUTH" nsenter -t 1 -m -- crictl pull "$IMAGE_REF" >&2 || exit 1
+  IMAGE_DIGEST=$(nsenter -t 1 -m -- crictl inspecti -o go-template \
+    --template '{{range .status.repoDigests}}{{println .}}{{end}}' "$IMAGE_REF" | sed -n 's/.*@//p' | head -n 1)
+fi
+if [ -z "$IMAGE_DIGEST" ]; then
+  echo "cannot resolve the digest of $IMAGE_REF" >&2
+  exit 1
+fi
+printf 'IMAGE_DIGEST\t%s\n' "$IMAGE_DIGEST"
+queue=${IMAGE_DIGEST#sha256:}
+seen=" "
+while [ -n "$queue" ]; do
+  set -- $queue
+  hex=$1
+  shift
+  queue="$*"
+  case "$seen" in *" $hex "*) continue ;; esac
+  seen="$seen$hex "
+  blob="$blobs/$hex"
+  [ -f "$blob" ] || continue
+  if [ "$(head -c 1 "$blob")" = "{" ]; then
+    queue="$queue $(grep -o 'sha256:[0-9a-f]\{64\}' "$blob" | sed 's/^sha256://' | tr '\n' ' ')"
+    continue
+  fi
+  size=$(stat -c %s "$blob")
+  if [ "$size" -gt "$MAX_LAYER_SIZE" ]; then
+    printf 'IMAGE_LAYER_SKIPPED\tsha256:%s\t%s\n' "$hex" "$size"
+    continue
+  fi
+  mkdir -p "/layers/$hex"
+  tar -xf "$blob" -C "/layers/$hex" --no-same-owner 2>/dev/null || cp "$blob" "/layers/$hex/layer"
+  printf 'IMAGE_LAYER\tsha256:%s\t%s\n' "$hex" "$size"
+done
+exit 0
+`
+
+// ImageScanReconciler reconciles an ImageScan object
+type ImageScanReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	Clientset    kubernetes.Interface
+	ScannerImage string
+	ClamavHost   string
+	ClamavPort   int
+}
+
+// +kubebuilder:rbac:groups=clamav.io,resources=imagescans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=clamav.io,resources=imagescans/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *ImageScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var imageScan clamavv1alpha1.ImageScan
+	if err := r.Get(ctx, req.NamespacedName, &imageScan); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	switch imageScan.Status.Phase {
+	case clamavv1alpha1.ImageScanPhaseCompleted, clamavv1alpha1.ImageScanPhaseFailed,
+		clamavv1alpha1.ImageScanPhasePartiallyComplete:
+		return ctrl.Result{}, nil
+	}
+
+	// The images are resolved once: the scan covers the images running when it started
+	if imageScan.Status.Phase == "" {
+		images, err := r.resolveImages(ctx, &imageScan)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		now := metav1.Now()
+		imageScan.Status.Phase = clamavv1alpha1.ImageScanPhasePending
+		imageScan.Status.StartTime = &now
+		imageScan.Status.Images = images
+		r.Recorder.Eventf(&imageScan, corev1.EventTypeNormal, "ImageScanStarted", "Scanning %d images", len(images))
+	}
+
+	var running int32
+	for i := range imageScan.Status.Images {
+		image := &imageScan.Status.Images[i]
+		if image.Phase != clamavv1alpha1.ImagePhaseRunning {
+			continue
+		}
+		if err := r.checkImageScanJob(ctx, &imageScan, image); err != nil {
+			return ctrl.Result{}, err
+		}
+		if image.Phase == clamavv1alpha1.ImagePhaseRunning {
+			running++
+		}
+	}
+
+	concurrent := imageScan.Spec.Concurrent
+	if concurrent == 0 {
+		concurrent = clamavv1alpha1.DefaultConcurrentImageScans
+	}
+	for i := range imageScan.Status.Images {
+		image := &imageScan.Status.Images[i]
+		if running >= concurrent {
+			break
+		}
+		if image.Phase != clamavv1alpha1.ImagePhasePending {
+			continue
+		}
+		if err := r.startImageScan(ctx, &imageScan, image, i); err != nil {
+			return ctrl.Result{}, err
+		}
+		if image.Phase == clamavv1alpha1.ImagePhaseRunning {
+			running++
+		}
+	}
+
+	updateImageScanStatus(&imageScan)
+	if imageScan.Status.Phase != clamavv1alpha1.ImageScanPhaseRunning {
+		now := metav1.Now()
+		imageScan.Status.CompletionTime = &now
+		r.Recorder.Eventf(&imageScan, corev1.EventTypeNormal, "ImageScanCompleted",
+			"Scanned %d of %d images, %d infected, %d failed, %d skipped", imageScan.Status.CompletedImages,
+			imageScan.Status.TotalImages, imageScan.Status.InfectedImages, imageScan.Status.FailedImages,
+			imageScan.Status.SkippedImages)
+	}
+
+	if err := r.Status().Update(ctx, &imageScan); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if imageScan.Status.Phase == clamavv1alpha1.ImageScanPhaseRunning {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// resolveImages returns the images scanned by an ImageScan. Running images
+// are deduplicated by digest and read from the node of one of their
+// containers; images that cannot be read from a node are skipped.
+func (r *ImageScanReconciler) resolveImages(ctx context.Context, imageScan *clamavv1alpha1.ImageScan) ([]clamavv1alpha1.ImageResult, error) {
+	if imageScan.Spec.RunningImages == nil {
+		image := clamavv1alpha1.ImageResult{
+			References: []string{imageScan.Spec.Image},
+			Phase:      clamavv1alpha1.ImagePhasePending,
+		}
+		if _, digest, found := strings.Cut(imageScan.Spec.Image, "@"); found {
+			image.Digest = digest
+		}
+		return []clamavv1alpha1.ImageResult{image}, nil
+	}
+	target := imageScan.Spec.RunningImages
+
+	podSelector := labels.Everything()
+	if target.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(target.PodSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod selector: %w", err)
+		}
+		podSelector = selector
+	}
+
+	namespaces, err := workloadNamespaces(ctx, r.Clientset, target)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := r.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: podSelector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var images []clamavv1alpha1.ImageResult
+	byKey := map[string]int{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !podSelector.Matches(labels.Set(pod.Labels)) || (namespaces != nil && !namespaces[pod.Namespace]) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Running == nil {
+				continue
+			}
+
+			// Images without a repository digest are told apart by reference
+			_, digest, _ := strings.Cut(status.ImageID, "@")
+			key := digest
+			if key == "" {
+				key = status.Image
+			}
+			index, ok := byKey[key]
+			if !ok {
+				index = len(images)
+				byKey[key] = index
+				images = append(images, clamavv1alpha1.ImageResult{
+					Digest: digest,
+					Phase:  clamavv1alpha1.ImagePhaseSkipped,
+				})
+			}
+
+			image := &images[index]
+			image.References = appendUnique(image.References, status.Image)
+			if len(image.Workloads) < maxImageWorkloads {
+				image.Workloads = append(image.Workloads, fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, status.Name))
+			}
+
+			// The layers are read from the node of the first containerd container
+			runtime, _, _ := strings.Cut(status.ContainerID, "://")
+			switch {
+			case image.Phase == clamavv1alpha1.ImagePhasePending:
+			case digest == "":
+				image.Message = "image has no repository digest"
+			case runtime != "containerd":
+				image.Message = fmt.Sprintf("container runtime %q is not supported", runtime)
+			default:
+				image.Phase = clamavv1alpha1.ImagePhasePending
+				image.NodeName = pod.Spec.NodeName
+				image.Message = ""
+			}
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].References[0] < images[j].References[0]
+	})
+	return images, nil
+}
+
+// startImageScan creates the scan Job of an image
+func (r *ImageScanReconciler) startImageScan(ctx context.Context, imageScan *clamavv1alpha1.ImageScan, image *clamavv1alpha1.ImageResult, index int) error {
+	jobName := imageScanJobName(imageScan, index)
+
+	var auth string
+	if imageScan.Spec.Image != "" && len(imageScan.Spec.ImagePullSecrets) > 0 {
+		var err error
+		auth, err = r.registryAuth(ctx, imageScan.Namespace, imageScan.Spec.ImagePullSecrets, imageScan.Spec.Image)
+		if err != nil {
+			r.Recorder.Event(imageScan, corev1.EventTypeWarning, "ImagePullSecretInvalid", err.Error())
+			finishImageScan(image, clamavv1alpha1.ImagePhaseFailed, err.Error())
+			return nil
+		}
+		if auth != "" {
+			if err := r.ensureRegistryAuthSecret(ctx, imageScan, jobName, auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := r.ensureImageScanResultConfigMap(ctx, imageScan, jobName); err != nil {
+		return err
+	}
+
+	job, err := r.constructImageScanJob(imageScan, image, jobName, auth != "")
+	if err != nil {
+		return err
+	}
+	// The Job may have been created by a reconcile whose status update failed
+	if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	image.Phase = clamavv1alpha1.ImagePhaseRunning
+	image.JobName = jobName
+	return nil
+}
+
+// checkImageScanJob records the result of the scan Job of an image once it finished
+func (r *ImageScanReconciler) checkImageScanJob(ctx context.Context, imageScan *clamavv1alpha1.ImageScan, image *clamavv1alpha1.ImageResult) error {
+	var job batchv1.Job
+	err := r.Get(ctx, types.NamespacedName{Name: image.JobName, Namespace: imageScan.Namespace}, &job)
+	if errors.IsNotFound(err) {
+		finishImageScan(image, clamavv1alpha1.ImagePhaseFailed, fmt.Sprintf("scan job %s was deleted", image.JobName))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		if err := r.collectImageScanResult(ctx, imageScan, image, &job); err != nil {
+			log.FromContext(ctx).Error(err, "failed to collect image scan result", "job", job.Name)
+			finishImageScan(image, clamavv1alpha1.ImagePhaseFailed, err.Error())
+		}
+	case jobFailed(&job):
+		finishImageScan(image, clamavv1alpha1.ImagePhaseFailed, jobFailureMessage(&job))
+	}
+	return nil
+}
+
+// collectImageScanResult records the infected layers of an image from the
+// output of its scan Job
+func (r *ImageScanReconciler) collectImageScanResult(ctx context.Context, imageScan *clamavv1alpha1.ImageScan, image *clamavv1alpha1.ImageResult, job *batchv1.Job) error {
+	layers, err := r.readImageLayers(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to read the image layers: %w", err)
+	}
+
+	result, err := readPublishedScanResult(ctx, r.Client, imageScan.Namespace, jobResultConfigMapName(job.Name), layers.nodeName)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("scan job %s published no result", job.Name)
+	}
+
+	applyImageScanResult(image, layers, result)
+	if len(image.InfectedLayers) > 0 {
+		r.Recorder.Eventf(imageScan, corev1.EventTypeWarning, "ImageInfected", "Image %s (%s) has %d infected layers",
+			image.References[0], image.Digest, len(image.InfectedLayers))
+	}
+	return nil
+}
+
+// imageLayers is the output of the image-layers init container of a scan Job
+type imageLayers struct {
+	// nodeName is the node the layers were read from
+	nodeName string
+	// digest is the resolved digest of the image
+	digest string
+	// sizes are the compressed sizes of the unpacked layers by digest
+	sizes map[string]int64
+	// skipped is the number of layers larger than the maximum layer size
+	skipped int32
+}
+
+// parseImageLayers reads the lines printed by imageLayersScript
+func parseImageLayers(r io.Reader) (*imageLayers, error) {
+	layers := &imageLayers{sizes: map[string]int64{}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		switch {
+		case len(fields) == 2 && fields[0] == imageDigestMarker:
+			layers.digest = fields[1]
+		case len(fields) == 3 && fields[0] == imageLayerMarker:
+			size, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid size of layer %s: %w", fields[1], err)
+			}
+			layers.sizes[fields[1]] = size
+		case len(fields) == 3 && fields[0] == imageLayerSkippedMarker:
+			layers.skipped++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+// readImageLayers returns the layers unpacked by the image-layers init
+// container of a completed scan Job
+func (r *ImageScanReconciler) readImageLayers(ctx context.Context, job *batchv1.Job) (*imageLayers, error) {
+	if job.Spec.Selector == nil {
+		return nil, fmt.Errorf("job %s has no pod selector", job.Name)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels(job.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: imageLayersContainer,
+		})
+		stream, err := req.Stream(ctx)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get pod logs: %w", err)
+			continue
+		}
+
+		layers, err := parseImageLayers(stream)
+		stream.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		layers.nodeName = pod.Spec.NodeName
+		return layers, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no completed pods found for job %s", job.Name)
+	}
+	return nil, lastErr
+}
+
+// applyImageScanResult records the layers of an image containing the
+// infected files of a scan result
+func applyImageScanResult(image *clamavv1alpha1.ImageResult, layers *imageLayers, result *scanResult) {
+	if layers.digest != "" {
+		image.Digest = layers.digest
+	}
+	image.NodeName = layers.nodeName
+	image.LayersScanned = int32(len(layers.sizes))
+	image.LayersSkipped = layers.skipped
+
+	// Infected files are found below the directory named after their layer
+	image.InfectedLayers = nil
+	byDigest := map[string]int{}
+	for _, file := range result.Infected {
+		hex, _, _ := strings.Cut(strings.TrimPrefix(file.Path, imageLayersDir+"/"), "/")
+		digest := "sha256:" + hex
+		index, ok := byDigest[digest]
+		if !ok {
+			index = len(image.InfectedLayers)
+			byDigest[digest] = index
+			image.InfectedLayers = append(image.InfectedLayers, clamavv1alpha1.InfectedLayer{
+				Digest:  digest,
+				Viruses: []string{},
+				Size:    layers.sizes[digest],
+			})
+		}
+		layer := &image.InfectedLayers[index]
+		for _, virus := range file.Viruses {
+			layer.Viruses = appendUnique(layer.Viruses, virus)
+		}
+	}
+
+	switch {
+	case image.LayersScanned == 0 && image.LayersSkipped == 0:
+		finishImageScan(image, clamavv1alpha1.ImagePhaseFailed,
+			fmt.Sprintf("no layer of the image found in the containerd content store of node %s", image.NodeName))
+	case image.LayersSkipped > 0:
+		finishImageScan(image, clamavv1alpha1.ImagePhaseCompleted,
+			fmt.Sprintf("%d layers larger than maxLayerSize were not scanned", image.LayersSkipped))
+	default:
+		finishImageScan(image, clamavv1alpha1.ImagePhaseCompleted, "")
+	}
+}
+
+// finishImageScan records the final phase of an image
+func finishImageScan(image *clamavv1alpha1.ImageResult, phase clamavv1alpha1.ImagePhase, message string) {
+	now := metav1.Now()
+	image.Phase = phase
+	image.Message = message
+	image.CompletionTime = &now
+}
+
+// updateImageScanStatus counts the images of an ImageScan by phase and
+// derives the phase of the scan
+func updateImageScanStatus(imageScan *clamavv1alpha1.ImageScan) {
+	var completed, failed, skipped, infected, active int32
+	for _, image := range imageScan.Status.Images {
+		switch image.Phase {
+		case clamavv1alpha1.ImagePhaseCompleted:
+			completed++
+			if len(image.InfectedLayers) > 0 {
+				infected++
+			}
+		case clamavv1alpha1.ImagePhaseFailed:
+			failed++
+		case clamavv1alpha1.ImagePhaseSkipped:
+			skipped++
+		default:
+			active++
+		}
+	}
+
+	imageScan.Status.TotalImages = int32(len(imageScan.Status.Images))
+	imageScan.Status.CompletedImages = completed
+	imageScan.Status.FailedImages = failed
+	imageScan.Status.SkippedImages = skipped
+	imageScan.Status.InfectedImages = infected
+
+	switch {
+	case active > 0:
+		imageScan.Status.Phase = clamavv1alpha1.ImageScanPhaseRunning
+	case failed == 0:
+		imageScan.Status.Phase = clamavv1alpha1.ImageScanPhaseCompleted
+	case completed > 0:
+		imageScan.Status.Phase = clamavv1alpha1.ImageScanPhasePartiallyComplete
+	default:
+		imageScan.Status.Phase = clamavv1alpha1.ImageScanPhaseFailed
+	}
+}
+
+// imageScanJobName returns the name of the scan Job of the image at index
+func imageScanJobName(imageScan *clamavv1alpha1.ImageScan, index int) string {
+	suffix := fmt.Sprintf("-%d", index)
+	name := fmt.Sprintf("imagescan-%s", imageScan.Name)
+	if len(name) > 63-len(suffix) {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// registryAuthSecretName returns the name of the Secret holding the registry
+// credentials of a Job
+func registryAuthSecretName(jobName string) string {
+	return jobName + "-auth"
+}
+
+// ensureImageScanResultConfigMap creates the empty ConfigMap the scanner of a
+// Job publishes its result to
+func (r *ImageScanReconciler) ensureImageScanResultConfigMap(ctx context.Context, imageScan *clamavv1alpha1.ImageScan, jobName string) error {
+	return ensureResultConfigMap(ctx, r.Client, r.Scheme, imageScan, jobResultConfigMapName(jobName), map[string]string{
+		"app.kubernetes.io/name":      "clamav",
+		"app.kubernetes.io/component": "scan-result",
+		"clamav.io/imagescan":         imageScan.Name,
+	})
+}
+
+// ensureRegistryAuthSecret stores the registry credentials of a Job in a
+// Secret owned by the ImageScan
+func (r *ImageScanReconciler) ensureRegistryAuthSecret(ctx context.Context, imageScan *clamavv1alpha1.ImageScan, jobName, auth string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      registryAuthSecretName(jobName),
+			Namespace: imageScan.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      "clamav",
+				"app.kubernetes.io/component": "registry-auth",
+				"clamav.io/imagescan":         imageScan.Name,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{registryAuthKey: []byte(auth)},
+	}
+
+	if err := controllerutil.SetControllerReference(imageScan, secret, r.Scheme); err != nil {
+		return err
+	}
+
+	if err := r.Create(ctx, secret); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// constructImageScanJob returns the Job scanning an image. The image-layers
+// init container unpacks the layers into a volume the scanner then scans.
+func (r *ImageScanReconciler) constructImageScanJob(imageScan *clamavv1alpha1.ImageScan, image *clamavv1alpha1.ImageResult, jobName string, withAuth bool) (*batchv1.Job, error) {
+	maxLayerSize := imageScan.Spec.MaxLayerSize
+	if maxLayerSize == 0 {
+		maxLayerSize = clamavv1alpha1.DefaultMaxLayerSize
+	}
+
+	layersEnv := []corev1.EnvVar{
+		{Name: "MAX_LAYER_SIZE", Value: fmt.Sprintf("%d", maxLayerSize)},
+	}
+	if imageScan.Spec.Image != "" {
+		layersEnv = append(layersEnv, corev1.EnvVar{Name: "IMAGE_REF", Value: imageScan.Spec.Image})
+	} else {
+		layersEnv = append(layersEnv, corev1.EnvVar{Name: "IMAGE_DIGEST", Value: image.Digest})
+	}
+	if withAuth {
+		layersEnv = append(layersEnv, corev1.EnvVar{
+			Name: "REGISTRY_AUTH",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: registryAuthSecretName(jobName)},
+					Key:                  registryAuthKey,
+				},
+			},
+		})
+	}
+
+	// The scanner reports the node the layers were read from
+	envVars := []corev1.EnvVar{
+		{
+			Name: "NODE_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+			},
+		},
+		{Name: "HOST_ROOT", Value: imageLayersDir},
+		{Name: "RESULTS_DIR", Value: "/results"},
+		{Name: "CLAMAV_HOST", Value: r.ClamavHost},
+		{Name: "CLAMAV_PORT", Value: fmt.Sprintf("%d", r.ClamavPort)},
+		{Name: "PATHS_TO_SCAN", Value: imageLayersDir},
+		{Name: "MAX_CONCURRENT", Value: fmt.Sprintf("%d", DefaultMaxConcurrent)},
+		{Name: "FILE_TIMEOUT", Value: fmt.Sprintf("%d", DefaultFileTimeout)},
+		{Name: "CONNECT_TIMEOUT", Value: fmt.Sprintf("%d", DefaultConnectTimeout)},
+		{Name: "MAX_FILE_SIZE", Value: fmt.Sprintf("%d", DefaultMaxFileSize)},
+		{Name: "RESULT_CONFIGMAP", Value: jobResultConfigMapName(jobName)},
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+			},
+		},
+	}
+
+	resources := DefaultScannerResources
+	if imageScan.Spec.Resources != nil {
+		resources = *imageScan.Spec.Resources
+	}
+
+	ttl := ptr.To(int32(DefaultTTLSecondsAfterFinished))
+	if imageScan.Spec.TTLSecondsAfterFinished != nil {
+		ttl = imageScan.Spec.TTLSecondsAfterFinished
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: imageScan.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      "clamav",
+				"app.kubernetes.io/component": "image-scanner",
+				"clamav.io/imagescan":         imageScan.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(3)),
+			TTLSecondsAfterFinished: ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":      "clamav-image-scanner",
+						"security": "clamav",
+						"clamav":   "scanner",
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: "clamav-scanner",
+					// Running images are read from a node they run on
+					NodeName: image.NodeName,
+					// The container runtime is reached in the mount namespace of the host
+					HostPID:   true,
+					DNSPolicy: corev1.DNSClusterFirst,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: ptr.To(false),
+						RunAsUser:    ptr.To(int64(0)),
+					},
+					Tolerations: []corev1.Toleration{
+						{Operator: corev1.TolerationOpExists},
+					},
+					InitContainers: []corev1.Container{
+						{
+							Name:            imageLayersContainer,
+							Image:           r.ScannerImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", imageLayersScript},
+							Env:             layersEnv,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "host-root",
+									MountPath: "/host",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "image-layers",
+									MountPath: imageLayersDir,
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								// Entering the mount namespace of the host to pull the image
+								Privileged: ptr.To(true),
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "scanner",
+							Image:           r.ScannerImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Env:             envVars,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "image-layers",
+									MountPath: imageLayersDir,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "scan-results",
+									MountPath: "/results",
+								},
+							},
+							Resources: resources,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "host-root",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/",
+									Type: ptr.To(corev1.HostPathDirectory),
+								},
+							},
+						},
+						{
+							Name:         "image-layers",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+						{
+							Name:         "scan-results",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Set ImageScan as owner
+	if err := controllerutil.SetControllerReference(imageScan, job, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// jobFailed reports whether a Job failed after exhausting its retries
+func jobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *ImageScanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clamavv1alpha1.ImageScan{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	testImageDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testLayerDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func newTestImageScanReconciler(objs ...client.Object) *ImageScanReconciler {
+	scheme := newTestScheme()
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&clamavv1alpha1.ImageScan{}).
+		Build()
+
+	return &ImageScanReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		Recorder:     record.NewFakeRecorder(100),
+		Clientset:    fake.NewSimpleClientset(),
+		ScannerImage: "test-scanner:latest",
+		ClamavHost:   "clamav.test.svc",
+		ClamavPort:   3310,
+	}
+}
+
+// newImageTestPod returns a running pod with one container of the image
+func newImageTestPod(name, nodeName, image, imageID, containerID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:        "app",
+				Image:       image,
+				ImageID:     imageID,
+				ContainerID: containerID,
+				State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+func TestImageScanReconciler_ResolveRunningImages(t *testing.T) {
+	r := newTestImageScanReconciler()
+	r.Clientset = fake.NewSimpleClientset(
+		newImageTestPod("web-1", "worker-1", "registry.example.com/shop/web:1.2",
+			"registry.example.com/shop/web@"+testImageDigest, "containerd://a1"),
+		newImageTestPod("web-2", "worker-2", "registry.example.com/shop/web:latest",
+			"registry.example.com/shop/web@"+testImageDigest, "containerd://a2"),
+		newImageTestPod("api", "worker-1", "registry.example.com/shop/api:2.0",
+			"registry.example.com/shop/api@"+testLayerDigest, "cri-o://b1"),
+		newImageTestPod("local", "worker-1", "shop/local:dev", "sha256:3333", "containerd://c1"),
+	)
+	imageScan := &clamavv1alpha1.ImageScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+		Spec: clamavv1alpha1.ImageScanSpec{RunningImages: &clamavv1alpha1.WorkloadTarget{
+			Namespaces: []string{"shop"},
+		}},
+	}
+
+	images, err := r.resolveImages(context.Background(), imageScan)
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+
+	// Containers of the same digest are scanned once
+	assert.Equal(t, clamavv1alpha1.ImageResult{
+		Digest:     testImageDigest,
+		References: []string{"registry.example.com/shop/web:1.2", "registry.example.com/shop/web:latest"},
+		Workloads:  []string{"shop/web-1/app", "shop/web-2/app"},
+		NodeName:   "worker-1",
+		Phase:      clamavv1alpha1.ImagePhasePending,
+	}, images[1])
+	assert.Equal(t, clamavv1alpha1.ImagePhaseSkipped, images[0].Phase)
+	assert.Equal(t, `container runtime "cri-o" is not supported`, images[0].Message)
+	assert.Equal(t, clamavv1alpha1.ImagePhaseSkipped, images[2].Phase)
+	assert.Equal(t, "image has no repository digest", images[2].Message)
+
+	// Namespaces that are not selected are ignored
+	imageScan.Spec.RunningImages.Namespaces = []string{"kube-system"}
+	images, err = r.resolveImages(context.Background(), imageScan)
+	require.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestImageScanReconciler_Reconcile_ImageReference(t *testing.T) {
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(
+			`{"auths":{"https://registry.example.com":{"username":"ci","password":"s3cret"}}}`)},
+	}
+	imageScan := &clamavv1alpha1.ImageScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: clamavv1alpha1.ImageScanSpec{
+			Image:            "registry.example.com/shop/web:1.2",
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		},
+	}
+	r := newTestImageScanReconciler(imageScan)
+	r.Clientset = fake.NewSimpleClientset(pullSecret)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "web", Namespace: "default"}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+
+	var updated clamavv1alpha1.ImageScan
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &updated))
+	assert.Equal(t, clamavv1alpha1.ImageScanPhaseRunning, updated.Status.Phase)
+	require.Len(t, updated.Status.Images, 1)
+	assert.Equal(t, clamavv1alpha1.ImagePhaseRunning, updated.Status.Images[0].Phase)
+	assert.Equal(t, "imagescan-web-0", updated.Status.Images[0].JobName)
+
+	// The Job pulls the image with the credentials of the registry
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "imagescan-web-0", Namespace: "default"}, &job))
+	layers := job.Spec.Template.Spec.InitContainers[0]
+	assert.Equal(t, imageLayersContainer, layers.Name)
+	assert.Contains(t, layers.Env, corev1.EnvVar{Name: "IMAGE_REF", Value: "registry.example.com/shop/web:1.2"})
+	assert.Empty(t, job.Spec.Template.Spec.NodeName)
+	var secret corev1.Secret
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "imagescan-web-0-auth", Namespace: "default"}, &secret))
+	assert.Equal(t, "Y2k6czNjcmV0", string(secret.Data[registryAuthKey]))
+	// The credentials stay out of the arguments of crictl
+	assert.NotContains(t, strings.Join(append(layers.Command, layers.Args...), " "), "--auth")
+	var configMap corev1.ConfigMap
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "imagescan-web-0-result", Namespace: "default"}, &configMap))
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, "ImageScan", configMap.OwnerReferences[0].Kind)
+
+	// A failed Job fails the scan
+	job.Status.Failed = 4
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+	}}
+	require.NoError(t, r.Status().Update(context.Background(), &job))
+	_, err = r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &updated))
+	assert.Equal(t, clamavv1alpha1.ImageScanPhaseFailed, updated.Status.Phase)
+	assert.Equal(t, int32(1), updated.Status.FailedImages)
+	assert.Equal(t, "Scan job failed: BackoffLimitExceeded", updated.Status.Images[0].Message)
+	assert.NotNil(t, updated.Status.CompletionTime)
+}
+
+func TestImageScanReconciler_Reconcile_Concurrency(t *testing.T) {
+	imageScan := &clamavv1alpha1.ImageScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+		Spec: clamavv1alpha1.ImageScanSpec{
+			RunningImages: &clamavv1alpha1.WorkloadTarget{},
+			Concurrent:    1,
+		},
+	}
+	r := newTestImageScanReconciler(imageScan)
+	r.Clientset = fake.NewSimpleClientset(
+		newImageTestPod("web", "worker-1", "registry.example.com/shop/web:1.2",
+			"registry.example.com/shop/web@"+testImageDigest, "containerd://a1"),
+		newImageTestPod("api", "worker-2", "registry.example.com/shop/api:2.0",
+			"registry.example.com/shop/api@"+testLayerDigest, "containerd://b1"),
+	)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "running", Namespace: "default"}}
+
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	var updated clamavv1alpha1.ImageScan
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &updated))
+	require.Len(t, updated.Status.Images, 2)
+	assert.Equal(t, clamavv1alpha1.ImagePhaseRunning, updated.Status.Images[0].Phase)
+	assert.Equal(t, clamavv1alpha1.ImagePhasePending, updated.Status.Images[1].Phase)
+
+	// Running images are read from the content store of their node
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "imagescan-running-0", Namespace: "default"}, &job))
+	assert.Equal(t, "worker-2", job.Spec.Template.Spec.NodeName)
+	assert.Contains(t, job.Spec.Template.Spec.InitContainers[0].Env, corev1.EnvVar{Name: "IMAGE_DIGEST", Value: testLayerDigest})
+}
+
+func TestImageScanJobName(t *testing.T) {
+	imageScan := &clamavv1alpha1.ImageScan{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 70)}}
+	name := imageScanJobName(imageScan, 12)
+	assert.Len(t, name, 63)
+	assert.True(t, strings.HasSuffix(name, "-12"))
+}
+
+func TestParseImageLayers(t *testing.T) {
+	output := "pulling image\n" +
+		"IMAGE_DIGEST\t" + testImageDigest + "\n" +
+		"IMAGE_LAYER\t" + testLayerDigest + "\t2048\n" +
+		"IMAGE_LAYER_SKIPPED\tsha256:4444\t4294967296\n"
+
+	layers, err := parseImageLayers(strings.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, testImageDigest, layers.digest)
+	assert.Equal(t, map[string]int64{testLayerDigest: 2048}, layers.sizes)
+	assert.Equal(t, int32(1), layers.skipped)
+
+	_, err = parseImageLayers(strings.NewReader("IMAGE_LAYER\t" + testLayerDigest + "\tlarge\n"))
+	assert.Error(t, err)
+}
+
+func TestApplyImageScanResult(t *testing.T) {
+	layers := &imageLayers{
+		nodeName: "worker-1",
+		digest:   testImageDigest,
+		sizes:    map[string]int64{testLayerDigest: 2048, "sha256:5555": 512},
+	}
+	result := &scanResult{Infected: []scanResultInfectedFile{
+		{Path: "/layers/2222222222222222222222222222222222222222222222222222222222222222/usr/bin/miner",
+			Viruses: []string{"Unix.Trojan.Miner"}},
+		{Path: "/layers/2222222222222222222222222222222222222222222222222222222222222222/tmp/eicar",
+			Viruses: []string{"Eicar-Signature", "Unix.Trojan.Miner"}},
+	}}
+
+	image := &clamavv1alpha1.ImageResult{References: []string{"shop/web:1.2"}, Phase: clamavv1alpha1.ImagePhaseRunning}
+	applyImageScanResult(image, layers, result)
+	assert.Equal(t, clamavv1alpha1.ImagePhaseCompleted, image.Phase)
+	assert.Equal(t, testImageDigest, image.Digest)
+	assert.Equal(t, "worker-1", image.NodeName)
+	assert.Equal(t, int32(2), image.LayersScanned)
+	assert.Equal(t, []clamavv1alpha1.InfectedLayer{{
+		Digest:  testLayerDigest,
+		Viruses: []string{"Unix.Trojan.Miner", "Eicar-Signature"},
+		Size:    2048,
+	}}, image.InfectedLayers)
+
+	// Images without layers in the content store cannot be scanned
+	image = &clamavv1alpha1.ImageResult{References: []string{"shop/web:1.2"}}
+	applyImageScanResult(image, &imageLayers{nodeName: "worker-1", sizes: map[string]int64{}}, &scanResult{})
+	assert.Equal(t, clamavv1alpha1.ImagePhaseFailed, image.Phase)
+	assert.Contains(t, image.Message, "content store of node worker-1")
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                                  "docker.io",
+		"library/nginx:1.27":                     "docker.io",
+		"registry.example.com/shop/web:1.2":      "registry.example.com",
+		"registry.example.com:5000/shop/web":     "registry.example.com:5000",
+		"localhost/shop/web":                     "localhost",
+		"index.docker.io/library/nginx:1.27":     "docker.io",
+		"ghcr.io/solucteam/clamav-scanner:1.0.0": "ghcr.io",
+	}
+	for image, registry := range tests {
+		assert.Equal(t, registry, imageRegistry(image), image)
+	}
+	assert.Equal(t, "docker.io", normalizeRegistry("https://index.docker.io/v1/"))
+}
@@ -8,30 +8,158 @@ Note: Les métriques sont maintenant définies dans metrics.go
 package controllers
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"sort"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
 )
 
+const (
+	// scanCacheMountPath est le répertoire où le ConfigMap du cache est monté dans le Job
+	scanCacheMountPath = "/cache"
+	// scanCacheConfigMapKey est la clé du cache compressé (gzip) dans le ConfigMap
+	scanCacheConfigMapKey = "cache.json.gz"
+	// maxInlineCacheFiles et maxInlineCacheBytes limitent le cache passé en variable d'environnement
+	maxInlineCacheFiles = 1000
+	maxInlineCacheBytes = 100000
+	// maxScanCacheEntries est le nombre maximum d'entrées conservées dans le cache
+	maxScanCacheEntries = 10000
+)
+
+// incrementalScanPlan décrit comment le Job doit exécuter le scan incrémental
+type incrementalScanPlan struct {
+	// strategy est la stratégie effective demandée au scanner
+	strategy clamavv1alpha1.ScanStrategy
+	// env contient les variables d'environnement à ajouter au conteneur scanner
+	env []corev1.EnvVar
+	// configMap est le ConfigMap contenant le cache à monter (vide si inutile)
+	configMap string
+}
+
+// incrementalConfigFor retourne la configuration incrémentale effective d'un NodeScan,
+// complétée avec les valeurs par défaut, ou nil si le scan incrémental n'est pas demandé
+func incrementalConfigFor(nodeScan *clamavv1alpha1.NodeScan) *clamavv1alpha1.IncrementalScanConfig {
+	config := &clamavv1alpha1.IncrementalScanConfig{SkipUnchangedFiles: true}
+	if nodeScan.Spec.IncrementalConfig != nil {
+		config = nodeScan.Spec.IncrementalConfig.DeepCopy()
+	}
+
+	// Une stratégie explicite sur le NodeScan active le scan incrémental
+	if nodeScan.Spec.Strategy != "" && nodeScan.Spec.Strategy != clamavv1alpha1.ScanStrategyFull {
+		config.Enabled = true
+		config.Strategy = nodeScan.Spec.Strategy
+	}
+
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Strategy == "" {
+		config.Strategy = clamavv1alpha1.ScanStrategyIncremental
+	}
+	if config.BaselineInterval == 0 {
+		config.BaselineInterval = DefaultBaselineInterval
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = DefaultIncrementalMaxAge
+	}
+	if config.MinTimeBetweenScans == 0 {
+		config.MinTimeBetweenScans = DefaultMinTimeBetweenScans
+	}
+	if config.CacheExpiration == 0 {
+		config.CacheExpiration = DefaultCacheExpiration
+	}
+
+	return config
+}
+
+// prepareIncrementalScan charge le cache du node et détermine comment le Job doit
+// s'exécuter. Retourne nil si le scan incrémental n'est pas demandé.
+func (r *NodeScanReconciler) prepareIncrementalScan(ctx context.Context,
+	nodeScan *clamavv1alpha1.NodeScan) (*incrementalScanPlan, error) {
+
+	config := incrementalConfigFor(nodeScan)
+	if config == nil {
+		return nil, nil
+	}
+
+	cache, err := r.getScanCache(ctx, nodeScan.Spec.NodeName, nodeScan.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	env, strategy := r.prepareIncrementalScanEnv(ctx, nodeScan, cache, config)
+	plan := &incrementalScanPlan{strategy: strategy, env: env}
+
+	// Le cache ne tient pas dans une variable d'environnement : le passer via un ConfigMap
+	if strategy != clamavv1alpha1.ScanStrategyFull && len(cache.Spec.Files) > 0 {
+		if _, ok := inlineScanCache(cache); !ok {
+			if err := r.createScanCacheConfigMap(ctx, nodeScan, cache); err != nil {
+				return nil, fmt.Errorf("failed to create scan cache ConfigMap: %w", err)
+			}
+			plan.configMap = scanCacheName(nodeScan.Spec.NodeName)
+		}
+	}
+
+	return plan, nil
+}
+
+// applyToJob ajoute l'environnement et, si besoin, le volume du cache au Job de scan
+func (p *incrementalScanPlan) applyToJob(job *batchv1.Job) {
+	podSpec := &job.Spec.Template.Spec
+	container := &podSpec.Containers[0]
+	container.Env = append(container.Env, p.env...)
+
+	if p.configMap == "" {
+		return
+	}
+
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "scan-cache",
+		MountPath: scanCacheMountPath,
+		ReadOnly:  true,
+	})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "scan-cache",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: p.configMap},
+				Optional:             ptr.To(true),
+			},
+		},
+	})
+}
+
+// scanCacheName retourne le nom du ScanCacheResource d'un node (et de son ConfigMap)
+func scanCacheName(nodeName string) string {
+	return fmt.Sprintf("scancache-%s", nodeName)
+}
+
 // getScanCache récupère le cache de scan pour un node
 func (r *NodeScanReconciler) getScanCache(ctx context.Context, nodeName, namespace string) (*clamavv1alpha1.ScanCacheResource, error) {
 	cache := &clamavv1alpha1.ScanCacheResource{}
-	cacheName := fmt.Sprintf("scancache-%s", nodeName)
-	
+	cacheName := scanCacheName(nodeName)
+
 	err := r.Get(ctx, types.NamespacedName{
 		Name:      cacheName,
 		Namespace: namespace,
 	}, cache)
-	
+
 	if errors.IsNotFound(err) {
 		// Créer un nouveau cache
 		cache = &clamavv1alpha1.ScanCacheResource{
@@ -41,7 +169,7 @@ func (r *NodeScanReconciler) getScanCache(ctx context.Context, nodeName, namespa
 				Labels: map[string]string{
 					"app.kubernetes.io/name":      "clamav",
 					"app.kubernetes.io/component": "scan-cache",
-					"clamav.io/node": nodeName,
+					"clamav.io/node":              nodeName,
 				},
 			},
 			Spec: clamavv1alpha1.ScanCache{
@@ -51,134 +179,157 @@ func (r *NodeScanReconciler) getScanCache(ctx context.Context, nodeName, namespa
 				CacheVersion: "v1",
 			},
 		}
-		
+
 		if err := r.Create(ctx, cache); err != nil {
 			return nil, fmt.Errorf("failed to create scan cache: %w", err)
 		}
-		
+
 		return cache, nil
 	}
-	
+
 	return cache, err
 }
 
 // updateScanCache met à jour le cache après un scan
-func (r *NodeScanReconciler) updateScanCache(ctx context.Context, cache *clamavv1alpha1.ScanCacheResource, 
+func (r *NodeScanReconciler) updateScanCache(ctx context.Context, cache *clamavv1alpha1.ScanCacheResource,
 	nodeScan *clamavv1alpha1.NodeScan, filesMetadata []clamavv1alpha1.FileMetadata) error {
-	
+
 	log := log.FromContext(ctx)
-	
+
 	now := time.Now().Unix()
-	
-	// Déterminer si c'était un full scan
-	isFullScan := nodeScan.Spec.Strategy == clamavv1alpha1.ScanStrategyFull || 
-		nodeScan.Spec.ForceFullScan
-	
+
+	// Déterminer si c'était un full scan (stratégie réellement utilisée par le scanner)
+	isFullScan := nodeScan.Status.StrategyUsed == "" ||
+		nodeScan.Status.StrategyUsed == clamavv1alpha1.ScanStrategyFull
+
+	var updatedFiles []clamavv1alpha1.FileMetadata
 	if isFullScan {
 		// Full scan : remplacer tout le cache
 		cache.Spec.LastFullScan = now
 		cache.Spec.ScanCount = 0
-		cache.Spec.Files = filesMetadata
+		updatedFiles = filesMetadata
 	} else {
 		// Incremental scan : merger avec le cache existant
 		cache.Spec.LastIncrementalScan = now
 		cache.Spec.ScanCount++
-		
+
 		// Créer une map pour merge efficace
 		existingFiles := make(map[string]clamavv1alpha1.FileMetadata)
 		for _, f := range cache.Spec.Files {
 			existingFiles[f.Path] = f
 		}
-		
+
 		// Ajouter/mettre à jour les nouveaux fichiers
 		for _, newFile := range filesMetadata {
 			existingFiles[newFile.Path] = newFile
 		}
-		
+
 		// Convertir la map en slice
-		updatedFiles := make([]clamavv1alpha1.FileMetadata, 0, len(existingFiles))
+		updatedFiles = make([]clamavv1alpha1.FileMetadata, 0, len(existingFiles))
 		for _, f := range existingFiles {
 			updatedFiles = append(updatedFiles, f)
 		}
-		
-		// Limiter à 10000 entrées (les plus récentes)
-		if len(updatedFiles) > 10000 {
-			// Trier par LastScanned (les plus récents en premier)
-			// Pour simplifier, on garde les 10000 premiers
-			updatedFiles = updatedFiles[:10000]
-			log.Info("Cache truncated to 10000 entries", "node", cache.Spec.NodeName)
-		}
-		
-		cache.Spec.Files = updatedFiles
 	}
-	
+
+	// Limiter à 10000 entrées (les plus récentes)
+	if len(updatedFiles) > maxScanCacheEntries {
+		sort.Slice(updatedFiles, func(i, j int) bool {
+			return updatedFiles[i].LastScanned > updatedFiles[j].LastScanned
+		})
+		updatedFiles = updatedFiles[:maxScanCacheEntries]
+		log.Info("Cache truncated to 10000 entries", "node", cache.Spec.NodeName)
+	}
+
+	// Ordre stable pour éviter des mises à jour inutiles
+	sort.Slice(updatedFiles, func(i, j int) bool {
+		return updatedFiles[i].Path < updatedFiles[j].Path
+	})
+	if updatedFiles == nil {
+		updatedFiles = []clamavv1alpha1.FileMetadata{}
+	}
+
+	cache.Spec.Files = updatedFiles
 	cache.Spec.TotalFiles = int64(len(cache.Spec.Files))
-	cache.Status.LastUpdated = metav1.Now()
-	
+
 	// Calculer la taille approximative
 	cacheJSON, _ := json.Marshal(cache.Spec.Files)
-	cache.Status.Size = int64(len(cacheJSON))
-	
+	status := clamavv1alpha1.ScanCacheStatus{
+		LastUpdated: metav1.Now(),
+		Size:        int64(len(cacheJSON)),
+		Compressed:  cache.Status.Compressed,
+	}
+
 	// ✅ Enregistrer les métriques du cache (utilise la fonction de metrics.go)
-	recordScanCacheMetrics(cache.Namespace, cache.Spec.NodeName, cache.Status.Size, cache.Spec.TotalFiles)
-	
-	// Mettre à jour
+	recordScanCacheMetrics(cache.Namespace, cache.Spec.NodeName, status.Size, cache.Spec.TotalFiles)
+
+	// Mettre à jour la spec puis le status (sous-ressource)
 	if err := r.Update(ctx, cache); err != nil {
 		return fmt.Errorf("failed to update scan cache: %w", err)
 	}
-	
+
+	cache.Status = status
+	if err := r.Status().Update(ctx, cache); err != nil {
+		return fmt.Errorf("failed to update scan cache status: %w", err)
+	}
+
 	return nil
 }
 
 // shouldForceFullScan détermine si un full scan doit être forcé
-func (r *NodeScanReconciler) shouldForceFullScan(ctx context.Context, 
-	nodeScan *clamavv1alpha1.NodeScan, 
+func (r *NodeScanReconciler) shouldForceFullScan(ctx context.Context,
+	nodeScan *clamavv1alpha1.NodeScan,
 	cache *clamavv1alpha1.ScanCacheResource,
 	config *clamavv1alpha1.IncrementalScanConfig) bool {
-	
+
 	log := log.FromContext(ctx)
-	
+
 	// Si ForceFullScan est explicitement défini
 	if nodeScan.Spec.ForceFullScan {
 		log.Info("Full scan forced by spec", "node", nodeScan.Spec.NodeName)
 		return true
 	}
-	
+
+	// Si pas de config incrémentale
+	if config == nil || !config.Enabled {
+		return true
+	}
+
 	// Si la stratégie est Full
-	if nodeScan.Spec.Strategy == clamavv1alpha1.ScanStrategyFull {
+	if config.Strategy == clamavv1alpha1.ScanStrategyFull {
 		return true
 	}
-	
-	// Si pas de config incrémentale
-	if config == nil || !config.Enabled {
+
+	// Pas encore de scan complet de référence
+	if cache.Spec.LastFullScan == 0 {
+		log.Info("No baseline full scan in cache, forcing full scan", "node", nodeScan.Spec.NodeName)
 		return true
 	}
-	
+
 	// Vérifier l'intervalle baseline
 	if cache.Spec.ScanCount >= config.BaselineInterval {
-		log.Info("Baseline interval reached, forcing full scan", 
-			"scanCount", cache.Spec.ScanCount, 
+		log.Info("Baseline interval reached, forcing full scan",
+			"scanCount", cache.Spec.ScanCount,
 			"baselineInterval", config.BaselineInterval)
 		return true
 	}
-	
+
 	// Vérifier l'expiration du cache
 	now := time.Now().Unix()
 	cacheAge := now - cache.Spec.LastFullScan
 	expirationSeconds := int64(config.CacheExpiration) * 3600
-	
+
 	if cacheAge > expirationSeconds {
 		log.Info("Cache expired, forcing full scan",
 			"cacheAge", cacheAge,
 			"expiration", expirationSeconds)
 		return true
 	}
-	
+
 	// Vérifier le délai minimum entre scans
 	if cache.Spec.LastIncrementalScan > 0 {
 		timeSinceLastScan := now - cache.Spec.LastIncrementalScan
 		minTimeSeconds := int64(config.MinTimeBetweenScans) * 3600
-		
+
 		if timeSinceLastScan < minTimeSeconds {
 			log.Info("Minimum time between scans not reached",
 				"timeSinceLastScan", timeSinceLastScan,
@@ -187,73 +338,71 @@ func (r *NodeScanReconciler) shouldForceFullScan(ctx context.Context,
 			// Mais ici on laisse passer en incremental
 		}
 	}
-	
+
 	return false
 }
 
 // prepareIncrementalScanEnv prépare les variables d'environnement pour le scan incrémental
+// et retourne la stratégie effective
 func (r *NodeScanReconciler) prepareIncrementalScanEnv(ctx context.Context,
 	nodeScan *clamavv1alpha1.NodeScan,
 	cache *clamavv1alpha1.ScanCacheResource,
-	config *clamavv1alpha1.IncrementalScanConfig) []corev1.EnvVar {
-	
+	config *clamavv1alpha1.IncrementalScanConfig) ([]corev1.EnvVar, clamavv1alpha1.ScanStrategy) {
+
 	envVars := []corev1.EnvVar{}
-	
+
 	// Déterminer la stratégie effective
-	strategy := nodeScan.Spec.Strategy
-	if strategy == "" {
-		strategy = clamavv1alpha1.ScanStrategyFull
+	strategy := clamavv1alpha1.ScanStrategyFull
+	if config != nil && config.Strategy != "" {
+		strategy = config.Strategy
 	}
-	
+
 	forceFullScan := r.shouldForceFullScan(ctx, nodeScan, cache, config)
-	
+
 	if forceFullScan {
 		strategy = clamavv1alpha1.ScanStrategyFull
 	}
-	
+
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "SCAN_STRATEGY",
 		Value: string(strategy),
 	})
-	
-	// Config incrémentale
-	if config != nil && config.Enabled && !forceFullScan {
+
+	// Config incrémentale : même lors d'un full scan forcé, le scanner doit
+	// enregistrer les métadonnées des fichiers pour reconstruire le cache
+	if config != nil && config.Enabled {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "INCREMENTAL_ENABLED",
 			Value: "true",
 		})
-		
+
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "MAX_FILE_AGE_HOURS",
 			Value: fmt.Sprintf("%d", config.MaxAge),
 		})
-		
+
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "SKIP_UNCHANGED_FILES",
 			Value: fmt.Sprintf("%t", config.SkipUnchangedFiles),
 		})
-		
-		// Sérialiser le cache en JSON et le passer en variable d'environnement
-		// Note: Pour de gros caches, il faudrait plutôt utiliser un ConfigMap
-		if len(cache.Spec.Files) > 0 && len(cache.Spec.Files) < 1000 {
-			cacheJSON, err := json.Marshal(cache.Spec.Files)
-			if err == nil && len(cacheJSON) < 100000 { // Limite de 100KB
-				envVars = append(envVars, corev1.EnvVar{
-					Name:  "SCAN_CACHE",
-					Value: string(cacheJSON),
-				})
-			}
-		}
-		
-		// Si le cache est trop gros, créer un ConfigMap
-		if len(cache.Spec.Files) >= 1000 {
+	}
+
+	// Le cache n'est utile au scanner que pour un scan non complet
+	if !forceFullScan && len(cache.Spec.Files) > 0 {
+		if cacheJSON, ok := inlineScanCache(cache); ok {
 			envVars = append(envVars, corev1.EnvVar{
-				Name:  "SCAN_CACHE_CONFIGMAP",
-				Value: fmt.Sprintf("scancache-%s", nodeScan.Spec.NodeName),
+				Name:  "SCAN_CACHE",
+				Value: cacheJSON,
+			})
+		} else {
+			// Cache trop gros : il est monté depuis un ConfigMap
+			envVars = append(envVars, corev1.EnvVar{
+				Name:  "SCAN_CACHE_FILE",
+				Value: path.Join(scanCacheMountPath, scanCacheConfigMapKey),
 			})
 		}
 	}
-	
+
 	// Timestamp du dernier scan
 	if cache.Spec.LastFullScan > 0 {
 		envVars = append(envVars, corev1.EnvVar{
@@ -261,73 +410,122 @@ func (r *NodeScanReconciler) prepareIncrementalScanEnv(ctx context.Context,
 			Value: fmt.Sprintf("%d", cache.Spec.LastFullScan),
 		})
 	}
-	
+
 	if cache.Spec.LastIncrementalScan > 0 {
 		envVars = append(envVars, corev1.EnvVar{
 			Name:  "LAST_INCREMENTAL_SCAN",
 			Value: fmt.Sprintf("%d", cache.Spec.LastIncrementalScan),
 		})
 	}
-	
-	return envVars
+
+	return envVars, strategy
 }
 
-// createScanCacheConfigMap crée un ConfigMap pour stocker un gros cache
+// inlineScanCache sérialise le cache s'il est assez petit pour une variable d'environnement
+func inlineScanCache(cache *clamavv1alpha1.ScanCacheResource) (string, bool) {
+	if len(cache.Spec.Files) >= maxInlineCacheFiles {
+		return "", false
+	}
+
+	cacheJSON, err := json.Marshal(cache.Spec.Files)
+	if err != nil || len(cacheJSON) >= maxInlineCacheBytes {
+		return "", false
+	}
+
+	return string(cacheJSON), true
+}
+
+// createScanCacheConfigMap crée un ConfigMap (gzip) pour stocker un gros cache
 func (r *NodeScanReconciler) createScanCacheConfigMap(ctx context.Context,
 	nodeScan *clamavv1alpha1.NodeScan,
 	cache *clamavv1alpha1.ScanCacheResource) error {
-	
+
 	cacheJSON, err := json.Marshal(cache.Spec.Files)
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache: %w", err)
 	}
-	
+
+	// Compresser : un ConfigMap est limité à 1 MiB
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(cacheJSON); err != nil {
+		return fmt.Errorf("failed to compress cache: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress cache: %w", err)
+	}
+
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("scancache-%s", nodeScan.Spec.NodeName),
+			Name:      scanCacheName(nodeScan.Spec.NodeName),
 			Namespace: nodeScan.Namespace,
 			Labels: map[string]string{
 				"app.kubernetes.io/name":      "clamav",
 				"app.kubernetes.io/component": "scan-cache",
-				"clamav.io/node": nodeScan.Spec.NodeName,
+				"clamav.io/node":              nodeScan.Spec.NodeName,
 			},
 		},
-		Data: map[string]string{
-			"cache.json": string(cacheJSON),
+		BinaryData: map[string][]byte{
+			scanCacheConfigMapKey: compressed.Bytes(),
 		},
 	}
-	
+
+	// Le ConfigMap suit le cycle de vie du ScanCacheResource
+	if err := controllerutil.SetControllerReference(cache, configMap, r.Scheme); err != nil {
+		return err
+	}
+
 	// Créer ou mettre à jour
 	existing := &corev1.ConfigMap{}
 	err = r.Get(ctx, types.NamespacedName{
 		Name:      configMap.Name,
 		Namespace: configMap.Namespace,
 	}, existing)
-	
+
 	if errors.IsNotFound(err) {
-		return r.Create(ctx, configMap)
-	} else if err != nil {
+		err = r.Create(ctx, configMap)
+	} else if err == nil {
+		// Mettre à jour
+		existing.Data = nil
+		existing.BinaryData = configMap.BinaryData
+		err = r.Update(ctx, existing)
+	}
+	if err != nil {
 		return err
 	}
-	
-	// Mettre à jour
-	existing.Data = configMap.Data
-	return r.Update(ctx, existing)
+
+	cache.Status.Compressed = true
+	return nil
 }
 
 // calculateIncrementalStats calcule les statistiques du scan incrémental
 func (r *NodeScanReconciler) calculateIncrementalStats(ctx context.Context,
 	nodeScan *clamavv1alpha1.NodeScan,
 	cache *clamavv1alpha1.ScanCacheResource) {
-	
+
 	if nodeScan.Status.FilesSkippedIncremental > 0 {
 		// Calculer le taux de hit du cache
 		totalChecked := nodeScan.Status.FilesScanned + nodeScan.Status.FilesSkippedIncremental
 		if totalChecked > 0 {
 			nodeScan.Status.CacheHitRate = float64(nodeScan.Status.FilesSkippedIncremental) / float64(totalChecked) * 100
 		}
-		
+
 		// Estimer le temps économisé (environ 0.1s par fichier sauté)
 		nodeScan.Status.TimeSaved = nodeScan.Status.FilesSkippedIncremental / 10
 	}
-}
\ No newline at end of file
+}
+
+// recordIncrementalScanResults met à jour les statistiques du NodeScan et le cache
+// du node à partir des métadonnées remontées par le scanner
+func (r *NodeScanReconciler) recordIncrementalScanResults(ctx context.Context,
+	nodeScan *clamavv1alpha1.NodeScan, filesMetadata []clamavv1alpha1.FileMetadata) error {
+
+	cache, err := r.getScanCache(ctx, nodeScan.Spec.NodeName, nodeScan.Namespace)
+	if err != nil {
+		return err
+	}
+
+	r.calculateIncrementalStats(ctx, nodeScan, cache)
+
+	return r.updateScanCache(ctx, cache, nodeScan, filesMetadata)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newIncrementalTestNodeScan() *clamavv1alpha1.NodeScan {
+	return &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-scan",
+			Namespace:  "default",
+			Finalizers: []string{nodeScanFinalizer},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName: "test-node",
+			Strategy: clamavv1alpha1.ScanStrategyIncremental,
+		},
+	}
+}
+
+func newTestScanCache(files int, scanCount int32, lastFullScan time.Time) *clamavv1alpha1.ScanCacheResource {
+	cache := &clamavv1alpha1.ScanCacheResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scancache-test-node",
+			Namespace: "default",
+		},
+		Spec: clamavv1alpha1.ScanCache{
+			NodeName:     "test-node",
+			ScanCount:    scanCount,
+			LastFullScan: lastFullScan.Unix(),
+			CacheVersion: "v1",
+		},
+	}
+	for i := 0; i < files; i++ {
+		cache.Spec.Files = append(cache.Spec.Files, clamavv1alpha1.FileMetadata{
+			Path:        fmt.Sprintf("/host/var/lib/file-%d", i),
+			ModTime:     lastFullScan.Unix(),
+			Size:        1024,
+			LastScanned: lastFullScan.Unix(),
+			ScanResult:  "clean",
+		})
+	}
+	return cache
+}
+
+// reconcileIncrementalJob runs a reconcile and returns the created scan Job and the NodeScan
+func reconcileIncrementalJob(t *testing.T, r *NodeScanReconciler) (*batchv1.Job, *clamavv1alpha1.NodeScan) {
+	t.Helper()
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "nodescan-test-scan",
+		Namespace: "default",
+	}, &job))
+
+	var nodeScan clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "test-scan",
+		Namespace: "default",
+	}, &nodeScan))
+
+	return &job, &nodeScan
+}
+
+func jobEnv(job *batchv1.Job) map[string]string {
+	env := map[string]string{}
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	return env
+}
+
+func TestIncrementalConfigFor(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{}
+	assert.Nil(t, incrementalConfigFor(nodeScan), "full scans need no incremental config")
+
+	nodeScan.Spec.Strategy = clamavv1alpha1.ScanStrategySmart
+	config := incrementalConfigFor(nodeScan)
+	require.NotNil(t, config)
+	assert.True(t, config.Enabled)
+	assert.Equal(t, clamavv1alpha1.ScanStrategySmart, config.Strategy)
+	assert.Equal(t, int32(DefaultBaselineInterval), config.BaselineInterval)
+	assert.Equal(t, int32(DefaultCacheExpiration), config.CacheExpiration)
+	assert.True(t, config.SkipUnchangedFiles)
+
+	nodeScan.Spec.Strategy = clamavv1alpha1.ScanStrategyFull
+	nodeScan.Spec.IncrementalConfig = &clamavv1alpha1.IncrementalScanConfig{Enabled: true, BaselineInterval: 3}
+	config = incrementalConfigFor(nodeScan)
+	require.NotNil(t, config)
+	assert.Equal(t, clamavv1alpha1.ScanStrategyIncremental, config.Strategy)
+	assert.Equal(t, int32(3), config.BaselineInterval)
+}
+
+func TestNodeScanReconciler_Reconcile_IncrementalWithoutBaseline(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	r := newTestNodeScanReconciler(node, newIncrementalTestNodeScan())
+
+	job, nodeScan := reconcileIncrementalJob(t, r)
+
+	env := jobEnv(job)
+	assert.Equal(t, "full", env["SCAN_STRATEGY"])
+	assert.Equal(t, "true", env["INCREMENTAL_ENABLED"])
+	assert.NotContains(t, env, "SCAN_CACHE")
+	assert.Equal(t, clamavv1alpha1.ScanStrategyFull, nodeScan.Status.StrategyUsed)
+
+	// The cache is created on first use
+	var cache clamavv1alpha1.ScanCacheResource
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "scancache-test-node",
+		Namespace: "default",
+	}, &cache))
+	assert.Equal(t, "test-node", cache.Spec.NodeName)
+}
+
+func TestNodeScanReconciler_Reconcile_IncrementalWithInlineCache(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	cache := newTestScanCache(2, 1, time.Now().Add(-time.Hour))
+	r := newTestNodeScanReconciler(node, newIncrementalTestNodeScan(), cache)
+
+	job, nodeScan := reconcileIncrementalJob(t, r)
+
+	env := jobEnv(job)
+	assert.Equal(t, "incremental", env["SCAN_STRATEGY"])
+	assert.Contains(t, env["SCAN_CACHE"], "/host/var/lib/file-1")
+	assert.Equal(t, clamavv1alpha1.ScanStrategyIncremental, nodeScan.Status.StrategyUsed)
+}
+
+func TestNodeScanReconciler_Reconcile_IncrementalBaselineReached(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	cache := newTestScanCache(2, DefaultBaselineInterval, time.Now().Add(-time.Hour))
+	r := newTestNodeScanReconciler(node, newIncrementalTestNodeScan(), cache)
+
+	job, _ := reconcileIncrementalJob(t, r)
+
+	assert.Equal(t, "full", jobEnv(job)["SCAN_STRATEGY"])
+}
+
+func TestNodeScanReconciler_Reconcile_IncrementalCacheExpired(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	cache := newTestScanCache(2, 1, time.Now().Add(-(DefaultCacheExpiration+1)*time.Hour))
+	r := newTestNodeScanReconciler(node, newIncrementalTestNodeScan(), cache)
+
+	job, _ := reconcileIncrementalJob(t, r)
+
+	assert.Equal(t, "full", jobEnv(job)["SCAN_STRATEGY"])
+}
+
+func TestNodeScanReconciler_Reconcile_IncrementalLargeCacheUsesConfigMap(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	cache := newTestScanCache(maxInlineCacheFiles, 1, time.Now().Add(-time.Hour))
+	r := newTestNodeScanReconciler(node, newIncrementalTestNodeScan(), cache)
+
+	job, _ := reconcileIncrementalJob(t, r)
+
+	env := jobEnv(job)
+	assert.NotContains(t, env, "SCAN_CACHE")
+	assert.Equal(t, "/cache/cache.json.gz", env["SCAN_CACHE_FILE"])
+
+	var configMap corev1.ConfigMap
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "scancache-test-node",
+		Namespace: "default",
+	}, &configMap))
+	assert.NotEmpty(t, configMap.BinaryData[scanCacheConfigMapKey])
+
+	var mounted bool
+	for _, v := range job.Spec.Template.Spec.Volumes {
+		if v.ConfigMap != nil && v.ConfigMap.Name == "scancache-test-node" {
+			mounted = true
+		}
+	}
+	assert.True(t, mounted, "cache ConfigMap should be mounted in the scan job")
+}
+
+func TestUpdateScanCache(t *testing.T) {
+	lastFull := time.Now().Add(-time.Hour)
+	cache := newTestScanCache(2, 1, lastFull)
+	r := newTestNodeScanReconciler(cache)
+
+	nodeScan := newIncrementalTestNodeScan()
+	nodeScan.Status.StrategyUsed = clamavv1alpha1.ScanStrategyIncremental
+	scanned := []clamavv1alpha1.FileMetadata{
+		{Path: "/host/var/lib/file-1", ModTime: 42, Size: 2048, ScanResult: "infected"},
+		{Path: "/host/var/lib/new", ModTime: 42, Size: 10, ScanResult: "clean"},
+	}
+
+	// Incremental scan: entries are merged
+	require.NoError(t, r.updateScanCache(context.Background(), cache, nodeScan, scanned))
+	assert.Equal(t, int32(2), cache.Spec.ScanCount)
+	assert.Equal(t, int64(3), cache.Spec.TotalFiles)
+	assert.Equal(t, lastFull.Unix(), cache.Spec.LastFullScan)
+	assert.NotZero(t, cache.Status.Size)
+
+	// Full scan: the cache is replaced and the baseline reset
+	nodeScan.Status.StrategyUsed = clamavv1alpha1.ScanStrategyFull
+	require.NoError(t, r.updateScanCache(context.Background(), cache, nodeScan, scanned[:1]))
+	assert.Equal(t, int32(0), cache.Spec.ScanCount)
+	assert.Equal(t, int64(1), cache.Spec.TotalFiles)
+	assert.Greater(t, cache.Spec.LastFullScan, lastFull.Unix())
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// Maintenance window condition
+const (
+	// conditionMaintenanceWindow reports whether scans may start now
+	conditionMaintenanceWindow = "MaintenanceWindow"
+
+	reasonWindowOpen          = "WindowOpen"
+	reasonWaitingForWindow    = "WaitingForMaintenanceWindow"
+	reasonInvalidWindowConfig = "InvalidMaintenanceWindows"
+)
+
+var weekdays = map[clamavv1alpha1.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// timeWindow is a parsed clamavv1alpha1.TimeWindow
+type timeWindow struct {
+	days map[time.Weekday]bool
+	// start and end are minutes since midnight, in wall clock time
+	start, end int
+}
+
+// maintenanceWindows is a parsed clamavv1alpha1.MaintenanceWindows
+type maintenanceWindows struct {
+	location  *time.Location
+	allowed   []timeWindow
+	forbidden []timeWindow
+}
+
+// parseClock parses a HH:MM time of day into minutes since midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", clock, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseMaintenanceWindows parses the windows, evaluated in defaultTimeZone when
+// they do not set their own time zone. A nil result allows scans at any time.
+func parseMaintenanceWindows(spec *clamavv1alpha1.MaintenanceWindows, defaultTimeZone string) (*maintenanceWindows, error) {
+	if spec == nil || (len(spec.Allowed) == 0 && len(spec.Forbidden) == 0) {
+		return nil, nil
+	}
+
+	tz := spec.TimeZone
+	if tz == "" {
+		tz = defaultTimeZone
+	}
+	location := time.Local
+	if tz != "" {
+		var err error
+		if location, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+	}
+
+	parse := func(specs []clamavv1alpha1.TimeWindow) ([]timeWindow, error) {
+		windows := make([]timeWindow, 0, len(specs))
+		for _, s := range specs {
+			start, err := parseClock(s.Start)
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseClock(s.End)
+			if err != nil {
+				return nil, err
+			}
+			w := timeWindow{start: start, end: end}
+			if len(s.Days) > 0 {
+				w.days = map[time.Weekday]bool{}
+				for _, d := range s.Days {
+					day, ok := weekdays[d]
+					if !ok {
+						return nil, fmt.Errorf("invalid day %q", d)
+					}
+					w.days[day] = true
+				}
+			}
+			windows = append(windows, w)
+		}
+		return windows, nil
+	}
+
+	allowed, err := parse(spec.Allowed)
+	if err != nil {
+		return nil, err
+	}
+	forbidden, err := parse(spec.Forbidden)
+	if err != nil {
+		return nil, err
+	}
+	return &maintenanceWindows{location: location, allowed: allowed, forbidden: forbidden}, nil
+}
+
+// bounds returns the occurrence of the window opening on the day of midnight.
+// Wall clock times are used so that windows follow DST changes.
+func (w timeWindow) bounds(midnight time.Time) (time.Time, time.Time, bool) {
+	if w.days != nil && !w.days[midnight.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := midnight.Date()
+	start := time.Date(y, m, d, 0, w.start, 0, 0, midnight.Location())
+	end := time.Date(y, m, d, 0, w.end, 0, 0, midnight.Location())
+	if w.end <= w.start {
+		// The window spans midnight
+		end = time.Date(y, m, d+1, 0, w.end, 0, 0, midnight.Location())
+	}
+	return start, end, true
+}
+
+// contains returns true if t falls inside an occurrence of the window
+func (w timeWindow) contains(t time.Time) bool {
+	// The occurrence may have opened on the previous day
+	for offset := -1; offset <= 0; offset++ {
+		midnight := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+		if start, end, ok := w.bounds(midnight); ok && !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpen returns true if scans may start at t
+func (m *maintenanceWindows) isOpen(t time.Time) bool {
+	if m == nil {
+		return true
+	}
+	t = t.In(m.location)
+
+	for _, w := range m.forbidden {
+		if w.contains(t) {
+			return false
+		}
+	}
+	if len(m.allowed) == 0 {
+		return true
+	}
+	for _, w := range m.allowed {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextOpen returns the first time at or after t when scans may start, or the
+// zero time if the windows never open within the next week
+func (m *maintenanceWindows) nextOpen(t time.Time) time.Time {
+	if m.isOpen(t) {
+		return t
+	}
+	t = t.In(m.location)
+
+	// Scans can only become allowed when an allowed window opens or a
+	// forbidden window closes
+	var candidates []time.Time
+	for offset := -1; offset <= 8; offset++ {
+		midnight := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, m.location)
+		for _, w := range m.allowed {
+			if start, _, ok := w.bounds(midnight); ok && start.After(t) {
+				candidates = append(candidates, start)
+			}
+		}
+		for _, w := range m.forbidden {
+			if _, end, ok := w.bounds(midnight); ok && end.After(t) {
+				candidates = append(candidates, end)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	for _, c := range candidates {
+		if m.isOpen(c) {
+			return c
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func TestMaintenanceWindows_IsOpen(t *testing.T) {
+	windows, err := parseMaintenanceWindows(&clamavv1alpha1.MaintenanceWindows{
+		TimeZone: "Europe/Paris",
+		Allowed: []clamavv1alpha1.TimeWindow{
+			{Start: "22:00", End: "06:00"},
+			{Days: []clamavv1alpha1.Weekday{"Sat", "Sun"}, Start: "00:00", End: "00:00"},
+		},
+		Forbidden: []clamavv1alpha1.TimeWindow{
+			{Days: []clamavv1alpha1.Weekday{"Sun"}, Start: "02:00", End: "04:00"},
+		},
+	}, "")
+	require.NoError(t, err)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		time time.Time
+		open bool
+	}{
+		{name: "weekday night", time: time.Date(2025, 1, 15, 23, 0, 0, 0, paris), open: true},
+		{name: "weekday early morning", time: time.Date(2025, 1, 15, 5, 59, 0, 0, paris), open: true},
+		{name: "weekday business hours", time: time.Date(2025, 1, 15, 10, 0, 0, 0, paris), open: false},
+		{name: "window end is exclusive", time: time.Date(2025, 1, 15, 6, 0, 0, 0, paris), open: false},
+		{name: "saturday afternoon", time: time.Date(2025, 1, 18, 15, 0, 0, 0, paris), open: true},
+		{name: "sunday blackout", time: time.Date(2025, 1, 19, 3, 0, 0, 0, paris), open: false},
+		{name: "evaluated in the window time zone", time: time.Date(2025, 1, 15, 21, 30, 0, 0, time.UTC), open: true},
+		{name: "summer time", time: time.Date(2025, 7, 16, 20, 30, 0, 0, time.UTC), open: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.open, windows.isOpen(tt.time))
+		})
+	}
+}
+
+func TestMaintenanceWindows_NextOpen(t *testing.T) {
+	windows, err := parseMaintenanceWindows(&clamavv1alpha1.MaintenanceWindows{
+		Allowed: []clamavv1alpha1.TimeWindow{
+			{Days: []clamavv1alpha1.Weekday{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "22:00", End: "06:00"},
+		},
+		Forbidden: []clamavv1alpha1.TimeWindow{
+			{Days: []clamavv1alpha1.Weekday{"Wed"}, Start: "20:00", End: "23:00"},
+		},
+	}, "UTC")
+	require.NoError(t, err)
+
+	// Wednesday afternoon: the window opens when the blackout ends
+	assert.Equal(t, time.Date(2025, 1, 15, 23, 0, 0, 0, time.UTC),
+		windows.nextOpen(time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)).UTC())
+
+	// Saturday: the window opens on Monday evening
+	assert.Equal(t, time.Date(2025, 1, 20, 22, 0, 0, 0, time.UTC),
+		windows.nextOpen(time.Date(2025, 1, 18, 12, 0, 0, 0, time.UTC)).UTC())
+
+	// Inside the window
+	now := time.Date(2025, 1, 16, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, now, windows.nextOpen(now))
+}
+
+func TestParseMaintenanceWindows_Empty(t *testing.T) {
+	windows, err := parseMaintenanceWindows(&clamavv1alpha1.MaintenanceWindows{TimeZone: "UTC"}, "")
+	require.NoError(t, err)
+	assert.Nil(t, windows)
+	assert.True(t, windows.isOpen(time.Now()))
+}
+
+// closedMaintenanceWindows returns windows that are closed for the next hour
+func closedMaintenanceWindows() *clamavv1alpha1.MaintenanceWindows {
+	now := time.Now().UTC()
+	return &clamavv1alpha1.MaintenanceWindows{
+		TimeZone: "UTC",
+		Forbidden: []clamavv1alpha1.TimeWindow{{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+		}},
+	}
+}
+
+func TestClusterScanReconciler_Reconcile_WaitsForMaintenanceWindow(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	clusterScan := &clamavv1alpha1.ClusterScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-clusterscan",
+			Namespace:  "default",
+			Finalizers: []string{clusterScanFinalizer},
+		},
+		Spec: clamavv1alpha1.ClusterScanSpec{
+			MaintenanceWindows: closedMaintenanceWindows(),
+		},
+	}
+	r := newTestClusterScanReconciler(node, clusterScan)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-clusterscan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 30*time.Minute && result.RequeueAfter <= time.Hour)
+
+	var nodeScans clamavv1alpha1.NodeScanList
+	require.NoError(t, r.List(context.Background(), &nodeScans, client.InNamespace("default")))
+	assert.Empty(t, nodeScans.Items)
+
+	var updated clamavv1alpha1.ClusterScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-clusterscan", Namespace: "default"}, &updated))
+	assert.Equal(t, clamavv1alpha1.ClusterScanPhasePending, updated.Status.Phase)
+	require.NotNil(t, updated.Status.NextWindowTime)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, conditionMaintenanceWindow)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonWaitingForWindow, condition.Reason)
+}
+
+func TestScanScheduleReconciler_Reconcile_WaitsForMaintenanceWindow(t *testing.T) {
+	scanSchedule := newTestScanSchedule(time.Hour)
+	scanSchedule.Spec.MaintenanceWindows = closedMaintenanceWindows()
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	updated, clusterScans := reconcileScanSchedule(t, r)
+
+	assert.Empty(t, clusterScans)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, conditionMaintenanceWindow)
+	require.NotNil(t, condition)
+	assert.Equal(t, reasonWaitingForWindow, condition.Reason)
+}
+
+func TestScanScheduleReconciler_Reconcile_PropagatesMaintenanceWindows(t *testing.T) {
+	scanSchedule := newTestScanSchedule(time.Hour)
+	scanSchedule.Spec.TimeZone = "Europe/Paris"
+	scanSchedule.Spec.MaintenanceWindows = &clamavv1alpha1.MaintenanceWindows{
+		Allowed: []clamavv1alpha1.TimeWindow{{Start: "00:00", End: "00:00"}},
+	}
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	_, clusterScans := reconcileScanSchedule(t, r)
+
+	require.Len(t, clusterScans, 1)
+	require.NotNil(t, clusterScans[0].Spec.MaintenanceWindows)
+	assert.Equal(t, "Europe/Paris", clusterScans[0].Spec.MaintenanceWindows.TimeZone)
+}
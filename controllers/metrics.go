@@ -105,6 +105,32 @@ var (
 		[]string{"namespace", "schedule", "status"},
 	)
 
+	scanScheduleMissedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "clamav_scanschedule_missed_total",
+			Help: "Total number of ScanSchedule runs that were missed",
+		},
+		[]string{"namespace", "schedule"},
+	)
+
+	// Quarantine metrics
+	quarantineFilesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "clamav_quarantine_files_total",
+			Help: "Total number of infected files handled by quarantine, by outcome",
+		},
+		[]string{"namespace", "node", "outcome"},
+	)
+
+	// Notification metrics
+	notificationDeliveriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "clamav_notification_deliveries_total",
+			Help: "Total number of notification delivery attempts, by channel and result",
+		},
+		[]string{"namespace", "channel", "result"},
+	)
+
 	// ✅ NOUVEAU : Incremental scan metrics
 	incrementalScansTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -169,6 +195,9 @@ func init() {
 		clusterScansTotal,
 		scanPolicyUsageTotal,
 		scanScheduleExecutionsTotal,
+		scanScheduleMissedTotal,
+		quarantineFilesTotal,
+		notificationDeliveriesTotal,
 		// Incremental metrics
 		incrementalScansTotal,
 		filesSkippedIncremental,
@@ -235,6 +264,26 @@ func recordClusterScanMetrics(clusterScan *clamavv1alpha1.ClusterScan, phase cla
 	clusterScanNodesFailed.WithLabelValues(namespace, name).Set(float64(clusterScan.Status.FailedNodes))
 }
 
+// recordNotificationDelivery records the result of a notification delivery attempt
+func recordNotificationDelivery(namespace, channel, result string) {
+	notificationDeliveriesTotal.WithLabelValues(namespace, channel, result).Inc()
+}
+
+// recordQuarantineMetrics records the outcome of a NodeScan quarantine step
+func recordQuarantineMetrics(nodeScan *clamavv1alpha1.NodeScan) {
+	quarantine := nodeScan.Status.Quarantine
+	if quarantine == nil {
+		return
+	}
+
+	namespace := nodeScan.Namespace
+	node := nodeScan.Spec.NodeName
+
+	quarantineFilesTotal.WithLabelValues(namespace, node, clamavv1alpha1.QuarantineOutcomeQuarantined).Add(float64(quarantine.FilesQuarantined))
+	quarantineFilesTotal.WithLabelValues(namespace, node, clamavv1alpha1.QuarantineOutcomeDeleted).Add(float64(quarantine.FilesDeleted))
+	quarantineFilesTotal.WithLabelValues(namespace, node, clamavv1alpha1.QuarantineOutcomeFailed).Add(float64(quarantine.FilesFailed))
+}
+
 // recordScanPolicyUsage records when a ScanPolicy is used
 func recordScanPolicyUsage(namespace, policyName string) {
 	scanPolicyUsageTotal.WithLabelValues(namespace, policyName).Inc()
@@ -245,6 +294,11 @@ func recordScanScheduleExecution(namespace, scheduleName, status string) {
 	scanScheduleExecutionsTotal.WithLabelValues(namespace, scheduleName, status).Inc()
 }
 
+// recordScanScheduleMissed records ScanSchedule runs that were missed
+func recordScanScheduleMissed(namespace, scheduleName string, missed int) {
+	scanScheduleMissedTotal.WithLabelValues(namespace, scheduleName).Add(float64(missed))
+}
+
 // ✅ NOUVEAU : recordScanCacheMetrics enregistre les métriques du cache
 func recordScanCacheMetrics(namespace, nodeName string, sizeBytes int64, filesCount int64) {
 	scanCacheSizeBytes.WithLabelValues(namespace, nodeName).Set(float64(sizeBytes))
@@ -0,0 +1,371 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// releaseIsolationAnnotation requests a NodeScan to undo the response
+	// actions it applied to its Node
+	releaseIsolationAnnotation = "clamav.io/release-isolation"
+	// isolatedByAnnotation records on a Node the NodeScan that isolated it
+	isolatedByAnnotation = "clamav.io/isolated-by"
+	// malwareDetectedTaint is the key of the taint added to infected Nodes
+	malwareDetectedTaint = "clamav.io/malware-detected"
+	// mirrorPodAnnotation marks the API representation of static pods
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// responseActionsEnabled returns true if the policy isolates infected nodes
+func responseActionsEnabled(policy *clamavv1alpha1.ScanPolicy) bool {
+	if policy == nil || policy.Spec.ResponseActions == nil {
+		return false
+	}
+	actions := policy.Spec.ResponseActions
+	return actions.Cordon || actions.Drain || actions.TaintEffect != ""
+}
+
+// isolatingFiles returns the number of infected files that count towards the
+// isolation threshold: files with at least one signature outside of the
+// allowed ones. Infected files missing from the status have unknown
+// signatures and always count.
+func isolatingFiles(nodeScan *clamavv1alpha1.NodeScan, allowedSignatures []string) int {
+	var allowed []*regexp.Regexp
+	for _, pattern := range allowedSignatures {
+		// Invalid patterns are rejected by the webhook
+		if re, err := regexp.Compile(pattern); err == nil {
+			allowed = append(allowed, re)
+		}
+	}
+	signatureAllowed := func(signature string) bool {
+		for _, re := range allowed {
+			if re.MatchString(signature) {
+				return true
+			}
+		}
+		return false
+	}
+
+	count := 0
+	for _, f := range nodeScan.Status.InfectedFiles {
+		for _, virus := range f.Viruses {
+			if !signatureAllowed(virus) {
+				count++
+				break
+			}
+		}
+	}
+	if unlisted := int(nodeScan.Status.FilesInfected) - len(nodeScan.Status.InfectedFiles); unlisted > 0 {
+		count += unlisted
+	}
+	return count
+}
+
+// reconcileIsolation applies the ScanPolicy response actions to the Node of
+// a NodeScan with a pending isolation, and undoes them when the NodeScan is
+// annotated with clamav.io/release-isolation. Every applied action is
+// recorded in the NodeScan status so that only those are undone.
+func (r *NodeScanReconciler) reconcileIsolation(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, policy *clamavv1alpha1.ScanPolicy) (ctrl.Result, error) {
+	if _, ok := nodeScan.Annotations[releaseIsolationAnnotation]; ok {
+		return ctrl.Result{}, r.releaseIsolation(ctx, nodeScan)
+	}
+
+	status := nodeScan.Status.Isolation
+	if status == nil {
+		return ctrl.Result{}, nil
+	}
+	if status.Phase == clamavv1alpha1.NodeIsolationPhasePending {
+		// The policy may have changed since the scan completed
+		if !responseActionsEnabled(policy) {
+			status.Phase = clamavv1alpha1.NodeIsolationPhaseSkipped
+			status.Message = "Response actions are disabled"
+			return ctrl.Result{}, r.Status().Update(ctx, nodeScan)
+		}
+		if err := r.isolateNode(ctx, nodeScan, policy.Spec.ResponseActions); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if nodeScan.Status.Isolation.Phase != clamavv1alpha1.NodeIsolationPhaseDraining {
+		return ctrl.Result{}, nil
+	}
+
+	return r.drainNode(ctx, nodeScan)
+}
+
+// isolateNode cordons and taints the Node of the NodeScan if its infected
+// files reach the threshold of the response actions
+func (r *NodeScanReconciler) isolateNode(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, config *clamavv1alpha1.ResponseActionsConfig) error {
+	minInfectedFiles := int(config.MinInfectedFiles)
+	if minInfectedFiles <= 0 {
+		minInfectedFiles = clamavv1alpha1.DefaultMinInfectedFiles
+	}
+
+	status := &clamavv1alpha1.NodeIsolationStatus{Phase: clamavv1alpha1.NodeIsolationPhaseSkipped}
+	if count := isolatingFiles(nodeScan, config.AllowedSignatures); count < minInfectedFiles {
+		status.Message = fmt.Sprintf("%d infected files outside of the allowed signatures, below the threshold of %d",
+			count, minInfectedFiles)
+		nodeScan.Status.Isolation = status
+		return r.Status().Update(ctx, nodeScan)
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.NodeName}, node); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		status.Message = fmt.Sprintf("Node %s not found", nodeScan.Spec.NodeName)
+		nodeScan.Status.Isolation = status
+		return r.Status().Update(ctx, nodeScan)
+	}
+
+	// Only the actions the Node does not already carry are applied and
+	// recorded, so that releasing them does not undo an administrator's
+	// cordon or another scan's taint
+	patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if (config.Cordon || config.Drain) && !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		status.Cordoned = true
+	}
+	if config.TaintEffect != "" {
+		taint := corev1.Taint{Key: malwareDetectedTaint, Value: "true", Effect: config.TaintEffect}
+		if !taintExists(node.Spec.Taints, &taint) {
+			now := metav1.Now()
+			taint.TimeAdded = &now
+			node.Spec.Taints = append(node.Spec.Taints, taint)
+			status.Taint = &taint
+		}
+	}
+	if status.Cordoned || status.Taint != nil {
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[isolatedByAnnotation] = nodeScan.Namespace + "/" + nodeScan.Name
+		if err := r.Patch(ctx, node, patch); err != nil {
+			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "NodeIsolationFailed",
+				fmt.Sprintf("Failed to isolate node %s: %v", node.Name, err))
+			return fmt.Errorf("failed to isolate node %s: %w", node.Name, err)
+		}
+	}
+
+	now := metav1.Now()
+	status.IsolationTime = &now
+	status.Phase = clamavv1alpha1.NodeIsolationPhaseIsolated
+	status.Message = fmt.Sprintf("Node isolated after %d infected files", nodeScan.Status.FilesInfected)
+	if config.Drain {
+		status.Phase = clamavv1alpha1.NodeIsolationPhaseDraining
+		status.Message = "Evicting the pods of the node"
+	}
+	nodeScan.Status.Isolation = status
+
+	message := fmt.Sprintf("Node %s isolated by NodeScan %s/%s: %s", node.Name, nodeScan.Namespace, nodeScan.Name,
+		strings.Join(isolationActions(status, config.Drain), ", "))
+	r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "NodeIsolated", message)
+	r.Recorder.Event(node, corev1.EventTypeWarning, "NodeIsolated", message)
+
+	return r.Status().Update(ctx, nodeScan)
+}
+
+// isolationActions describes the actions of an isolation
+func isolationActions(status *clamavv1alpha1.NodeIsolationStatus, drain bool) []string {
+	var actions []string
+	if status.Cordoned {
+		actions = append(actions, "cordoned")
+	}
+	if status.Taint != nil {
+		actions = append(actions, fmt.Sprintf("tainted %s:%s", status.Taint.Key, status.Taint.Effect))
+	}
+	if drain {
+		actions = append(actions, "draining")
+	}
+	if len(actions) == 0 {
+		actions = append(actions, "already isolated")
+	}
+	return actions
+}
+
+// evictablePod returns true if draining the node evicts the pod. DaemonSet
+// pods would be recreated on the node and static pods cannot be evicted. The
+// scan and quarantine pods of the operator finish on their own, evicting the
+// quarantine would leave the infected files in place.
+func evictablePod(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if pod.Labels["security"] == "clamav" {
+		return false
+	}
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return false
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
+
+// drainNode evicts the pods of the Node of the NodeScan. Evictions refused
+// by a PodDisruptionBudget and pods still terminating are retried until the
+// node is empty.
+func (r *NodeScanReconciler) drainNode(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	status := nodeScan.Status.Isolation
+
+	pods, err := r.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeScan.Spec.NodeName).String(),
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	evicted := make(map[string]bool, len(status.EvictedPods))
+	for _, name := range status.EvictedPods {
+		evicted[name] = true
+	}
+	remaining := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeScan.Spec.NodeName || !evictablePod(pod) {
+			continue
+		}
+		remaining++
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		name := pod.Namespace + "/" + pod.Name
+		err := r.Clientset.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		})
+		switch {
+		case err == nil:
+			if !evicted[name] {
+				evicted[name] = true
+				status.EvictedPods = append(status.EvictedPods, name)
+			}
+		case errors.IsNotFound(err):
+			remaining--
+		case errors.IsTooManyRequests(err):
+			log.Info("eviction refused by a disruption budget, will retry", "pod", name)
+		default:
+			log.Error(err, "failed to evict pod", "pod", name)
+			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "PodEvictionFailed",
+				fmt.Sprintf("Failed to evict pod %s: %v", name, err))
+		}
+	}
+
+	if remaining > 0 {
+		status.Message = fmt.Sprintf("Waiting for %d pods to leave the node", remaining)
+		if err := r.Status().Update(ctx, nodeScan); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	status.Phase = clamavv1alpha1.NodeIsolationPhaseIsolated
+	status.Message = fmt.Sprintf("Node drained, %d pods evicted", len(status.EvictedPods))
+	r.Recorder.Event(nodeScan, corev1.EventTypeNormal, "NodeDrained",
+		fmt.Sprintf("Node %s drained, %d pods evicted", nodeScan.Spec.NodeName, len(status.EvictedPods)))
+	return ctrl.Result{}, r.Status().Update(ctx, nodeScan)
+}
+
+// releaseIsolation undoes the cordon and the taint recorded in the status of
+// the NodeScan and removes the release annotation once the release is
+// recorded, so that failed releases are retried. Evicted pods are left to
+// their controllers.
+func (r *NodeScanReconciler) releaseIsolation(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) error {
+	status := nodeScan.Status.Isolation
+	if status == nil || status.Phase == clamavv1alpha1.NodeIsolationPhaseSkipped ||
+		status.Phase == clamavv1alpha1.NodeIsolationPhaseReleased {
+		return r.removeReleaseAnnotation(ctx, nodeScan)
+	}
+
+	node := &corev1.Node{}
+	err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.NodeName}, node)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if status.Cordoned {
+			node.Spec.Unschedulable = false
+		}
+		if status.Taint != nil {
+			taints := node.Spec.Taints[:0]
+			for _, taint := range node.Spec.Taints {
+				if !taint.MatchTaint(status.Taint) {
+					taints = append(taints, taint)
+				}
+			}
+			node.Spec.Taints = taints
+		}
+		if node.Annotations[isolatedByAnnotation] == nodeScan.Namespace+"/"+nodeScan.Name {
+			delete(node.Annotations, isolatedByAnnotation)
+		}
+		if err := r.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to release node %s: %w", node.Name, err)
+		}
+		r.Recorder.Event(node, corev1.EventTypeNormal, "NodeReleased",
+			fmt.Sprintf("Isolation by NodeScan %s/%s released", nodeScan.Namespace, nodeScan.Name))
+	}
+
+	now := metav1.Now()
+	status.Phase = clamavv1alpha1.NodeIsolationPhaseReleased
+	status.Message = "Isolation released manually"
+	status.ReleaseTime = &now
+	if err := r.Status().Update(ctx, nodeScan); err != nil {
+		return err
+	}
+	r.Recorder.Event(nodeScan, corev1.EventTypeNormal, "NodeReleased",
+		fmt.Sprintf("Isolation of node %s released", nodeScan.Spec.NodeName))
+	return r.removeReleaseAnnotation(ctx, nodeScan)
+}
+
+// removeReleaseAnnotation removes the release annotation of the NodeScan
+func (r *NodeScanReconciler) removeReleaseAnnotation(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) error {
+	annotations := nodeScan.GetAnnotations()
+	delete(annotations, releaseIsolationAnnotation)
+	nodeScan.SetAnnotations(annotations)
+	return r.Update(ctx, nodeScan)
+}
+
+// taintExists returns true if the taints contain one matching the taint
+func taintExists(taints []corev1.Taint, taint *corev1.Taint) bool {
+	for i := range taints {
+		if taints[i].MatchTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// newInfectedNodeScan returns a NodeScan of worker-1 with a pending isolation
+func newInfectedNodeScan(viruses ...string) *clamavv1alpha1.NodeScan {
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:     clamavv1alpha1.NodeScanPhaseCompleted,
+			Isolation: &clamavv1alpha1.NodeIsolationStatus{Phase: clamavv1alpha1.NodeIsolationPhasePending},
+		},
+	}
+	for _, virus := range viruses {
+		nodeScan.Status.InfectedFiles = append(nodeScan.Status.InfectedFiles,
+			clamavv1alpha1.InfectedFile{Path: "/host/tmp/" + virus, Viruses: []string{virus}})
+	}
+	nodeScan.Status.FilesInfected = int64(len(viruses))
+	return nodeScan
+}
+
+// responseActionsPolicy returns a policy with the response actions
+func responseActionsPolicy(actions clamavv1alpha1.ResponseActionsConfig) *clamavv1alpha1.ScanPolicy {
+	return &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{ResponseActions: &actions}}
+}
+
+func TestIsolatingFiles(t *testing.T) {
+	nodeScan := newInfectedNodeScan("Eicar-Signature", "PUA.Win.Tool.Packed", "Unix.Trojan.Mirai")
+
+	assert.Equal(t, 3, isolatingFiles(nodeScan, nil))
+	assert.Equal(t, 2, isolatingFiles(nodeScan, []string{`^PUA\.`}))
+	assert.Equal(t, 0, isolatingFiles(nodeScan, []string{`^PUA\.`, "Eicar", "Mirai"}))
+
+	// Files with one signature outside of the allowed ones count
+	nodeScan.Status.InfectedFiles[1].Viruses = append(nodeScan.Status.InfectedFiles[1].Viruses, "Win.Ransomware.Locky")
+	assert.Equal(t, 1, isolatingFiles(nodeScan, []string{`^PUA\.`, "Eicar", "Mirai"}))
+
+	// Infected files missing from the status always count
+	nodeScan.Status.FilesInfected = 5
+	assert.Equal(t, 3, isolatingFiles(nodeScan, []string{`^PUA\.`, "Eicar", "Mirai"}))
+}
+
+func TestReconcileIsolation_CordonTaintAndRelease(t *testing.T) {
+	adminTaint := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{adminTaint}},
+	}
+	nodeScan := newInfectedNodeScan("Eicar-Signature")
+	r := newTestNodeScanReconciler(node, nodeScan)
+	ctx := context.Background()
+	policy := responseActionsPolicy(clamavv1alpha1.ResponseActionsConfig{
+		Cordon:      true,
+		TaintEffect: corev1.TaintEffectNoExecute,
+	})
+
+	result, err := r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	status := nodeScan.Status.Isolation
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, status.Phase)
+	assert.True(t, status.Cordoned)
+	require.NotNil(t, status.Taint)
+	assert.Equal(t, malwareDetectedTaint, status.Taint.Key)
+	assert.NotNil(t, status.IsolationTime)
+
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.True(t, updated.Spec.Unschedulable)
+	require.Len(t, updated.Spec.Taints, 2)
+	assert.Equal(t, corev1.TaintEffectNoExecute, updated.Spec.Taints[1].Effect)
+	assert.Equal(t, "default/scan-worker-1", updated.Annotations[isolatedByAnnotation])
+
+	// Isolated nodes are left alone by later reconciles
+	_, err = r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+
+	// The release annotation undoes the recorded actions only
+	var stored clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "scan-worker-1", Namespace: "default"}, &stored))
+	stored.Annotations = map[string]string{releaseIsolationAnnotation: "true"}
+	require.NoError(t, r.Update(ctx, &stored))
+
+	_, err = r.reconcileIsolation(ctx, &stored, policy)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Annotations, releaseIsolationAnnotation)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseReleased, stored.Status.Isolation.Phase)
+	assert.NotNil(t, stored.Status.Isolation.ReleaseTime)
+
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.False(t, updated.Spec.Unschedulable)
+	assert.Equal(t, []corev1.Taint{adminTaint}, updated.Spec.Taints)
+	assert.NotContains(t, updated.Annotations, isolatedByAnnotation)
+}
+
+func TestReconcileIsolation_ThresholdAndExistingCordon(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec:       corev1.NodeSpec{Unschedulable: true},
+	}
+	nodeScan := newInfectedNodeScan("PUA.Win.Tool.Packed", "Eicar-Signature")
+	r := newTestNodeScanReconciler(node, nodeScan)
+	ctx := context.Background()
+
+	// Allowed signatures do not count towards the threshold
+	policy := responseActionsPolicy(clamavv1alpha1.ResponseActionsConfig{
+		Cordon:            true,
+		MinInfectedFiles:  2,
+		AllowedSignatures: []string{`^PUA\.`},
+	})
+	_, err := r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseSkipped, nodeScan.Status.Isolation.Phase)
+	assert.Contains(t, nodeScan.Status.Isolation.Message, "below the threshold of 2")
+
+	// A node cordoned by an administrator stays cordoned when released
+	nodeScan.Status.Isolation = &clamavv1alpha1.NodeIsolationStatus{Phase: clamavv1alpha1.NodeIsolationPhasePending}
+	policy.Spec.ResponseActions.MinInfectedFiles = 0
+	_, err = r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, nodeScan.Status.Isolation.Phase)
+	assert.False(t, nodeScan.Status.Isolation.Cordoned)
+
+	nodeScan.Annotations = map[string]string{releaseIsolationAnnotation: "true"}
+	require.NoError(t, r.Update(ctx, nodeScan))
+	_, err = r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.True(t, updated.Spec.Unschedulable)
+
+	// Policies that disabled response actions skip pending isolations
+	nodeScan.Status.Isolation = &clamavv1alpha1.NodeIsolationStatus{Phase: clamavv1alpha1.NodeIsolationPhasePending}
+	_, err = r.reconcileIsolation(ctx, nodeScan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseSkipped, nodeScan.Status.Isolation.Phase)
+}
+
+func TestReconcileIsolation_Drain(t *testing.T) {
+	daemonSet := true
+	pod := func(name, nodeName string, mutate func(*corev1.Pod)) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if mutate != nil {
+			mutate(p)
+		}
+		return p
+	}
+	clientset := fake.NewSimpleClientset(
+		pod("web", "worker-1", nil),
+		pod("guarded", "worker-1", nil),
+		pod("other-node", "worker-2", nil),
+		pod("done", "worker-1", func(p *corev1.Pod) { p.Status.Phase = corev1.PodSucceeded }),
+		pod("static", "worker-1", func(p *corev1.Pod) {
+			p.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+		}),
+		pod("agent", "worker-1", func(p *corev1.Pod) {
+			p.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: &daemonSet}}
+		}),
+		pod("quarantine", "worker-1", func(p *corev1.Pod) {
+			p.Labels = map[string]string{"app": "clamav-quarantine", "security": "clamav"}
+		}),
+	)
+	// The disruption budget of the guarded pod refuses its first eviction
+	var evictions []string
+	refused := false
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if eviction.Name == "guarded" && !refused {
+			refused = true
+			return true, nil, errors.NewTooManyRequests("disruption budget", 10)
+		}
+		evictions = append(evictions, eviction.Name)
+		return true, nil, clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	nodeScan := newInfectedNodeScan("Eicar-Signature")
+	r := newTestNodeScanReconciler(node, nodeScan)
+	r.Clientset = clientset
+	ctx := context.Background()
+	policy := responseActionsPolicy(clamavv1alpha1.ResponseActionsConfig{Drain: true})
+
+	result, err := r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+	status := nodeScan.Status.Isolation
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseDraining, status.Phase)
+	assert.True(t, status.Cordoned, "drain implies cordon")
+	assert.Equal(t, []string{"apps/web"}, status.EvictedPods)
+
+	// The refused eviction is retried until the node is empty
+	for i := 0; i < 3 && status.Phase == clamavv1alpha1.NodeIsolationPhaseDraining; i++ {
+		_, err = r.reconcileIsolation(ctx, nodeScan, policy)
+		require.NoError(t, err)
+		status = nodeScan.Status.Isolation
+	}
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, status.Phase)
+	assert.Equal(t, []string{"apps/web", "apps/guarded"}, status.EvictedPods)
+	assert.ElementsMatch(t, []string{"web", "guarded"}, evictions)
+}
+
+func TestReconcileIsolation_FailedReleaseIsRetried(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	nodeScan := newInfectedNodeScan("Eicar-Signature")
+	r := newTestNodeScanReconciler(node, nodeScan)
+	ctx := context.Background()
+	policy := responseActionsPolicy(clamavv1alpha1.ResponseActionsConfig{Cordon: true})
+
+	_, err := r.reconcileIsolation(ctx, nodeScan, policy)
+	require.NoError(t, err)
+	require.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, nodeScan.Status.Isolation.Phase)
+	nodeScan.Annotations = map[string]string{releaseIsolationAnnotation: "true"}
+	require.NoError(t, r.Update(ctx, nodeScan))
+
+	// The annotation is kept while the node cannot be released
+	working := r.Client
+	r.Client = &failingNodePatchClient{Client: working}
+	_, err = r.reconcileIsolation(ctx, nodeScan, policy)
+	require.Error(t, err)
+	var stored clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "scan-worker-1", Namespace: "default"}, &stored))
+	assert.Contains(t, stored.Annotations, releaseIsolationAnnotation)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, stored.Status.Isolation.Phase)
+
+	r.Client = working
+	_, err = r.reconcileIsolation(ctx, &stored, policy)
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "scan-worker-1", Namespace: "default"}, &stored))
+	assert.NotContains(t, stored.Annotations, releaseIsolationAnnotation)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseReleased, stored.Status.Isolation.Phase)
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.False(t, updated.Spec.Unschedulable)
+}
+
+func TestNodeScanReconciler_Reconcile_ReleaseWithoutJob(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	nodeScan := newInfectedNodeScan("Eicar-Signature")
+	r := newTestNodeScanReconciler(node, nodeScan)
+	ctx := context.Background()
+
+	_, err := r.reconcileIsolation(ctx, nodeScan, responseActionsPolicy(clamavv1alpha1.ResponseActionsConfig{Cordon: true}))
+	require.NoError(t, err)
+	require.Equal(t, clamavv1alpha1.NodeIsolationPhaseIsolated, nodeScan.Status.Isolation.Phase)
+
+	// The scan Job was garbage collected after its TTL
+	nodeScan.Annotations = map[string]string{releaseIsolationAnnotation: "true"}
+	require.NoError(t, r.Update(ctx, nodeScan))
+
+	_, err = r.Reconcile(ctx, ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "scan-worker-1", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var stored clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "scan-worker-1", Namespace: "default"}, &stored))
+	assert.NotContains(t, stored.Annotations, releaseIsolationAnnotation)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseCompleted, stored.Status.Phase)
+	assert.Equal(t, clamavv1alpha1.NodeIsolationPhaseReleased, stored.Status.Isolation.Phase)
+
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.False(t, updated.Spec.Unschedulable)
+
+	// The released node is not scanned again
+	var jobs batchv1.JobList
+	require.NoError(t, r.List(ctx, &jobs, client.InNamespace("default")))
+	assert.Empty(t, jobs.Items)
+}
+
+// failingNodePatchClient fails to patch Nodes
+type failingNodePatchClient struct {
+	client.Client
+}
+
+func (c *failingNodePatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Node); ok {
+		return errors.NewServiceUnavailable("node patch failed")
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
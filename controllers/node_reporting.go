@@ -0,0 +1,238 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// nodeConditionMalwareDetected is the Node condition reporting infections
+	nodeConditionMalwareDetected corev1.NodeConditionType = "MalwareDetected"
+	// malwareDetectedLabel marks the Nodes on which malware was detected
+	malwareDetectedLabel = "clamav.io/malware-detected"
+	// malwareDetectedPathsAnnotation lists the paths scanned by the scans that
+	// flagged the Node, only a clean scan covering them clears it
+	malwareDetectedPathsAnnotation = "clamav.io/malware-detected-paths"
+)
+
+// nodeReportingFor returns the node reporting settings of the policy
+func nodeReportingFor(policy *clamavv1alpha1.ScanPolicy) clamavv1alpha1.NodeReportingConfig {
+	if policy == nil || policy.Spec.NodeReporting == nil {
+		return clamavv1alpha1.NodeReportingConfig{}
+	}
+	return *policy.Spec.NodeReporting
+}
+
+// cleanNodeScan reports whether a completed NodeScan proves that its node is
+// clean. Incremental scans skip unchanged files, workload scans only cover
+// some containers and partial results may miss infections, so none clears
+// the node.
+func cleanNodeScan(nodeScan *clamavv1alpha1.NodeScan) bool {
+	return nodeScan.Status.FilesInfected == 0 &&
+		incrementalConfigFor(nodeScan) == nil &&
+		nodeScan.Spec.Workloads == nil &&
+		!meta.IsStatusConditionFalse(nodeScan.Status.Conditions, conditionResultsParsed)
+}
+
+// nodeScanPaths returns the paths scanned by a NodeScan: the root filesystems
+// of its containers for workload scans, the paths its Job was given otherwise
+func nodeScanPaths(nodeScan *clamavv1alpha1.NodeScan, policies clamavv1alpha1.ScanPolicies) []string {
+	if nodeScan.Spec.Workloads != nil {
+		return workloadScanPaths(nodeScan.Status.Workloads)
+	}
+	return clamavv1alpha1.ResolveScanParameters(&nodeScan.Spec, policies).Paths
+}
+
+// pathsCover reports whether every path is equal to or below one of the
+// scanned paths
+func pathsCover(scanned, paths []string) bool {
+	for _, p := range paths {
+		covered := false
+		for _, s := range scanned {
+			if s = strings.TrimSuffix(s, "/"); s == "" || p == s || strings.HasPrefix(p, s+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// malwareDetectedPaths returns the paths recorded on a flagged Node, or nil
+// if the Node was flagged without them
+func malwareDetectedPaths(node *corev1.Node) []string {
+	if raw := node.Annotations[malwareDetectedPathsAnnotation]; raw != "" {
+		return strings.Split(raw, ",")
+	}
+	return nil
+}
+
+// reportToNode publishes the results of a completed NodeScan on its Node. An
+// infection records a MalwareDetected event on the Node and, if the policy
+// enables them, sets the MalwareDetected condition and label. A clean full
+// scan of the paths of the scans that set them clears them.
+func (r *NodeScanReconciler) reportToNode(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, policies clamavv1alpha1.ScanPolicies) error {
+	infected := nodeScan.Status.FilesInfected > 0
+	if !infected && !cleanNodeScan(nodeScan) {
+		return nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.NodeName}, node); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	config := nodeReportingFor(effectiveScanPolicy(nodeScan, policies))
+	paths := nodeScanPaths(nodeScan, policies)
+
+	condition := nodeCondition(node, nodeConditionMalwareDetected)
+	wasInfected := node.Labels[malwareDetectedLabel] == "true" ||
+		(condition != nil && condition.Status == corev1.ConditionTrue)
+
+	// A clean scan of other paths says nothing about the infections found
+	if !infected && wasInfected && !pathsCover(paths, malwareDetectedPaths(node)) {
+		return nil
+	}
+
+	message := fmt.Sprintf("No malware detected by NodeScan %s/%s", nodeScan.Namespace, nodeScan.Name)
+	if infected {
+		message = fmt.Sprintf("%d infected files found by NodeScan %s/%s: %s", nodeScan.Status.FilesInfected,
+			nodeScan.Namespace, nodeScan.Name, strings.Join(nodeScanSignatures(nodeScan), ", "))
+		r.Recorder.Event(node, corev1.EventTypeWarning, "MalwareDetected", message)
+	} else if wasInfected {
+		r.Recorder.Event(node, corev1.EventTypeNormal, "MalwareCleared", message)
+	}
+
+	// Labels are set when enabled and removed whenever present, so that
+	// disabling them does not leave stale labels behind. The scanned paths
+	// are recorded while the label or the condition flags the node.
+	patch := client.MergeFrom(node.DeepCopy())
+	if infected && config.Label {
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[malwareDetectedLabel] = "true"
+	} else if !infected {
+		delete(node.Labels, malwareDetectedLabel)
+	}
+	if infected && (config.Label || config.Condition || wasInfected) {
+		recorded := map[string]bool{}
+		for _, p := range append(malwareDetectedPaths(node), paths...) {
+			recorded[p] = true
+		}
+		union := make([]string, 0, len(recorded))
+		for p := range recorded {
+			union = append(union, p)
+		}
+		sort.Strings(union)
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[malwareDetectedPathsAnnotation] = strings.Join(union, ",")
+	} else if !infected {
+		delete(node.Annotations, malwareDetectedPathsAnnotation)
+	}
+	data, err := patch.Data(node)
+	if err != nil {
+		return err
+	}
+	if string(data) != "{}" {
+		if err := r.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to label node %s: %w", node.Name, err)
+		}
+	}
+
+	if config.Condition || condition != nil {
+		status, reason := corev1.ConditionFalse, "NoMalwareDetected"
+		if infected {
+			status, reason = corev1.ConditionTrue, "InfectedFilesFound"
+		}
+		// The strategic merge patch only touches this condition, the
+		// kubelet keeps updating the others
+		patch := client.StrategicMergeFrom(node.DeepCopy())
+		setNodeCondition(node, corev1.NodeCondition{
+			Type:    nodeConditionMalwareDetected,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+		})
+		if err := r.Status().Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to set condition of node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// nodeScanSignatures returns the sorted signatures detected by a NodeScan
+func nodeScanSignatures(nodeScan *clamavv1alpha1.NodeScan) []string {
+	seen := map[string]bool{}
+	var signatures []string
+	for _, f := range nodeScan.Status.InfectedFiles {
+		for _, virus := range f.Viruses {
+			if !seen[virus] {
+				seen[virus] = true
+				signatures = append(signatures, virus)
+			}
+		}
+	}
+	sort.Strings(signatures)
+	if len(signatures) > digestListLimit {
+		signatures = append(signatures[:digestListLimit], fmt.Sprintf("and %d more", len(signatures)-digestListLimit))
+	}
+	return signatures
+}
+
+// nodeCondition returns the condition of the Node with the type, if any
+func nodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setNodeCondition sets a condition of the Node, keeping its transition time
+// while its status does not change
+func setNodeCondition(node *corev1.Node, condition corev1.NodeCondition) {
+	now := metav1.Now()
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	if existing := nodeCondition(node, condition.Type); existing != nil {
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+}
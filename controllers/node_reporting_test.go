@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func TestReportToNode(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady"},
+		}},
+	}
+	r := newTestNodeScanReconciler(node)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	ctx := context.Background()
+	policy := &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{
+		NodeReporting: &clamavv1alpha1.NodeReportingConfig{Condition: true, Label: true},
+	}}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesInfected: 1,
+			InfectedFiles: []clamavv1alpha1.InfectedFile{{Path: "/host/tmp/eicar.com", Viruses: []string{"Eicar-Signature"}}},
+		},
+	}
+
+	require.NoError(t, r.reportToNode(ctx, nodeScan, clamavv1alpha1.ScanPolicies{Namespace: policy}))
+	assert.Equal(t, "Warning MalwareDetected 1 infected files found by NodeScan default/scan-worker-1: Eicar-Signature",
+		<-recorder.Events)
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.Equal(t, "true", updated.Labels[malwareDetectedLabel])
+	require.Len(t, updated.Status.Conditions, 2)
+	assert.Equal(t, corev1.NodeReady, updated.Status.Conditions[0].Type)
+	condition := nodeCondition(&updated, nodeConditionMalwareDetected)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, "InfectedFilesFound", condition.Reason)
+
+	// Incremental scans do not prove the node is clean
+	incremental := nodeScan.DeepCopy()
+	incremental.Status.FilesInfected = 0
+	incremental.Status.InfectedFiles = nil
+	incremental.Spec.Strategy = clamavv1alpha1.ScanStrategyIncremental
+	require.NoError(t, r.reportToNode(ctx, incremental, clamavv1alpha1.ScanPolicies{Namespace: policy}))
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.Equal(t, "true", updated.Labels[malwareDetectedLabel])
+	assert.Empty(t, recorder.Events)
+
+	// A clean full scan clears the label and the condition, even once the
+	// policy no longer enables them
+	clean := incremental.DeepCopy()
+	clean.Spec.Strategy = ""
+	require.NoError(t, r.reportToNode(ctx, clean, clamavv1alpha1.ScanPolicies{}))
+	assert.Equal(t, "Normal MalwareCleared No malware detected by NodeScan default/scan-worker-1", <-recorder.Events)
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.NotContains(t, updated.Labels, malwareDetectedLabel)
+	condition = nodeCondition(&updated, nodeConditionMalwareDetected)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, "NoMalwareDetected", condition.Reason)
+}
+
+func TestReportToNode_ClearedOnlyByScansOfTheSamePaths(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	r := newTestNodeScanReconciler(node)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	ctx := context.Background()
+	policy := &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{
+		Paths:         []string{"/host/var/lib"},
+		NodeReporting: &clamavv1alpha1.NodeReportingConfig{Condition: true, Label: true},
+	}}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status:     clamavv1alpha1.NodeScanStatus{FilesInfected: 1},
+	}
+	require.NoError(t, r.reportToNode(ctx, nodeScan, clamavv1alpha1.ScanPolicies{Namespace: policy}))
+	<-recorder.Events
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.Equal(t, "/host/var/lib", updated.Annotations[malwareDetectedPathsAnnotation])
+
+	// A clean scan of other paths keeps the node flagged
+	clean := nodeScan.DeepCopy()
+	clean.Status.FilesInfected = 0
+	clean.Spec.Paths = []string{"/host/tmp"}
+	require.NoError(t, r.reportToNode(ctx, clean, clamavv1alpha1.ScanPolicies{Namespace: policy}))
+	assert.Empty(t, recorder.Events)
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.Equal(t, "true", updated.Labels[malwareDetectedLabel])
+	assert.Equal(t, corev1.ConditionTrue, nodeCondition(&updated, nodeConditionMalwareDetected).Status)
+
+	// A clean scan of a parent path clears it
+	clean.Spec.Paths = []string{"/host/var/"}
+	require.NoError(t, r.reportToNode(ctx, clean, clamavv1alpha1.ScanPolicies{Namespace: policy}))
+	assert.Contains(t, <-recorder.Events, "Normal MalwareCleared")
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.NotContains(t, updated.Labels, malwareDetectedLabel)
+	assert.NotContains(t, updated.Annotations, malwareDetectedPathsAnnotation)
+	assert.Equal(t, corev1.ConditionFalse, nodeCondition(&updated, nodeConditionMalwareDetected).Status)
+}
+
+func TestNodeScanPaths(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{Spec: clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"}}
+	assert.Equal(t, DefaultScanPaths, nodeScanPaths(nodeScan, clamavv1alpha1.ScanPolicies{}))
+
+	// The paths of a ClusterScanPolicy apply when the ScanPolicy sets none
+	policies := clamavv1alpha1.ScanPolicies{
+		Cluster:   &clamavv1alpha1.ClusterScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{Paths: []string{"/host/etc"}}},
+		Namespace: &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{MaxConcurrent: 2}},
+	}
+	assert.Equal(t, []string{"/host/etc"}, nodeScanPaths(nodeScan, policies))
+
+	nodeScan.Spec.Paths = []string{"/host/opt"}
+	assert.Equal(t, []string{"/host/opt"}, nodeScanPaths(nodeScan, policies))
+
+	// Workload scans cover the root filesystems of their containers
+	nodeScan.Spec.Workloads = &clamavv1alpha1.WorkloadTarget{}
+	nodeScan.Status.Workloads = []clamavv1alpha1.WorkloadScanStatus{
+		{Namespace: "default", Pod: "web", Container: "nginx", Paths: []string{"/host/run/containerd/abc/rootfs"}},
+	}
+	assert.Equal(t, []string{"/host/run/containerd/abc/rootfs"}, nodeScanPaths(nodeScan, policies))
+}
+
+func TestPathsCover(t *testing.T) {
+	assert.True(t, pathsCover([]string{"/host"}, []string{"/host/var/lib", "/host/tmp"}))
+	assert.True(t, pathsCover([]string{"/host/var/lib"}, []string{"/host/var/lib"}))
+	assert.False(t, pathsCover([]string{"/host/var/lib"}, []string{"/host/var"}))
+	assert.False(t, pathsCover([]string{"/host/var/li"}, []string{"/host/var/lib"}))
+	assert.True(t, pathsCover([]string{"/"}, []string{"/host/tmp"}))
+	assert.True(t, pathsCover(nil, nil))
+}
+
+func TestReportToNode_EventsOnly(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	r := newTestNodeScanReconciler(node)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	ctx := context.Background()
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status:     clamavv1alpha1.NodeScanStatus{FilesInfected: 2},
+	}
+
+	require.NoError(t, r.reportToNode(ctx, nodeScan, clamavv1alpha1.ScanPolicies{}))
+	assert.Contains(t, <-recorder.Events, "Warning MalwareDetected 2 infected files")
+	var updated corev1.Node
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "worker-1"}, &updated))
+	assert.Empty(t, updated.Labels)
+	assert.Empty(t, updated.Status.Conditions)
+
+	// Deleted nodes are skipped
+	nodeScan.Spec.NodeName = "deleted"
+	assert.NoError(t, r.reportToNode(ctx, nodeScan, clamavv1alpha1.ScanPolicies{}))
+}
@@ -27,6 +27,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -58,16 +59,27 @@ type NodeScanReconciler struct {
 	ScannerImage string
 	ClamavHost   string
 	ClamavPort   int
+	// ClusterName identifies the cluster in notification templates
+	ClusterName string
 }
 
 // +kubebuilder:rbac:groups=clamav.io,resources=nodescans,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=clamav.io,resources=nodescans/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=clamav.io,resources=nodescans/finalizers,verbs=update
 // +kubebuilder:rbac:groups=clamav.io,resources=scanpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=clamav.io,resources=clusterscanpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=clamav.io,resources=clusterscanpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=clamav.io,resources=scancacheresources,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=clamav.io,resources=scancacheresources/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=clamav.io,resources=scanreports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=nodes/status,verbs=patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
 // +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop
@@ -106,18 +118,6 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
-	// Verify node exists
-	var node corev1.Node
-	if err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.NodeName}, &node); err != nil {
-		if errors.IsNotFound(err) {
-			r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "NodeNotFound",
-				fmt.Sprintf("Node %s not found", nodeScan.Spec.NodeName))
-			return ctrl.Result{}, r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseFailed,
-				"NodeNotFound", metav1.ConditionFalse, "Node does not exist")
-		}
-		return ctrl.Result{}, err
-	}
-
 	// Get the scan policy if specified
 	var scanPolicy *clamavv1alpha1.ScanPolicy
 	if nodeScan.Spec.ScanPolicy != "" {
@@ -136,6 +136,49 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Get the cluster scan policy if specified
+	var clusterScanPolicy *clamavv1alpha1.ClusterScanPolicy
+	if nodeScan.Spec.ClusterScanPolicy != "" {
+		clusterScanPolicy = &clamavv1alpha1.ClusterScanPolicy{}
+		if err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.ClusterScanPolicy}, clusterScanPolicy); err != nil {
+			if errors.IsNotFound(err) {
+				r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ClusterScanPolicyNotFound",
+					fmt.Sprintf("ClusterScanPolicy %s not found", nodeScan.Spec.ClusterScanPolicy))
+				return ctrl.Result{}, r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseFailed,
+					"ClusterScanPolicyNotFound", metav1.ConditionFalse, "ClusterScanPolicy does not exist")
+			}
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The cluster policy is layered under the namespace policy
+	policies := clamavv1alpha1.ScanPolicies{Cluster: clusterScanPolicy, Namespace: scanPolicy}
+	effectivePolicy := effectiveScanPolicy(&nodeScan, policies)
+
+	// Verify node exists
+	var node corev1.Node
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeScan.Spec.NodeName}, &node); err != nil {
+		if errors.IsNotFound(err) {
+			r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "NodeNotFound",
+				fmt.Sprintf("Node %s not found", nodeScan.Spec.NodeName))
+			if nodeScan.Status.Phase != clamavv1alpha1.NodeScanPhaseCompleted && !nodeScan.Spec.SuppressNotifications {
+				enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventScanFailed)
+			}
+			if err := r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseFailed,
+				"NodeNotFound", metav1.ConditionFalse, fmt.Sprintf("Node %s does not exist", nodeScan.Spec.NodeName)); err != nil {
+				return ctrl.Result{}, err
+			}
+			return r.reconcileNotifications(ctx, &nodeScan, effectivePolicy)
+		}
+		return ctrl.Result{}, err
+	}
+
+	// The Job of a completed scan is garbage collected after its TTL, the
+	// response actions continue from the recorded status without it
+	if nodeScan.Status.Phase == clamavv1alpha1.NodeScanPhaseCompleted {
+		return r.reconcileCompletedNodeScan(ctx, &nodeScan, effectivePolicy)
+	}
+
 	// Check if Job already exists
 	jobName := fmt.Sprintf("nodescan-%s", nodeScan.Name)
 	if len(jobName) > 63 {
@@ -156,13 +199,61 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			}
 		}
 
+		// Resolve the container filesystems selected by the workloads
+		if nodeScan.Spec.Workloads != nil {
+			workloads, err := r.resolveWorkloads(ctx, &nodeScan)
+			if err != nil {
+				log.Error(err, "unable to resolve workloads")
+				return ctrl.Result{}, err
+			}
+			nodeScan.Status.Workloads = workloads
+
+			if len(workloadScanPaths(workloads)) == 0 {
+				now := metav1.Now()
+				nodeScan.Status.CompletionTime = &now
+				nodeScan.Status.ParameterSources = parameterSources(&nodeScan, policies)
+				r.Recorder.Event(&nodeScan, corev1.EventTypeNormal, "NoWorkloadsFound",
+					fmt.Sprintf("No running container selected on node %s", nodeScan.Spec.NodeName))
+				if err := r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseCompleted,
+					"NoWorkloadsFound", metav1.ConditionTrue, "No container filesystem to scan"); err != nil {
+					return ctrl.Result{}, err
+				}
+				recordNodeScanMetrics(&nodeScan, clamavv1alpha1.NodeScanPhaseCompleted)
+				return ctrl.Result{}, nil
+			}
+		}
+
+		// Load the node scan cache and resolve the strategy for incremental scans
+		incrementalPlan, err := r.prepareIncrementalScan(ctx, &nodeScan)
+		if err != nil {
+			log.Error(err, "unable to prepare incremental scan")
+			return ctrl.Result{}, err
+		}
+
+		// Create the ConfigMap the scanner publishes its result to
+		if err := r.ensureScanResultConfigMap(ctx, &nodeScan); err != nil {
+			log.Error(err, "unable to create scan result ConfigMap")
+			return ctrl.Result{}, err
+		}
+
 		// Create the Job
-		job, err := r.constructJobForNodeScan(&nodeScan, scanPolicy)
+		job, err := r.constructJobForNodeScan(&nodeScan, policies)
 		if err != nil {
 			log.Error(err, "unable to construct job")
 			return ctrl.Result{}, err
 		}
 
+		if nodeScan.Spec.Workloads != nil {
+			applyWorkloadPaths(job, workloadScanPaths(nodeScan.Status.Workloads))
+		}
+
+		nodeScan.Status.ParameterSources = parameterSources(&nodeScan, policies)
+		nodeScan.Status.StrategyUsed = clamavv1alpha1.ScanStrategyFull
+		if incrementalPlan != nil {
+			incrementalPlan.applyToJob(job)
+			nodeScan.Status.StrategyUsed = incrementalPlan.strategy
+		}
+
 		if err := r.Create(ctx, job); err != nil {
 			log.Error(err, "unable to create Job for NodeScan", "job", job)
 			r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "JobCreationFailed",
@@ -183,6 +274,10 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		r.Recorder.Event(&nodeScan, corev1.EventTypeNormal, "JobCreated",
 			fmt.Sprintf("Scan job created for node %s", nodeScan.Spec.NodeName))
 
+		if !nodeScan.Spec.SuppressNotifications {
+			enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventScanStarted)
+		}
+
 		if err := r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseRunning,
 			"JobCreated", metav1.ConditionTrue, "Scan job has been created"); err != nil {
 			return ctrl.Result{}, err
@@ -191,7 +286,11 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		// Record metrics
 		recordNodeScanMetrics(&nodeScan, clamavv1alpha1.NodeScanPhaseRunning)
 
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		notificationResult, err := r.reconcileNotifications(ctx, &nodeScan, effectivePolicy)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return earliestRequeue(ctrl.Result{RequeueAfter: 30 * time.Second}, notificationResult), nil
 	} else if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -206,8 +305,9 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 				nodeScan.Status.Duration = int64(now.Sub(nodeScan.Status.StartTime.Time).Seconds())
 			}
 
-			// Parse results from Job with retry on transient errors
-			if err := r.parseJobResults(ctx, &nodeScan, &existingJob); err != nil {
+			// Collect results from Job with retry on transient errors
+			result, err := r.collectScanResults(ctx, &nodeScan, &existingJob)
+			if err != nil {
 				// Track retry count in annotations
 				retryCount := 0
 				if nodeScan.Annotations != nil {
@@ -223,6 +323,12 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 						"retries", retryCount)
 					r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ParseResultsMaxRetries",
 						fmt.Sprintf("Failed to parse scan results after %d attempts: %v", retryCount, err))
+					meta.SetStatusCondition(&nodeScan.Status.Conditions, metav1.Condition{
+						Type:    conditionResultsParsed,
+						Status:  metav1.ConditionFalse,
+						Reason:  "ParseFailed",
+						Message: fmt.Sprintf("Failed to parse scan results after %d attempts: %v", retryCount, err),
+					})
 					// Continue with completion - don't block on parse failures
 				} else {
 					// Update retry count annotation
@@ -244,10 +350,92 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 				}
 			}
 
+			if result != nil {
+				// Map infected files under runtime storage to their pods
+				if err := r.attributeInfectedFiles(ctx, &nodeScan, &existingJob, result); err != nil {
+					log.Error(err, "failed to attribute infected files")
+					r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "FileAttributionFailed",
+						fmt.Sprintf("Failed to attribute infected files to pods: %v", err))
+				}
+				applyScanResult(&nodeScan, result)
+				countWorkloadInfections(&nodeScan, result)
+
+				// Store the complete findings in ScanReports owned by the NodeScan
+				if err := r.reconcileScanReports(ctx, &nodeScan, result); err != nil {
+					log.Error(err, "failed to store scan reports")
+					r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ScanReportFailed",
+						fmt.Sprintf("Failed to store scan reports: %v", err))
+					return ctrl.Result{}, err
+				}
+			}
+
+			// Merge the scanned files into the node scan cache
+			if result != nil && incrementalConfigFor(&nodeScan) != nil {
+				if err := r.recordIncrementalScanResults(ctx, &nodeScan, result.CacheEntries); err != nil {
+					log.Error(err, "failed to update scan cache")
+					r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ScanCacheUpdateFailed",
+						fmt.Sprintf("Failed to update scan cache: %v", err))
+				}
+			}
+
 			r.Recorder.Event(&nodeScan, corev1.EventTypeNormal, "ScanCompleted",
 				fmt.Sprintf("Scan completed: %d files scanned, %d infected",
 					nodeScan.Status.FilesScanned, nodeScan.Status.FilesInfected))
 
+			// Publish the results on the Node
+			if err := r.reportToNode(ctx, &nodeScan, policies); err != nil {
+				log.Error(err, "failed to report scan results on node")
+				r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "NodeReportFailed",
+					fmt.Sprintf("Failed to report scan results on node %s: %v", nodeScan.Spec.NodeName, err))
+			}
+
+			// Isolate the node below if the policy responds to infections.
+			// Scans that completed before the policy enabled response actions
+			// are never isolated.
+			if nodeScan.Status.FilesInfected > 0 && responseActionsEnabled(effectivePolicy) {
+				nodeScan.Status.Isolation = &clamavv1alpha1.NodeIsolationStatus{Phase: clamavv1alpha1.NodeIsolationPhasePending}
+			}
+
+			// Quarantine the infected files below if the policy requires it.
+			// The action is decided once, later policy changes do not apply
+			// to completed scans.
+			if nodeScan.Status.FilesInfected > 0 && quarantineEnabled(effectivePolicy) {
+				nodeScan.Status.Quarantine = newPendingQuarantine(effectivePolicy.Spec.Quarantine)
+			}
+
+			// Queue notifications, they are delivered below. ClusterScans
+			// that send a digest suppress them.
+			if !nodeScan.Spec.SuppressNotifications {
+				known := false
+				if nodeScan.Status.FilesInfected > 0 && effectivePolicy != nil {
+					keys, complete := nodeScanFindingKeys(nodeScan)
+					known, err = recordFindings(ctx, r.Client, nodeScan.Namespace, nodeScan.UID,
+						notificationDeduplication(effectivePolicy.Spec.Notifications), keys)
+					if err != nil {
+						// Without the state the infections are notified again
+						log.Error(err, "failed to record notified infections")
+						r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "NotificationStateFailed",
+							fmt.Sprintf("Failed to record notified infections, deduplication is skipped: %v", err))
+					}
+					known = known && complete && err == nil
+				}
+				if nodeScan.Status.FilesInfected > 0 && !known {
+					enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventScanCompleted)
+				} else {
+					// Clean scans and infections notified recently are only
+					// sent on channels that report every scan
+					enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventScanCompleted,
+						clamavv1alpha1.NotificationTriggerScanCompleted)
+				}
+				if known {
+					r.Recorder.Event(&nodeScan, corev1.EventTypeNormal, "InfectionNotificationSuppressed",
+						"All infections were already notified within the deduplication window")
+				}
+				if meta.IsStatusConditionFalse(nodeScan.Status.Conditions, conditionResultsParsed) {
+					enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventParseFailed)
+				}
+			}
+
 			if err := r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseCompleted,
 				"ScanCompleted", metav1.ConditionTrue, "Scan completed successfully"); err != nil {
 				return ctrl.Result{}, err
@@ -256,80 +444,82 @@ func (r *NodeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			// Record metrics
 			recordNodeScanMetrics(&nodeScan, clamavv1alpha1.NodeScanPhaseCompleted)
 
-			// Send notifications if infected files found
-			if nodeScan.Status.FilesInfected > 0 && scanPolicy != nil {
-				r.sendNotifications(ctx, &nodeScan, scanPolicy)
-			}
-
-			// Update ScanPolicy usage stats
+			// Update ScanPolicy and ClusterScanPolicy usage stats
 			if scanPolicy != nil {
 				r.updatePolicyStats(ctx, scanPolicy)
 			}
+			if clusterScanPolicy != nil {
+				r.updateClusterPolicyStats(ctx, clusterScanPolicy)
+			}
 		}
-		return ctrl.Result{}, nil
+
+		return r.reconcileCompletedNodeScan(ctx, &nodeScan, effectivePolicy)
 
 	} else if existingJob.Status.Failed > 0 {
 		if nodeScan.Status.Phase != clamavv1alpha1.NodeScanPhaseFailed {
 			nodeScan.Status.Phase = clamavv1alpha1.NodeScanPhaseFailed
+			message := jobFailureMessage(&existingJob)
+
+			r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ScanFailed", message)
 
-			r.Recorder.Event(&nodeScan, corev1.EventTypeWarning, "ScanFailed",
-				"Scan job failed")
+			if !nodeScan.Spec.SuppressNotifications {
+				enqueueNotifications(&nodeScan, effectivePolicy, clamavv1alpha1.NotificationEventScanFailed)
+			}
 
 			if err := r.updateStatus(ctx, &nodeScan, clamavv1alpha1.NodeScanPhaseFailed,
-				"ScanFailed", metav1.ConditionFalse, "Scan job failed"); err != nil {
+				"ScanFailed", metav1.ConditionFalse, message); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			// Record metrics
 			recordNodeScanMetrics(&nodeScan, clamavv1alpha1.NodeScanPhaseFailed)
 		}
-		return ctrl.Result{}, nil
-	}
-
-	// Job is still running
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
-}
 
-// constructJobForNodeScan creates a Job for scanning a node
-func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) (*batchv1.Job, error) {
-	// Determine paths to scan
-	paths := nodeScan.Spec.Paths
-	if len(paths) == 0 && scanPolicy != nil {
-		paths = scanPolicy.Spec.Paths
-	}
-	if len(paths) == 0 {
-		paths = []string{"/host/var/lib", "/host/opt"}
+		// Deliver pending notifications and schedule their retries
+		return r.reconcileNotifications(ctx, &nodeScan, effectivePolicy)
 	}
 
-	// Determine other parameters
-	maxConcurrent := nodeScan.Spec.MaxConcurrent
-	if maxConcurrent == 0 && scanPolicy != nil {
-		maxConcurrent = scanPolicy.Spec.MaxConcurrent
-	}
-	if maxConcurrent == 0 {
-		maxConcurrent = 5
+	// Job is still running, retry the pending ScanStarted notifications
+	notificationResult, err := r.reconcileNotifications(ctx, &nodeScan, effectivePolicy)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
+	return earliestRequeue(ctrl.Result{RequeueAfter: 30 * time.Second}, notificationResult), nil
+}
 
-	fileTimeout := nodeScan.Spec.FileTimeout
-	if fileTimeout == 0 && scanPolicy != nil {
-		fileTimeout = scanPolicy.Spec.FileTimeout
-	}
-	if fileTimeout == 0 {
-		fileTimeout = 300000
+// reconcileCompletedNodeScan quarantines the infected files, isolates or
+// releases the node and delivers the pending notifications of a completed
+// NodeScan
+func (r *NodeScanReconciler) reconcileCompletedNodeScan(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, effectivePolicy *clamavv1alpha1.ScanPolicy) (ctrl.Result, error) {
+	// Move or delete the infected files of a pending quarantine
+	var result ctrl.Result
+	var err error
+	if quarantineInProgress(nodeScan.Status.Quarantine) {
+		if result, err = r.reconcileQuarantine(ctx, nodeScan, effectivePolicy); err != nil {
+			return result, err
+		}
 	}
 
-	maxFileSize := nodeScan.Spec.MaxFileSize
-	if maxFileSize == 0 && scanPolicy != nil {
-		maxFileSize = scanPolicy.Spec.MaxFileSize
-	}
-	if maxFileSize == 0 {
-		maxFileSize = 104857600
+	// Cordon, taint or drain the infected node, or release it on request
+	isolationResult, err := r.reconcileIsolation(ctx, nodeScan, effectivePolicy)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
+	result = earliestRequeue(result, isolationResult)
 
-	connectTimeout := int64(60000)
-	if scanPolicy != nil && scanPolicy.Spec.ConnectTimeout > 0 {
-		connectTimeout = scanPolicy.Spec.ConnectTimeout
+	// Deliver pending notifications and schedule their retries
+	notificationResult, err := r.reconcileNotifications(ctx, nodeScan, effectivePolicy)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
+	return earliestRequeue(result, notificationResult), nil
+}
+
+// constructJobForNodeScan creates a Job for scanning a node
+func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.NodeScan, policies clamavv1alpha1.ScanPolicies) (*batchv1.Job, error) {
+	// Resolve the effective parameters; the defaulting webhook records the
+	// same values in the resolved-spec annotation
+	params := clamavv1alpha1.ResolveScanParameters(&nodeScan.Spec, policies)
 
 	// Environment variables
 	envVars := []corev1.EnvVar{
@@ -338,26 +528,25 @@ func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.No
 		{Name: "RESULTS_DIR", Value: "/results"},
 		{Name: "CLAMAV_HOST", Value: r.ClamavHost},
 		{Name: "CLAMAV_PORT", Value: fmt.Sprintf("%d", r.ClamavPort)},
-		{Name: "PATHS_TO_SCAN", Value: strings.Join(paths, ",")},
-		{Name: "MAX_CONCURRENT", Value: fmt.Sprintf("%d", maxConcurrent)},
-		{Name: "FILE_TIMEOUT", Value: fmt.Sprintf("%d", fileTimeout)},
-		{Name: "CONNECT_TIMEOUT", Value: fmt.Sprintf("%d", connectTimeout)},
-		{Name: "MAX_FILE_SIZE", Value: fmt.Sprintf("%d", maxFileSize)},
+		{Name: "PATHS_TO_SCAN", Value: strings.Join(params.Paths, ",")},
+		{Name: "MAX_CONCURRENT", Value: fmt.Sprintf("%d", params.MaxConcurrent)},
+		{Name: "FILE_TIMEOUT", Value: fmt.Sprintf("%d", params.FileTimeout)},
+		{Name: "CONNECT_TIMEOUT", Value: fmt.Sprintf("%d", params.ConnectTimeout)},
+		{Name: "MAX_FILE_SIZE", Value: fmt.Sprintf("%d", params.MaxFileSize)},
+		{Name: "RESULT_CONFIGMAP", Value: scanResultConfigMapName(nodeScan)},
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+			},
+		},
 	}
 
 	// Resources - apply in priority order:
 	// 1. NodeScan.Spec.Resources (explicit)
-	// 2. ScanPolicy.Spec.Resources (policy-defined)
+	// 2. ScanPolicy, then ClusterScanPolicy resources (policy-defined)
 	// 3. Priority-based defaults (high/medium/low)
-	var resources corev1.ResourceRequirements
-	if nodeScan.Spec.Resources != nil {
-		resources = *nodeScan.Spec.Resources
-	} else if scanPolicy != nil && scanPolicy.Spec.Resources != nil {
-		resources = *scanPolicy.Spec.Resources
-	} else {
-		// Use priority-based default resources
-		resources = GetResourcesForPriority(nodeScan.Spec.Priority)
-	}
+	resources := params.Resources
 
 	// Job name
 	jobName := fmt.Sprintf("nodescan-%s", nodeScan.Name)
@@ -366,10 +555,7 @@ func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.No
 	}
 
 	// TTL
-	ttl := nodeScan.Spec.TTLSecondsAfterFinished
-	if ttl == nil {
-		ttl = ptr.To(int32(86400)) // 24 hours default
-	}
+	ttl := ptr.To(params.TTLSecondsAfterFinished)
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -412,6 +598,7 @@ func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.No
 					},
 					// ImagePullSecrets can be configured via Helm values or ScanPolicy
 					ImagePullSecrets: []corev1.LocalObjectReference{},
+					InitContainers:   []corev1.Container{r.overlayMountsInitContainer()},
 					Containers: []corev1.Container{
 						{
 							Name:            "scanner",
@@ -456,7 +643,7 @@ func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.No
 							Name: "scan-results",
 							VolumeSource: corev1.VolumeSource{
 								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/log/clamav-scans",
+									Path: scanResultsHostPath,
 									Type: ptr.To(corev1.HostPathDirectoryOrCreate),
 								},
 							},
@@ -475,18 +662,19 @@ func (r *NodeScanReconciler) constructJobForNodeScan(nodeScan *clamavv1alpha1.No
 	return job, nil
 }
 
-// parseJobResults parses the scan results from the completed Job
-func (r *NodeScanReconciler) parseJobResults(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, job *batchv1.Job) error {
+// parseJobResults builds the scan result from the logs of the completed Job.
+// It is only used for scanner images that do not publish a scan result.
+func (r *NodeScanReconciler) parseJobResults(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, job *batchv1.Job) (*scanResult, error) {
 	log := log.FromContext(ctx)
 
 	// Get the Pod from the Job
 	podList := &corev1.PodList{}
 	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels(job.Spec.Selector.MatchLabels)); err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(podList.Items) == 0 {
-		return fmt.Errorf("no pods found for job")
+		return nil, fmt.Errorf("no pods found for job")
 	}
 
 	pod := podList.Items[0]
@@ -498,36 +686,34 @@ func (r *NodeScanReconciler) parseJobResults(ctx context.Context, nodeScan *clam
 
 	stream, err := req.Stream(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get pod logs: %w", err)
+		return nil, fmt.Errorf("failed to get pod logs: %w", err)
 	}
 	defer stream.Close()
 
-	// Parse log lines to extract JSON
+	// Parse log lines to extract JSON; scan cache lines can be large
 	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
-	var (
-		filesScanned  int64
-		filesInfected int64
-		filesSkipped  int64
-		errorCount    int64
-		infectedFiles []clamavv1alpha1.InfectedFile
-	)
+	result := &scanResult{Node: nodeScan.Spec.NodeName}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		// JSON log structure
 		type LogEntry struct {
-			Level         string   `json:"level"`
-			Message       string   `json:"message"`
-			FilesScanned  int64    `json:"files_scanned"`
-			FilesInfected int64    `json:"files_infected"`
-			FilesSkipped  int64    `json:"files_skipped"`
-			ErrorsCount   int64    `json:"errors_count"`
-			FilePath      string   `json:"file_path"`
-			VirusNames    []string `json:"virus_names"`
-			FileSize      int64    `json:"file_size"`
-			Alert         string   `json:"alert"`
+			Level                   string                        `json:"level"`
+			Message                 string                        `json:"message"`
+			FilesScanned            int64                         `json:"files_scanned"`
+			FilesInfected           int64                         `json:"files_infected"`
+			FilesSkipped            int64                         `json:"files_skipped"`
+			FilesSkippedIncremental int64                         `json:"files_skipped_incremental"`
+			ErrorsCount             int64                         `json:"errors_count"`
+			Strategy                string                        `json:"strategy"`
+			FilePath                string                        `json:"file_path"`
+			VirusNames              []string                      `json:"virus_names"`
+			FileSize                int64                         `json:"file_size"`
+			Alert                   string                        `json:"alert"`
+			CacheEntries            []clamavv1alpha1.FileMetadata `json:"cache_entries"`
 		}
 
 		var entry LogEntry
@@ -537,43 +723,42 @@ func (r *NodeScanReconciler) parseJobResults(ctx context.Context, nodeScan *clam
 
 		// Scan completion log
 		if entry.Message == "Scan terminé avec succès" {
-			filesScanned = entry.FilesScanned
-			filesInfected = entry.FilesInfected
-			filesSkipped = entry.FilesSkipped
-			errorCount = entry.ErrorsCount
+			result.Statistics = scanResultStatistics{
+				FilesScanned:            entry.FilesScanned,
+				FilesInfected:           entry.FilesInfected,
+				FilesSkipped:            entry.FilesSkipped,
+				FilesSkippedIncremental: entry.FilesSkippedIncremental,
+				Errors:                  entry.ErrorsCount,
+			}
+			result.Strategy = clamavv1alpha1.ScanStrategy(entry.Strategy)
+		}
+
+		// Scan cache entries reported for incremental scans
+		if entry.Alert == "SCAN_CACHE_ENTRIES" {
+			result.CacheEntries = append(result.CacheEntries, entry.CacheEntries...)
 		}
 
 		// Individual infected file log
 		if entry.Alert == "INFECTED_FILE" && entry.FilePath != "" {
-			infectedFile := clamavv1alpha1.InfectedFile{
+			result.Infected = append(result.Infected, scanResultInfectedFile{
 				Path:    entry.FilePath,
 				Viruses: entry.VirusNames,
 				Size:    entry.FileSize,
-			}
-
-			infectedFiles = append(infectedFiles, infectedFile)
+			})
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		log.Error(err, "error reading logs")
-		return fmt.Errorf("error reading logs: %w", err)
+		return nil, fmt.Errorf("error reading logs: %w", err)
 	}
 
-	// Update status
-	nodeScan.Status.FilesScanned = filesScanned
-	nodeScan.Status.FilesInfected = filesInfected
-	nodeScan.Status.FilesSkipped = filesSkipped
-	nodeScan.Status.ErrorCount = errorCount
-
-	// Limit to 100 infected files for performance
-	if len(infectedFiles) > 100 {
-		nodeScan.Status.InfectedFiles = infectedFiles[:100]
-	} else {
-		nodeScan.Status.InfectedFiles = infectedFiles
+	result.Status = scanResultStatusClean
+	if result.Statistics.FilesInfected > 0 {
+		result.Status = scanResultStatusInfected
 	}
 
-	return nil
+	return result, nil
 }
 
 // updateStatus updates the NodeScan status with a condition
@@ -618,6 +803,64 @@ func (r *NodeScanReconciler) updatePolicyStats(ctx context.Context, scanPolicy *
 	r.Status().Update(ctx, scanPolicy)
 }
 
+// updateClusterPolicyStats updates the usage statistics of a ClusterScanPolicy
+func (r *NodeScanReconciler) updateClusterPolicyStats(ctx context.Context, clusterScanPolicy *clamavv1alpha1.ClusterScanPolicy) {
+	now := metav1.Now()
+	clusterScanPolicy.Status.LastUsed = &now
+	clusterScanPolicy.Status.UsageCount++
+	r.Status().Update(ctx, clusterScanPolicy)
+}
+
+// jobFailureMessage describes why a scanner Job failed
+func jobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			if c.Message != "" {
+				return fmt.Sprintf("Scan job failed: %s", c.Message)
+			}
+			return fmt.Sprintf("Scan job failed: %s", c.Reason)
+		}
+	}
+	return "Scan job failed"
+}
+
+// earliestRequeue combines two reconcile results, requeueing at the earliest time
+func earliestRequeue(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 || (b.RequeueAfter > 0 && b.RequeueAfter < a.RequeueAfter) {
+		a.RequeueAfter = b.RequeueAfter
+	}
+	a.Requeue = a.Requeue || b.Requeue
+	return a
+}
+
+// effectiveScanPolicy merges the policies of a NodeScan into a single ScanPolicy
+// in the NodeScan namespace, used for notifications and quarantine. It returns
+// nil when the NodeScan references no policy.
+func effectiveScanPolicy(nodeScan *clamavv1alpha1.NodeScan, policies clamavv1alpha1.ScanPolicies) *clamavv1alpha1.ScanPolicy {
+	spec, _ := policies.Merge()
+	if spec == nil {
+		return nil
+	}
+	name := nodeScan.Spec.ScanPolicy
+	if name == "" {
+		name = nodeScan.Spec.ClusterScanPolicy
+	}
+	return &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodeScan.Namespace},
+		Spec:       *spec,
+	}
+}
+
+// parameterSources returns where each scan parameter comes from. The defaulting
+// webhook copies resolved values into the spec, so the sources it recorded at
+// admission time take precedence.
+func parameterSources(nodeScan *clamavv1alpha1.NodeScan, policies clamavv1alpha1.ScanPolicies) map[string]string {
+	if recorded, ok := nodeScan.ResolvedParameters(); ok && len(recorded.Sources) > 0 {
+		return recorded.Sources
+	}
+	return clamavv1alpha1.ResolveScanParameters(&nodeScan.Spec, policies).Sources
+}
+
 // cleanupNodeScan cleans up resources when NodeScan is deleted
 func (r *NodeScanReconciler) cleanupNodeScan(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) error {
 	// Delete associated Job if it exists
@@ -50,7 +50,7 @@ func newTestNodeScanReconciler(objs ...client.Object) *NodeScanReconciler {
 	fakeClient := fakeclient.NewClientBuilder().
 		WithScheme(scheme).
 		WithObjects(objs...).
-		WithStatusSubresource(&clamavv1alpha1.NodeScan{}).
+		WithStatusSubresource(&clamavv1alpha1.NodeScan{}, &clamavv1alpha1.ScanCacheResource{}).
 		Build()
 
 	return &NodeScanReconciler{
@@ -192,6 +192,67 @@ func TestNodeScanReconciler_Reconcile_WithScanPolicy(t *testing.T) {
 	assert.Equal(t, 30*time.Second, result.RequeueAfter)
 }
 
+func TestNodeScanReconciler_Reconcile_WithClusterScanPolicy(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	clusterScanPolicy := &clamavv1alpha1.ClusterScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "baseline"},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Paths:          []string{"/host/etc"},
+			MaxConcurrent:  4,
+			ConnectTimeout: 30000,
+		},
+	}
+	scanPolicy := &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec:       clamavv1alpha1.ScanPolicySpec{MaxConcurrent: 10},
+	}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName:          "test-node",
+			ScanPolicy:        "test-policy",
+			ClusterScanPolicy: "baseline",
+		},
+	}
+	r := newTestNodeScanReconciler(node, clusterScanPolicy, scanPolicy, nodeScan)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "nodescan-test-scan", Namespace: "default"}, &job))
+	env := jobEnv(&job)
+	assert.Equal(t, "/host/etc", env["PATHS_TO_SCAN"])
+	assert.Equal(t, "10", env["MAX_CONCURRENT"])
+	assert.Equal(t, "30000", env["CONNECT_TIMEOUT"])
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Equal(t, "ClusterScanPolicy/baseline", updated.Status.ParameterSources["paths"])
+	assert.Equal(t, "ScanPolicy/test-policy", updated.Status.ParameterSources["maxConcurrent"])
+	assert.Equal(t, clamavv1alpha1.ParameterSourceDefault, updated.Status.ParameterSources["fileTimeout"])
+}
+
+func TestNodeScanReconciler_Reconcile_ClusterScanPolicyNotFound(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "test-node", ClusterScanPolicy: "missing"},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseFailed, updated.Status.Phase)
+}
+
 func TestNodeScanReconciler_Reconcile_Deletion(t *testing.T) {
 	now := metav1.Now()
 	nodeScan := &clamavv1alpha1.NodeScan{
@@ -283,7 +344,7 @@ func TestConstructJobForNodeScan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := newTestNodeScanReconciler()
-			job, err := r.constructJobForNodeScan(tt.nodeScan, tt.scanPolicy)
+			job, err := r.constructJobForNodeScan(tt.nodeScan, clamavv1alpha1.ScanPolicies{Namespace: tt.scanPolicy})
 
 			if tt.wantErr {
 				assert.Error(t, err)
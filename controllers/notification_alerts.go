@@ -0,0 +1,328 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// conditionResultsParsed reports whether the results of a NodeScan could be read
+const conditionResultsParsed = "ResultsParsed"
+
+// alert is a channel independent notification. Scan failures are sent as
+// alerts on every channel, other events only on the incident channels.
+type alert struct {
+	// title is the headline of the alert
+	title string
+	// eventType is the type of the webhook payload
+	eventType string
+	// severity is critical, warning or info
+	severity string
+	// message explains what happened
+	message string
+	// source is the node or the object the alert is about
+	source string
+	// dedupKey identifies the alert in incident channels when it has no findings
+	dedupKey string
+	// fields describe the affected object
+	fields []alertField
+	// findings are the infections reported by the alert
+	findings []alertFinding
+	// infectedFiles are attached to emails
+	infectedFiles []reportedFile
+}
+
+// alertField is a detail of an alert. key names it in webhook payloads.
+type alertField struct {
+	key   string
+	title string
+	value string
+}
+
+// alertFinding is a signature detected on a node. Incident channels open one
+// incident per finding.
+type alertFinding struct {
+	node      string
+	signature string
+	// paths are the infected files, if known
+	paths []string
+	// workloads are the pods and containers of the infected files, if known
+	workloads []string
+}
+
+// dedupKey identifies the finding across scans
+func (f alertFinding) dedupKey() string {
+	return fmt.Sprintf("clamav/%s/%s", f.node, f.signature)
+}
+
+// details returns the fields of an alert keyed for JSON payloads
+func (a alert) details() map[string]interface{} {
+	details := map[string]interface{}{}
+	for _, f := range a.fields {
+		details[f.key] = f.value
+	}
+	return details
+}
+
+// nodeScanAlert describes the failure of a NodeScan
+func nodeScanAlert(nodeScan *clamavv1alpha1.NodeScan, event string) alert {
+	a := alert{
+		severity: "warning",
+		source:   nodeScan.Spec.NodeName,
+		fields: []alertField{
+			{key: "name", title: "Scan Name", value: nodeScan.Name},
+			{key: "namespace", title: "Namespace", value: nodeScan.Namespace},
+			{key: "node", title: "Node", value: nodeScan.Spec.NodeName},
+			{key: "phase", title: "Status", value: string(nodeScan.Status.Phase)},
+		},
+	}
+
+	switch event {
+	case clamavv1alpha1.NotificationEventParseFailed:
+		a.title = "ClamAV Scan Results Incomplete"
+		a.eventType = "clamav.scan.parse_failed"
+		a.dedupKey = fmt.Sprintf("clamav/%s/parse-failed", nodeScan.Spec.NodeName)
+		a.message = "The scan results could not be read, the scan completed with partial results"
+		if c := meta.FindStatusCondition(nodeScan.Status.Conditions, conditionResultsParsed); c != nil {
+			a.message = c.Message
+		}
+	default:
+		a.title = "ClamAV Scan Failed"
+		a.eventType = "clamav.scan.failed"
+		a.dedupKey = fmt.Sprintf("clamav/%s/scan-failed", nodeScan.Spec.NodeName)
+		a.message = "The scan did not complete"
+		if c := latestFailedCondition(nodeScan.Status.Conditions); c != nil {
+			a.message = c.Message
+		}
+	}
+	return a
+}
+
+// scanStartedAlert describes the start of a NodeScan
+func scanStartedAlert(nodeScan *clamavv1alpha1.NodeScan) alert {
+	return alert{
+		title:     "ClamAV Scan Started",
+		eventType: "clamav.scan.started",
+		severity:  "info",
+		message:   fmt.Sprintf("Scan job created for node %s", nodeScan.Spec.NodeName),
+		source:    nodeScan.Spec.NodeName,
+		dedupKey:  fmt.Sprintf("clamav/%s/scan-started", nodeScan.Spec.NodeName),
+		fields: []alertField{
+			{key: "name", title: "Scan Name", value: nodeScan.Name},
+			{key: "namespace", title: "Namespace", value: nodeScan.Namespace},
+			{key: "node", title: "Node", value: nodeScan.Spec.NodeName},
+			{key: "phase", title: "Status", value: string(nodeScan.Status.Phase)},
+		},
+	}
+}
+
+// scanCompletedAlert describes the result of a NodeScan with one finding per
+// detected signature
+func scanCompletedAlert(nodeScan *clamavv1alpha1.NodeScan) alert {
+	status := nodeScan.Status
+	a := alert{
+		title:     "ClamAV Scan Completed",
+		eventType: "clamav.scan.completed",
+		severity:  "info",
+		message:   fmt.Sprintf("%d files scanned, no malware detected", status.FilesScanned),
+		source:    nodeScan.Spec.NodeName,
+		dedupKey:  fmt.Sprintf("clamav/%s/scan-completed", nodeScan.Spec.NodeName),
+		fields: []alertField{
+			{key: "name", title: "Scan Name", value: nodeScan.Name},
+			{key: "namespace", title: "Namespace", value: nodeScan.Namespace},
+			{key: "node", title: "Node", value: nodeScan.Spec.NodeName},
+			{key: "phase", title: "Status", value: string(status.Phase)},
+			{key: "filesScanned", title: "Files Scanned", value: fmt.Sprintf("%d", status.FilesScanned)},
+			{key: "filesInfected", title: "Files Infected", value: fmt.Sprintf("%d", status.FilesInfected)},
+			{key: "duration", title: "Duration", value: fmt.Sprintf("%d seconds", status.Duration)},
+		},
+	}
+	if status.FilesInfected == 0 {
+		return a
+	}
+
+	a.title = "ClamAV Malware Detected"
+	a.severity = "critical"
+	a.message = fmt.Sprintf("%d of %d scanned files are infected on node %s",
+		status.FilesInfected, status.FilesScanned, nodeScan.Spec.NodeName)
+
+	paths := map[string][]string{}
+	workloads := map[string][]string{}
+	for _, f := range status.InfectedFiles {
+		for _, virus := range f.Viruses {
+			paths[virus] = append(paths[virus], f.Path)
+			workloads[virus] = appendUnique(workloads[virus], attributionWorkload(f.Attribution))
+		}
+	}
+	signatures := make([]string, 0, len(paths))
+	for virus := range paths {
+		signatures = append(signatures, virus)
+	}
+	sort.Strings(signatures)
+	for _, virus := range signatures {
+		a.findings = append(a.findings, alertFinding{node: nodeScan.Spec.NodeName, signature: virus,
+			paths: paths[virus], workloads: workloads[virus]})
+	}
+	a.infectedFiles = nodeScanReportedFiles(nodeScan)
+	return a
+}
+
+// quarantineAlert describes the outcome of the quarantine of a NodeScan
+func quarantineAlert(nodeScan *clamavv1alpha1.NodeScan) alert {
+	quarantine := nodeScan.Status.Quarantine
+	a := alert{
+		title:     "ClamAV Quarantine Completed",
+		eventType: "clamav.quarantine.completed",
+		severity:  "info",
+		message: fmt.Sprintf("%d files quarantined, %d deleted, %d failed",
+			quarantine.FilesQuarantined, quarantine.FilesDeleted, quarantine.FilesFailed),
+		source:   nodeScan.Spec.NodeName,
+		dedupKey: fmt.Sprintf("clamav/%s/quarantine", nodeScan.Spec.NodeName),
+		fields: []alertField{
+			{key: "name", title: "Scan Name", value: nodeScan.Name},
+			{key: "namespace", title: "Namespace", value: nodeScan.Namespace},
+			{key: "node", title: "Node", value: nodeScan.Spec.NodeName},
+			{key: "action", title: "Action", value: quarantine.Action},
+		},
+	}
+	if quarantine.FilesFailed > 0 {
+		a.severity = "warning"
+	}
+	return a
+}
+
+// latestFailedCondition returns the most recent condition that is not true
+func latestFailedCondition(conditions []metav1.Condition) *metav1.Condition {
+	var latest *metav1.Condition
+	for i := range conditions {
+		c := &conditions[i]
+		if c.Status == metav1.ConditionTrue {
+			continue
+		}
+		if latest == nil || !c.LastTransitionTime.Before(&latest.LastTransitionTime) {
+			latest = c
+		}
+	}
+	return latest
+}
+
+// alertStyle returns the Slack attachment color and the icon of a severity
+func alertStyle(severity string) (string, string) {
+	switch severity {
+	case "critical":
+		return "danger", "🚨"
+	case "info":
+		return "good", "ℹ️"
+	default:
+		return "warning", "⚠️"
+	}
+}
+
+// alertEmailBody returns the plain text email body of an alert
+func alertEmailBody(a alert) string {
+	var body strings.Builder
+	body.WriteString("================================================================================\n")
+	body.WriteString(fmt.Sprintf("  %s\n", strings.ToUpper(a.title)))
+	body.WriteString("================================================================================\n\n")
+	for _, f := range a.fields {
+		body.WriteString(fmt.Sprintf("%-19s%s\n", f.title+":", f.value))
+	}
+	body.WriteString("\n")
+	body.WriteString("DETAILS:\n")
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	body.WriteString(a.message + "\n\n")
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	body.WriteString("This is an automated message from ClamAV Operator.\n")
+	body.WriteString("For more information, check the Kubernetes events of the resource.\n")
+	body.WriteString("================================================================================\n")
+	return body.String()
+}
+
+// sendAlert sends the alert on the channel. Secrets are read from namespace.
+func sendAlert(ctx context.Context, c client.Reader, notifications *clamavv1alpha1.NotificationConfig,
+	namespace, channel string, a alert) error {
+	if err := checkNotificationChannel(notifications, channel); err != nil {
+		return err
+	}
+
+	color, icon := alertStyle(a.severity)
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		fields := []map[string]interface{}{}
+		for _, f := range a.fields {
+			fields = append(fields, map[string]interface{}{
+				"title": f.title,
+				"value": f.value,
+				"short": true,
+			})
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": "Details",
+			"value": a.message,
+			"short": false,
+		})
+
+		message := map[string]interface{}{
+			"channel":    notifications.Slack.Channel,
+			"username":   "ClamAV Operator",
+			"icon_emoji": ":shield:",
+			"text":       fmt.Sprintf("%s %s", icon, a.title),
+			"attachments": []map[string]interface{}{
+				{
+					"color":  color,
+					"fields": fields,
+					"footer": "ClamAV Operator",
+					"ts":     time.Now().Unix(),
+				},
+			},
+		}
+		return postSlackMessage(ctx, c, notifications.Slack, namespace, message)
+
+	case clamavv1alpha1.NotificationChannelEmail:
+		return deliverEmail(ctx, c, notifications.Email, namespace, alertEmail(icon+" "+a.title, alertEmailBody(a), a))
+
+	case clamavv1alpha1.NotificationChannelTeams:
+		return postTeamsCard(ctx, c, notifications.Teams, namespace, a)
+
+	case clamavv1alpha1.NotificationChannelPagerDuty:
+		return sendPagerDutyEvents(ctx, c, notifications.PagerDuty, namespace, a)
+
+	case clamavv1alpha1.NotificationChannelOpsgenie:
+		return sendOpsgenieAlerts(ctx, c, notifications.Opsgenie, namespace, a)
+
+	default:
+		payload := map[string]interface{}{
+			"type":      a.eventType,
+			"timestamp": time.Now().Format(time.RFC3339),
+			"severity":  a.severity,
+			"title":     a.title,
+			"message":   a.message,
+			"details":   a.details(),
+		}
+		return postWebhook(ctx, c, notifications.Webhook, namespace, payload)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// webhookRecorder is a webhook receiver that records the payloads it receives
+type webhookRecorder struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func newWebhookRecorder(t *testing.T) *webhookRecorder {
+	rec := &webhookRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rec.mu.Lock()
+		rec.payloads = append(rec.payloads, payload)
+		rec.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func (rec *webhookRecorder) received() []map[string]interface{} {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]map[string]interface{}(nil), rec.payloads...)
+}
+
+func newTestAlertPolicy(url string, triggers ...clamavv1alpha1.NotificationTrigger) *clamavv1alpha1.ScanPolicy {
+	return &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Notifications: &clamavv1alpha1.NotificationConfig{
+				Webhook: &clamavv1alpha1.WebhookConfig{URL: url, Triggers: triggers},
+			},
+		},
+	}
+}
+
+func TestNodeScanReconciler_Reconcile_JobFailedNotification(t *testing.T) {
+	receiver := newWebhookRecorder(t)
+
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "test-node", ScanPolicy: "test-policy"},
+		Status:     clamavv1alpha1.NodeScanStatus{Phase: clamavv1alpha1.NodeScanPhaseRunning},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodescan-test-scan", Namespace: "default"},
+		Status: batchv1.JobStatus{
+			Failed: 1,
+			Conditions: []batchv1.JobCondition{{
+				Type:    batchv1.JobFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "BackoffLimitExceeded",
+				Message: "Job has reached the specified backoff limit",
+			}},
+		},
+	}
+	r := newTestNodeScanReconciler(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+		newTestAlertPolicy(receiver.URL, clamavv1alpha1.NotificationTriggerJobFailed),
+		nodeScan, job,
+	)
+	key := types.NamespacedName{Name: "test-scan", Namespace: "default"}
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+	}
+
+	payloads := receiver.received()
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "clamav.scan.failed", payloads[0]["type"])
+	assert.Equal(t, "Scan job failed: Job has reached the specified backoff limit", payloads[0]["message"])
+	assert.Equal(t, "test-node", payloads[0]["details"].(map[string]interface{})["node"])
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), key, &updated))
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseFailed, updated.Status.Phase)
+	require.Len(t, updated.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationEventScanFailed, updated.Status.Notifications[0].Event)
+	assert.Equal(t, clamavv1alpha1.NotificationStateSent, updated.Status.Notifications[0].State)
+}
+
+func TestNodeScanReconciler_Reconcile_NodeNotFoundNotification(t *testing.T) {
+	tests := []struct {
+		name     string
+		triggers []clamavv1alpha1.NotificationTrigger
+		suppress bool
+		want     int
+	}{
+		{name: "job failed trigger", triggers: []clamavv1alpha1.NotificationTrigger{clamavv1alpha1.NotificationTriggerJobFailed}, want: 1},
+		{name: "default triggers", want: 0},
+		{name: "suppressed by a cluster scan digest", triggers: []clamavv1alpha1.NotificationTrigger{clamavv1alpha1.NotificationTriggerJobFailed}, suppress: true, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newWebhookRecorder(t)
+			nodeScan := &clamavv1alpha1.NodeScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+				Spec: clamavv1alpha1.NodeScanSpec{
+					NodeName:              "nonexistent-node",
+					ScanPolicy:            "test-policy",
+					SuppressNotifications: tt.suppress,
+				},
+			}
+			r := newTestNodeScanReconciler(newTestAlertPolicy(receiver.URL, tt.triggers...), nodeScan)
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+			})
+			require.NoError(t, err)
+
+			payloads := receiver.received()
+			require.Len(t, payloads, tt.want)
+			if tt.want > 0 {
+				assert.Equal(t, "Node nonexistent-node does not exist", payloads[0]["message"])
+			}
+		})
+	}
+}
+
+func TestJobFailureMessage(t *testing.T) {
+	assert.Equal(t, "Scan job failed", jobFailureMessage(&batchv1.Job{}))
+	assert.Equal(t, "Scan job failed: DeadlineExceeded", jobFailureMessage(&batchv1.Job{
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+		}},
+	}))
+}
+
+func TestScanScheduleReconciler_Reconcile_ScanOverdueNotification(t *testing.T) {
+	receiver := newWebhookRecorder(t)
+
+	scanSchedule := newTestScanSchedule(5 * time.Hour)
+	deadline := int64(1)
+	scanSchedule.Spec.StartingDeadlineSeconds = &deadline
+	scanSchedule.Spec.ClusterScan = &clamavv1alpha1.ClusterScanSpec{
+		Notifications: &clamavv1alpha1.ClusterScanNotifications{
+			NotificationConfig: clamavv1alpha1.NotificationConfig{
+				Webhook: &clamavv1alpha1.WebhookConfig{URL: receiver.URL, Triggers: []clamavv1alpha1.NotificationTrigger{
+					clamavv1alpha1.NotificationTriggerScanOverdue,
+				}},
+			},
+		},
+	}
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	// The current hour started more than a second ago
+	if time.Since(time.Now().Truncate(time.Hour)) < 2*time.Second {
+		time.Sleep(2 * time.Second)
+	}
+	updated, _ := reconcileScanSchedule(t, r)
+
+	payloads := receiver.received()
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "clamav.schedule.overdue", payloads[0]["type"])
+	assert.Contains(t, payloads[0]["message"], "older than the starting deadline")
+	require.Len(t, updated.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationStateSent, updated.Status.Notifications[0].State)
+
+	// A later occurrence is notified again
+	enqueueOverdue(updated)
+	require.Len(t, updated.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationStatePending, updated.Status.Notifications[0].State)
+	assert.Zero(t, updated.Status.Notifications[0].Attempts)
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// opsgenieMessageLimit is the maximum length of the message of an Opsgenie alert
+const opsgenieMessageLimit = 130
+
+// secretKeyValue reads the value of a Secret key in the namespace
+func secretKeyValue(ctx context.Context, c client.Reader, namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	if ref == nil {
+		return "", fmt.Errorf("secret reference not configured")
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+// postJSON posts a JSON payload and fails on a non-2xx response
+func postJSON(ctx context.Context, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ClamAV-Operator/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// findingLines lists the findings of an alert, capped at digestListLimit
+func findingLines(findings []alertFinding) []string {
+	var lines []string
+	for i, f := range findings {
+		if i >= digestListLimit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(findings)-digestListLimit))
+			break
+		}
+		line := fmt.Sprintf("%s on %s", f.signature, f.node)
+		if len(f.paths) > 0 {
+			line += fmt.Sprintf(" (%d files)", len(f.paths))
+		}
+		if len(f.workloads) > 0 {
+			line += " in " + strings.Join(f.workloads, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// postTeamsCard posts the alert to Teams as an Adaptive Card
+func postTeamsCard(ctx context.Context, c client.Reader, config *clamavv1alpha1.TeamsConfig, namespace string, a alert) error {
+	webhookURL := config.WebhookURL
+	if config.WebhookSecretRef != nil {
+		value, err := secretKeyValue(ctx, c, namespace, config.WebhookSecretRef)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook URL: %w", err)
+		}
+		webhookURL = value
+	}
+	if webhookURL == "" {
+		return fmt.Errorf("webhook URL not configured")
+	}
+
+	color := "Good"
+	switch a.severity {
+	case "critical":
+		color = "Attention"
+	case "warning":
+		color = "Warning"
+	}
+
+	facts := []map[string]interface{}{}
+	for _, f := range a.fields {
+		facts = append(facts, map[string]interface{}{"title": f.title, "value": f.value})
+	}
+
+	body := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   a.title,
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  color,
+		},
+		{
+			"type": "TextBlock",
+			"text": a.message,
+			"wrap": true,
+		},
+		{
+			"type":  "FactSet",
+			"facts": facts,
+		},
+	}
+	if len(a.findings) > 0 {
+		var items []string
+		for _, line := range findingLines(a.findings) {
+			items = append(items, "- "+line)
+		}
+		body = append(body, map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   "Findings",
+			"weight": "Bolder",
+		}, map[string]interface{}{
+			"type": "TextBlock",
+			"text": strings.Join(items, "\r"),
+			"wrap": true,
+		})
+	}
+
+	message := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+
+	return postJSON(ctx, webhookURL, nil, message)
+}
+
+// sendPagerDutyEvents triggers a PagerDuty event per finding of the alert, or
+// a single event if it has no findings. The dedup keys make retries and
+// repeated detections update the open incident instead of creating new ones.
+func sendPagerDutyEvents(ctx context.Context, c client.Reader, config *clamavv1alpha1.PagerDutyConfig, namespace string, a alert) error {
+	routingKey, err := secretKeyValue(ctx, c, namespace, config.RoutingKeySecretRef)
+	if err != nil {
+		return fmt.Errorf("failed to get routing key: %w", err)
+	}
+	eventsURL := config.EventsURL
+	if eventsURL == "" {
+		eventsURL = clamavv1alpha1.DefaultPagerDutyEventsURL
+	}
+
+	event := func(dedupKey, summary, source string, details map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"routing_key":  routingKey,
+			"event_action": "trigger",
+			"dedup_key":    dedupKey,
+			"client":       "ClamAV Operator",
+			"payload": map[string]interface{}{
+				"summary":        summary,
+				"source":         source,
+				"severity":       a.severity,
+				"component":      "clamav",
+				"class":          a.eventType,
+				"timestamp":      time.Now().Format(time.RFC3339),
+				"custom_details": details,
+			},
+		}
+	}
+
+	if len(a.findings) == 0 {
+		details := a.details()
+		details["message"] = a.message
+		return postJSON(ctx, eventsURL, nil, event(a.dedupKey, fmt.Sprintf("%s: %s", a.title, a.message), a.source, details))
+	}
+
+	for _, f := range a.findings {
+		details := a.details()
+		details["node"] = f.node
+		details["signature"] = f.signature
+		if len(f.paths) > 0 {
+			details["files"] = f.paths
+		}
+		if len(f.workloads) > 0 {
+			details["workloads"] = f.workloads
+		}
+		summary := fmt.Sprintf("ClamAV detected %s on node %s", f.signature, f.node)
+		if err := postJSON(ctx, eventsURL, nil, event(f.dedupKey(), summary, f.node, details)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendOpsgenieAlerts creates an Opsgenie alert per finding of the alert, or a
+// single alert if it has no findings. Opsgenie deduplicates alerts by alias.
+func sendOpsgenieAlerts(ctx context.Context, c client.Reader, config *clamavv1alpha1.OpsgenieConfig, namespace string, a alert) error {
+	apiKey, err := secretKeyValue(ctx, c, namespace, config.APIKeySecretRef)
+	if err != nil {
+		return fmt.Errorf("failed to get API key: %w", err)
+	}
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = clamavv1alpha1.DefaultOpsgenieAPIURL
+	}
+	alertsURL := strings.TrimSuffix(apiURL, "/") + "/v2/alerts"
+	headers := map[string]string{"Authorization": "GenieKey " + apiKey}
+
+	priority := "P5"
+	switch a.severity {
+	case "critical":
+		priority = "P1"
+	case "warning":
+		priority = "P3"
+	}
+	tags := append([]string{"clamav"}, config.Tags...)
+
+	details := map[string]string{}
+	for _, f := range a.fields {
+		details[f.key] = f.value
+	}
+
+	opsgenieAlert := func(alias, message, description, entity string) map[string]interface{} {
+		if len(message) > opsgenieMessageLimit {
+			message = message[:opsgenieMessageLimit]
+		}
+		return map[string]interface{}{
+			"message":     message,
+			"alias":       alias,
+			"description": description,
+			"entity":      entity,
+			"source":      "ClamAV Operator",
+			"priority":    priority,
+			"tags":        tags,
+			"details":     details,
+		}
+	}
+
+	if len(a.findings) == 0 {
+		return postJSON(ctx, alertsURL, headers, opsgenieAlert(a.dedupKey, a.title, a.message, a.source))
+	}
+
+	for _, f := range a.findings {
+		description := a.message
+		if len(f.paths) > 0 {
+			description += "\n\nInfected files:\n" + strings.Join(f.paths, "\n")
+		}
+		if len(f.workloads) > 0 {
+			description += "\n\nAffected workloads:\n" + strings.Join(f.workloads, "\n")
+		}
+		message := fmt.Sprintf("ClamAV detected %s on node %s", f.signature, f.node)
+		if err := postJSON(ctx, alertsURL, headers, opsgenieAlert(f.dedupKey(), message, description, f.node)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestChannelSecret(key, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "channel-secret", Namespace: "default"},
+		Data:       map[string][]byte{key: []byte(value)},
+	}
+}
+
+func newTestSecretKeySelector(key string) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "channel-secret"},
+		Key:                  key,
+	}
+}
+
+func newTestInfectedNodeScan() *clamavv1alpha1.NodeScan {
+	return &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesScanned:  100,
+			FilesInfected: 3,
+			InfectedFiles: []clamavv1alpha1.InfectedFile{
+				{Path: "/host/opt/a", Viruses: []string{"Eicar-Test-Signature"}},
+				{Path: "/host/opt/b", Viruses: []string{"Eicar-Test-Signature"}},
+				{Path: "/host/opt/c", Viruses: []string{"Win.Trojan.Agent"}},
+			},
+		},
+	}
+}
+
+// channelRecorder records the requests received by an incident channel
+type channelRecorder struct {
+	*httptest.Server
+	requests []*http.Request
+	payloads []map[string]interface{}
+}
+
+func newChannelRecorder(t *testing.T, status int) *channelRecorder {
+	rec := &channelRecorder{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+		rec.requests = append(rec.requests, req)
+		rec.payloads = append(rec.payloads, payload)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func TestScanCompletedAlert(t *testing.T) {
+	a := scanCompletedAlert(newTestInfectedNodeScan())
+
+	assert.Equal(t, "critical", a.severity)
+	require.Len(t, a.findings, 2)
+	assert.Equal(t, "clamav/worker-1/Eicar-Test-Signature", a.findings[0].dedupKey())
+	assert.Equal(t, []string{"/host/opt/a", "/host/opt/b"}, a.findings[0].paths)
+	assert.Equal(t, "clamav/worker-1/Win.Trojan.Agent", a.findings[1].dedupKey())
+}
+
+func TestPostTeamsCard(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusAccepted)
+	r := newTestNodeScanReconciler(newTestChannelSecret("url", receiver.URL))
+	config := &clamavv1alpha1.TeamsConfig{Enabled: true, WebhookSecretRef: newTestSecretKeySelector("url")}
+
+	err := postTeamsCard(context.Background(), r.Client, config, "default", scanCompletedAlert(newTestInfectedNodeScan()))
+	require.NoError(t, err)
+
+	require.Len(t, receiver.payloads, 1)
+	attachment := receiver.payloads[0]["attachments"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	card := attachment["content"].(map[string]interface{})
+	assert.Equal(t, "AdaptiveCard", card["type"])
+	title := card["body"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "ClamAV Malware Detected", title["text"])
+	assert.Equal(t, "Attention", title["color"])
+}
+
+func TestSendPagerDutyEvents(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusAccepted)
+	r := newTestNodeScanReconciler(newTestChannelSecret("routingKey", "R0UT1NG"))
+	config := &clamavv1alpha1.PagerDutyConfig{
+		Enabled:             true,
+		RoutingKeySecretRef: newTestSecretKeySelector("routingKey"),
+		EventsURL:           receiver.URL,
+	}
+
+	err := sendPagerDutyEvents(context.Background(), r.Client, config, "default", scanCompletedAlert(newTestInfectedNodeScan()))
+	require.NoError(t, err)
+
+	// One event per node and signature
+	require.Len(t, receiver.payloads, 2)
+	event := receiver.payloads[0]
+	assert.Equal(t, "R0UT1NG", event["routing_key"])
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, "clamav/worker-1/Eicar-Test-Signature", event["dedup_key"])
+	payload := event["payload"].(map[string]interface{})
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "worker-1", payload["source"])
+	assert.Equal(t, "clamav/worker-1/Win.Trojan.Agent", receiver.payloads[1]["dedup_key"])
+
+	// Alerts without findings are sent as a single event
+	nodeScan := &clamavv1alpha1.NodeScan{Spec: clamavv1alpha1.NodeScanSpec{NodeName: "worker-2"}}
+	err = sendPagerDutyEvents(context.Background(), r.Client, config, "default",
+		nodeScanAlert(nodeScan, clamavv1alpha1.NotificationEventScanFailed))
+	require.NoError(t, err)
+	require.Len(t, receiver.payloads, 3)
+	assert.Equal(t, "clamav/worker-2/scan-failed", receiver.payloads[2]["dedup_key"])
+	assert.Equal(t, "warning", receiver.payloads[2]["payload"].(map[string]interface{})["severity"])
+}
+
+func TestSendOpsgenieAlerts(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusAccepted)
+	r := newTestNodeScanReconciler(newTestChannelSecret("apiKey", "K3Y"))
+	config := &clamavv1alpha1.OpsgenieConfig{
+		Enabled:         true,
+		APIKeySecretRef: newTestSecretKeySelector("apiKey"),
+		APIURL:          receiver.URL,
+		Tags:            []string{"security"},
+	}
+
+	err := sendOpsgenieAlerts(context.Background(), r.Client, config, "default", scanCompletedAlert(newTestInfectedNodeScan()))
+	require.NoError(t, err)
+
+	require.Len(t, receiver.requests, 2)
+	assert.Equal(t, "/v2/alerts", receiver.requests[0].URL.Path)
+	assert.Equal(t, "GenieKey K3Y", receiver.requests[0].Header.Get("Authorization"))
+	alert := receiver.payloads[0]
+	assert.Equal(t, "clamav/worker-1/Eicar-Test-Signature", alert["alias"])
+	assert.Equal(t, "P1", alert["priority"])
+	assert.Equal(t, "worker-1", alert["entity"])
+	assert.Equal(t, []interface{}{"clamav", "security"}, alert["tags"])
+	assert.Contains(t, alert["description"], "/host/opt/b")
+}
+
+func TestSendOpsgenieAlerts_MissingSecret(t *testing.T) {
+	r := newTestNodeScanReconciler()
+	config := &clamavv1alpha1.OpsgenieConfig{Enabled: true, APIKeySecretRef: newTestSecretKeySelector("apiKey")}
+
+	err := sendOpsgenieAlerts(context.Background(), r.Client, config, "default", scanCompletedAlert(newTestInfectedNodeScan()))
+	assert.Error(t, err)
+}
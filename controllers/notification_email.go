@@ -0,0 +1,384 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// smtpTimeout bounds the connection to the SMTP server and the delivery
+	smtpTimeout = 30 * time.Second
+	// infectedFilesAttachment is the name of the infected files attachment
+	infectedFilesAttachment = "infected-files.json"
+)
+
+// emailMessage is an email notification
+type emailMessage struct {
+	subject string
+	// text is the plain text body
+	text string
+	// html renders the HTML body, nil when the body is templated
+	html *alert
+	// infectedFiles are listed in the attachment
+	infectedFiles []reportedFile
+}
+
+// reportedFile is an infected file listed in email attachments
+type reportedFile struct {
+	Node     string `json:"node"`
+	NodeScan string `json:"nodeScan"`
+	clamavv1alpha1.InfectedFile
+}
+
+// nodeScanReportedFiles returns the infected files of a NodeScan
+func nodeScanReportedFiles(nodeScan *clamavv1alpha1.NodeScan) []reportedFile {
+	var files []reportedFile
+	for _, f := range nodeScan.Status.InfectedFiles {
+		files = append(files, reportedFile{Node: nodeScan.Spec.NodeName, NodeScan: nodeScan.Name, InfectedFile: f})
+	}
+	return files
+}
+
+// alertEmail returns the email of an alert with the given subject and text
+func alertEmail(subject, text string, a alert) emailMessage {
+	return emailMessage{subject: subject, text: text, html: &a, infectedFiles: a.infectedFiles}
+}
+
+// alertEmailTemplate renders the HTML body of an alert
+var alertEmailTemplate = htmltemplate.Must(htmltemplate.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, Helvetica, sans-serif; color: #1f2328;">
+<h2 style="color: {{ .Color }};">{{ .Icon }} {{ .Title }}</h2>
+<table style="border-collapse: collapse;">
+{{- range .Fields }}
+<tr><th style="text-align: left; padding: 4px 16px 4px 0;">{{ .Title }}</th><td style="padding: 4px 0;">{{ .Value }}</td></tr>
+{{- end }}
+</table>
+<p>{{ .Message }}</p>
+{{- if .Findings }}
+<h3>Findings</h3>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 4px 16px 4px 0;">Node</th><th style="text-align: left; padding: 4px 16px 4px 0;">Signature</th><th style="text-align: left; padding: 4px 16px 4px 0;">Files</th><th style="text-align: left; padding: 4px 0;">Workloads</th></tr>
+{{- range .Findings }}
+<tr><td style="padding: 4px 16px 4px 0; vertical-align: top;">{{ .Node }}</td><td style="padding: 4px 16px 4px 0; vertical-align: top;">{{ .Signature }}</td><td style="padding: 4px 16px 4px 0; vertical-align: top;">{{ range .Paths }}<code>{{ . }}</code><br>{{ end }}</td><td style="padding: 4px 0; vertical-align: top;">{{ range .Workloads }}{{ . }}<br>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+<p style="color: #656d76; font-size: 12px;">This is an automated message from ClamAV Operator.</p>
+</body>
+</html>
+`))
+
+// alertEmailHTML returns the HTML email body of an alert
+func alertEmailHTML(a alert) (string, error) {
+	_, icon := alertStyle(a.severity)
+	color := "#9a6700"
+	switch a.severity {
+	case "critical":
+		color = "#d1242f"
+	case "info":
+		color = "#1a7f37"
+	}
+
+	type field struct{ Title, Value string }
+	data := struct {
+		Title, Icon, Color, Message string
+		Fields                      []field
+		Findings                    []templateFinding
+	}{Title: a.title, Icon: icon, Color: color, Message: a.message}
+	for _, f := range a.fields {
+		data.Fields = append(data.Fields, field{Title: f.title, Value: f.value})
+	}
+	for _, f := range a.findings {
+		data.Findings = append(data.Findings, templateFinding{Node: f.node, Signature: f.signature, Paths: f.paths,
+			Workloads: f.workloads})
+	}
+
+	var out bytes.Buffer
+	if err := alertEmailTemplate.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render HTML email: %w", err)
+	}
+	return out.String(), nil
+}
+
+// mimeEntity is a MIME header and its encoded body
+type mimeEntity struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// textEntity returns a quoted-printable text entity of the subtype, e.g. plain
+func textEntity(subtype, text string) (mimeEntity, error) {
+	var body bytes.Buffer
+	w := quotedprintable.NewWriter(&body)
+	if _, err := w.Write([]byte(text)); err != nil {
+		return mimeEntity{}, err
+	}
+	if err := w.Close(); err != nil {
+		return mimeEntity{}, err
+	}
+	return mimeEntity{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType("text/"+subtype, map[string]string{"charset": "UTF-8"})},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: body.Bytes(),
+	}, nil
+}
+
+// attachmentEntity returns a base64 encoded attachment
+func attachmentEntity(name, contentType string, data []byte) mimeEntity {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var body bytes.Buffer
+	// RFC 2045 limits encoded lines to 76 characters
+	for len(encoded) > 76 {
+		body.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	body.WriteString(encoded)
+	return mimeEntity{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": name})},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		},
+		body: body.Bytes(),
+	}
+}
+
+// multipartEntity returns a multipart entity of the subtype, e.g. mixed
+func multipartEntity(subtype string, parts ...mimeEntity) (mimeEntity, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range parts {
+		pw, err := w.CreatePart(part.header)
+		if err != nil {
+			return mimeEntity{}, err
+		}
+		if _, err := pw.Write(part.body); err != nil {
+			return mimeEntity{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return mimeEntity{}, err
+	}
+	return mimeEntity{
+		header: textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()})},
+		},
+		body: body.Bytes(),
+	}, nil
+}
+
+// composeEmail returns the MIME message of an email. The body is sent as
+// plain text, with an HTML alternative if the channel sends HTML, and the
+// infected files are attached if the channel attaches them.
+func composeEmail(config *clamavv1alpha1.EmailConfig, msg emailMessage, now time.Time) ([]byte, error) {
+	entity, err := textEntity("plain", msg.text)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HTML && msg.html != nil {
+		html, err := alertEmailHTML(*msg.html)
+		if err != nil {
+			return nil, err
+		}
+		htmlEntity, err := textEntity("html", html)
+		if err != nil {
+			return nil, err
+		}
+		if entity, err = multipartEntity("alternative", entity, htmlEntity); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.AttachInfectedFiles && len(msg.infectedFiles) > 0 {
+		data, err := json.MarshalIndent(msg.infectedFiles, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if entity, err = multipartEntity("mixed", entity,
+			attachmentEntity(infectedFilesAttachment, "application/json", data)); err != nil {
+			return nil, err
+		}
+	}
+
+	var message bytes.Buffer
+	message.WriteString("From: " + config.From + "\r\n")
+	message.WriteString("To: " + strings.Join(config.Recipients, ", ") + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", msg.subject) + "\r\n")
+	message.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	keys := make([]string, 0, len(entity.header))
+	for key := range entity.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		message.WriteString(key + ": " + entity.header.Get(key) + "\r\n")
+	}
+	message.WriteString("\r\n")
+	message.Write(entity.body)
+	return message.Bytes(), nil
+}
+
+// emailTLSConfig returns the TLS configuration of the SMTP connection with
+// the CA bundle read from a Secret in the namespace
+func emailTLSConfig(ctx context.Context, c client.Reader, config *clamavv1alpha1.EmailConfig, namespace, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if config.CASecretRef == nil {
+		return tlsConfig, nil
+	}
+	caBundle, err := secretKeyValue(ctx, c, namespace, config.CASecretRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SMTP CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		return nil, fmt.Errorf("SMTP CA bundle %s contains no PEM certificate", config.CASecretRef.Name)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// deliverEmail sends an email to the configured recipients
+func deliverEmail(ctx context.Context, c client.Reader, config *clamavv1alpha1.EmailConfig, namespace string, msg emailMessage) error {
+	// Get SMTP credentials from secret
+	var auth smtp.Auth
+	host, _, err := net.SplitHostPort(config.SMTPServer)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP server %s: %w", config.SMTPServer, err)
+	}
+	if config.SMTPAuthSecretRef != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{
+			Name:      config.SMTPAuthSecretRef.Name,
+			Namespace: namespace,
+		}, secret); err != nil {
+			return fmt.Errorf("failed to get SMTP secret: %w", err)
+		}
+		auth = smtp.PlainAuth("", string(secret.Data["username"]), string(secret.Data["password"]), host)
+	}
+
+	tlsConfig, err := emailTLSConfig(ctx, c, config, namespace, host)
+	if err != nil {
+		return err
+	}
+	message, err := composeEmail(config, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	conn, implicitTLS, err := dialSMTP(ctx, config, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", config.SMTPServer, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	defer client.Close()
+
+	if !implicitTLS && config.TLSMode != clamavv1alpha1.EmailTLSModeNone {
+		ok, _ := client.Extension("STARTTLS")
+		if !ok && config.TLSMode == clamavv1alpha1.EmailTLSModeStartTLS {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", config.SMTPServer)
+		}
+		if ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range config.Recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to get data writer: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close writer: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dialSMTP connects to the SMTP server and reports whether the connection
+// uses implicit TLS. Without a TLS mode implicit TLS is tried first.
+func dialSMTP(ctx context.Context, config *clamavv1alpha1.EmailConfig, tlsConfig *tls.Config) (net.Conn, bool, error) {
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	switch config.TLSMode {
+	case clamavv1alpha1.EmailTLSModeTLS:
+		conn, err := (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", config.SMTPServer)
+		return conn, true, err
+	case clamavv1alpha1.EmailTLSModeNone, clamavv1alpha1.EmailTLSModeStartTLS:
+		conn, err := dialer.DialContext(ctx, "tcp", config.SMTPServer)
+		return conn, false, err
+	}
+
+	if conn, err := (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", config.SMTPServer); err == nil {
+		return conn, true, nil
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", config.SMTPServer)
+	return conn, false, err
+}
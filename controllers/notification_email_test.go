@@ -0,0 +1,299 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// mimePart is a decoded part of a multipart entity
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// readMIMEParts returns the decoded parts of a multipart entity
+func readMIMEParts(t *testing.T, contentType string, body io.Reader) []mimePart {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(mediaType, "multipart/"), mediaType)
+
+	var parts []mimePart
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+		// The reader decodes quoted-printable parts only
+		var data io.Reader = part
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			data = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		decoded, err := io.ReadAll(data)
+		require.NoError(t, err)
+		parts = append(parts, mimePart{header: part.Header, body: decoded})
+	}
+}
+
+func TestComposeEmail_HTMLAndAttachment(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "worker-1"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesScanned:  10,
+			FilesInfected: 1,
+			InfectedFiles: []clamavv1alpha1.InfectedFile{
+				{Path: "/host/tmp/<script>.sh", Viruses: []string{"Eicar-Signature"}, Size: 68},
+			},
+		},
+	}
+	config := &clamavv1alpha1.EmailConfig{
+		From:                "clamav@example.com",
+		Recipients:          []string{"secops@example.com", "audit@example.com"},
+		HTML:                true,
+		AttachInfectedFiles: true,
+	}
+	a := scanCompletedAlert(nodeScan)
+
+	raw, err := composeEmail(config, alertEmail("🚨 "+a.title, alertEmailBody(a), a), time.Now())
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "🚨 ClamAV Malware Detected", subject)
+	assert.Equal(t, "secops@example.com, audit@example.com", msg.Header.Get("To"))
+
+	// multipart/mixed with the body alternatives and the attachment
+	mixed := readMIMEParts(t, msg.Header.Get("Content-Type"), msg.Body)
+	require.Len(t, mixed, 2)
+	alternatives := readMIMEParts(t, mixed[0].header.Get("Content-Type"), bytes.NewReader(mixed[0].body))
+	require.Len(t, alternatives, 2)
+	assert.Contains(t, alternatives[0].header.Get("Content-Type"), "text/plain")
+	assert.Contains(t, string(alternatives[0].body), "CLAMAV MALWARE DETECTED")
+	assert.Contains(t, alternatives[1].header.Get("Content-Type"), "text/html")
+	html := string(alternatives[1].body)
+	assert.Contains(t, html, "Eicar-Signature")
+	assert.Contains(t, html, "/host/tmp/&lt;script&gt;.sh")
+
+	assert.Equal(t, "attachment", strings.SplitN(mixed[1].header.Get("Content-Disposition"), ";", 2)[0])
+	assert.Contains(t, mixed[1].header.Get("Content-Disposition"), infectedFilesAttachment)
+	var files []map[string]interface{}
+	require.NoError(t, json.Unmarshal(mixed[1].body, &files))
+	require.Len(t, files, 1)
+	assert.Equal(t, "worker-1", files[0]["node"])
+	assert.Equal(t, "scan-worker-1", files[0]["nodeScan"])
+	assert.Equal(t, "/host/tmp/<script>.sh", files[0]["path"])
+	assert.Equal(t, []interface{}{"Eicar-Signature"}, files[0]["viruses"])
+}
+
+func TestComposeEmail_PlainText(t *testing.T) {
+	config := &clamavv1alpha1.EmailConfig{From: "clamav@example.com", Recipients: []string{"secops@example.com"}}
+	a := alert{title: "ClamAV Scan Completed", severity: "info", message: "no malware detected",
+		infectedFiles: []reportedFile{{Node: "worker-1"}}}
+
+	// Neither HTML nor attachments without the options
+	raw, err := composeEmail(config, alertEmail(a.title, "été\n", a), time.Now())
+	require.NoError(t, err)
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+	assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+	assert.Contains(t, string(raw), "=C3=A9t=C3=A9")
+}
+
+// fakeSMTPServer accepts emails on a local port. It offers STARTTLS if
+// startTLS is set and speaks TLS from the start if implicitTLS is set.
+type fakeSMTPServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	startTLS    bool
+	implicitTLS bool
+	received    chan smtpDelivery
+}
+
+// smtpDelivery is an email received by the fake SMTP server
+type smtpDelivery struct {
+	data   string
+	tls    bool
+	authed bool
+}
+
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config, startTLS, implicitTLS bool) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{listener: listener, tlsConfig: tlsConfig, startTLS: startTLS, implicitTLS: implicitTLS,
+		received: make(chan smtpDelivery, 1)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	if s.implicitTLS {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	delivery := smtpDelivery{tls: s.implicitTLS}
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		// Clients probing for implicit TLS send a handshake that is not a command
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			_ = tp.PrintfLine("500 unrecognized command")
+			continue
+		}
+		switch verb := strings.ToUpper(fields[0]); verb {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-fake")
+			if s.startTLS && !delivery.tls {
+				_ = tp.PrintfLine("250-STARTTLS")
+			}
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+			conn = tls.Server(conn, s.tlsConfig)
+			tp = textproto.NewConn(conn)
+			delivery.tls = true
+		case "AUTH":
+			delivery.authed = true
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			delivery.data = string(data)
+			s.received <- delivery
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 unsupported")
+		}
+	}
+}
+
+func TestDeliverEmail_TLSModes(t *testing.T) {
+	// Borrow the localhost certificate of httptest
+	tlsServer := httptest.NewTLSServer(nil)
+	serverTLS := &tls.Config{Certificates: tlsServer.TLS.Certificates}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	tlsServer.Close()
+
+	r := newTestNodeScanReconciler(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "smtp-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": caPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "smtp-auth", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("clamav"), "password": []byte("s3cr3t")},
+		},
+	)
+	msg := emailMessage{subject: "ClamAV Scan Completed", text: "no malware detected"}
+
+	tests := []struct {
+		name        string
+		mode        clamavv1alpha1.EmailTLSMode
+		startTLS    bool
+		implicitTLS bool
+		expectTLS   bool
+		expectError bool
+	}{
+		{name: "starttls", mode: clamavv1alpha1.EmailTLSModeStartTLS, startTLS: true, expectTLS: true},
+		{name: "starttls not offered", mode: clamavv1alpha1.EmailTLSModeStartTLS, expectError: true},
+		{name: "implicit tls", mode: clamavv1alpha1.EmailTLSModeTLS, implicitTLS: true, expectTLS: true},
+		{name: "none", mode: clamavv1alpha1.EmailTLSModeNone, startTLS: true},
+		{name: "unset prefers implicit tls", implicitTLS: true, expectTLS: true},
+		{name: "unset falls back to starttls", startTLS: true, expectTLS: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, serverTLS, tt.startTLS, tt.implicitTLS)
+			config := &clamavv1alpha1.EmailConfig{
+				SMTPServer: server.listener.Addr().String(),
+				TLSMode:    tt.mode,
+				From:       "clamav@example.com",
+				Recipients: []string{"secops@example.com"},
+				CASecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "smtp-ca"},
+					Key:                  "ca.crt",
+				},
+			}
+			if tt.mode != clamavv1alpha1.EmailTLSModeNone {
+				config.SMTPAuthSecretRef = &corev1.SecretReference{Name: "smtp-auth"}
+			}
+
+			err := deliverEmail(context.Background(), r.Client, config, "default", msg)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			delivery := <-server.received
+			assert.Equal(t, tt.expectTLS, delivery.tls)
+			assert.Equal(t, tt.mode != clamavv1alpha1.EmailTLSModeNone, delivery.authed)
+			assert.Contains(t, delivery.data, "Subject: ClamAV Scan Completed")
+			assert.Contains(t, delivery.data, "no malware detected")
+		})
+	}
+}
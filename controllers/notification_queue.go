@@ -0,0 +1,549 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// replayNotificationsAnnotation requests the redelivery of the dead-lettered
+// notifications of a NodeScan or a ClusterScan
+const replayNotificationsAnnotation = "clamav.io/replay-notifications"
+
+// notificationRetryPolicy is a NotificationRetryPolicy with defaults applied
+type notificationRetryPolicy struct {
+	maxAttempts    int32
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// retryPolicyFor returns the retry policy of the notification settings
+func retryPolicyFor(notifications *clamavv1alpha1.NotificationConfig) notificationRetryPolicy {
+	policy := notificationRetryPolicy{
+		maxAttempts:    clamavv1alpha1.DefaultNotificationMaxAttempts,
+		initialBackoff: clamavv1alpha1.DefaultNotificationInitialBackoffSeconds * time.Second,
+		maxBackoff:     clamavv1alpha1.DefaultNotificationMaxBackoffSeconds * time.Second,
+	}
+	if notifications == nil || notifications.Retry == nil {
+		return policy
+	}
+	if retry := notifications.Retry; retry.MaxAttempts > 0 {
+		policy.maxAttempts = retry.MaxAttempts
+	}
+	if retry := notifications.Retry; retry.InitialBackoffSeconds > 0 {
+		policy.initialBackoff = time.Duration(retry.InitialBackoffSeconds) * time.Second
+	}
+	if retry := notifications.Retry; retry.MaxBackoffSeconds > 0 {
+		policy.maxBackoff = time.Duration(retry.MaxBackoffSeconds) * time.Second
+	}
+	return policy
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts
+func (p notificationRetryPolicy) backoff(attempts int32) time.Duration {
+	delay := p.initialBackoff
+	for i := int32(1); i < attempts && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+	return delay
+}
+
+// enabledNotificationChannels returns the channels configured in the notification settings
+func enabledNotificationChannels(notifications *clamavv1alpha1.NotificationConfig) []string {
+	if notifications == nil {
+		return nil
+	}
+	var channels []string
+	if notifications.Slack != nil && notifications.Slack.Enabled {
+		channels = append(channels, clamavv1alpha1.NotificationChannelSlack)
+	}
+	if notifications.Email != nil && notifications.Email.Enabled {
+		channels = append(channels, clamavv1alpha1.NotificationChannelEmail)
+	}
+	if notifications.Webhook != nil {
+		channels = append(channels, clamavv1alpha1.NotificationChannelWebhook)
+	}
+	if notifications.Teams != nil && notifications.Teams.Enabled {
+		channels = append(channels, clamavv1alpha1.NotificationChannelTeams)
+	}
+	if notifications.PagerDuty != nil && notifications.PagerDuty.Enabled {
+		channels = append(channels, clamavv1alpha1.NotificationChannelPagerDuty)
+	}
+	if notifications.Opsgenie != nil && notifications.Opsgenie.Enabled {
+		channels = append(channels, clamavv1alpha1.NotificationChannelOpsgenie)
+	}
+	return channels
+}
+
+// eventTriggers maps notification events to the triggers that enable them.
+// Events without a trigger are sent on every enabled channel.
+var eventTriggers = map[string][]clamavv1alpha1.NotificationTrigger{
+	clamavv1alpha1.NotificationEventScanStarted: {clamavv1alpha1.NotificationTriggerScanStarted},
+	clamavv1alpha1.NotificationEventScanCompleted: {
+		clamavv1alpha1.NotificationTriggerInfection, clamavv1alpha1.NotificationTriggerScanCompleted,
+	},
+	clamavv1alpha1.NotificationEventScanFailed:  {clamavv1alpha1.NotificationTriggerJobFailed},
+	clamavv1alpha1.NotificationEventParseFailed: {clamavv1alpha1.NotificationTriggerParseFailed},
+	clamavv1alpha1.NotificationEventScanOverdue: {clamavv1alpha1.NotificationTriggerScanOverdue},
+}
+
+// channelTriggers returns the triggers of an enabled channel
+func channelTriggers(notifications *clamavv1alpha1.NotificationConfig, channel string) []clamavv1alpha1.NotificationTrigger {
+	var triggers []clamavv1alpha1.NotificationTrigger
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		triggers = notifications.Slack.Triggers
+	case clamavv1alpha1.NotificationChannelEmail:
+		triggers = notifications.Email.Triggers
+	case clamavv1alpha1.NotificationChannelWebhook:
+		triggers = notifications.Webhook.Triggers
+	case clamavv1alpha1.NotificationChannelTeams:
+		triggers = notifications.Teams.Triggers
+	case clamavv1alpha1.NotificationChannelPagerDuty:
+		triggers = notifications.PagerDuty.Triggers
+	case clamavv1alpha1.NotificationChannelOpsgenie:
+		triggers = notifications.Opsgenie.Triggers
+	}
+	if len(triggers) == 0 {
+		return clamavv1alpha1.DefaultNotificationTriggers
+	}
+	return triggers
+}
+
+// notifiesOn reports whether an enabled channel is notified of the trigger
+func notifiesOn(notifications *clamavv1alpha1.NotificationConfig, channel string, trigger clamavv1alpha1.NotificationTrigger) bool {
+	for _, t := range channelTriggers(notifications, channel) {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueNotification records a pending notification of the event for every
+// enabled channel that is notified of one of the triggers, which default to
+// the triggers of the event
+func enqueueNotification(statuses *[]clamavv1alpha1.NotificationStatus, notifications *clamavv1alpha1.NotificationConfig,
+	event string, triggers ...clamavv1alpha1.NotificationTrigger) {
+	if len(triggers) == 0 {
+		triggers = eventTriggers[event]
+	}
+	for _, channel := range enabledNotificationChannels(notifications) {
+		if !notifiesOnAny(notifications, channel, triggers) {
+			continue
+		}
+		if findNotification(*statuses, channel, event) != nil {
+			continue
+		}
+		*statuses = append(*statuses, clamavv1alpha1.NotificationStatus{
+			Channel: channel,
+			Event:   event,
+			State:   clamavv1alpha1.NotificationStatePending,
+		})
+	}
+}
+
+// notifiesOnAny reports whether an enabled channel is notified of one of the
+// triggers. Every channel is notified if there are no triggers.
+func notifiesOnAny(notifications *clamavv1alpha1.NotificationConfig, channel string, triggers []clamavv1alpha1.NotificationTrigger) bool {
+	if len(triggers) == 0 {
+		return true
+	}
+	for _, trigger := range triggers {
+		if notifiesOn(notifications, channel, trigger) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueNotifications records a pending notification of the event for every
+// enabled channel. Delivery happens in reconcileNotifications.
+func enqueueNotifications(nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy,
+	event string, triggers ...clamavv1alpha1.NotificationTrigger) {
+	if scanPolicy == nil {
+		return
+	}
+	enqueueNotification(&nodeScan.Status.Notifications, scanPolicy.Spec.Notifications, event, triggers...)
+}
+
+// findNotification returns the status of the notification of the event on the channel
+func findNotification(notifications []clamavv1alpha1.NotificationStatus, channel, event string) *clamavv1alpha1.NotificationStatus {
+	for i := range notifications {
+		n := &notifications[i]
+		if n.Channel == channel && n.Event == event {
+			return n
+		}
+	}
+	return nil
+}
+
+// notificationQueue delivers the notifications recorded in the status of a
+// NodeScan or a ClusterScan. Failed deliveries are retried with exponential
+// backoff and dead-lettered once the retry policy is exhausted.
+type notificationQueue struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	retry    notificationRetryPolicy
+	// notifications are the settings of the owner, they set the rate limits
+	notifications *clamavv1alpha1.NotificationConfig
+
+	// owner receives the events and owns the dead-letter ConfigMaps
+	owner client.Object
+	// kind names the owner in dead-letter ConfigMaps, e.g. "nodeScan"
+	kind string
+	// labels are set on the dead-letter ConfigMaps
+	labels map[string]string
+	// deliver sends the notification of the event on the channel
+	deliver func(ctx context.Context, channel, event string) error
+}
+
+// process attempts the pending notifications whose retry time has come.
+// Notifications over the rate limit of their channel are postponed. It
+// reports whether any notification changed and when the next attempt is due.
+func (q *notificationQueue) process(ctx context.Context, notifications []clamavv1alpha1.NotificationStatus) (bool, time.Time, error) {
+	log := log.FromContext(ctx)
+
+	now := time.Now()
+	var nextAttempt time.Time
+	changed := false
+	for i := range notifications {
+		n := &notifications[i]
+		if n.State != clamavv1alpha1.NotificationStatePending {
+			continue
+		}
+		if n.NextAttemptTime != nil && n.NextAttemptTime.Time.After(now) {
+			if nextAttempt.IsZero() || n.NextAttemptTime.Time.Before(nextAttempt) {
+				nextAttempt = n.NextAttemptTime.Time
+			}
+			continue
+		}
+
+		if limit := rateLimitFor(q.notifications, n.Channel); limit != nil {
+			allowed, retryAt, err := reserveDelivery(ctx, q.client, q.owner.GetNamespace(), limit, now)
+			if err != nil {
+				return changed, nextAttempt, err
+			}
+			if !allowed {
+				next := metav1.NewTime(retryAt)
+				n.NextAttemptTime = &next
+				changed = true
+				if nextAttempt.IsZero() || retryAt.Before(nextAttempt) {
+					nextAttempt = retryAt
+				}
+				log.Info("notification rate limited", "channel", n.Channel, "event", n.Event, "retryAt", retryAt)
+				recordNotificationDelivery(q.owner.GetNamespace(), n.Channel, "rate-limited")
+				continue
+			}
+		}
+
+		err := q.deliver(ctx, n.Channel, n.Event)
+		attemptTime := metav1.NewTime(now)
+		n.Attempts++
+		n.LastAttemptTime = &attemptTime
+		n.NextAttemptTime = nil
+		changed = true
+
+		if err == nil {
+			n.State = clamavv1alpha1.NotificationStateSent
+			n.SentTime = &attemptTime
+			n.LastError = ""
+			recordNotificationDelivery(q.owner.GetNamespace(), n.Channel, "sent")
+			continue
+		}
+
+		log.Error(err, "failed to deliver notification", "channel", n.Channel, "event", n.Event, "attempt", n.Attempts)
+		n.LastError = err.Error()
+
+		if n.Attempts >= q.retry.maxAttempts {
+			n.State = clamavv1alpha1.NotificationStateFailed
+			name, dlErr := q.deadLetter(ctx, n)
+			if dlErr != nil {
+				return changed, nextAttempt, dlErr
+			}
+			n.DeadLetter = name
+			recordNotificationDelivery(q.owner.GetNamespace(), n.Channel, "dead-lettered")
+			q.recorder.Event(q.owner, corev1.EventTypeWarning, "NotificationDeadLettered",
+				fmt.Sprintf("%s notification on %s failed after %d attempts, recorded in ConfigMap %s: %v",
+					n.Event, n.Channel, n.Attempts, name, err))
+			continue
+		}
+
+		next := metav1.NewTime(now.Add(q.retry.backoff(n.Attempts)))
+		n.NextAttemptTime = &next
+		if nextAttempt.IsZero() || next.Time.Before(nextAttempt) {
+			nextAttempt = next.Time
+		}
+		recordNotificationDelivery(q.owner.GetNamespace(), n.Channel, "failed")
+		q.recorder.Event(q.owner, corev1.EventTypeWarning, "NotificationFailed",
+			fmt.Sprintf("Failed to send %s notification on %s (attempt %d/%d), retrying at %s: %v",
+				n.Event, n.Channel, n.Attempts, q.retry.maxAttempts, next.Format(time.RFC3339), err))
+	}
+
+	return changed, nextAttempt, nil
+}
+
+// checkNotificationChannel returns an error if the channel is not configured
+// anymore, so that queued notifications fail instead of being dropped silently
+func checkNotificationChannel(notifications *clamavv1alpha1.NotificationConfig, channel string) error {
+	if notifications == nil {
+		return fmt.Errorf("notifications are no longer configured")
+	}
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		if notifications.Slack == nil || !notifications.Slack.Enabled {
+			return fmt.Errorf("slack notifications are no longer configured")
+		}
+	case clamavv1alpha1.NotificationChannelEmail:
+		if notifications.Email == nil || !notifications.Email.Enabled {
+			return fmt.Errorf("email notifications are no longer configured")
+		}
+	case clamavv1alpha1.NotificationChannelWebhook:
+		if notifications.Webhook == nil {
+			return fmt.Errorf("webhook notifications are no longer configured")
+		}
+	case clamavv1alpha1.NotificationChannelTeams:
+		if notifications.Teams == nil || !notifications.Teams.Enabled {
+			return fmt.Errorf("teams notifications are no longer configured")
+		}
+	case clamavv1alpha1.NotificationChannelPagerDuty:
+		if notifications.PagerDuty == nil || !notifications.PagerDuty.Enabled {
+			return fmt.Errorf("pagerduty notifications are no longer configured")
+		}
+	case clamavv1alpha1.NotificationChannelOpsgenie:
+		if notifications.Opsgenie == nil || !notifications.Opsgenie.Enabled {
+			return fmt.Errorf("opsgenie notifications are no longer configured")
+		}
+	default:
+		return fmt.Errorf("unknown notification channel %q", channel)
+	}
+	return nil
+}
+
+// deadLetterName returns the name of the dead-letter ConfigMap of a notification
+func deadLetterName(kind, name string, n *clamavv1alpha1.NotificationStatus) string {
+	return fmt.Sprintf("%s-%s-dead-letter-%s-%s", strings.ToLower(kind), name, n.Channel, strings.ToLower(n.Event))
+}
+
+// deadLetter records a notification that could not be delivered in a
+// ConfigMap owned by the queue owner and returns its name
+func (q *notificationQueue) deadLetter(ctx context.Context, n *clamavv1alpha1.NotificationStatus) (string, error) {
+	labels := map[string]string{
+		"app.kubernetes.io/name":      "clamav",
+		"app.kubernetes.io/component": "notification-dead-letter",
+	}
+	for k, v := range q.labels {
+		labels[k] = v
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deadLetterName(q.kind, q.owner.GetName(), n),
+			Namespace: q.owner.GetNamespace(),
+			Labels:    labels,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, q.client, configMap, func() error {
+		configMap.Data = map[string]string{
+			q.kind:            q.owner.GetName(),
+			"channel":         n.Channel,
+			"event":           n.Event,
+			"attempts":        strconv.Itoa(int(n.Attempts)),
+			"lastError":       n.LastError,
+			"lastAttemptTime": n.LastAttemptTime.Format(time.RFC3339),
+		}
+		return controllerutil.SetControllerReference(q.owner, configMap, q.scheme)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to record dead-lettered notification: %w", err)
+	}
+	return configMap.Name, nil
+}
+
+// replay requeues the dead-lettered notifications if the owner carries the
+// replay annotation, and removes the annotation. notifications points into the
+// owner status, which is refreshed when the annotation is removed.
+func (q *notificationQueue) replay(ctx context.Context, notifications *[]clamavv1alpha1.NotificationStatus) error {
+	annotations := q.owner.GetAnnotations()
+	if _, ok := annotations[replayNotificationsAnnotation]; !ok {
+		return nil
+	}
+	delete(annotations, replayNotificationsAnnotation)
+	q.owner.SetAnnotations(annotations)
+	if err := q.client.Update(ctx, q.owner); err != nil {
+		return err
+	}
+
+	replayed := 0
+	for i := range *notifications {
+		n := &(*notifications)[i]
+		if n.State != clamavv1alpha1.NotificationStateFailed {
+			continue
+		}
+		if n.DeadLetter != "" {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: n.DeadLetter, Namespace: q.owner.GetNamespace()}}
+			if err := q.client.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		n.State = clamavv1alpha1.NotificationStatePending
+		n.Attempts = 0
+		n.NextAttemptTime = nil
+		n.DeadLetter = ""
+		replayed++
+	}
+
+	if replayed > 0 {
+		q.recorder.Event(q.owner, corev1.EventTypeNormal, "NotificationsReplayed",
+			fmt.Sprintf("Replaying %d dead-lettered notifications", replayed))
+	}
+	return nil
+}
+
+// reconcileNotifications delivers the pending notifications of a NodeScan
+// whose retry time has come
+func (r *NodeScanReconciler) reconcileNotifications(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) (ctrl.Result, error) {
+	var notifications *clamavv1alpha1.NotificationConfig
+	if scanPolicy != nil {
+		notifications = scanPolicy.Spec.Notifications
+	}
+	queue := &notificationQueue{
+		client:        r.Client,
+		scheme:        r.Scheme,
+		recorder:      r.Recorder,
+		retry:         retryPolicyFor(notifications),
+		notifications: notifications,
+		owner:         nodeScan,
+		kind:          "nodeScan",
+		labels: map[string]string{
+			"clamav.io/nodescan": nodeScan.Name,
+			"clamav.io/node":     nodeScan.Spec.NodeName,
+		},
+		deliver: func(ctx context.Context, channel, event string) error {
+			return r.deliverNotification(ctx, nodeScan, scanPolicy, channel, event)
+		},
+	}
+
+	if err := queue.replay(ctx, &nodeScan.Status.Notifications); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(nodeScan.Status.Notifications) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	changed, nextAttempt, err := queue.process(ctx, nodeScan.Status.Notifications)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err := r.Status().Update(ctx, nodeScan); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if nextAttempt.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Until(nextAttempt)}, nil
+}
+
+// deliverNotification sends the notification of the event on the channel
+func (r *NodeScanReconciler) deliverNotification(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy, channel, event string) error {
+	if scanPolicy == nil {
+		return fmt.Errorf("notifications are no longer configured")
+	}
+	if err := checkNotificationChannel(scanPolicy.Spec.Notifications, channel); err != nil {
+		return err
+	}
+
+	if usesCloudEvents(scanPolicy.Spec.Notifications, channel) {
+		events, err := nodeScanCloudEvents(nodeScan, event)
+		if err != nil {
+			return err
+		}
+		return sendCloudEvents(ctx, r.Client, scanPolicy.Spec.Notifications.Webhook, scanPolicy.Namespace, events)
+	}
+
+	if hasNotificationTemplate(scanPolicy.Spec.Notifications, channel) {
+		a, err := nodeScanEventAlert(nodeScan, event)
+		if err != nil {
+			return err
+		}
+		data := newNotificationTemplateData(event, a, r.ClusterName)
+		data.NodeScan = nodeScan
+		data.ScanPolicy = scanPolicy
+		return sendTemplated(ctx, r.Client, scanPolicy.Spec.Notifications, scanPolicy.Namespace, channel, a, data)
+	}
+
+	switch event {
+	case clamavv1alpha1.NotificationEventScanStarted:
+		return sendAlert(ctx, r.Client, scanPolicy.Spec.Notifications, scanPolicy.Namespace, channel,
+			scanStartedAlert(nodeScan))
+	case clamavv1alpha1.NotificationEventScanCompleted:
+		switch channel {
+		case clamavv1alpha1.NotificationChannelSlack:
+			return r.sendSlackNotification(ctx, nodeScan, scanPolicy)
+		case clamavv1alpha1.NotificationChannelEmail:
+			return r.sendEmailNotification(ctx, nodeScan, scanPolicy)
+		case clamavv1alpha1.NotificationChannelWebhook:
+			return r.sendWebhookNotification(ctx, nodeScan, scanPolicy)
+		default:
+			return sendAlert(ctx, r.Client, scanPolicy.Spec.Notifications, scanPolicy.Namespace, channel,
+				scanCompletedAlert(nodeScan))
+		}
+	case clamavv1alpha1.NotificationEventScanFailed, clamavv1alpha1.NotificationEventParseFailed:
+		return sendAlert(ctx, r.Client, scanPolicy.Spec.Notifications, scanPolicy.Namespace, channel,
+			nodeScanAlert(nodeScan, event))
+	case clamavv1alpha1.NotificationEventQuarantineCompleted:
+		if nodeScan.Status.Quarantine == nil {
+			return fmt.Errorf("no quarantine to report")
+		}
+		switch channel {
+		case clamavv1alpha1.NotificationChannelSlack:
+			return r.sendSlackQuarantineNotification(ctx, nodeScan, scanPolicy)
+		case clamavv1alpha1.NotificationChannelEmail:
+			return r.sendEmailQuarantineNotification(ctx, nodeScan, scanPolicy)
+		case clamavv1alpha1.NotificationChannelWebhook:
+			return r.sendWebhookQuarantineNotification(ctx, nodeScan, scanPolicy)
+		default:
+			return sendAlert(ctx, r.Client, scanPolicy.Spec.Notifications, scanPolicy.Namespace, channel,
+				quarantineAlert(nodeScan))
+		}
+	}
+	return fmt.Errorf("unknown notification event %q", event)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func TestNotificationRetryPolicy_Backoff(t *testing.T) {
+	policy := retryPolicyFor(&clamavv1alpha1.NotificationConfig{
+		Retry: &clamavv1alpha1.NotificationRetryPolicy{InitialBackoffSeconds: 10, MaxBackoffSeconds: 60},
+	})
+
+	assert.Equal(t, int32(clamavv1alpha1.DefaultNotificationMaxAttempts), policy.maxAttempts)
+	assert.Equal(t, 10*time.Second, policy.backoff(1))
+	assert.Equal(t, 20*time.Second, policy.backoff(2))
+	assert.Equal(t, 40*time.Second, policy.backoff(3))
+	assert.Equal(t, 60*time.Second, policy.backoff(4))
+	assert.Equal(t, 60*time.Second, policy.backoff(10))
+}
+
+func TestEnqueueNotifications(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{}
+	scanPolicy := &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{
+		Notifications: &clamavv1alpha1.NotificationConfig{
+			Slack:   &clamavv1alpha1.SlackConfig{Enabled: false},
+			Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com"},
+		},
+	}}
+
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted)
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted)
+	enqueueNotifications(nodeScan, nil, clamavv1alpha1.NotificationEventQuarantineCompleted)
+
+	require.Len(t, nodeScan.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationChannelWebhook, nodeScan.Status.Notifications[0].Channel)
+	assert.Equal(t, clamavv1alpha1.NotificationStatePending, nodeScan.Status.Notifications[0].State)
+}
+
+func TestReconcileNotifications_RetryDeadLetterAndReplay(t *testing.T) {
+	var healthy atomic.Bool
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	scanPolicy := &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Notifications: &clamavv1alpha1.NotificationConfig{
+				Webhook: &clamavv1alpha1.WebhookConfig{URL: server.URL},
+				Retry:   &clamavv1alpha1.NotificationRetryPolicy{MaxAttempts: 2, InitialBackoffSeconds: 60},
+			},
+		},
+	}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-scan", Namespace: "default"},
+		Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "test-node"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesInfected: 1,
+		},
+	}
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted)
+	r := newTestNodeScanReconciler(nodeScan)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "test-scan", Namespace: "default"}
+
+	// First attempt fails and is retried after the backoff
+	result, err := r.reconcileNotifications(ctx, nodeScan, scanPolicy)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, result.RequeueAfter, float64(time.Second))
+	n := nodeScan.Status.Notifications[0]
+	assert.Equal(t, clamavv1alpha1.NotificationStatePending, n.State)
+	assert.Equal(t, int32(1), n.Attempts)
+	require.NotNil(t, n.NextAttemptTime)
+	assert.Contains(t, n.LastError, "503")
+
+	// Not due yet
+	_, err = r.reconcileNotifications(ctx, nodeScan, scanPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// The last attempt fails and the notification is dead-lettered
+	past := metav1.NewTime(time.Now().Add(-time.Second))
+	nodeScan.Status.Notifications[0].NextAttemptTime = &past
+	result, err = r.reconcileNotifications(ctx, nodeScan, scanPolicy)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	n = nodeScan.Status.Notifications[0]
+	assert.Equal(t, clamavv1alpha1.NotificationStateFailed, n.State)
+	require.NotEmpty(t, n.DeadLetter)
+
+	var deadLetter corev1.ConfigMap
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: n.DeadLetter, Namespace: "default"}, &deadLetter))
+	assert.Equal(t, "webhook", deadLetter.Data["channel"])
+	assert.Equal(t, "2", deadLetter.Data["attempts"])
+
+	// Replaying redelivers the notification once the receiver is back
+	healthy.Store(true)
+	nodeScan.Annotations = map[string]string{replayNotificationsAnnotation: "true"}
+	require.NoError(t, r.Update(ctx, nodeScan))
+	_, err = r.reconcileNotifications(ctx, nodeScan, scanPolicy)
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(ctx, key, &updated))
+	assert.NotContains(t, updated.Annotations, replayNotificationsAnnotation)
+	require.Len(t, updated.Status.Notifications, 1)
+	assert.Equal(t, clamavv1alpha1.NotificationStateSent, updated.Status.Notifications[0].State)
+	assert.NotNil(t, updated.Status.Notifications[0].SentTime)
+	err = r.Get(ctx, types.NamespacedName{Name: n.DeadLetter, Namespace: "default"}, &deadLetter)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestEnqueueNotifications_Triggers(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{}
+	scanPolicy := &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{
+		Notifications: &clamavv1alpha1.NotificationConfig{
+			// Without triggers a channel is only notified of infections
+			Slack: &clamavv1alpha1.SlackConfig{Enabled: true},
+			Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com", Triggers: []clamavv1alpha1.NotificationTrigger{
+				clamavv1alpha1.NotificationTriggerJobFailed, clamavv1alpha1.NotificationTriggerParseFailed,
+			}},
+		},
+	}}
+
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted)
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanFailed)
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventParseFailed)
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventQuarantineCompleted)
+
+	var queued []string
+	for _, n := range nodeScan.Status.Notifications {
+		queued = append(queued, n.Channel+"/"+n.Event)
+	}
+	assert.Equal(t, []string{
+		"slack/ScanCompleted",
+		"webhook/ScanFailed",
+		"webhook/ParseFailed",
+		"slack/QuarantineCompleted",
+		"webhook/QuarantineCompleted",
+	}, queued)
+}
+
+func TestEnqueueNotifications_LifecycleTriggers(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{}
+	scanPolicy := &clamavv1alpha1.ScanPolicy{Spec: clamavv1alpha1.ScanPolicySpec{
+		Notifications: &clamavv1alpha1.NotificationConfig{
+			Slack: &clamavv1alpha1.SlackConfig{Enabled: true},
+			Webhook: &clamavv1alpha1.WebhookConfig{URL: "https://example.com", Triggers: []clamavv1alpha1.NotificationTrigger{
+				clamavv1alpha1.NotificationTriggerScanStarted, clamavv1alpha1.NotificationTriggerScanCompleted,
+			}},
+		},
+	}}
+
+	// Clean scans are only notified on channels with the ScanCompleted trigger
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanStarted)
+	enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted,
+		clamavv1alpha1.NotificationTriggerScanCompleted)
+
+	var queued []string
+	for _, n := range nodeScan.Status.Notifications {
+		queued = append(queued, n.Channel+"/"+n.Event)
+	}
+	assert.Equal(t, []string{"webhook/ScanStarted", "webhook/ScanCompleted"}, queued)
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// notificationStateConfigMap stores the deduplication and rate limit
+	// state of the notifications of a namespace, so that it survives operator
+	// restarts and leader changes
+	notificationStateConfigMap = "clamav-notification-state"
+	// notificationStateKey is the ConfigMap key holding the state
+	notificationStateKey = "state.json"
+	// maxRateLimitWindow is the longest rate limit window, deliveries older
+	// than it are forgotten
+	maxRateLimitWindow = 24 * time.Hour
+	// notificationStateBytes is the size budget of the state, well below the
+	// 1 MiB ConfigMap limit. The findings closest to expiry are forgotten
+	// first when it is exceeded.
+	notificationStateBytes = 512 * 1024
+)
+
+// notificationState is the notification state of a namespace
+type notificationState struct {
+	// Findings maps the key of each notified infection to its suppression
+	Findings map[string]notifiedFinding `json:"findings,omitempty"`
+	// Deliveries maps each rate limited channel to its recent delivery times
+	Deliveries map[string][]metav1.Time `json:"deliveries,omitempty"`
+}
+
+// notifiedFinding records the notification of an infection
+type notifiedFinding struct {
+	// Until is the end of the suppression window
+	Until metav1.Time `json:"until"`
+	// Owner is the UID of the scan that notified the infection. The scan
+	// that recorded an infection is not suppressed by its own record.
+	Owner types.UID `json:"owner"`
+}
+
+// prune forgets expired findings and deliveries
+func (s *notificationState) prune(now time.Time) {
+	for key, f := range s.Findings {
+		if !f.Until.Time.After(now) {
+			delete(s.Findings, key)
+		}
+	}
+	for channel, times := range s.Deliveries {
+		recent := times[:0]
+		for _, t := range times {
+			if now.Sub(t.Time) < maxRateLimitWindow {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(s.Deliveries, channel)
+		} else {
+			s.Deliveries[channel] = recent
+		}
+	}
+}
+
+// shrink forgets the findings closest to expiry until the serialized state
+// fits in budget bytes. Forgotten findings are notified again, which is
+// preferable to losing the state.
+func (s *notificationState) shrink(budget int) ([]byte, error) {
+	raw, err := json.Marshal(s)
+	if err != nil || len(raw) <= budget {
+		return raw, err
+	}
+
+	keys := make([]string, 0, len(s.Findings))
+	for key := range s.Findings {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.Findings[keys[i]].Until.Time.Before(s.Findings[keys[j]].Until.Time)
+	})
+
+	for len(raw) > budget && len(keys) > 0 {
+		excess := len(raw) - budget
+		for excess > 0 && len(keys) > 0 {
+			excess -= len(keys[0]) + entrySize(s.Findings[keys[0]])
+			delete(s.Findings, keys[0])
+			keys = keys[1:]
+		}
+		if raw, err = json.Marshal(s); err != nil {
+			return nil, err
+		}
+	}
+	return raw, nil
+}
+
+// updateNotificationState applies update to the notification state of the
+// namespace and stores it if update reports a change. Concurrent updates are
+// retried.
+func updateNotificationState(ctx context.Context, c client.Client, namespace string, update func(*notificationState) bool) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.Get(ctx, types.NamespacedName{Name: notificationStateConfigMap, Namespace: namespace}, configMap)
+		found := err == nil
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		state := notificationState{}
+		if raw := configMap.Data[notificationStateKey]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &state); err != nil {
+				// A corrupted state must not block notifications
+				log.FromContext(ctx).Error(err, "discarding unreadable notification state", "namespace", namespace)
+				state = notificationState{}
+			}
+		}
+		if state.Findings == nil {
+			state.Findings = map[string]notifiedFinding{}
+		}
+		if state.Deliveries == nil {
+			state.Deliveries = map[string][]metav1.Time{}
+		}
+
+		if !update(&state) {
+			return nil
+		}
+		state.prune(time.Now())
+		raw, err := state.shrink(notificationStateBytes)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      notificationStateConfigMap,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":      "clamav",
+						"app.kubernetes.io/component": "notification-state",
+					},
+				},
+				Data: map[string]string{notificationStateKey: string(raw)},
+			}
+			return c.Create(ctx, configMap)
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[notificationStateKey] = string(raw)
+		return c.Update(ctx, configMap)
+	})
+}
+
+// findingKey identifies an infection by node, path and signature. Keys are
+// hashed to bound the size of the state.
+func findingKey(node, path, signature string) string {
+	sum := sha256.Sum256([]byte(node + "\x00" + path + "\x00" + signature))
+	return hex.EncodeToString(sum[:16])
+}
+
+// nodeScanFindingKeys returns the keys of the infections of NodeScans and
+// whether they cover every infection. The status lists a limited number of
+// infected files, the infections beyond it are never known.
+func nodeScanFindingKeys(nodeScans ...clamavv1alpha1.NodeScan) ([]string, bool) {
+	var keys []string
+	complete := true
+	for _, ns := range nodeScans {
+		if ns.Status.FilesInfected > int64(len(ns.Status.InfectedFiles)) {
+			complete = false
+		}
+		for _, f := range ns.Status.InfectedFiles {
+			for _, virus := range f.Viruses {
+				keys = append(keys, findingKey(ns.Spec.NodeName, f.Path, virus))
+			}
+		}
+	}
+	return keys, complete
+}
+
+// recordFindings records the infections as notified by owner and reports
+// whether all of them were already notified within the deduplication window,
+// in which case they are not notified again. Without deduplication no
+// infection is known.
+func recordFindings(ctx context.Context, c client.Client, namespace string, owner types.UID,
+	dedup *clamavv1alpha1.NotificationDeduplication, keys []string) (bool, error) {
+	if dedup == nil || !dedup.Enabled || len(keys) == 0 {
+		return false, nil
+	}
+	window := time.Duration(clamavv1alpha1.DefaultNotificationDeduplicationWindowSeconds) * time.Second
+	if dedup.WindowSeconds > 0 {
+		window = time.Duration(dedup.WindowSeconds) * time.Second
+	}
+
+	known := true
+	err := updateNotificationState(ctx, c, namespace, func(state *notificationState) bool {
+		now := time.Now()
+		known = true
+		for _, key := range keys {
+			f, ok := state.Findings[key]
+			if ok && f.Owner != owner && f.Until.Time.After(now) {
+				continue
+			}
+			known = false
+			state.Findings[key] = notifiedFinding{Until: metav1.NewTime(now.Add(window)), Owner: owner}
+		}
+		return !known
+	})
+	return known, err
+}
+
+// notificationDeduplication returns the deduplication settings, if any
+func notificationDeduplication(notifications *clamavv1alpha1.NotificationConfig) *clamavv1alpha1.NotificationDeduplication {
+	if notifications == nil {
+		return nil
+	}
+	return notifications.Deduplication
+}
+
+// rateLimitFor returns the rate limit of the channel, if any
+func rateLimitFor(notifications *clamavv1alpha1.NotificationConfig, channel string) *clamavv1alpha1.NotificationRateLimit {
+	if notifications == nil {
+		return nil
+	}
+	for i := range notifications.RateLimits {
+		if notifications.RateLimits[i].Channel == channel {
+			return &notifications.RateLimits[i]
+		}
+	}
+	return nil
+}
+
+// reserveDelivery records a delivery on a rate limited channel of the
+// namespace if the limit allows it. Otherwise it returns the time at which
+// the window allows the next delivery.
+func reserveDelivery(ctx context.Context, c client.Client, namespace string, limit *clamavv1alpha1.NotificationRateLimit,
+	now time.Time) (bool, time.Time, error) {
+	window := time.Duration(clamavv1alpha1.DefaultNotificationRateLimitWindowSeconds) * time.Second
+	if limit.WindowSeconds > 0 {
+		window = time.Duration(limit.WindowSeconds) * time.Second
+	}
+
+	var allowed bool
+	var retryAt time.Time
+	err := updateNotificationState(ctx, c, namespace, func(state *notificationState) bool {
+		var recent []metav1.Time
+		for _, t := range state.Deliveries[limit.Channel] {
+			if now.Sub(t.Time) < window {
+				recent = append(recent, t)
+			}
+		}
+		allowed = len(recent) == 0 || int32(len(recent)) < limit.MaxNotifications
+		if !allowed {
+			// Deliveries are recorded in order, the oldest leaves the window first
+			retryAt = recent[0].Time.Add(window)
+			return false
+		}
+		state.Deliveries[limit.Channel] = append(state.Deliveries[limit.Channel], metav1.NewTime(now))
+		return true
+	})
+	return allowed, retryAt, err
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func TestRecordFindings_Deduplication(t *testing.T) {
+	r := newTestNodeScanReconciler()
+	ctx := context.Background()
+	dedup := &clamavv1alpha1.NotificationDeduplication{Enabled: true}
+	nodeScan := clamavv1alpha1.NodeScan{
+		Spec: clamavv1alpha1.NodeScanSpec{NodeName: "node-1"},
+		Status: clamavv1alpha1.NodeScanStatus{InfectedFiles: []clamavv1alpha1.InfectedFile{
+			{Path: "/host/tmp/eicar.com", Viruses: []string{"Eicar-Signature"}},
+		}},
+	}
+	keys, complete := nodeScanFindingKeys(nodeScan)
+	require.Len(t, keys, 1)
+	assert.True(t, complete)
+
+	// Without deduplication every infection is notified
+	known, err := recordFindings(ctx, r.Client, "default", "scan-1", nil, keys)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	known, err = recordFindings(ctx, r.Client, "default", "scan-1", dedup, keys)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	// The scan that notified the infection is not suppressed by its record
+	known, err = recordFindings(ctx, r.Client, "default", "scan-1", dedup, keys)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	known, err = recordFindings(ctx, r.Client, "default", "scan-2", dedup, keys)
+	require.NoError(t, err)
+	assert.True(t, known)
+
+	// A new infection notifies the scan again
+	nodeScan.Status.InfectedFiles = append(nodeScan.Status.InfectedFiles,
+		clamavv1alpha1.InfectedFile{Path: "/host/tmp/other", Viruses: []string{"Eicar-Signature"}})
+	newKeys, _ := nodeScanFindingKeys(nodeScan)
+	known, err = recordFindings(ctx, r.Client, "default", "scan-3", dedup, newKeys)
+	require.NoError(t, err)
+	assert.False(t, known)
+
+	// Infections are forgotten when the window expires
+	var configMap corev1.ConfigMap
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: notificationStateConfigMap, Namespace: "default"}, &configMap))
+	var state notificationState
+	require.NoError(t, json.Unmarshal([]byte(configMap.Data[notificationStateKey]), &state))
+	assert.Len(t, state.Findings, 2)
+	for key, f := range state.Findings {
+		f.Until = metav1.NewTime(time.Now().Add(-time.Second))
+		state.Findings[key] = f
+	}
+	raw, err := json.Marshal(state)
+	require.NoError(t, err)
+	configMap.Data[notificationStateKey] = string(raw)
+	require.NoError(t, r.Update(ctx, &configMap))
+
+	known, err = recordFindings(ctx, r.Client, "default", "scan-4", dedup, keys)
+	require.NoError(t, err)
+	assert.False(t, known)
+}
+
+func TestNodeScanFindingKeys_UnlistedInfections(t *testing.T) {
+	nodeScan := clamavv1alpha1.NodeScan{
+		Spec: clamavv1alpha1.NodeScanSpec{NodeName: "node-1"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			FilesInfected: 150,
+			InfectedFiles: []clamavv1alpha1.InfectedFile{
+				{Path: "/host/tmp/eicar.com", Viruses: []string{"Eicar-Signature"}},
+			},
+		},
+	}
+
+	// The infections beyond the status list are never known
+	keys, complete := nodeScanFindingKeys(nodeScan)
+	assert.Len(t, keys, 1)
+	assert.False(t, complete)
+}
+
+func TestNotificationState_Shrink(t *testing.T) {
+	now := time.Now()
+	state := notificationState{Findings: map[string]notifiedFinding{}}
+	for i := 0; i < 1000; i++ {
+		state.Findings[findingKey("node-1", fmt.Sprintf("/host/tmp/%d", i), "Eicar-Signature")] = notifiedFinding{
+			Until: metav1.NewTime(now.Add(time.Duration(i) * time.Minute)),
+			Owner: "scan-1",
+		}
+	}
+	latest := findingKey("node-1", "/host/tmp/999", "Eicar-Signature")
+
+	raw, err := state.shrink(16 * 1024)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(raw), 16*1024)
+	assert.Less(t, len(state.Findings), 1000)
+	assert.NotEmpty(t, state.Findings)
+	// The findings closest to expiry are forgotten first
+	assert.Contains(t, state.Findings, latest)
+}
+
+func TestNodeScanReconciler_Reconcile_NotificationStateFailureFailsOpen(t *testing.T) {
+	node, nodeScan, job := newScanResultTestObjects()
+	nodeScan.Spec.ScanPolicy = "test-policy"
+	scanPolicy := &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Notifications: &clamavv1alpha1.NotificationConfig{
+				Deduplication: &clamavv1alpha1.NotificationDeduplication{Enabled: true},
+			},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodescan-test-scan-result", Namespace: "default"},
+		BinaryData: map[string][]byte{scanResultKey: encodeTestScanResult(t, newTestScanResult())},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan, job, configMap, scanPolicy)
+	r.Client = &failingStateClient{Client: r.Client}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseCompleted, updated.Status.Phase)
+
+	// Deduplication is skipped and the failure is reported
+	var events []string
+	for len(r.Recorder.(*record.FakeRecorder).Events) > 0 {
+		events = append(events, <-r.Recorder.(*record.FakeRecorder).Events)
+	}
+	assert.Contains(t, strings.Join(events, "\n"), "NotificationStateFailed")
+}
+
+// failingStateClient fails to store the notification state
+type failingStateClient struct {
+	client.Client
+}
+
+func (c *failingStateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetName() == notificationStateConfigMap {
+		return fmt.Errorf("configmap too large")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *failingStateClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if obj.GetName() == notificationStateConfigMap {
+		return fmt.Errorf("configmap too large")
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestReserveDelivery(t *testing.T) {
+	r := newTestNodeScanReconciler()
+	ctx := context.Background()
+	limit := &clamavv1alpha1.NotificationRateLimit{Channel: "slack", MaxNotifications: 2, WindowSeconds: 60}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := reserveDelivery(ctx, r.Client, "default", limit, now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAt, err := reserveDelivery(ctx, r.Client, "default", limit, now.Add(2*time.Second))
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.WithinDuration(t, now.Add(time.Minute), retryAt, time.Second)
+
+	// Other channels and namespaces have their own budget
+	allowed, _, err = reserveDelivery(ctx, r.Client, "other", limit, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, _, err = reserveDelivery(ctx, r.Client, "default",
+		&clamavv1alpha1.NotificationRateLimit{Channel: "email", MaxNotifications: 1}, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// The window slides
+	allowed, _, err = reserveDelivery(ctx, r.Client, "default", limit, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestReconcileNotifications_RateLimited(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	scanPolicy := &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Notifications: &clamavv1alpha1.NotificationConfig{
+				Webhook: &clamavv1alpha1.WebhookConfig{URL: server.URL},
+				RateLimits: []clamavv1alpha1.NotificationRateLimit{
+					{Channel: clamavv1alpha1.NotificationChannelWebhook, MaxNotifications: 1},
+				},
+			},
+		},
+	}
+	var nodeScans []*clamavv1alpha1.NodeScan
+	for _, name := range []string{"scan-1", "scan-2"} {
+		nodeScan := &clamavv1alpha1.NodeScan{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       clamavv1alpha1.NodeScanSpec{NodeName: "test-node"},
+			Status: clamavv1alpha1.NodeScanStatus{
+				Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+				FilesInfected: 1,
+			},
+		}
+		enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventScanCompleted)
+		nodeScans = append(nodeScans, nodeScan)
+	}
+	r := newTestNodeScanReconciler(nodeScans[0], nodeScans[1])
+	ctx := context.Background()
+
+	_, err := r.reconcileNotifications(ctx, nodeScans[0], scanPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, clamavv1alpha1.NotificationStateSent, nodeScans[0].Status.Notifications[0].State)
+
+	// The second notification waits for the window without using an attempt
+	result, err := r.reconcileNotifications(ctx, nodeScans[1], scanPolicy)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, result.RequeueAfter, float64(time.Minute))
+	n := nodeScans[1].Status.Notifications[0]
+	assert.Equal(t, clamavv1alpha1.NotificationStatePending, n.State)
+	assert.Zero(t, n.Attempts)
+	require.NotNil(t, n.NextAttemptTime)
+	assert.Equal(t, int32(1), requests.Load())
+}
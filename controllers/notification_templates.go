@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// notificationTemplateData is the data notification templates are rendered
+// with. Only the object the event is about is set.
+type notificationTemplateData struct {
+	// Event is the notification event, e.g. ScanCompleted
+	Event    string
+	Title    string
+	Severity string
+	Message  string
+	// Fields are the details of the default message by key, e.g. node
+	Fields   map[string]string
+	Findings []templateFinding
+	Time     time.Time
+	Cluster  templateCluster
+
+	NodeScan     *clamavv1alpha1.NodeScan
+	ScanPolicy   *clamavv1alpha1.ScanPolicy
+	ClusterScan  *clamavv1alpha1.ClusterScan
+	ScanSchedule *clamavv1alpha1.ScanSchedule
+}
+
+// templateCluster describes the cluster the operator runs in
+type templateCluster struct {
+	Name string
+}
+
+// templateFinding is a signature detected on a node
+type templateFinding struct {
+	Node      string
+	Signature string
+	Paths     []string
+	// Workloads are the infected pods as namespace/pod/container
+	Workloads []string
+}
+
+// newNotificationTemplateData returns the template data of an alert
+func newNotificationTemplateData(event string, a alert, clusterName string) notificationTemplateData {
+	data := notificationTemplateData{
+		Event:    event,
+		Title:    a.title,
+		Severity: a.severity,
+		Message:  a.message,
+		Fields:   map[string]string{},
+		Findings: []templateFinding{},
+		Time:     time.Now(),
+		Cluster:  templateCluster{Name: clusterName},
+	}
+	for _, f := range a.fields {
+		data.Fields[f.key] = f.value
+	}
+	for _, f := range a.findings {
+		data.Findings = append(data.Findings, templateFinding{Node: f.node, Signature: f.signature, Paths: f.paths,
+			Workloads: f.workloads})
+	}
+	return data
+}
+
+// nodeScanEventAlert returns the alert of a NodeScan notification event
+func nodeScanEventAlert(nodeScan *clamavv1alpha1.NodeScan, event string) (alert, error) {
+	switch event {
+	case clamavv1alpha1.NotificationEventScanStarted:
+		return scanStartedAlert(nodeScan), nil
+	case clamavv1alpha1.NotificationEventScanCompleted:
+		return scanCompletedAlert(nodeScan), nil
+	case clamavv1alpha1.NotificationEventScanFailed, clamavv1alpha1.NotificationEventParseFailed:
+		return nodeScanAlert(nodeScan, event), nil
+	case clamavv1alpha1.NotificationEventQuarantineCompleted:
+		if nodeScan.Status.Quarantine == nil {
+			return alert{}, fmt.Errorf("no quarantine to report")
+		}
+		return quarantineAlert(nodeScan), nil
+	}
+	return alert{}, fmt.Errorf("unknown notification event %q", event)
+}
+
+// hasNotificationTemplate reports whether an enabled channel renders its
+// notifications with a template
+func hasNotificationTemplate(notifications *clamavv1alpha1.NotificationConfig, channel string) bool {
+	if notifications == nil {
+		return false
+	}
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		return notifications.Slack != nil && notifications.Slack.Template != nil
+	case clamavv1alpha1.NotificationChannelEmail:
+		return notifications.Email != nil &&
+			(notifications.Email.Template != nil || notifications.Email.SubjectTemplate != "")
+	case clamavv1alpha1.NotificationChannelWebhook:
+		return notifications.Webhook != nil && notifications.Webhook.Template != nil
+	}
+	return false
+}
+
+// renderNotificationTemplate renders an inline template or the template of a
+// ConfigMap in the namespace
+func renderNotificationTemplate(ctx context.Context, c client.Reader, namespace, name string,
+	tmpl *clamavv1alpha1.NotificationTemplate, data notificationTemplateData) (string, error) {
+	text := tmpl.Inline
+	if ref := tmpl.ConfigMapRef; ref != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, configMap); err != nil {
+			return "", fmt.Errorf("failed to get %s template ConfigMap: %w", name, err)
+		}
+		var ok bool
+		if text, ok = configMap.Data[ref.Key]; !ok {
+			return "", fmt.Errorf("key %s not found in %s template ConfigMap %s", ref.Key, name, ref.Name)
+		}
+	}
+	return executeNotificationTemplate(name, text, data)
+}
+
+// executeNotificationTemplate parses and renders a template
+func executeNotificationTemplate(name, text string, data notificationTemplateData) (string, error) {
+	t, err := clamavv1alpha1.ParseNotificationTemplate(name, text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// sendTemplated renders the notification with the templates of the channel
+// and sends it. The email body defaults to the text of the alert when only
+// the subject is templated; a templated body has no HTML version.
+func sendTemplated(ctx context.Context, c client.Reader, notifications *clamavv1alpha1.NotificationConfig,
+	namespace, channel string, a alert, data notificationTemplateData) error {
+	if err := checkNotificationChannel(notifications, channel); err != nil {
+		return err
+	}
+
+	switch channel {
+	case clamavv1alpha1.NotificationChannelSlack:
+		text, err := renderNotificationTemplate(ctx, c, namespace, "slack", notifications.Slack.Template, data)
+		if err != nil {
+			return err
+		}
+		message := map[string]interface{}{
+			"channel":    notifications.Slack.Channel,
+			"username":   "ClamAV Operator",
+			"icon_emoji": ":shield:",
+			"text":       text,
+		}
+		return postSlackMessage(ctx, c, notifications.Slack, namespace, message)
+
+	case clamavv1alpha1.NotificationChannelEmail:
+		config := notifications.Email
+		_, icon := alertStyle(a.severity)
+		subject := icon + " " + a.title
+		if config.SubjectTemplate != "" {
+			var err error
+			if subject, err = executeNotificationTemplate("email subject", config.SubjectTemplate, data); err != nil {
+				return err
+			}
+		}
+		msg := alertEmail(subject, alertEmailBody(a), a)
+		if config.Template != nil {
+			var err error
+			if msg.text, err = renderNotificationTemplate(ctx, c, namespace, "email", config.Template, data); err != nil {
+				return err
+			}
+			msg.html = nil
+		}
+		return deliverEmail(ctx, c, config, namespace, msg)
+
+	case clamavv1alpha1.NotificationChannelWebhook:
+		body, err := renderNotificationTemplate(ctx, c, namespace, "webhook", notifications.Webhook.Template, data)
+		if err != nil {
+			return err
+		}
+		if !json.Valid([]byte(body)) {
+			return fmt.Errorf("webhook template did not render valid JSON")
+		}
+		return sendWebhookRequest(ctx, c, notifications.Webhook, namespace, []byte(body),
+			map[string]string{"Content-Type": "application/json"})
+	}
+	return fmt.Errorf("channel %s does not support templates", channel)
+}
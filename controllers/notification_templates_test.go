@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestTemplateData(nodeScan *clamavv1alpha1.NodeScan) (alert, notificationTemplateData) {
+	a := scanCompletedAlert(nodeScan)
+	data := newNotificationTemplateData(clamavv1alpha1.NotificationEventScanCompleted, a, "prod-eu")
+	data.NodeScan = nodeScan
+	return a, data
+}
+
+func TestSendTemplated_SlackFromConfigMap(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusOK)
+	r := newTestNodeScanReconciler(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "notification-templates", Namespace: "default"},
+		Data: map[string]string{
+			"slack.tmpl": `:rotating_light: {{ .NodeScan.Status.FilesInfected }} fichiers infectés sur {{ .Fields.node }} ({{ .Cluster.Name }})
+{{- range .Findings }}
+• {{ .Signature }}: {{ join ", " .Paths }}
+{{- end }}
+Runbook: https://runbooks.example.com/clamav`,
+		},
+	})
+	notifications := &clamavv1alpha1.NotificationConfig{
+		Slack: &clamavv1alpha1.SlackConfig{
+			Enabled:    true,
+			WebhookURL: receiver.URL,
+			Template: &clamavv1alpha1.NotificationTemplate{ConfigMapRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "notification-templates"},
+				Key:                  "slack.tmpl",
+			}},
+		},
+	}
+
+	a, data := newTestTemplateData(newTestInfectedNodeScan())
+	err := sendTemplated(context.Background(), r.Client, notifications, "default", clamavv1alpha1.NotificationChannelSlack, a, data)
+	require.NoError(t, err)
+
+	require.Len(t, receiver.payloads, 1)
+	assert.Equal(t, ":rotating_light: 3 fichiers infectés sur worker-1 (prod-eu)\n"+
+		"• Eicar-Test-Signature: /host/opt/a, /host/opt/b\n"+
+		"• Win.Trojan.Agent: /host/opt/c\n"+
+		"Runbook: https://runbooks.example.com/clamav", receiver.payloads[0]["text"])
+}
+
+func TestSendTemplated_Webhook(t *testing.T) {
+	receiver := newChannelRecorder(t, http.StatusOK)
+	r := newTestNodeScanReconciler()
+	notifications := &clamavv1alpha1.NotificationConfig{
+		Webhook: &clamavv1alpha1.WebhookConfig{
+			URL: receiver.URL,
+			Template: &clamavv1alpha1.NotificationTemplate{
+				Inline: `{"event": {{ toJSON .Event }}, "node": {{ toJSON .NodeScan.Spec.NodeName }}, "team": "secops"}`,
+			},
+		},
+	}
+
+	a, data := newTestTemplateData(newTestInfectedNodeScan())
+	err := sendTemplated(context.Background(), r.Client, notifications, "default", clamavv1alpha1.NotificationChannelWebhook, a, data)
+	require.NoError(t, err)
+
+	require.Len(t, receiver.payloads, 1)
+	assert.Equal(t, map[string]interface{}{"event": "ScanCompleted", "node": "worker-1", "team": "secops"}, receiver.payloads[0])
+
+	// Payloads that are not JSON are not sent
+	notifications.Webhook.Template.Inline = `node={{ .NodeScan.Spec.NodeName }}`
+	err = sendTemplated(context.Background(), r.Client, notifications, "default", clamavv1alpha1.NotificationChannelWebhook, a, data)
+	assert.Error(t, err)
+	assert.Len(t, receiver.payloads, 1)
+}
+
+func TestRenderNotificationTemplate_Errors(t *testing.T) {
+	r := newTestNodeScanReconciler()
+	_, data := newTestTemplateData(newTestInfectedNodeScan())
+
+	// Missing ConfigMap
+	_, err := renderNotificationTemplate(context.Background(), r.Client, "default", "slack",
+		&clamavv1alpha1.NotificationTemplate{ConfigMapRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "missing"},
+			Key:                  "slack.tmpl",
+		}}, data)
+	assert.Error(t, err)
+
+	// Unknown field
+	_, err = renderNotificationTemplate(context.Background(), r.Client, "default", "slack",
+		&clamavv1alpha1.NotificationTemplate{Inline: `{{ .NodeScan.Spec.Unknown }}`}, data)
+	assert.Error(t, err)
+
+	// Unknown key of the fields
+	_, err = renderNotificationTemplate(context.Background(), r.Client, "default", "slack",
+		&clamavv1alpha1.NotificationTemplate{Inline: `{{ .Fields.unknown }}`}, data)
+	assert.Error(t, err)
+}
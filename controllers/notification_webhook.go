@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// webhookTimestampHeader carries the Unix time at which a webhook request was signed
+	webhookTimestampHeader = "X-ClamAV-Timestamp"
+	// webhookSignatureHeader carries the HMAC-SHA256 signature of a webhook request
+	webhookSignatureHeader = "X-ClamAV-Signature"
+)
+
+// webhookSignature returns the signature of a webhook body sent at timestamp.
+// The signed content is "<timestamp>.<body>", so that a receiver can reject
+// replayed requests by checking the timestamp.
+func webhookSignature(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signWebhookRequest sets the timestamp and signature headers of a webhook request
+func signWebhookRequest(req *http.Request, key, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, webhookSignature(key, timestamp, body))
+}
+
+// webhookTLSConfig returns the TLS configuration of webhook requests with the
+// CA bundle and the client certificate read from Secrets in the namespace
+func webhookTLSConfig(ctx context.Context, c client.Reader, config *clamavv1alpha1.WebhookTLSConfig, namespace string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config == nil {
+		return tlsConfig, nil
+	}
+
+	if config.CASecretRef != nil {
+		caBundle, err := secretKeyValue(ctx, c, namespace, config.CASecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get webhook CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("webhook CA bundle %s contains no PEM certificate", config.CASecretRef.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCertSecretRef != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{
+			Name:      config.ClientCertSecretRef.Name,
+			Namespace: namespace,
+		}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get webhook client certificate secret: %w", err)
+		}
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook client certificate in secret %s: %w", config.ClientCertSecretRef.Name, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
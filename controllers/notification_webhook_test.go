@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// newTestClientCertificate returns a self-signed client certificate and its key in PEM
+func newTestClientCertificate(t *testing.T) (*x509.Certificate, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "clamav-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return cert,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestWebhookSignature(t *testing.T) {
+	// Computed with: printf '1700000000.{"type":"test"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=5164242d2d7c1061af198b4bfea622c8f5aeec1b9276e38d50a14d7f9dd39bee",
+		webhookSignature([]byte("secret"), "1700000000", []byte(`{"type":"test"}`)))
+}
+
+func TestPostWebhook_Signed(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header = req.Header.Clone()
+		body, _ = io.ReadAll(req.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	r := newTestNodeScanReconciler(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook-signing", Namespace: "default"},
+		Data:       map[string][]byte{"key": []byte("s3cr3t")},
+	})
+	config := &clamavv1alpha1.WebhookConfig{
+		URL: server.URL,
+		SigningSecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "webhook-signing"},
+			Key:                  "key",
+		},
+	}
+
+	err := postWebhook(context.Background(), r.Client, config, "default", map[string]interface{}{"type": "test"})
+	require.NoError(t, err)
+
+	timestamp := header.Get(webhookTimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(sent, 0), time.Minute)
+	assert.Equal(t, webhookSignature([]byte("s3cr3t"), timestamp, body), header.Get(webhookSignatureHeader))
+}
+
+func TestPostWebhook_MutualTLS(t *testing.T) {
+	clientCert, certPEM, keyPEM := newTestClientCertificate(t)
+
+	var peerCertificates []*x509.Certificate
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		peerCertificates = req.TLS.PeerCertificates
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	r := newTestNodeScanReconciler(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "siem-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": caPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "siem-client", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+		},
+	)
+	tlsConfig := &clamavv1alpha1.WebhookTLSConfig{
+		CASecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "siem-ca"},
+			Key:                  "ca.crt",
+		},
+	}
+	config := &clamavv1alpha1.WebhookConfig{URL: server.URL, TLS: tlsConfig}
+
+	// The server requires a client certificate
+	err := postWebhook(context.Background(), r.Client, config, "default", map[string]interface{}{"type": "test"})
+	assert.Error(t, err)
+
+	tlsConfig.ClientCertSecretRef = &corev1.SecretReference{Name: "siem-client"}
+	err = postWebhook(context.Background(), r.Client, config, "default", map[string]interface{}{"type": "test"})
+	require.NoError(t, err)
+	require.Len(t, peerCertificates, 1)
+	assert.Equal(t, "clamav-operator", peerCertificates[0].Subject.CommonName)
+}
+
+func TestWebhookTLSConfig_InvalidCABundle(t *testing.T) {
+	r := newTestNodeScanReconciler(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "siem-ca", Namespace: "default"},
+		Data:       map[string][]byte{"ca.crt": []byte("not a certificate")},
+	})
+	_, err := webhookTLSConfig(context.Background(), r.Client, &clamavv1alpha1.WebhookTLSConfig{
+		CASecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "siem-ca"},
+			Key:                  "ca.crt",
+		},
+	}, "default")
+	assert.Error(t, err)
+}
@@ -19,57 +19,19 @@ package controllers
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/smtp"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
 )
 
-// sendNotifications sends notifications about infected files
-func (r *NodeScanReconciler) sendNotifications(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) {
-	log := log.FromContext(ctx)
-
-	if scanPolicy.Spec.Notifications == nil {
-		return
-	}
-
-	// Slack
-	if scanPolicy.Spec.Notifications.Slack != nil && scanPolicy.Spec.Notifications.Slack.Enabled {
-		if err := r.sendSlackNotification(ctx, nodeScan, scanPolicy); err != nil {
-			log.Error(err, "failed to send Slack notification")
-			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "NotificationFailed",
-				fmt.Sprintf("Failed to send Slack notification: %v", err))
-		}
-	}
-
-	// Email
-	if scanPolicy.Spec.Notifications.Email != nil && scanPolicy.Spec.Notifications.Email.Enabled {
-		if err := r.sendEmailNotification(ctx, nodeScan, scanPolicy); err != nil {
-			log.Error(err, "failed to send Email notification")
-			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "NotificationFailed",
-				fmt.Sprintf("Failed to send Email notification: %v", err))
-		}
-	}
-
-	// Webhook
-	if scanPolicy.Spec.Notifications.Webhook != nil {
-		if err := r.sendWebhookNotification(ctx, nodeScan, scanPolicy); err != nil {
-			log.Error(err, "failed to send Webhook notification")
-			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "NotificationFailed",
-				fmt.Sprintf("Failed to send Webhook notification: %v", err))
-		}
-	}
-}
-
 // sendSlackNotification sends a Slack notification
 func (r *NodeScanReconciler) sendSlackNotification(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) error {
 	config := scanPolicy.Spec.Notifications.Slack
@@ -79,23 +41,6 @@ func (r *NodeScanReconciler) sendSlackNotification(ctx context.Context, nodeScan
 		return nil
 	}
 
-	// Get webhook URL from secret
-	webhookURL := config.WebhookURL
-	if config.WebhookSecretRef != nil {
-		secret := &corev1.Secret{}
-		if err := r.Get(ctx, types.NamespacedName{
-			Name:      config.WebhookSecretRef.Name,
-			Namespace: scanPolicy.Namespace,
-		}, secret); err != nil {
-			return fmt.Errorf("failed to get webhook secret: %w", err)
-		}
-		webhookURL = string(secret.Data[config.WebhookSecretRef.Key])
-	}
-
-	if webhookURL == "" {
-		return fmt.Errorf("webhook URL not configured")
-	}
-
 	// Build message
 	color := "good"
 	icon := "✅"
@@ -140,7 +85,11 @@ func (r *NodeScanReconciler) sendSlackNotification(ctx context.Context, nodeScan
 				infectedList = append(infectedList, fmt.Sprintf("... and %d more", len(nodeScan.Status.InfectedFiles)-10))
 				break
 			}
-			infectedList = append(infectedList, fmt.Sprintf("• `%s` - %s", f.Path, strings.Join(f.Viruses, ", ")))
+			line := fmt.Sprintf("• `%s` - %s", f.Path, strings.Join(f.Viruses, ", "))
+			if f.Attribution != nil {
+				line += fmt.Sprintf(" (pod `%s`)", attributionWorkload(f.Attribution))
+			}
+			infectedList = append(infectedList, line)
 		}
 
 		fields = append(fields, map[string]interface{}{
@@ -165,6 +114,28 @@ func (r *NodeScanReconciler) sendSlackNotification(ctx context.Context, nodeScan
 		},
 	}
 
+	return postSlackMessage(ctx, r.Client, config, scanPolicy.Namespace, message)
+}
+
+// postSlackMessage resolves the Slack webhook URL and posts the message to it
+func postSlackMessage(ctx context.Context, c client.Reader, config *clamavv1alpha1.SlackConfig, namespace string, message map[string]interface{}) error {
+	// Get webhook URL from secret
+	webhookURL := config.WebhookURL
+	if config.WebhookSecretRef != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{
+			Name:      config.WebhookSecretRef.Name,
+			Namespace: namespace,
+		}, secret); err != nil {
+			return fmt.Errorf("failed to get webhook secret: %w", err)
+		}
+		webhookURL = string(secret.Data[config.WebhookSecretRef.Key])
+	}
+
+	if webhookURL == "" {
+		return fmt.Errorf("webhook URL not configured")
+	}
+
 	// Send request
 	body, err := json.Marshal(message)
 	if err != nil {
@@ -193,20 +164,6 @@ func (r *NodeScanReconciler) sendEmailNotification(ctx context.Context, nodeScan
 		return nil
 	}
 
-	// Get SMTP credentials from secret
-	var username, password string
-	if config.SMTPAuthSecretRef != nil {
-		secret := &corev1.Secret{}
-		if err := r.Get(ctx, types.NamespacedName{
-			Name:      config.SMTPAuthSecretRef.Name,
-			Namespace: scanPolicy.Namespace,
-		}, secret); err != nil {
-			return fmt.Errorf("failed to get SMTP secret: %w", err)
-		}
-		username = string(secret.Data["username"])
-		password = string(secret.Data["password"])
-	}
-
 	// Build email
 	subject := "ClamAV Scan Completed"
 	if nodeScan.Status.FilesInfected > 0 {
@@ -240,6 +197,12 @@ func (r *NodeScanReconciler) sendEmailNotification(ctx context.Context, nodeScan
 			body.WriteString(fmt.Sprintf("%d. File: %s\n", i+1, f.Path))
 			body.WriteString(fmt.Sprintf("   Viruses: %s\n", strings.Join(f.Viruses, ", ")))
 			body.WriteString(fmt.Sprintf("   Size: %d bytes\n", f.Size))
+			if f.Attribution != nil {
+				body.WriteString(fmt.Sprintf("   Workload: %s\n", attributionWorkload(f.Attribution)))
+				if f.Attribution.Image != "" {
+					body.WriteString(fmt.Sprintf("   Image: %s\n", f.Attribution.Image))
+				}
+			}
 			body.WriteString("\n")
 		}
 	} else {
@@ -252,82 +215,8 @@ func (r *NodeScanReconciler) sendEmailNotification(ctx context.Context, nodeScan
 	body.WriteString("For more information, check the Kubernetes cluster logs.\n")
 	body.WriteString("================================================================================\n")
 
-	// Compose message
-	message := []byte(
-		"From: " + config.From + "\r\n" +
-			"To: " + strings.Join(config.Recipients, ",") + "\r\n" +
-			"Subject: " + subject + "\r\n" +
-			"Content-Type: text/plain; charset=UTF-8\r\n" +
-			"\r\n" +
-			body.String() + "\r\n",
-	)
-
-	// Parse server address
-	host := strings.Split(config.SMTPServer, ":")[0]
-
-	// Setup TLS
-	tlsconfig := &tls.Config{
-		ServerName: host,
-	}
-
-	// Connect
-	auth := smtp.PlainAuth("", username, password, host)
-
-	// Send with TLS
-	conn, err := tls.Dial("tcp", config.SMTPServer, tlsconfig)
-	if err != nil {
-		// Fallback to non-TLS
-		err = smtp.SendMail(
-			config.SMTPServer,
-			auth,
-			config.From,
-			config.Recipients,
-			message,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to send email: %w", err)
-		}
-	} else {
-		defer conn.Close()
-
-		client, err := smtp.NewClient(conn, host)
-		if err != nil {
-			return fmt.Errorf("failed to create SMTP client: %w", err)
-		}
-
-		if err = client.Auth(auth); err != nil {
-			return fmt.Errorf("failed to authenticate: %w", err)
-		}
-
-		if err = client.Mail(config.From); err != nil {
-			return fmt.Errorf("failed to set sender: %w", err)
-		}
-
-		for _, recipient := range config.Recipients {
-			if err = client.Rcpt(recipient); err != nil {
-				return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
-			}
-		}
-
-		w, err := client.Data()
-		if err != nil {
-			return fmt.Errorf("failed to get data writer: %w", err)
-		}
-
-		_, err = w.Write(message)
-		if err != nil {
-			return fmt.Errorf("failed to write message: %w", err)
-		}
-
-		err = w.Close()
-		if err != nil {
-			return fmt.Errorf("failed to close writer: %w", err)
-		}
-
-		client.Quit()
-	}
-
-	return nil
+	return deliverEmail(ctx, r.Client, config, scanPolicy.Namespace,
+		alertEmail(subject, body.String(), scanCompletedAlert(nodeScan)))
 }
 
 // sendWebhookNotification sends a generic webhook notification
@@ -361,11 +250,15 @@ func (r *NodeScanReconciler) sendWebhookNotification(ctx context.Context, nodeSc
 	if nodeScan.Status.FilesInfected > 0 {
 		var infectedFiles []map[string]interface{}
 		for _, f := range nodeScan.Status.InfectedFiles {
-			infectedFiles = append(infectedFiles, map[string]interface{}{
+			infectedFile := map[string]interface{}{
 				"path":    f.Path,
 				"viruses": f.Viruses,
 				"size":    f.Size,
-			})
+			}
+			if f.Attribution != nil {
+				infectedFile["attribution"] = f.Attribution
+			}
+			infectedFiles = append(infectedFiles, infectedFile)
 		}
 		payload["infectedFiles"] = infectedFiles
 		payload["severity"] = "critical"
@@ -373,19 +266,32 @@ func (r *NodeScanReconciler) sendWebhookNotification(ctx context.Context, nodeSc
 		payload["severity"] = "info"
 	}
 
+	return postWebhook(ctx, r.Client, config, scanPolicy.Namespace, payload)
+}
+
+// postWebhook sends a JSON payload to the configured webhook endpoint
+func postWebhook(ctx context.Context, c client.Reader, config *clamavv1alpha1.WebhookConfig, namespace string, payload map[string]interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	return sendWebhookRequest(ctx, c, config, namespace, body, map[string]string{"Content-Type": "application/json"})
+}
 
+// sendWebhookRequest posts the body to the webhook with the given headers,
+// then adds the configured headers, the signature and the TLS configuration
+func sendWebhookRequest(ctx context.Context, c client.Reader, config *clamavv1alpha1.WebhookConfig, namespace string,
+	body []byte, headers map[string]string) error {
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "POST", config.URL, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "ClamAV-Operator/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	// Add headers from config
 	for key, value := range config.Headers {
@@ -395,9 +301,9 @@ func (r *NodeScanReconciler) sendWebhookNotification(ctx context.Context, nodeSc
 	// Add headers from secret
 	if config.SecretRef != nil {
 		secret := &corev1.Secret{}
-		if err := r.Get(ctx, types.NamespacedName{
+		if err := c.Get(ctx, types.NamespacedName{
 			Name:      config.SecretRef.Name,
-			Namespace: scanPolicy.Namespace,
+			Namespace: namespace,
 		}, secret); err != nil {
 			return fmt.Errorf("failed to get webhook secret: %w", err)
 		}
@@ -407,13 +313,25 @@ func (r *NodeScanReconciler) sendWebhookNotification(ctx context.Context, nodeSc
 		}
 	}
 
+	// Sign the body
+	if config.SigningSecretRef != nil {
+		key, err := secretKeyValue(ctx, c, namespace, config.SigningSecretRef)
+		if err != nil {
+			return fmt.Errorf("failed to get signing key: %w", err)
+		}
+		signWebhookRequest(req, []byte(key), body, time.Now())
+	}
+
+	tlsConfig, err := webhookTLSConfig(ctx, c, config.TLS, namespace)
+	if err != nil {
+		return err
+	}
+
 	// Send request
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false,
-			},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 	resp, err := client.Do(req)
@@ -428,3 +346,107 @@ func (r *NodeScanReconciler) sendWebhookNotification(ctx context.Context, nodeSc
 
 	return nil
 }
+
+// sendSlackQuarantineNotification notifies administrators on Slack about the outcome of a quarantine
+func (r *NodeScanReconciler) sendSlackQuarantineNotification(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) error {
+	config := scanPolicy.Spec.Notifications.Slack
+	quarantine := nodeScan.Status.Quarantine
+
+	color := "good"
+	if quarantine.FilesFailed > 0 {
+		color = "danger"
+	}
+
+	var fileList []string
+	for i, f := range quarantine.Files {
+		if i >= 10 {
+			fileList = append(fileList, fmt.Sprintf("... and %d more", len(quarantine.Files)-10))
+			break
+		}
+		fileList = append(fileList, fmt.Sprintf("• `%s` - %s", f.Path, f.Outcome))
+	}
+
+	message := map[string]interface{}{
+		"channel":    config.Channel,
+		"username":   "ClamAV Operator",
+		"icon_emoji": ":shield:",
+		"text":       "🔒 ClamAV Quarantine Completed",
+		"attachments": []map[string]interface{}{
+			{
+				"color": color,
+				"fields": []map[string]interface{}{
+					{"title": "Node", "value": nodeScan.Spec.NodeName, "short": true},
+					{"title": "Action", "value": quarantine.Action, "short": true},
+					{"title": "Quarantined", "value": fmt.Sprintf("%d", quarantine.FilesQuarantined), "short": true},
+					{"title": "Deleted", "value": fmt.Sprintf("%d", quarantine.FilesDeleted), "short": true},
+					{"title": "Failed", "value": fmt.Sprintf("%d", quarantine.FilesFailed), "short": true},
+					{"title": "Files", "value": strings.Join(fileList, "\n"), "short": false},
+				},
+				"footer": "ClamAV Operator",
+				"ts":     time.Now().Unix(),
+			},
+		},
+	}
+
+	return postSlackMessage(ctx, r.Client, config, scanPolicy.Namespace, message)
+}
+
+// sendEmailQuarantineNotification emails administrators about the outcome of a quarantine
+func (r *NodeScanReconciler) sendEmailQuarantineNotification(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) error {
+	config := scanPolicy.Spec.Notifications.Email
+	quarantine := nodeScan.Status.Quarantine
+
+	var body strings.Builder
+	body.WriteString("================================================================================\n")
+	body.WriteString("                       ClamAV QUARANTINE REPORT\n")
+	body.WriteString("================================================================================\n\n")
+
+	body.WriteString(fmt.Sprintf("Node:              %s\n", nodeScan.Spec.NodeName))
+	body.WriteString(fmt.Sprintf("Scan Name:         %s\n", nodeScan.Name))
+	body.WriteString(fmt.Sprintf("Action:            %s\n", quarantine.Action))
+	if quarantine.QuarantineDir != "" {
+		body.WriteString(fmt.Sprintf("Quarantine Dir:    %s\n", quarantine.QuarantineDir))
+	}
+	body.WriteString(fmt.Sprintf("Files Quarantined: %d\n", quarantine.FilesQuarantined))
+	body.WriteString(fmt.Sprintf("Files Deleted:     %d\n", quarantine.FilesDeleted))
+	body.WriteString(fmt.Sprintf("Files Failed:      %d\n", quarantine.FilesFailed))
+	body.WriteString("\n")
+
+	body.WriteString("FILES:\n")
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	for i, f := range quarantine.Files {
+		body.WriteString(fmt.Sprintf("%d. File: %s\n", i+1, f.Path))
+		body.WriteString(fmt.Sprintf("   Outcome: %s\n", f.Outcome))
+		if f.QuarantinePath != "" {
+			body.WriteString(fmt.Sprintf("   Moved to: %s\n", f.QuarantinePath))
+		}
+		if f.Message != "" {
+			body.WriteString(fmt.Sprintf("   Error: %s\n", f.Message))
+		}
+		body.WriteString("\n")
+	}
+
+	body.WriteString("--------------------------------------------------------------------------------\n")
+	body.WriteString("This is an automated message from ClamAV Operator.\n")
+	body.WriteString("================================================================================\n")
+
+	subject := fmt.Sprintf("ClamAV Quarantine Report: %s", nodeScan.Spec.NodeName)
+	return deliverEmail(ctx, r.Client, config, scanPolicy.Namespace,
+		alertEmail(subject, body.String(), quarantineAlert(nodeScan)))
+}
+
+// sendWebhookQuarantineNotification posts the outcome of a quarantine to the webhook
+func (r *NodeScanReconciler) sendWebhookQuarantineNotification(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) error {
+	payload := map[string]interface{}{
+		"type":      "clamav.quarantine.completed",
+		"timestamp": time.Now().Format(time.RFC3339),
+		"scan": map[string]interface{}{
+			"name":      nodeScan.Name,
+			"namespace": nodeScan.Namespace,
+			"node":      nodeScan.Spec.NodeName,
+		},
+		"quarantine": nodeScan.Status.Quarantine,
+	}
+
+	return postWebhook(ctx, r.Client, scanPolicy.Spec.Notifications.Webhook, scanPolicy.Namespace, payload)
+}
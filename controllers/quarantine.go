@@ -0,0 +1,537 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultQuarantineDir is the directory on the node infected files are moved to
+	// when the ScanPolicy does not set one
+	DefaultQuarantineDir = "/var/lib/clamav-quarantine"
+
+	// quarantineResultMarker prefixes the result lines printed by the quarantine Job
+	quarantineResultMarker = "QUARANTINE_RESULT"
+
+	// hostRootMount is where the node root filesystem is mounted in scanner pods
+	hostRootMount = "/host"
+
+	// quarantineFilesMount is where the file list is mounted in the quarantine Job
+	quarantineFilesMount = "/quarantine"
+	// quarantineFilesKey is the ConfigMap key holding the file list
+	quarantineFilesKey = "files"
+	// quarantineFilesBytes is the size budget of the file list, well below the
+	// 1 MiB ConfigMap limit. The files beyond it are left untouched.
+	quarantineFilesBytes = 900 * 1024
+)
+
+// quarantineScript moves or deletes every file listed in the quarantine file list and
+// prints one tab-separated result line per file: marker, outcome, path, detail. Moved
+// files keep their path on the node below the quarantine directory, so files never
+// collide. The paths come from filesystems that workloads can write to: symbolic links
+// are refused, and so are files whose parent directory resolves to another path, since
+// a linked parent would redirect the action to other files of the node.
+const quarantineScript = `set -u
+dest="${HOST_ROOT}${QUARANTINE_DIR}/${NODESCAN_NAME}"
+if [ "$QUARANTINE_ACTION" = "move" ]; then
+  if [ "$(realpath -m -- "$dest")" != "$dest" ]; then
+    echo "quarantine directory $dest resolves to another path" >&2
+    exit 1
+  fi
+  mkdir -p "$dest" && chmod 700 "$dest" || exit 1
+fi
+fail() {
+  printf 'QUARANTINE_RESULT\tfailed\t%s\t%s\n' "$f" "$(printf '%s' "$1" | tr '\t\n' '  ')"
+}
+while IFS= read -r f; do
+  [ -z "$f" ] && continue
+  parent=$(dirname -- "$f")
+  if [ -L "$f" ]; then
+    fail "file is a symbolic link"
+    continue
+  elif [ ! -f "$f" ]; then
+    fail "not a regular file"
+    continue
+  elif [ "$(realpath -e -- "$parent" 2>/dev/null)" != "$parent" ]; then
+    fail "parent directory resolves to another path"
+    continue
+  fi
+  case "$parent/" in
+  "$HOST_ROOT"/*) ;;
+  *)
+    fail "outside the node filesystem"
+    continue
+    ;;
+  esac
+  case "$QUARANTINE_ACTION" in
+  move)
+    target="$dest/${f#${HOST_ROOT}/}"
+    if [ -e "$target" ] || [ -L "$target" ]; then
+      fail "quarantine target already exists"
+    elif out=$(mkdir -p -- "$(dirname -- "$target")" 2>&1) && out=$(mv -- "$f" "$target" 2>&1) &&
+      out=$(chmod 000 "$target" 2>&1); then
+      printf 'QUARANTINE_RESULT\tquarantined\t%s\t%s\n' "$f" "${target#${HOST_ROOT}}"
+    else
+      fail "$out"
+    fi
+    ;;
+  delete)
+    if out=$(rm -f -- "$f" 2>&1) && [ ! -e "$f" ]; then
+      printf 'QUARANTINE_RESULT\tdeleted\t%s\t\n' "$f"
+    else
+      fail "$out"
+    fi
+    ;;
+  esac
+done < "$QUARANTINE_FILES"
+`
+
+// quarantineEnabled returns true if the policy requires infected files to be moved or deleted
+func quarantineEnabled(scanPolicy *clamavv1alpha1.ScanPolicy) bool {
+	if scanPolicy == nil || scanPolicy.Spec.Quarantine == nil || !scanPolicy.Spec.Quarantine.Enabled {
+		return false
+	}
+	action := scanPolicy.Spec.Quarantine.Action
+	return action == clamavv1alpha1.QuarantineActionMove || action == clamavv1alpha1.QuarantineActionDelete
+}
+
+// newPendingQuarantine returns the status of a quarantine required by the
+// policy when a scan completes
+func newPendingQuarantine(config *clamavv1alpha1.QuarantineConfig) *clamavv1alpha1.QuarantineStatus {
+	quarantineDir := config.QuarantineDir
+	if quarantineDir == "" {
+		quarantineDir = DefaultQuarantineDir
+	}
+	return &clamavv1alpha1.QuarantineStatus{
+		Action:        config.Action,
+		Phase:         clamavv1alpha1.QuarantinePhasePending,
+		QuarantineDir: quarantineDir,
+	}
+}
+
+// quarantineInProgress returns true if the quarantine has yet to start or finish
+func quarantineInProgress(status *clamavv1alpha1.QuarantineStatus) bool {
+	return status != nil &&
+		(status.Phase == clamavv1alpha1.QuarantinePhasePending || status.Phase == clamavv1alpha1.QuarantinePhaseRunning)
+}
+
+// quarantineCandidates returns the infected files the quarantine Job may act on.
+// Only paths under the host root mount are accepted so the Job cannot touch
+// anything outside the node filesystem, and the list fits in quarantineFilesBytes.
+func quarantineCandidates(infectedFiles []clamavv1alpha1.InfectedFile) []string {
+	seen := make(map[string]bool)
+	var files []string
+	size := 0
+	for _, f := range infectedFiles {
+		p := path.Clean(f.Path)
+		if !strings.HasPrefix(p, hostRootMount+"/") || strings.ContainsAny(p, "\n\t") || seen[p] {
+			continue
+		}
+		if size += len(p) + 1; size > quarantineFilesBytes {
+			break
+		}
+		seen[p] = true
+		files = append(files, p)
+	}
+	return files
+}
+
+// quarantineJobName returns the name of the quarantine Job for a NodeScan
+func quarantineJobName(nodeScan *clamavv1alpha1.NodeScan) string {
+	jobName := fmt.Sprintf("quarantine-%s", nodeScan.Name)
+	if len(jobName) > 63 {
+		jobName = jobName[:63]
+	}
+	return jobName
+}
+
+// quarantineFilesConfigMapName returns the name of the ConfigMap holding the
+// files of the quarantine Job of a NodeScan
+func quarantineFilesConfigMapName(nodeScan *clamavv1alpha1.NodeScan) string {
+	return quarantineJobName(nodeScan) + "-files"
+}
+
+// ensureQuarantineFiles stores the files of the quarantine Job in a ConfigMap
+// owned by the NodeScan. The list can exceed what fits in the Pod object.
+func (r *NodeScanReconciler) ensureQuarantineFiles(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, files []string) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      quarantineFilesConfigMapName(nodeScan),
+			Namespace: nodeScan.Namespace,
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = map[string]string{
+			"app.kubernetes.io/name":      "clamav",
+			"app.kubernetes.io/component": "quarantine",
+			"clamav.io/nodescan":          nodeScan.Name,
+			"clamav.io/node":              nodeScan.Spec.NodeName,
+		}
+		configMap.Data = map[string]string{quarantineFilesKey: strings.Join(files, "\n") + "\n"}
+		return controllerutil.SetControllerReference(nodeScan, configMap, r.Scheme)
+	})
+	return err
+}
+
+// reconcileQuarantine applies the quarantine action recorded when the NodeScan
+// completed to its infected files and records each file's outcome in the
+// NodeScan status
+func (r *NodeScanReconciler) reconcileQuarantine(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, scanPolicy *clamavv1alpha1.ScanPolicy) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	status := nodeScan.Status.Quarantine
+	if !quarantineInProgress(status) {
+		return ctrl.Result{}, nil
+	}
+
+	// The status only lists the first infected files, the ScanReports list them all
+	infectedFiles, err := r.reportedInfectedFiles(ctx, nodeScan)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	files := quarantineCandidates(infectedFiles)
+
+	// Files missing from the findings, or outside the node filesystem, are left in place
+	untouched := nodeScan.Status.FilesInfected - int64(len(files))
+	if untouched < 0 {
+		untouched = 0
+	}
+
+	if len(files) == 0 {
+		now := metav1.Now()
+		status.CompletionTime = &now
+		status.FilesUntouched = untouched
+		if untouched == 0 {
+			status.Phase = clamavv1alpha1.QuarantinePhaseCompleted
+		} else {
+			status.Phase = clamavv1alpha1.QuarantinePhaseFailed
+			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "QuarantineFailed",
+				fmt.Sprintf("None of the %d infected files are listed in the scan findings, they were left in place", untouched))
+		}
+		if err := r.Status().Update(ctx, nodeScan); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	var job batchv1.Job
+	err = r.Get(ctx, types.NamespacedName{Name: quarantineJobName(nodeScan), Namespace: nodeScan.Namespace}, &job)
+	if errors.IsNotFound(err) {
+		if err := r.ensureQuarantineFiles(ctx, nodeScan, files); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to store the quarantine files: %w", err)
+		}
+
+		newJob, err := r.constructQuarantineJob(nodeScan, status.Action, status.QuarantineDir)
+		if err != nil {
+			log.Error(err, "unable to construct quarantine job")
+			return ctrl.Result{}, err
+		}
+
+		if err := r.Create(ctx, newJob); err != nil {
+			log.Error(err, "unable to create quarantine Job", "job", newJob.Name)
+			r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "QuarantineFailed",
+				fmt.Sprintf("Failed to create quarantine Job: %v", err))
+			return ctrl.Result{}, err
+		}
+
+		status.Phase = clamavv1alpha1.QuarantinePhaseRunning
+		status.JobRef = &corev1.ObjectReference{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+			Name:       newJob.Name,
+			Namespace:  newJob.Namespace,
+			UID:        newJob.UID,
+		}
+
+		r.Recorder.Event(nodeScan, corev1.EventTypeNormal, "QuarantineStarted",
+			fmt.Sprintf("Quarantine job created to %s %d infected files", status.Action, len(files)))
+
+		if err := r.Status().Update(ctx, nodeScan); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if job.Status.Succeeded == 0 && job.Status.Failed == 0 {
+		// Quarantine Job is still running
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	results, err := r.parseQuarantineResults(ctx, &job)
+	if err != nil && job.Status.Succeeded > 0 {
+		log.Error(err, "failed to read quarantine results, will retry")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	applyQuarantineResults(status, files, results)
+	status.FilesUntouched = untouched
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	if job.Status.Succeeded > 0 && untouched == 0 {
+		status.Phase = clamavv1alpha1.QuarantinePhaseCompleted
+	} else {
+		status.Phase = clamavv1alpha1.QuarantinePhaseFailed
+	}
+
+	recordQuarantineMetrics(nodeScan)
+
+	if status.FilesFailed > 0 || status.Phase == clamavv1alpha1.QuarantinePhaseFailed {
+		r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "QuarantineFailed",
+			fmt.Sprintf("Quarantine finished with %d failures and %d files left untouched (%d quarantined, %d deleted)",
+				status.FilesFailed, status.FilesUntouched, status.FilesQuarantined, status.FilesDeleted))
+	} else {
+		r.Recorder.Event(nodeScan, corev1.EventTypeNormal, "QuarantineCompleted",
+			fmt.Sprintf("Quarantine completed: %d quarantined, %d deleted",
+				status.FilesQuarantined, status.FilesDeleted))
+	}
+
+	if scanPolicy != nil && scanPolicy.Spec.Quarantine != nil && scanPolicy.Spec.Quarantine.NotifyAdmin {
+		enqueueNotifications(nodeScan, scanPolicy, clamavv1alpha1.NotificationEventQuarantineCompleted)
+	}
+
+	if err := r.Status().Update(ctx, nodeScan); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// applyQuarantineResults records the outcome of every candidate file in the status.
+// Files the Job did not report on are marked as failed.
+func applyQuarantineResults(status *clamavv1alpha1.QuarantineStatus, files []string, results map[string]clamavv1alpha1.QuarantinedFile) {
+	status.Files = make([]clamavv1alpha1.QuarantinedFile, 0, len(files))
+	status.FilesQuarantined = 0
+	status.FilesDeleted = 0
+	status.FilesFailed = 0
+
+	for _, f := range files {
+		result, ok := results[f]
+		if !ok {
+			result = clamavv1alpha1.QuarantinedFile{
+				Path:    f,
+				Outcome: clamavv1alpha1.QuarantineOutcomeFailed,
+				Message: "no result reported by quarantine job",
+			}
+		}
+
+		switch result.Outcome {
+		case clamavv1alpha1.QuarantineOutcomeQuarantined:
+			status.FilesQuarantined++
+		case clamavv1alpha1.QuarantineOutcomeDeleted:
+			status.FilesDeleted++
+		default:
+			status.FilesFailed++
+		}
+		status.Files = append(status.Files, result)
+	}
+}
+
+// parseQuarantineResults reads the result lines printed by the quarantine Job
+func (r *NodeScanReconciler) parseQuarantineResults(ctx context.Context, job *batchv1.Job) (map[string]clamavv1alpha1.QuarantinedFile, error) {
+	if job.Spec.Selector == nil {
+		return nil, fmt.Errorf("job %s has no pod selector", job.Name)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels(job.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+
+	if len(podList.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for job")
+	}
+
+	results := make(map[string]clamavv1alpha1.QuarantinedFile)
+	var lastErr error
+
+	// A retried Job leaves several pods behind; merge what each of them reported
+	for _, pod := range podList.Items {
+		req := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: "quarantine",
+		})
+
+		stream, err := req.Stream(ctx)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get pod logs: %w", err)
+			continue
+		}
+
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			fields := strings.SplitN(scanner.Text(), "\t", 4)
+			if len(fields) < 3 || fields[0] != quarantineResultMarker {
+				continue
+			}
+
+			result := clamavv1alpha1.QuarantinedFile{
+				Path:    fields[2],
+				Outcome: fields[1],
+			}
+			if len(fields) == 4 {
+				if result.Outcome == clamavv1alpha1.QuarantineOutcomeQuarantined {
+					result.QuarantinePath = fields[3]
+				} else {
+					result.Message = strings.TrimSpace(fields[3])
+				}
+			}
+
+			// Keep a success reported by any attempt
+			if prev, ok := results[result.Path]; ok && prev.Outcome != clamavv1alpha1.QuarantineOutcomeFailed {
+				continue
+			}
+			results[result.Path] = result
+		}
+		if err := scanner.Err(); err != nil {
+			lastErr = fmt.Errorf("error reading logs: %w", err)
+		}
+		stream.Close()
+	}
+
+	if len(results) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return results, nil
+}
+
+// constructQuarantineJob creates a Job that moves or deletes infected files on the node
+func (r *NodeScanReconciler) constructQuarantineJob(nodeScan *clamavv1alpha1.NodeScan, action, quarantineDir string) (*batchv1.Job, error) {
+	envVars := []corev1.EnvVar{
+		{Name: "NODE_NAME", Value: nodeScan.Spec.NodeName},
+		{Name: "NODESCAN_NAME", Value: nodeScan.Name},
+		{Name: "HOST_ROOT", Value: hostRootMount},
+		{Name: "QUARANTINE_ACTION", Value: action},
+		{Name: "QUARANTINE_DIR", Value: quarantineDir},
+		{Name: "QUARANTINE_FILES", Value: path.Join(quarantineFilesMount, quarantineFilesKey)},
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      quarantineJobName(nodeScan),
+			Namespace: nodeScan.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":      "clamav",
+				"app.kubernetes.io/component": "quarantine",
+				"clamav.io/nodescan":          nodeScan.Name,
+				"clamav.io/node":              nodeScan.Spec.NodeName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(2)),
+			TTLSecondsAfterFinished: ptr.To(int32(DefaultTTLSecondsAfterFinished)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":         "clamav-quarantine",
+						"target-node": nodeScan.Spec.NodeName,
+						"security":    "clamav",
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: "clamav-scanner",
+					NodeName:           nodeScan.Spec.NodeName,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot: ptr.To(false),
+						RunAsUser:    ptr.To(int64(0)),
+					},
+					Tolerations: []corev1.Toleration{
+						{Operator: corev1.TolerationOpExists},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "quarantine",
+							Image:           r.ScannerImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", quarantineScript},
+							Env:             envVars,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "host-root",
+									MountPath: hostRootMount,
+								},
+								{
+									Name:      "quarantine-files",
+									MountPath: quarantineFilesMount,
+									ReadOnly:  true,
+								},
+							},
+							Resources: LowPriorityScannerResources,
+							SecurityContext: &corev1.SecurityContext{
+								Privileged:             ptr.To(true),
+								ReadOnlyRootFilesystem: ptr.To(true),
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "host-root",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/",
+									Type: ptr.To(corev1.HostPathDirectory),
+								},
+							},
+						},
+						{
+							Name: "quarantine-files",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: quarantineFilesConfigMapName(nodeScan)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The infected files of workload scans are in container root filesystems,
+	// which are only visible through the same propagation as the scan Job
+	if nodeScan.Spec.Workloads != nil {
+		job.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPropagation = ptr.To(corev1.MountPropagationHostToContainer)
+	}
+
+	// Set NodeScan as owner
+	if err := controllerutil.SetControllerReference(nodeScan, job, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,383 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newQuarantineTestObjects(action string) (*corev1.Node, *clamavv1alpha1.ScanPolicy, *clamavv1alpha1.NodeScan, *batchv1.Job) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+
+	scanPolicy := &clamavv1alpha1.ScanPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-policy",
+			Namespace: "default",
+		},
+		Spec: clamavv1alpha1.ScanPolicySpec{
+			Paths: []string{"/host/var/lib"},
+			Quarantine: &clamavv1alpha1.QuarantineConfig{
+				Enabled:       true,
+				Action:        action,
+				QuarantineDir: "/var/quarantine",
+			},
+		},
+	}
+
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-scan",
+			Namespace:  "default",
+			Finalizers: []string{nodeScanFinalizer},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName:   "test-node",
+			ScanPolicy: "test-policy",
+		},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase:         clamavv1alpha1.NodeScanPhaseCompleted,
+			FilesInfected: 2,
+			InfectedFiles: []clamavv1alpha1.InfectedFile{
+				{Path: "/host/var/lib/app/eicar.com", Viruses: []string{"Eicar-Test-Signature"}},
+				{Path: "/host/opt/tool/bad.bin", Viruses: []string{"Win.Trojan.Agent"}},
+			},
+		},
+	}
+
+	// The scan completed while the policy required a quarantine
+	if quarantineEnabled(scanPolicy) {
+		nodeScan.Status.Quarantine = newPendingQuarantine(scanPolicy.Spec.Quarantine)
+	}
+
+	scanJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan",
+			Namespace: "default",
+		},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+
+	return node, scanPolicy, nodeScan, scanJob
+}
+
+func TestQuarantineCandidates(t *testing.T) {
+	infectedFiles := []clamavv1alpha1.InfectedFile{
+		{Path: "/host/var/lib/a"},
+		{Path: "/host/var/lib/a"},
+		{Path: "/host/var/../../etc/shadow"},
+		{Path: "/etc/passwd"},
+		{Path: "/host/opt/b"},
+	}
+
+	assert.Equal(t, []string{"/host/var/lib/a", "/host/opt/b"}, quarantineCandidates(infectedFiles))
+
+	// The list is cut to fit in its ConfigMap
+	var many []clamavv1alpha1.InfectedFile
+	for i := 0; i < 20000; i++ {
+		many = append(many, clamavv1alpha1.InfectedFile{Path: fmt.Sprintf("/host/var/lib/app/%064d", i)})
+	}
+	files := quarantineCandidates(many)
+	assert.Less(t, len(files), len(many))
+	assert.LessOrEqual(t, len(strings.Join(files, "\n"))+1, quarantineFilesBytes)
+}
+
+func TestApplyQuarantineResults(t *testing.T) {
+	status := &clamavv1alpha1.QuarantineStatus{Action: clamavv1alpha1.QuarantineActionMove}
+	results := map[string]clamavv1alpha1.QuarantinedFile{
+		"/host/a": {Path: "/host/a", Outcome: clamavv1alpha1.QuarantineOutcomeQuarantined, QuarantinePath: "/q/a"},
+		"/host/b": {Path: "/host/b", Outcome: clamavv1alpha1.QuarantineOutcomeFailed, Message: "permission denied"},
+	}
+
+	applyQuarantineResults(status, []string{"/host/a", "/host/b", "/host/c"}, results)
+
+	assert.Equal(t, int64(1), status.FilesQuarantined)
+	assert.Equal(t, int64(0), status.FilesDeleted)
+	assert.Equal(t, int64(2), status.FilesFailed)
+	require.Len(t, status.Files, 3)
+	assert.Equal(t, "/q/a", status.Files[0].QuarantinePath)
+	assert.Equal(t, clamavv1alpha1.QuarantineOutcomeFailed, status.Files[2].Outcome)
+}
+
+func TestNodeScanReconciler_Reconcile_CreatesQuarantineJob(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionMove)
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "quarantine-test-scan",
+		Namespace: "default",
+	}, &job))
+	assert.Equal(t, "test-node", job.Spec.Template.Spec.NodeName)
+
+	env := map[string]string{}
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "move", env["QUARANTINE_ACTION"])
+	assert.Equal(t, "/var/quarantine", env["QUARANTINE_DIR"])
+	assert.Equal(t, "/quarantine/files", env["QUARANTINE_FILES"])
+
+	// The files are mounted from a ConfigMap, they may not fit in the Pod
+	var files corev1.ConfigMap
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "quarantine-test-scan-files",
+		Namespace: "default",
+	}, &files))
+	assert.Equal(t, "/host/var/lib/app/eicar.com\n/host/opt/tool/bad.bin\n", files.Data[quarantineFilesKey])
+	require.Len(t, files.OwnerReferences, 1)
+	assert.Equal(t, "NodeScan", files.OwnerReferences[0].Kind)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	require.NotNil(t, updated.Status.Quarantine)
+	assert.Equal(t, clamavv1alpha1.QuarantinePhaseRunning, updated.Status.Quarantine.Phase)
+	assert.Equal(t, "quarantine-test-scan", updated.Status.Quarantine.JobRef.Name)
+}
+
+func TestConstructQuarantineJob_WorkloadMountPropagation(t *testing.T) {
+	_, _, nodeScan, _ := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionMove)
+	r := newTestNodeScanReconciler()
+
+	job, err := r.constructQuarantineJob(nodeScan, clamavv1alpha1.QuarantineActionMove, "/var/quarantine")
+	require.NoError(t, err)
+	mount := job.Spec.Template.Spec.Containers[0].VolumeMounts[0]
+	assert.Equal(t, "host-root", mount.Name)
+	assert.Nil(t, mount.MountPropagation)
+
+	// Container root filesystems are mounted after the Job starts
+	nodeScan.Spec.Workloads = &clamavv1alpha1.WorkloadTarget{Namespaces: []string{"default"}}
+	job, err = r.constructQuarantineJob(nodeScan, clamavv1alpha1.QuarantineActionMove, "/var/quarantine")
+	require.NoError(t, err)
+	mount = job.Spec.Template.Spec.Containers[0].VolumeMounts[0]
+	require.NotNil(t, mount.MountPropagation)
+	assert.Equal(t, corev1.MountPropagationHostToContainer, *mount.MountPropagation)
+}
+
+func TestNodeScanReconciler_Reconcile_QuarantinesFilesOfScanReports(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionMove)
+	// The status only lists the first infected file, the ScanReports list all three
+	nodeScan.UID = "test-scan-uid"
+	nodeScan.Status.FilesInfected = 3
+	nodeScan.Status.InfectedFiles = nodeScan.Status.InfectedFiles[:1]
+	nodeScan.Status.Report = &clamavv1alpha1.ScanReportReference{
+		Names:         []string{"test-scan-report-0", "test-scan-report-1"},
+		InfectedFiles: 3,
+	}
+	newReport := func(chunk int32, paths ...string) *clamavv1alpha1.ScanReport {
+		report := &clamavv1alpha1.ScanReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      scanReportName(nodeScan, int(chunk)),
+				Namespace: "default",
+				Labels:    map[string]string{"clamav.io/nodescan": "test-scan"},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "clamav.io/v1alpha1",
+					Kind:       "NodeScan",
+					Name:       "test-scan",
+					UID:        "test-scan-uid",
+					Controller: ptr.To(true),
+				}},
+			},
+			Spec: clamavv1alpha1.ScanReportSpec{NodeScan: "test-scan", Chunk: chunk, TotalChunks: 2},
+		}
+		for _, p := range paths {
+			report.Spec.InfectedFiles = append(report.Spec.InfectedFiles, clamavv1alpha1.InfectedFile{Path: p})
+		}
+		return report
+	}
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob,
+		newReport(1, "/host/srv/c"), newReport(0, "/host/var/lib/app/eicar.com", "/host/opt/tool/bad.bin"))
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "quarantine-test-scan",
+		Namespace: "default",
+	}, &job))
+	var files corev1.ConfigMap
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "quarantine-test-scan-files",
+		Namespace: "default",
+	}, &files))
+	assert.Equal(t, "/host/var/lib/app/eicar.com\n/host/opt/tool/bad.bin\n/host/srv/c\n", files.Data[quarantineFilesKey])
+}
+
+func TestNodeScanReconciler_Reconcile_QuarantinePendingOnCompletion(t *testing.T) {
+	node, nodeScan, job := newScanResultTestObjects()
+	nodeScan.Spec.ScanPolicy = "test-policy"
+	_, scanPolicy, _, _ := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionDelete)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nodescan-test-scan-result", Namespace: "default"},
+		BinaryData: map[string][]byte{scanResultKey: encodeTestScanResult(t, newTestScanResult())},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan, job, configMap, scanPolicy)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	require.NotNil(t, updated.Status.Quarantine)
+	assert.Equal(t, clamavv1alpha1.QuarantinePhaseRunning, updated.Status.Quarantine.Phase)
+	assert.Equal(t, clamavv1alpha1.QuarantineActionDelete, updated.Status.Quarantine.Action)
+	var quarantineJob batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "quarantine-test-scan", Namespace: "default"}, &quarantineJob))
+}
+
+func TestNodeScanReconciler_Reconcile_PolicyEnabledAfterCompletionSkipsQuarantine(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionDelete)
+	// The scan completed before the policy enabled quarantine
+	nodeScan.Status.Quarantine = nil
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var job batchv1.Job
+	err = r.Get(context.Background(), types.NamespacedName{Name: "quarantine-test-scan", Namespace: "default"}, &job)
+	assert.True(t, errors.IsNotFound(err), "no quarantine job expected for a scan completed before quarantine was enabled")
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Nil(t, updated.Status.Quarantine)
+}
+
+func TestNodeScanReconciler_Reconcile_AlertOnlySkipsQuarantine(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionAlertOnly)
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	var job batchv1.Job
+	err = r.Get(context.Background(), types.NamespacedName{Name: "quarantine-test-scan", Namespace: "default"}, &job)
+	assert.True(t, err != nil, "no quarantine job expected for alert-only")
+}
+
+func TestNodeScanReconciler_Reconcile_QuarantineJobFinished(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionDelete)
+	nodeScan.Status.Quarantine = &clamavv1alpha1.QuarantineStatus{
+		Action: clamavv1alpha1.QuarantineActionDelete,
+		Phase:  clamavv1alpha1.QuarantinePhaseRunning,
+	}
+	quarantineJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quarantine-test-scan",
+			Namespace: "default",
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "quarantine-test-scan"}},
+		},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quarantine-test-scan-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": "quarantine-test-scan"},
+		},
+	}
+
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob, quarantineJob, pod)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	require.NotNil(t, updated.Status.Quarantine)
+	assert.Equal(t, clamavv1alpha1.QuarantinePhaseCompleted, updated.Status.Quarantine.Phase)
+	assert.NotNil(t, updated.Status.Quarantine.CompletionTime)
+	// The fake clientset returns no result lines, so every file is reported as failed
+	assert.Equal(t, int64(2), updated.Status.Quarantine.FilesFailed)
+	assert.Len(t, updated.Status.Quarantine.Files, 2)
+}
+
+func TestNodeScanReconciler_Reconcile_QuarantineReportsUntouchedFiles(t *testing.T) {
+	node, scanPolicy, nodeScan, scanJob := newQuarantineTestObjects(clamavv1alpha1.QuarantineActionDelete)
+	// A truncated result lists fewer files than were found infected
+	nodeScan.Status.FilesInfected = 5
+	nodeScan.Status.Quarantine = &clamavv1alpha1.QuarantineStatus{
+		Action: clamavv1alpha1.QuarantineActionDelete,
+		Phase:  clamavv1alpha1.QuarantinePhaseRunning,
+	}
+	quarantineJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quarantine-test-scan",
+			Namespace: "default",
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "quarantine-test-scan"}},
+		},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quarantine-test-scan-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": "quarantine-test-scan"},
+		},
+	}
+
+	r := newTestNodeScanReconciler(node, scanPolicy, nodeScan, scanJob, quarantineJob, pod)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	require.NotNil(t, updated.Status.Quarantine)
+	assert.Equal(t, clamavv1alpha1.QuarantinePhaseFailed, updated.Status.Quarantine.Phase)
+	assert.Equal(t, int64(3), updated.Status.Quarantine.FilesUntouched)
+}
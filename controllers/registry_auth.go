@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dockerHubRegistry is the registry of image references without a host
+const dockerHubRegistry = "docker.io"
+
+// dockerConfigJSON is the content of a kubernetes.io/dockerconfigjson Secret
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// dockerConfigEntry holds the credentials of one registry
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// imageRegistry returns the registry host of an image reference. The first
+// component of the reference is a host if it contains a dot or a port, or is
+// localhost.
+func imageRegistry(image string) string {
+	host, rest, found := strings.Cut(image, "/")
+	if !found || rest == "" {
+		return dockerHubRegistry
+	}
+	if host != "localhost" && !strings.ContainsAny(host, ".:") {
+		return dockerHubRegistry
+	}
+	return normalizeRegistry(host)
+}
+
+// normalizeRegistry returns the host of a registry key of a Docker config,
+// which may be a URL. The Docker Hub aliases map to docker.io.
+func normalizeRegistry(key string) string {
+	host := key
+	if _, after, found := strings.Cut(host, "://"); found {
+		host = after
+	}
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubRegistry
+	}
+	return host
+}
+
+// registryAuth returns the credentials of the pull secrets for the registry
+// of an image, encoded as the base64 "username:password" string accepted by
+// crictl. It returns an empty string if no secret has credentials for the
+// registry.
+func (r *ImageScanReconciler) registryAuth(ctx context.Context, namespace string, pullSecrets []corev1.LocalObjectReference, image string) (string, error) {
+	registry := imageRegistry(image)
+
+	for _, ref := range pullSecrets {
+		secret, err := r.Clientset.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get image pull secret %s: %w", ref.Name, err)
+		}
+		if secret.Type != corev1.SecretTypeDockerConfigJson {
+			return "", fmt.Errorf("image pull secret %s is not of type %s", ref.Name, corev1.SecretTypeDockerConfigJson)
+		}
+
+		var config dockerConfigJSON
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return "", fmt.Errorf("invalid image pull secret %s: %w", ref.Name, err)
+		}
+		for key, entry := range config.Auths {
+			if normalizeRegistry(key) != registry {
+				continue
+			}
+			if entry.Auth != "" {
+				return entry.Auth, nil
+			}
+			return base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password)), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// scanResultAPIVersion is the version of the result contract between
+	// scanner Jobs and the operator. It must match RESULT_API_VERSION in
+	// scanner/src/result.js.
+	scanResultAPIVersion = "clamav.io/scan-result/v1"
+
+	// scanResultKey is the binaryData key holding the gzip-compressed result
+	scanResultKey = "result.json.gz"
+
+	// maxScanResultBytes bounds the decompressed result size
+	maxScanResultBytes = 64 * 1024 * 1024
+
+	// scanResultsHostPath is the node directory the scanner writes its reports to
+	scanResultsHostPath = "/var/log/clamav-scans"
+)
+
+// Scan result status values
+const (
+	scanResultStatusClean    = "CLEAN"
+	scanResultStatusInfected = "INFECTED"
+)
+
+// scanResult is the versioned, machine-readable result published by the scanner
+type scanResult struct {
+	APIVersion     string                        `json:"apiVersion"`
+	Node           string                        `json:"node"`
+	Status         string                        `json:"status"`
+	Strategy       clamavv1alpha1.ScanStrategy   `json:"strategy,omitempty"`
+	StartTime      *time.Time                    `json:"startTime,omitempty"`
+	CompletionTime *time.Time                    `json:"completionTime,omitempty"`
+	Duration       int64                         `json:"duration"`
+	Truncated      bool                          `json:"truncated,omitempty"`
+	Statistics     scanResultStatistics          `json:"statistics"`
+	Infected       []scanResultInfectedFile      `json:"infected"`
+	Errors         []scanResultError             `json:"errors,omitempty"`
+	Skipped        []scanResultSkippedFile       `json:"skipped,omitempty"`
+	ReportFile     string                        `json:"reportFile,omitempty"`
+	CacheEntries   []clamavv1alpha1.FileMetadata `json:"cacheEntries,omitempty"`
+}
+
+// scanResultStatistics holds the counters of a scan result
+type scanResultStatistics struct {
+	FilesScanned            int64 `json:"filesScanned"`
+	FilesInfected           int64 `json:"filesInfected"`
+	FilesSkipped            int64 `json:"filesSkipped"`
+	FilesSkippedIncremental int64 `json:"filesSkippedIncremental"`
+	Errors                  int64 `json:"errors"`
+}
+
+// scanResultInfectedFile describes an infected file in a scan result
+type scanResultInfectedFile struct {
+	Path    string   `json:"path"`
+	Viruses []string `json:"viruses"`
+	Size    int64    `json:"size,omitempty"`
+	// Attribution is resolved by the operator, not published by scanners
+	Attribution *clamavv1alpha1.FileAttribution `json:"-"`
+}
+
+// scanResultError describes a file that could not be scanned
+type scanResultError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// scanResultSkippedFile describes a file that was not scanned
+type scanResultSkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// validate checks the result against the v1 contract
+func (r *scanResult) validate(nodeName string) error {
+	if r.APIVersion != scanResultAPIVersion {
+		return fmt.Errorf("unsupported scan result apiVersion %q (expected %q)", r.APIVersion, scanResultAPIVersion)
+	}
+	if r.Node != nodeName {
+		return fmt.Errorf("scan result is for node %q, expected %q", r.Node, nodeName)
+	}
+
+	switch r.Strategy {
+	case "", clamavv1alpha1.ScanStrategyFull, clamavv1alpha1.ScanStrategyIncremental,
+		clamavv1alpha1.ScanStrategyModifiedOnly, clamavv1alpha1.ScanStrategySmart:
+	default:
+		return fmt.Errorf("invalid strategy %q", r.Strategy)
+	}
+
+	stats := r.Statistics
+	if stats.FilesScanned < 0 || stats.FilesInfected < 0 || stats.FilesSkipped < 0 ||
+		stats.FilesSkippedIncremental < 0 || stats.Errors < 0 || r.Duration < 0 {
+		return fmt.Errorf("statistics must not be negative")
+	}
+
+	switch r.Status {
+	case scanResultStatusClean:
+		if stats.FilesInfected != 0 || len(r.Infected) != 0 {
+			return fmt.Errorf("status %s with %d infected files", r.Status, stats.FilesInfected)
+		}
+	case scanResultStatusInfected:
+		if stats.FilesInfected == 0 {
+			return fmt.Errorf("status %s without infected files", r.Status)
+		}
+	default:
+		return fmt.Errorf("invalid status %q", r.Status)
+	}
+
+	if int64(len(r.Infected)) > stats.FilesInfected {
+		return fmt.Errorf("%d infected files listed but filesInfected is %d", len(r.Infected), stats.FilesInfected)
+	}
+	if !r.Truncated && int64(len(r.Infected)) != stats.FilesInfected {
+		return fmt.Errorf("%d infected files listed but filesInfected is %d", len(r.Infected), stats.FilesInfected)
+	}
+	for i, f := range r.Infected {
+		if f.Path == "" {
+			return fmt.Errorf("infected[%d]: path is required", i)
+		}
+		if len(f.Viruses) == 0 {
+			return fmt.Errorf("infected[%d]: at least one virus name is required", i)
+		}
+	}
+	for i, e := range r.Errors {
+		if e.Path == "" {
+			return fmt.Errorf("errors[%d]: path is required", i)
+		}
+	}
+	for i, f := range r.Skipped {
+		if f.Path == "" || f.Reason == "" {
+			return fmt.Errorf("skipped[%d]: path and reason are required", i)
+		}
+	}
+	if r.ReportFile != "" && (strings.Contains(r.ReportFile, "/") || strings.HasPrefix(r.ReportFile, ".")) {
+		return fmt.Errorf("reportFile must be a plain file name")
+	}
+	for i, c := range r.CacheEntries {
+		if c.Path == "" {
+			return fmt.Errorf("cacheEntries[%d]: path is required", i)
+		}
+	}
+
+	return nil
+}
+
+// decodeScanResult decompresses and decodes a published scan result
+func decodeScanResult(data []byte) (*scanResult, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan result encoding: %w", err)
+	}
+	defer gz.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(gz, maxScanResultBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan result encoding: %w", err)
+	}
+	if len(raw) > maxScanResultBytes {
+		return nil, fmt.Errorf("scan result exceeds %d bytes", maxScanResultBytes)
+	}
+
+	var result scanResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid scan result: %w", err)
+	}
+	return &result, nil
+}
+
+// scanResultConfigMapName returns the name of the ConfigMap receiving the scan result
+func scanResultConfigMapName(nodeScan *clamavv1alpha1.NodeScan) string {
+	return fmt.Sprintf("nodescan-%s-result", nodeScan.Name)
+}
+
+// jobResultConfigMapName returns the name of the ConfigMap receiving the scan
+// result of the Job of an ImageScan or VolumeScan
+func jobResultConfigMapName(jobName string) string {
+	return jobName + "-result"
+}
+
+// ensureResultConfigMap creates the empty ConfigMap, owned by the scan, that
+// the scanner publishes its result to
+func ensureResultConfigMap(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object,
+	name string, labels map[string]string) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels:    labels,
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(owner, configMap, scheme); err != nil {
+		return err
+	}
+
+	if err := c.Create(ctx, configMap); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// ensureScanResultConfigMap creates the empty ConfigMap the scanner publishes its result to
+func (r *NodeScanReconciler) ensureScanResultConfigMap(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) error {
+	return ensureResultConfigMap(ctx, r.Client, r.Scheme, nodeScan, scanResultConfigMapName(nodeScan), map[string]string{
+		"app.kubernetes.io/name":      "clamav",
+		"app.kubernetes.io/component": "scan-result",
+		"clamav.io/nodescan":          nodeScan.Name,
+		"clamav.io/node":              nodeScan.Spec.NodeName,
+	})
+}
+
+// readScanResult returns the result published by the scanner, or nil if none was published
+func (r *NodeScanReconciler) readScanResult(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) (*scanResult, error) {
+	return readPublishedScanResult(ctx, r.Client, nodeScan.Namespace, scanResultConfigMapName(nodeScan), nodeScan.Spec.NodeName)
+}
+
+// applyScanResult updates the NodeScan status from a scan result
+func applyScanResult(nodeScan *clamavv1alpha1.NodeScan, result *scanResult) {
+	stats := result.Statistics
+	nodeScan.Status.FilesScanned = stats.FilesScanned
+	nodeScan.Status.FilesInfected = stats.FilesInfected
+	nodeScan.Status.FilesSkipped = stats.FilesSkipped
+	nodeScan.Status.FilesSkippedIncremental = stats.FilesSkippedIncremental
+	nodeScan.Status.ErrorCount = stats.Errors
+	if result.Strategy != "" {
+		// The scanner reports the strategy it actually applied (e.g. for "smart")
+		nodeScan.Status.StrategyUsed = result.Strategy
+	}
+	if result.ReportFile != "" {
+		nodeScan.Status.ReportPath = path.Join(scanResultsHostPath, result.ReportFile)
+	}
+
+	infectedFiles := make([]clamavv1alpha1.InfectedFile, 0, len(result.Infected))
+	for _, f := range result.Infected {
+		infectedFiles = append(infectedFiles, clamavv1alpha1.InfectedFile{
+			Path:        f.Path,
+			Viruses:     f.Viruses,
+			Size:        f.Size,
+			Attribution: f.Attribution,
+		})
+	}
+
+	// Limit to 100 infected files for performance
+	if len(infectedFiles) > 100 {
+		infectedFiles = infectedFiles[:100]
+	}
+	nodeScan.Status.InfectedFiles = infectedFiles
+}
+
+// collectScanResults reads the scan result published by the scanner and falls
+// back to parsing the pod logs for scanner images that predate the result contract
+func (r *NodeScanReconciler) collectScanResults(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, job *batchv1.Job) (*scanResult, error) {
+	log := log.FromContext(ctx)
+
+	result, err := r.readScanResult(ctx, nodeScan)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		log.Info("No scan result published, falling back to pod logs", "job", job.Name)
+		return r.parseJobResults(ctx, nodeScan, job)
+	}
+
+	if result.Truncated {
+		r.Recorder.Event(nodeScan, corev1.EventTypeWarning, "ScanResultTruncated",
+			"Scan result exceeded the ConfigMap size limit and was truncated by the scanner")
+	}
+
+	return result, nil
+}
+
+// readPublishedScanResult returns the result published to a ConfigMap by the
+// scanner of a Job that ran on nodeName, or nil if none was published
+func readPublishedScanResult(ctx context.Context, c client.Reader, namespace, name, nodeName string) (*scanResult, error) {
+	var configMap corev1.ConfigMap
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &configMap)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	data, ok := configMap.BinaryData[scanResultKey]
+	if !ok {
+		return nil, nil
+	}
+
+	result, err := decodeScanResult(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.validate(nodeName); err != nil {
+		return nil, fmt.Errorf("scan result failed validation: %w", err)
+	}
+	return result, nil
+}
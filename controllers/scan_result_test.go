@@ -0,0 +1,231 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestScanResult() *scanResult {
+	return &scanResult{
+		APIVersion: scanResultAPIVersion,
+		Node:       "test-node",
+		Status:     scanResultStatusInfected,
+		Strategy:   clamavv1alpha1.ScanStrategyIncremental,
+		Duration:   12,
+		Statistics: scanResultStatistics{
+			FilesScanned:            40,
+			FilesInfected:           1,
+			FilesSkipped:            2,
+			FilesSkippedIncremental: 60,
+			Errors:                  1,
+		},
+		Infected: []scanResultInfectedFile{
+			{Path: "/host/var/lib/eicar.com", Viruses: []string{"Eicar-Test-Signature"}, Size: 68},
+		},
+		Errors: []scanResultError{{Path: "/host/var/lib/locked", Error: "EACCES"}},
+		CacheEntries: []clamavv1alpha1.FileMetadata{
+			{Path: "/host/var/lib/eicar.com", ModTime: 1, Size: 68, LastScanned: 2, ScanResult: "infected"},
+		},
+	}
+}
+
+func encodeTestScanResult(t *testing.T, result *scanResult) []byte {
+	t.Helper()
+
+	raw, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err = gz.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestScanResult_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(r *scanResult)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(r *scanResult) {}},
+		{name: "unsupported version", mutate: func(r *scanResult) { r.APIVersion = "clamav.io/scan-result/v2" }, wantErr: true},
+		{name: "other node", mutate: func(r *scanResult) { r.Node = "other-node" }, wantErr: true},
+		{name: "invalid status", mutate: func(r *scanResult) { r.Status = "UNKNOWN" }, wantErr: true},
+		{name: "invalid strategy", mutate: func(r *scanResult) { r.Strategy = "fast" }, wantErr: true},
+		{name: "negative counter", mutate: func(r *scanResult) { r.Statistics.FilesScanned = -1 }, wantErr: true},
+		{name: "clean with infected files", mutate: func(r *scanResult) { r.Status = scanResultStatusClean }, wantErr: true},
+		{name: "infected count mismatch", mutate: func(r *scanResult) { r.Statistics.FilesInfected = 3 }, wantErr: true},
+		{name: "truncated infected list", mutate: func(r *scanResult) {
+			r.Statistics.FilesInfected = 3
+			r.Truncated = true
+		}},
+		{name: "infected file without virus", mutate: func(r *scanResult) { r.Infected[0].Viruses = nil }, wantErr: true},
+		{name: "error without path", mutate: func(r *scanResult) { r.Errors[0].Path = "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newTestScanResult()
+			tt.mutate(result)
+
+			err := result.validate("test-node")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecodeScanResult_InvalidEncoding(t *testing.T) {
+	_, err := decodeScanResult([]byte("not gzip"))
+	assert.Error(t, err)
+}
+
+func newScanResultTestObjects() (*corev1.Node, *clamavv1alpha1.NodeScan, *batchv1.Job) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-scan",
+			Namespace:  "default",
+			Finalizers: []string{nodeScanFinalizer},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{NodeName: "test-node"},
+		Status: clamavv1alpha1.NodeScanStatus{
+			Phase: clamavv1alpha1.NodeScanPhaseRunning,
+		},
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan",
+			Namespace: "default",
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "nodescan-test-scan"}},
+		},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+
+	return node, nodeScan, job
+}
+
+func TestNodeScanReconciler_Reconcile_CreatesScanResultConfigMap(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-scan",
+			Namespace:  "default",
+			Finalizers: []string{nodeScanFinalizer},
+		},
+		Spec: clamavv1alpha1.NodeScanSpec{NodeName: "test-node"},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan)
+
+	job, _ := reconcileIncrementalJob(t, r)
+
+	assert.Equal(t, "nodescan-test-scan-result", jobEnv(job)["RESULT_CONFIGMAP"])
+
+	var configMap corev1.ConfigMap
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{
+		Name:      "nodescan-test-scan-result",
+		Namespace: "default",
+	}, &configMap))
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, "NodeScan", configMap.OwnerReferences[0].Kind)
+}
+
+func TestNodeScanReconciler_Reconcile_UsesPublishedScanResult(t *testing.T) {
+	node, nodeScan, job := newScanResultTestObjects()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan-result",
+			Namespace: "default",
+		},
+		BinaryData: map[string][]byte{
+			scanResultKey: encodeTestScanResult(t, newTestScanResult()),
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": "nodescan-test-scan"},
+		},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan, job, configMap, pod)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseCompleted, updated.Status.Phase)
+	assert.Equal(t, int64(40), updated.Status.FilesScanned)
+	assert.Equal(t, int64(1), updated.Status.FilesInfected)
+	assert.Equal(t, int64(60), updated.Status.FilesSkippedIncremental)
+	assert.Equal(t, clamavv1alpha1.ScanStrategyIncremental, updated.Status.StrategyUsed)
+	require.Len(t, updated.Status.InfectedFiles, 1)
+	assert.Equal(t, "/host/var/lib/eicar.com", updated.Status.InfectedFiles[0].Path)
+}
+
+func TestNodeScanReconciler_Reconcile_InvalidScanResultIsRetried(t *testing.T) {
+	node, nodeScan, job := newScanResultTestObjects()
+	result := newTestScanResult()
+	result.APIVersion = "clamav.io/scan-result/v0"
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan-result",
+			Namespace: "default",
+		},
+		BinaryData: map[string][]byte{
+			scanResultKey: encodeTestScanResult(t, result),
+		},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan, job, configMap)
+
+	res, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, res.RequeueAfter)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Equal(t, "1", updated.Annotations[parseRetryAnnotation])
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// scanReportChunkBytes is the approximate size budget of a single ScanReport,
+	// well below the 1.5 MiB etcd object limit
+	scanReportChunkBytes = 512 * 1024
+
+	// scanReportChunkLabel holds the chunk index of a ScanReport
+	scanReportChunkLabel = "clamav.io/scanreport-chunk"
+)
+
+// scanReportName returns the name of a ScanReport chunk
+func scanReportName(nodeScan *clamavv1alpha1.NodeScan, chunk int) string {
+	return fmt.Sprintf("%s-report-%d", nodeScan.Name, chunk)
+}
+
+// entrySize estimates the serialized size of a report entry
+func entrySize(entry interface{}) int {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return 0
+	}
+	return len(raw)
+}
+
+// omittedFindings counts the findings of a scan result that the scanner did
+// not publish, or nil if the result lists them all
+func omittedFindings(result *scanResult) *clamavv1alpha1.OmittedFindings {
+	missing := func(total int64, listed int) int64 {
+		if total > int64(listed) {
+			return total - int64(listed)
+		}
+		return 0
+	}
+
+	omitted := &clamavv1alpha1.OmittedFindings{
+		InfectedFiles: missing(result.Statistics.FilesInfected, len(result.Infected)),
+		Errors:        missing(result.Statistics.Errors, len(result.Errors)),
+		SkippedFiles:  missing(result.Statistics.FilesSkipped, len(result.Skipped)),
+	}
+	if *omitted == (clamavv1alpha1.OmittedFindings{}) {
+		return nil
+	}
+	return omitted
+}
+
+// buildScanReportSpecs splits the findings of a scan result into ScanReport specs
+// that each fit in scanReportChunkBytes
+func buildScanReportSpecs(nodeScan *clamavv1alpha1.NodeScan, result *scanResult, completionTime *metav1.Time) []clamavv1alpha1.ScanReportSpec {
+	// Every chunk tells whether the findings are complete
+	omitted := omittedFindings(result)
+	newSpec := func() clamavv1alpha1.ScanReportSpec {
+		return clamavv1alpha1.ScanReportSpec{
+			NodeScan:       nodeScan.Name,
+			NodeName:       nodeScan.Spec.NodeName,
+			CompletionTime: completionTime,
+			Truncated:      result.Truncated || omitted != nil,
+			Omitted:        omitted.DeepCopy(),
+		}
+	}
+
+	specs := []clamavv1alpha1.ScanReportSpec{newSpec()}
+	size := 0
+
+	// add appends an entry to the current chunk, starting a new chunk when full
+	add := func(entrySize int, appendTo func(spec *clamavv1alpha1.ScanReportSpec)) {
+		if size > 0 && size+entrySize > scanReportChunkBytes {
+			specs = append(specs, newSpec())
+			size = 0
+		}
+		appendTo(&specs[len(specs)-1])
+		size += entrySize
+	}
+
+	for _, f := range result.Infected {
+		infected := clamavv1alpha1.InfectedFile{Path: f.Path, Viruses: f.Viruses, Size: f.Size, Attribution: f.Attribution}
+		if completionTime != nil {
+			infected.DetectedAt = *completionTime
+		}
+		add(entrySize(infected), func(spec *clamavv1alpha1.ScanReportSpec) {
+			spec.InfectedFiles = append(spec.InfectedFiles, infected)
+		})
+	}
+	for _, e := range result.Errors {
+		scanErr := clamavv1alpha1.ScanError{Path: e.Path, Error: e.Error}
+		add(entrySize(scanErr), func(spec *clamavv1alpha1.ScanReportSpec) {
+			spec.Errors = append(spec.Errors, scanErr)
+		})
+	}
+	for _, f := range result.Skipped {
+		skipped := clamavv1alpha1.SkippedFile{Path: f.Path, Reason: f.Reason}
+		add(entrySize(skipped), func(spec *clamavv1alpha1.ScanReportSpec) {
+			spec.SkippedFiles = append(spec.SkippedFiles, skipped)
+		})
+	}
+
+	for i := range specs {
+		specs[i].Chunk = int32(i)
+		specs[i].TotalChunks = int32(len(specs))
+	}
+	return specs
+}
+
+// reconcileScanReports stores the complete findings of a scan result in ScanReports
+// owned by the NodeScan and links them from the NodeScan status
+func (r *NodeScanReconciler) reconcileScanReports(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, result *scanResult) error {
+	omitted := omittedFindings(result)
+	if len(result.Infected) == 0 && len(result.Errors) == 0 && len(result.Skipped) == 0 && omitted == nil {
+		nodeScan.Status.Report = nil
+		return r.deleteScanReports(ctx, nodeScan, 0)
+	}
+
+	specs := buildScanReportSpecs(nodeScan, result, nodeScan.Status.CompletionTime)
+	reference := &clamavv1alpha1.ScanReportReference{
+		InfectedFiles: int64(len(result.Infected)),
+		Errors:        int64(len(result.Errors)),
+		SkippedFiles:  int64(len(result.Skipped)),
+		Truncated:     result.Truncated || omitted != nil,
+		Omitted:       omitted,
+	}
+
+	for i, spec := range specs {
+		report := &clamavv1alpha1.ScanReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      scanReportName(nodeScan, i),
+				Namespace: nodeScan.Namespace,
+			},
+		}
+
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, report, func() error {
+			report.Labels = map[string]string{
+				"app.kubernetes.io/name":      "clamav",
+				"app.kubernetes.io/component": "scan-report",
+				"clamav.io/nodescan":          nodeScan.Name,
+				"clamav.io/node":              nodeScan.Spec.NodeName,
+				scanReportChunkLabel:          strconv.Itoa(i),
+			}
+			report.Spec = spec
+			return controllerutil.SetControllerReference(nodeScan, report, r.Scheme)
+		}); err != nil {
+			return fmt.Errorf("failed to store scan report %s: %w", report.Name, err)
+		}
+
+		reference.Names = append(reference.Names, report.Name)
+	}
+
+	nodeScan.Status.Report = reference
+	return r.deleteScanReports(ctx, nodeScan, len(specs))
+}
+
+// deleteScanReports deletes the ScanReport chunks of a NodeScan from index "from" onwards
+func (r *NodeScanReconciler) deleteScanReports(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan, from int) error {
+	var reports clamavv1alpha1.ScanReportList
+	if err := r.List(ctx, &reports, client.InNamespace(nodeScan.Namespace),
+		client.MatchingLabels{"clamav.io/nodescan": nodeScan.Name}); err != nil {
+		return err
+	}
+
+	for i := range reports.Items {
+		report := &reports.Items[i]
+		if !metav1.IsControlledBy(report, nodeScan) || int(report.Spec.Chunk) < from {
+			continue
+		}
+		if err := r.Delete(ctx, report); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportedInfectedFiles returns the infected files of a NodeScan from its
+// ScanReports, in order, or from its status when it has no ScanReport
+func (r *NodeScanReconciler) reportedInfectedFiles(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) ([]clamavv1alpha1.InfectedFile, error) {
+	if nodeScan.Status.Report == nil {
+		return nodeScan.Status.InfectedFiles, nil
+	}
+
+	var reports clamavv1alpha1.ScanReportList
+	if err := r.List(ctx, &reports, client.InNamespace(nodeScan.Namespace),
+		client.MatchingLabels{"clamav.io/nodescan": nodeScan.Name}); err != nil {
+		return nil, err
+	}
+
+	var chunks []clamavv1alpha1.ScanReport
+	for _, report := range reports.Items {
+		if metav1.IsControlledBy(&report, nodeScan) {
+			chunks = append(chunks, report)
+		}
+	}
+	if len(chunks) == 0 {
+		return nodeScan.Status.InfectedFiles, nil
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Spec.Chunk < chunks[j].Spec.Chunk })
+
+	var infectedFiles []clamavv1alpha1.InfectedFile
+	for _, report := range chunks {
+		infectedFiles = append(infectedFiles, report.Spec.InfectedFiles...)
+	}
+	return infectedFiles, nil
+}
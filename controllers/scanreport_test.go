@@ -0,0 +1,139 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// newLargeTestScanResult returns an infected result with n infected files
+func newLargeTestScanResult(n int) *scanResult {
+	result := newTestScanResult()
+	result.Infected = nil
+	for i := 0; i < n; i++ {
+		result.Infected = append(result.Infected, scanResultInfectedFile{
+			Path:    fmt.Sprintf("/host/var/lib/infected/%05d-%s", i, strings.Repeat("x", 100)),
+			Viruses: []string{"Eicar-Test-Signature"},
+			Size:    68,
+		})
+	}
+	result.Statistics.FilesInfected = int64(n)
+	return result
+}
+
+func TestBuildScanReportSpecs_Chunking(t *testing.T) {
+	_, nodeScan, _ := newScanResultTestObjects()
+	result := newLargeTestScanResult(10000)
+
+	specs := buildScanReportSpecs(nodeScan, result, nil)
+
+	require.Greater(t, len(specs), 1)
+	total := 0
+	for i, spec := range specs {
+		assert.Equal(t, int32(i), spec.Chunk)
+		assert.Equal(t, int32(len(specs)), spec.TotalChunks)
+		assert.Equal(t, "test-scan", spec.NodeScan)
+		assert.Equal(t, "test-node", spec.NodeName)
+		total += len(spec.InfectedFiles)
+	}
+	assert.Equal(t, 10000, total)
+	assert.Len(t, specs[len(specs)-1].Errors, 1)
+}
+
+func TestBuildScanReportSpecs_RecordsOmittedFindings(t *testing.T) {
+	_, nodeScan, _ := newScanResultTestObjects()
+	result := newLargeTestScanResult(10)
+	// The scanner dropped findings to fit the result in its ConfigMap
+	result.Truncated = true
+	result.Statistics.FilesInfected = 25
+	result.Statistics.Errors = 150
+	result.Statistics.FilesSkipped = 1
+	result.Skipped = []scanResultSkippedFile{{Path: "/host/var/lib/big.iso", Reason: "too_large"}}
+
+	specs := buildScanReportSpecs(nodeScan, result, nil)
+
+	require.Len(t, specs, 1)
+	assert.True(t, specs[0].Truncated)
+	require.NotNil(t, specs[0].Omitted)
+	assert.Equal(t, clamavv1alpha1.OmittedFindings{InfectedFiles: 15, Errors: 149}, *specs[0].Omitted)
+
+	// A complete result has nothing omitted
+	result = newLargeTestScanResult(10)
+	result.Statistics.FilesSkipped = 0
+	specs = buildScanReportSpecs(nodeScan, result, nil)
+	assert.False(t, specs[0].Truncated)
+	assert.Nil(t, specs[0].Omitted)
+}
+
+func TestNodeScanReconciler_Reconcile_StoresScanReports(t *testing.T) {
+	node, nodeScan, job := newScanResultTestObjects()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nodescan-test-scan-result",
+			Namespace: "default",
+		},
+		BinaryData: map[string][]byte{
+			scanResultKey: encodeTestScanResult(t, newLargeTestScanResult(250)),
+		},
+	}
+	// Left over from a previous result with more chunks
+	stale := &clamavv1alpha1.ScanReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-scan-report-5",
+			Namespace: "default",
+			Labels:    map[string]string{"clamav.io/nodescan": "test-scan"},
+		},
+		Spec: clamavv1alpha1.ScanReportSpec{NodeScan: "test-scan", Chunk: 5, TotalChunks: 6},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan, job, configMap)
+	require.NoError(t, ctrl.SetControllerReference(nodeScan, stale, r.Scheme))
+	require.NoError(t, r.Create(context.Background(), stale))
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-scan", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "test-scan", Namespace: "default"}, &updated))
+	assert.Len(t, updated.Status.InfectedFiles, 100)
+	require.NotNil(t, updated.Status.Report)
+	assert.Equal(t, []string{"test-scan-report-0"}, updated.Status.Report.Names)
+	assert.Equal(t, int64(250), updated.Status.Report.InfectedFiles)
+	assert.Equal(t, int64(1), updated.Status.Report.Errors)
+
+	var reports clamavv1alpha1.ScanReportList
+	require.NoError(t, r.List(context.Background(), &reports, client.InNamespace("default"),
+		client.MatchingLabels{"clamav.io/nodescan": "test-scan"}))
+	require.Len(t, reports.Items, 1)
+	assert.Len(t, reports.Items[0].Spec.InfectedFiles, 250)
+	require.Len(t, reports.Items[0].OwnerReferences, 1)
+	assert.Equal(t, "NodeScan", reports.Items[0].OwnerReferences[0].Kind)
+}
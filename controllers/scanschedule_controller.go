@@ -7,32 +7,52 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1" // ✅ AJOUTÉ : Import manquant
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
 )
 
+const (
+	// scheduleConditionMissed reports whether scheduled runs were missed
+	scheduleConditionMissed = "MissedSchedule"
+
+	// pendingScheduleRequeue is how often a run held back by the concurrency policy is retried
+	pendingScheduleRequeue = time.Minute
+
+	// maxMissedScheduleTimes bounds the schedule times walked to find the
+	// most recent one, like the CronJob controller
+	maxMissedScheduleTimes = 100
+)
+
 // ScanScheduleReconciler reconciles a ScanSchedule object
 type ScanScheduleReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// ClusterName identifies the cluster in notification templates
+	ClusterName string
 }
 
 // +kubebuilder:rbac:groups=clamav.io,resources=scanschedules,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=clamav.io,resources=scanschedules/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=clamav.io,resources=scanschedules/finalizers,verbs=update
 // +kubebuilder:rbac:groups=clamav.io,resources=clusterscans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=clamav.io,resources=volumescans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *ScanScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
@@ -46,9 +66,10 @@ func (r *ScanScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// Parse cron schedule
-	schedule, err := cron.ParseStandard(scanSchedule.Spec.Schedule)
+	schedule, err := parseSchedule(&scanSchedule)
 	if err != nil {
 		log.Error(err, "invalid cron schedule")
+		r.Recorder.Event(&scanSchedule, corev1.EventTypeWarning, "InvalidSchedule", err.Error())
 		return ctrl.Result{}, err
 	}
 
@@ -67,29 +88,70 @@ func (r *ScanScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{RequeueAfter: time.Until(nextRun)}, nil
 	}
 
-	// Check if it's time to run
-	var needsRun bool
-	if scanSchedule.Status.LastScheduleTime == nil {
-		needsRun = true
-	} else {
-		lastRun := scanSchedule.Status.LastScheduleTime.Time
-		missedRun := schedule.Next(lastRun)
-		if missedRun.Before(now) || missedRun.Equal(now) {
-			needsRun = true
+	windows, err := parseMaintenanceWindows(scanSchedule.Spec.MaintenanceWindows, scanSchedule.Spec.TimeZone)
+	if err != nil {
+		log.Error(err, "invalid maintenance windows")
+		r.Recorder.Event(&scanSchedule, corev1.EventTypeWarning, reasonInvalidWindowConfig, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// Find the most recent schedule time that has not been handled yet
+	scheduledTime, missed, err := mostRecentScheduleTime(schedule, scheduleEarliestTime(&scanSchedule), now)
+	if err != nil {
+		// The missed runs are skipped, the schedule resumes at its next run
+		log.Error(err, "too many missed runs")
+		scanSchedule.Status.LastMissedScheduleTime = &metav1.Time{Time: now}
+		r.recordMissedSchedules(&scanSchedule, missed, "TooManyMissedRuns",
+			fmt.Sprintf("Too many missed runs (more than %d), all of them were skipped", maxMissedScheduleTimes))
+	}
+	needsRun := scheduledTime != nil
+	pending := false
+	var nextWindow time.Time
+
+	// Skip runs that are older than the starting deadline
+	if needsRun && scanSchedule.Spec.StartingDeadlineSeconds != nil {
+		deadline := time.Duration(*scanSchedule.Spec.StartingDeadlineSeconds) * time.Second
+		if scheduledTime.Add(deadline).Before(now) {
+			log.Info("missed starting deadline", "scheduledTime", scheduledTime, "deadline", deadline)
+			scanSchedule.Status.LastMissedScheduleTime = &metav1.Time{Time: *scheduledTime}
+			r.recordMissedSchedules(&scanSchedule, missed+1, "DeadlineExceeded",
+				fmt.Sprintf("Missed %d scheduled run(s), the last one at %s is older than the starting deadline of %s",
+					missed+1, scheduledTime.Format(time.RFC3339), deadline))
+			needsRun = false
 		}
 	}
 
+	// Hold the run until the next maintenance window opens
+	if needsRun && !windows.isOpen(now) {
+		nextWindow = windows.nextOpen(now)
+		message := "Waiting for a maintenance window to start the scheduled run"
+		if !nextWindow.IsZero() {
+			message = fmt.Sprintf("Waiting for the maintenance window opening at %s", nextWindow.Format(time.RFC3339))
+		}
+		log.Info("outside maintenance windows", "scheduledTime", scheduledTime, "nextWindow", nextWindow)
+		meta.SetStatusCondition(&scanSchedule.Status.Conditions, metav1.Condition{
+			Type:    conditionMaintenanceWindow,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonWaitingForWindow,
+			Message: message,
+		})
+		needsRun = false
+		pending = true
+	}
+
 	if needsRun {
 		// Check concurrency policy
 		if scanSchedule.Spec.ConcurrencyPolicy == "Forbid" && len(scanSchedule.Status.Active) > 0 {
+			// The run stays pending until the active scans finish or the deadline passes
 			log.Info("skipping run due to concurrency policy", "policy", "Forbid")
 			needsRun = false
+			pending = true
 		} else if scanSchedule.Spec.ConcurrencyPolicy == "Replace" && len(scanSchedule.Status.Active) > 0 {
 			// Delete active scans
 			for _, ref := range scanSchedule.Status.Active {
-				cs := &clamavv1alpha1.ClusterScan{}
-				if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, cs); err == nil {
-					r.Delete(ctx, cs)
+				scan := newScheduledScanObject(ref.Kind)
+				if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, scan); err == nil {
+					r.Delete(ctx, scan)
 				}
 			}
 			scanSchedule.Status.Active = []corev1.ObjectReference{}
@@ -97,35 +159,43 @@ func (r *ScanScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	if needsRun {
-		// Create new ClusterScan
-		clusterScan := &clamavv1alpha1.ClusterScan{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-%d", scanSchedule.Name, now.Unix()),
-				Namespace: scanSchedule.Namespace,
-				Labels: map[string]string{
-					"clamav.io/schedule": scanSchedule.Name,
-				},
-			},
-			Spec: scanSchedule.Spec.ClusterScan,
-		}
-
-		if err := r.Create(ctx, clusterScan); err != nil {
-			log.Error(err, "failed to create cluster scan")
+		scan, err := r.createScheduledScan(ctx, &scanSchedule, *scheduledTime)
+		if err != nil {
+			log.Error(err, "failed to create scheduled scan")
 			// Enregistrer métrique d'échec
 			recordScanScheduleExecution(scanSchedule.Namespace, scanSchedule.Name, "failed")
 			return ctrl.Result{}, err
 		}
 
 		// Update status
-		scanSchedule.Status.LastScheduleTime = &metav1.Time{Time: now}
-		scanSchedule.Status.LastClusterScan = clusterScan.Name
-		scanSchedule.Status.Active = append(scanSchedule.Status.Active, corev1.ObjectReference{
-			Name:      clusterScan.Name,
-			Namespace: clusterScan.Namespace,
-		})
+		scanSchedule.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+		scanSchedule.Status.Active = append(scanSchedule.Status.Active, scan)
 
 		r.Recorder.Event(&scanSchedule, corev1.EventTypeNormal, "ScanCreated",
-			fmt.Sprintf("Created ClusterScan %s", clusterScan.Name))
+			fmt.Sprintf("Created %s %s", scan.Kind, scan.Name))
+
+		if windows != nil {
+			meta.SetStatusCondition(&scanSchedule.Status.Conditions, metav1.Condition{
+				Type:    conditionMaintenanceWindow,
+				Status:  metav1.ConditionTrue,
+				Reason:  reasonWindowOpen,
+				Message: fmt.Sprintf("Run scheduled at %s was started", scheduledTime.Format(time.RFC3339)),
+			})
+		}
+
+		if missed > 0 {
+			// Older runs are collapsed into this one
+			r.recordMissedSchedules(&scanSchedule, missed, "RunsCollapsed",
+				fmt.Sprintf("Missed %d scheduled run(s) before %s, only the most recent one was started",
+					missed, scheduledTime.Format(time.RFC3339)))
+		} else {
+			meta.SetStatusCondition(&scanSchedule.Status.Conditions, metav1.Condition{
+				Type:    scheduleConditionMissed,
+				Status:  metav1.ConditionFalse,
+				Reason:  "OnSchedule",
+				Message: fmt.Sprintf("Run scheduled at %s was started", scheduledTime.Format(time.RFC3339)),
+			})
+		}
 
 		// Enregistrer métrique de succès
 		recordScanScheduleExecution(scanSchedule.Namespace, scanSchedule.Name, "success")
@@ -140,31 +210,225 @@ func (r *ScanScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: time.Until(nextRun)}, nil
+	// Deliver pending ScanOverdue notifications and schedule their retries
+	notificationResult, err := r.reconcileNotifications(ctx, &scanSchedule)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	requeueAfter := time.Until(nextRun)
+	if !nextWindow.IsZero() && nextWindow.Before(nextRun) {
+		// Start the pending run when the maintenance window opens
+		requeueAfter = time.Until(nextWindow)
+	} else if pending && nextWindow.IsZero() && requeueAfter > pendingScheduleRequeue {
+		// Retry the pending run once the active scans have finished
+		requeueAfter = pendingScheduleRequeue
+	}
+	return earliestRequeue(ctrl.Result{RequeueAfter: requeueAfter}, notificationResult), nil
 }
 
-func (r *ScanScheduleReconciler) cleanupHistory(ctx context.Context, scanSchedule *clamavv1alpha1.ScanSchedule) error {
-	// Get all ClusterScans for this schedule
+// createScheduledScan creates the scan of a scheduled run from the template of
+// the ScanSchedule: a VolumeScan when set, a ClusterScan otherwise
+func (r *ScanScheduleReconciler) createScheduledScan(ctx context.Context,
+	scanSchedule *clamavv1alpha1.ScanSchedule, scheduledTime time.Time) (corev1.ObjectReference, error) {
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-%d", scanSchedule.Name, scheduledTime.Unix()),
+		Namespace: scanSchedule.Namespace,
+		Labels: map[string]string{
+			"clamav.io/schedule": scanSchedule.Name,
+		},
+	}
+
+	if scanSchedule.Spec.VolumeScan != nil {
+		volumeScan := &clamavv1alpha1.VolumeScan{
+			ObjectMeta: objectMeta,
+			Spec:       *scanSchedule.Spec.VolumeScan.DeepCopy(),
+		}
+		if err := controllerutil.SetControllerReference(scanSchedule, volumeScan, r.Scheme); err != nil {
+			return corev1.ObjectReference{}, err
+		}
+		if err := r.Create(ctx, volumeScan); err != nil && !errors.IsAlreadyExists(err) {
+			return corev1.ObjectReference{}, err
+		}
+		scanSchedule.Status.LastVolumeScan = volumeScan.Name
+		return corev1.ObjectReference{Kind: "VolumeScan", Name: volumeScan.Name, Namespace: volumeScan.Namespace}, nil
+	}
+
+	clusterScan := &clamavv1alpha1.ClusterScan{ObjectMeta: objectMeta}
+	if scanSchedule.Spec.ClusterScan != nil {
+		clusterScan.Spec = *scanSchedule.Spec.ClusterScan.DeepCopy()
+	}
+
+	// The ClusterScan rollout honors the schedule windows unless the template sets its own
+	if clusterScan.Spec.MaintenanceWindows == nil && scanSchedule.Spec.MaintenanceWindows != nil {
+		clusterScan.Spec.MaintenanceWindows = scanSchedule.Spec.MaintenanceWindows.DeepCopy()
+		if clusterScan.Spec.MaintenanceWindows.TimeZone == "" {
+			clusterScan.Spec.MaintenanceWindows.TimeZone = scanSchedule.Spec.TimeZone
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(scanSchedule, clusterScan, r.Scheme); err != nil {
+		return corev1.ObjectReference{}, err
+	}
+	if err := r.Create(ctx, clusterScan); err != nil && !errors.IsAlreadyExists(err) {
+		return corev1.ObjectReference{}, err
+	}
+	scanSchedule.Status.LastClusterScan = clusterScan.Name
+	return corev1.ObjectReference{Kind: "ClusterScan", Name: clusterScan.Name, Namespace: clusterScan.Namespace}, nil
+}
+
+// newScheduledScanObject returns an empty scan of the kind of an active
+// reference. References without a kind predate VolumeScans and are ClusterScans.
+func newScheduledScanObject(kind string) client.Object {
+	if kind == "VolumeScan" {
+		return &clamavv1alpha1.VolumeScan{}
+	}
+	return &clamavv1alpha1.ClusterScan{}
+}
+
+// parseSchedule parses the cron schedule in the time zone of the ScanSchedule
+func parseSchedule(scanSchedule *clamavv1alpha1.ScanSchedule) (cron.Schedule, error) {
+	spec := scanSchedule.Spec.Schedule
+	if tz := scanSchedule.Spec.TimeZone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+		spec = fmt.Sprintf("CRON_TZ=%s %s", tz, spec)
+	}
+	return cron.ParseStandard(spec)
+}
+
+// scheduleEarliestTime returns the time after which schedule times have not been handled yet
+func scheduleEarliestTime(scanSchedule *clamavv1alpha1.ScanSchedule) time.Time {
+	earliest := scanSchedule.CreationTimestamp.Time
+	if t := scanSchedule.Status.LastScheduleTime; t != nil && t.Time.After(earliest) {
+		earliest = t.Time
+	}
+	if t := scanSchedule.Status.LastMissedScheduleTime; t != nil && t.Time.After(earliest) {
+		earliest = t.Time
+	}
+	return earliest
+}
+
+// mostRecentScheduleTime returns the most recent schedule time in (earliest, now],
+// or nil if there is none, and the number of older schedule times it supersedes.
+// It fails when more than maxMissedScheduleTimes schedule times were missed.
+func mostRecentScheduleTime(schedule cron.Schedule, earliest, now time.Time) (*time.Time, int, error) {
+	var mostRecent *time.Time
+	missed := 0
+	for t := schedule.Next(earliest); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		if mostRecent != nil {
+			missed++
+		}
+		if missed > maxMissedScheduleTimes {
+			return nil, missed, fmt.Errorf("too many missed runs (> %d)", maxMissedScheduleTimes)
+		}
+		scheduled := t
+		mostRecent = &scheduled
+	}
+	return mostRecent, missed, nil
+}
+
+// recordMissedSchedules reports missed runs through an event, the MissedSchedule
+// condition, the missed runs metric and a ScanOverdue notification
+func (r *ScanScheduleReconciler) recordMissedSchedules(scanSchedule *clamavv1alpha1.ScanSchedule,
+	missed int, reason, message string) {
+
+	meta.SetStatusCondition(&scanSchedule.Status.Conditions, metav1.Condition{
+		Type:    scheduleConditionMissed,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	r.Recorder.Event(scanSchedule, corev1.EventTypeWarning, "MissedSchedule", message)
+	recordScanScheduleMissed(scanSchedule.Namespace, scanSchedule.Name, missed)
+	enqueueOverdue(scanSchedule)
+}
+
+// scheduledScan is a ClusterScan or VolumeScan created by a ScanSchedule
+type scheduledScan struct {
+	object         client.Object
+	kind           string
+	completionTime *metav1.Time
+	// outcome is "successful", "failed" or empty while the scan runs
+	outcome string
+}
+
+// listScheduledScans returns the scans of a ScanSchedule, oldest first
+func (r *ScanScheduleReconciler) listScheduledScans(ctx context.Context,
+	scanSchedule *clamavv1alpha1.ScanSchedule) ([]scheduledScan, error) {
+
+	opts := []client.ListOption{
+		client.InNamespace(scanSchedule.Namespace),
+		client.MatchingLabels{"clamav.io/schedule": scanSchedule.Name},
+	}
+
 	clusterScans := &clamavv1alpha1.ClusterScanList{}
-	if err := r.List(ctx, clusterScans, client.InNamespace(scanSchedule.Namespace),
-		client.MatchingLabels{"clamav.io/schedule": scanSchedule.Name}); err != nil {
+	if err := r.List(ctx, clusterScans, opts...); err != nil {
+		return nil, err
+	}
+	volumeScans := &clamavv1alpha1.VolumeScanList{}
+	if err := r.List(ctx, volumeScans, opts...); err != nil {
+		return nil, err
+	}
+
+	var scans []scheduledScan
+	for i := range clusterScans.Items {
+		cs := &clusterScans.Items[i]
+		scan := scheduledScan{object: cs, kind: "ClusterScan", completionTime: cs.Status.CompletionTime}
+		switch cs.Status.Phase {
+		case clamavv1alpha1.ClusterScanPhaseCompleted:
+			scan.outcome = "successful"
+		case clamavv1alpha1.ClusterScanPhaseFailed, clamavv1alpha1.ClusterScanPhasePartiallyComplete:
+			scan.outcome = "failed"
+		}
+		scans = append(scans, scan)
+	}
+	for i := range volumeScans.Items {
+		vs := &volumeScans.Items[i]
+		scan := scheduledScan{object: vs, kind: "VolumeScan", completionTime: vs.Status.CompletionTime}
+		switch vs.Status.Phase {
+		case clamavv1alpha1.NodeScanPhaseCompleted:
+			scan.outcome = "successful"
+		case clamavv1alpha1.NodeScanPhaseFailed:
+			scan.outcome = "failed"
+		}
+		scans = append(scans, scan)
+	}
+
+	sort.SliceStable(scans, func(i, j int) bool {
+		ti, tj := scans[i].object.GetCreationTimestamp(), scans[j].object.GetCreationTimestamp()
+		if ti.Equal(&tj) {
+			return scans[i].object.GetName() < scans[j].object.GetName()
+		}
+		return ti.Before(&tj)
+	})
+	return scans, nil
+}
+
+func (r *ScanScheduleReconciler) cleanupHistory(ctx context.Context, scanSchedule *clamavv1alpha1.ScanSchedule) error {
+	// Get all ClusterScans and VolumeScans for this schedule
+	scans, err := r.listScheduledScans(ctx, scanSchedule)
+	if err != nil {
 		return err
 	}
 
 	// Separate by status
-	var successful, failed []clamavv1alpha1.ClusterScan
+	var successful, failed []scheduledScan
 	var active []corev1.ObjectReference
 
-	for _, cs := range clusterScans.Items {
-		switch cs.Status.Phase {
-		case clamavv1alpha1.ClusterScanPhaseCompleted:
-			successful = append(successful, cs)
-		case clamavv1alpha1.ClusterScanPhaseFailed, clamavv1alpha1.ClusterScanPhasePartiallyComplete:
-			failed = append(failed, cs)
+	for _, scan := range scans {
+		switch scan.outcome {
+		case "successful":
+			successful = append(successful, scan)
+		case "failed":
+			failed = append(failed, scan)
 		default:
 			active = append(active, corev1.ObjectReference{
-				Name:      cs.Name,
-				Namespace: cs.Namespace,
+				Kind:      scan.kind,
+				Name:      scan.object.GetName(),
+				Namespace: scan.object.GetNamespace(),
 			})
 		}
 	}
@@ -178,9 +442,8 @@ func (r *ScanScheduleReconciler) cleanupHistory(ctx context.Context, scanSchedul
 		successLimit = *scanSchedule.Spec.SuccessfulScansHistoryLimit
 	}
 	if len(successful) > int(successLimit) {
-		// Sort by creation timestamp
 		for i := 0; i < len(successful)-int(successLimit); i++ {
-			if err := r.Delete(ctx, &successful[i]); err != nil && !errors.IsNotFound(err) {
+			if err := r.Delete(ctx, successful[i].object); err != nil && !errors.IsNotFound(err) {
 				return err
 			}
 		}
@@ -193,7 +456,7 @@ func (r *ScanScheduleReconciler) cleanupHistory(ctx context.Context, scanSchedul
 	}
 	if len(failed) > int(failedLimit) {
 		for i := 0; i < len(failed)-int(failedLimit); i++ {
-			if err := r.Delete(ctx, &failed[i]); err != nil && !errors.IsNotFound(err) {
+			if err := r.Delete(ctx, failed[i].object); err != nil && !errors.IsNotFound(err) {
 				return err
 			}
 		}
@@ -202,8 +465,8 @@ func (r *ScanScheduleReconciler) cleanupHistory(ctx context.Context, scanSchedul
 	// Update last successful time if there are successful scans
 	if len(successful) > 0 {
 		lastSuccessful := successful[len(successful)-1]
-		if lastSuccessful.Status.CompletionTime != nil {
-			scanSchedule.Status.LastSuccessfulTime = lastSuccessful.Status.CompletionTime
+		if lastSuccessful.completionTime != nil {
+			scanSchedule.Status.LastSuccessfulTime = lastSuccessful.completionTime
 		}
 	}
 
@@ -214,5 +477,6 @@ func (r *ScanScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clamavv1alpha1.ScanSchedule{}).
 		Owns(&clamavv1alpha1.ClusterScan{}).
+		Owns(&clamavv1alpha1.VolumeScan{}).
 		Complete(r)
 }
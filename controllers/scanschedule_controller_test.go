@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestScanScheduleReconciler(objs ...client.Object) *ScanScheduleReconciler {
+	scheme := newTestScheme()
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&clamavv1alpha1.ScanSchedule{}, &clamavv1alpha1.ClusterScan{}).
+		Build()
+
+	return &ScanScheduleReconciler{
+		Client:   fakeClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+// newTestScanSchedule returns an hourly schedule created two days ago whose last
+// run was scheduled lastRun ago
+func newTestScanSchedule(lastRun time.Duration) *clamavv1alpha1.ScanSchedule {
+	lastScheduleTime := metav1.NewTime(time.Now().Add(-lastRun).Truncate(time.Hour))
+	return &clamavv1alpha1.ScanSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "hourly",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+		},
+		Spec: clamavv1alpha1.ScanScheduleSpec{
+			Schedule: "0 * * * *",
+		},
+		Status: clamavv1alpha1.ScanScheduleStatus{
+			LastScheduleTime: &lastScheduleTime,
+		},
+	}
+}
+
+func reconcileScanSchedule(t *testing.T, r *ScanScheduleReconciler) (*clamavv1alpha1.ScanSchedule, []clamavv1alpha1.ClusterScan) {
+	t.Helper()
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "hourly", Namespace: "default"},
+	})
+	require.NoError(t, err)
+
+	var scanSchedule clamavv1alpha1.ScanSchedule
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "hourly", Namespace: "default"}, &scanSchedule))
+
+	var clusterScans clamavv1alpha1.ClusterScanList
+	require.NoError(t, r.List(context.Background(), &clusterScans, client.InNamespace("default")))
+	return &scanSchedule, clusterScans.Items
+}
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 * * * *")
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	scheduled, missed, err := mostRecentScheduleTime(schedule, now.Add(-10*time.Minute), now)
+	require.NoError(t, err)
+	assert.Nil(t, scheduled)
+	assert.Equal(t, 0, missed)
+
+	scheduled, missed, err = mostRecentScheduleTime(schedule, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), now)
+	require.NoError(t, err)
+	assert.Nil(t, scheduled)
+	assert.Equal(t, 0, missed)
+
+	scheduled, missed, err = mostRecentScheduleTime(schedule, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), now)
+	require.NoError(t, err)
+	require.NotNil(t, scheduled)
+	assert.Equal(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), *scheduled)
+	assert.Equal(t, 3, missed)
+
+	// The schedule times walked are bounded
+	scheduled, missed, err = mostRecentScheduleTime(schedule, now.Add(-101*time.Hour), now)
+	require.NoError(t, err)
+	require.NotNil(t, scheduled)
+	assert.Equal(t, maxMissedScheduleTimes, missed)
+
+	_, _, err = mostRecentScheduleTime(schedule, now.Add(-365*24*time.Hour), now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many missed runs")
+}
+
+func TestParseSchedule_TimeZone(t *testing.T) {
+	scanSchedule := newTestScanSchedule(time.Hour)
+	scanSchedule.Spec.Schedule = "0 2 * * *"
+	scanSchedule.Spec.TimeZone = "Europe/Paris"
+
+	schedule, err := parseSchedule(scanSchedule)
+	require.NoError(t, err)
+
+	// 02:00 in Paris is 01:00 UTC in winter and 00:00 UTC in summer
+	assert.True(t, time.Date(2025, 1, 15, 1, 0, 0, 0, time.UTC).Equal(
+		schedule.Next(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC).Equal(
+		schedule.Next(time.Date(2025, 7, 14, 23, 0, 0, 0, time.UTC))))
+
+	scanSchedule.Spec.TimeZone = "Mars/Olympus"
+	_, err = parseSchedule(scanSchedule)
+	assert.Error(t, err)
+}
+
+func TestScanScheduleReconciler_Reconcile_RunsOnSchedule(t *testing.T) {
+	r := newTestScanScheduleReconciler(newTestScanSchedule(time.Hour))
+
+	scanSchedule, clusterScans := reconcileScanSchedule(t, r)
+
+	require.Len(t, clusterScans, 1)
+	// The schedule owns its scans, so that it is reconciled when they change
+	owner := metav1.GetControllerOf(&clusterScans[0])
+	require.NotNil(t, owner)
+	assert.Equal(t, "ScanSchedule", owner.Kind)
+	condition := meta.FindStatusCondition(scanSchedule.Status.Conditions, scheduleConditionMissed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, time.Now().Truncate(time.Hour), scanSchedule.Status.LastScheduleTime.Time.Local())
+}
+
+func TestScanScheduleReconciler_Reconcile_CollapsesMissedRuns(t *testing.T) {
+	r := newTestScanScheduleReconciler(newTestScanSchedule(5 * time.Hour))
+
+	scanSchedule, clusterScans := reconcileScanSchedule(t, r)
+
+	// Only the most recent run is started
+	require.Len(t, clusterScans, 1)
+	// The schedule owns its scans, so that it is reconciled when they change
+	owner := metav1.GetControllerOf(&clusterScans[0])
+	require.NotNil(t, owner)
+	assert.Equal(t, "ScanSchedule", owner.Kind)
+	condition := meta.FindStatusCondition(scanSchedule.Status.Conditions, scheduleConditionMissed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "RunsCollapsed", condition.Reason)
+}
+
+func TestScanScheduleReconciler_Reconcile_StartingDeadlineExceeded(t *testing.T) {
+	scanSchedule := newTestScanSchedule(5 * time.Hour)
+	deadline := int64(1)
+	scanSchedule.Spec.StartingDeadlineSeconds = &deadline
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	// The current hour started more than a second ago
+	if time.Since(time.Now().Truncate(time.Hour)) < 2*time.Second {
+		time.Sleep(2 * time.Second)
+	}
+	updated, clusterScans := reconcileScanSchedule(t, r)
+
+	assert.Empty(t, clusterScans)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, scheduleConditionMissed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "DeadlineExceeded", condition.Reason)
+	require.NotNil(t, updated.Status.LastMissedScheduleTime)
+
+	// Missed runs are not reported again
+	recorder := r.Recorder.(*record.FakeRecorder)
+	<-recorder.Events
+	_, clusterScans = reconcileScanSchedule(t, r)
+	assert.Empty(t, clusterScans)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestScanScheduleReconciler_Reconcile_TooManyMissedRuns(t *testing.T) {
+	scanSchedule := newTestScanSchedule(30 * 24 * time.Hour)
+	scanSchedule.CreationTimestamp = metav1.NewTime(time.Now().Add(-60 * 24 * time.Hour))
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	updated, clusterScans := reconcileScanSchedule(t, r)
+
+	// The missed runs are skipped until the next scheduled run
+	assert.Empty(t, clusterScans)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, scheduleConditionMissed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "TooManyMissedRuns", condition.Reason)
+	require.NotNil(t, updated.Status.LastMissedScheduleTime)
+
+	recorder := r.Recorder.(*record.FakeRecorder)
+	assert.Contains(t, <-recorder.Events, "Too many missed runs")
+	_, clusterScans = reconcileScanSchedule(t, r)
+	assert.Empty(t, clusterScans)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestScanScheduleReconciler_Reconcile_WithinStartingDeadline(t *testing.T) {
+	scanSchedule := newTestScanSchedule(5 * time.Hour)
+	deadline := int64(3600)
+	scanSchedule.Spec.StartingDeadlineSeconds = &deadline
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	_, clusterScans := reconcileScanSchedule(t, r)
+
+	assert.Len(t, clusterScans, 1)
+}
+
+func TestScanScheduleReconciler_Reconcile_CreatesVolumeScan(t *testing.T) {
+	scanSchedule := newTestScanSchedule(time.Hour)
+	scanSchedule.Spec.VolumeScan = &clamavv1alpha1.VolumeScanSpec{PersistentVolumeClaim: "uploads"}
+	r := newTestScanScheduleReconciler(scanSchedule)
+
+	updated, clusterScans := reconcileScanSchedule(t, r)
+	assert.Empty(t, clusterScans)
+
+	var volumeScans clamavv1alpha1.VolumeScanList
+	require.NoError(t, r.List(context.Background(), &volumeScans, client.InNamespace("default")))
+	require.Len(t, volumeScans.Items, 1)
+	assert.Equal(t, "uploads", volumeScans.Items[0].Spec.PersistentVolumeClaim)
+	assert.Equal(t, "hourly", volumeScans.Items[0].Labels["clamav.io/schedule"])
+	assert.True(t, metav1.IsControlledBy(&volumeScans.Items[0], updated))
+	assert.Equal(t, volumeScans.Items[0].Name, updated.Status.LastVolumeScan)
+	require.Len(t, updated.Status.Active, 1)
+	assert.Equal(t, "VolumeScan", updated.Status.Active[0].Kind)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// enqueueOverdue records a pending ScanOverdue notification on the channels of
+// the ClusterScan template. A schedule can be overdue many times, so
+// notifications of a previous occurrence are queued again.
+func enqueueOverdue(scanSchedule *clamavv1alpha1.ScanSchedule) {
+	notifications := scheduleNotifications(scanSchedule)
+	if notifications == nil {
+		return
+	}
+	for i := range scanSchedule.Status.Notifications {
+		n := &scanSchedule.Status.Notifications[i]
+		if n.Event == clamavv1alpha1.NotificationEventScanOverdue && n.State != clamavv1alpha1.NotificationStatePending {
+			*n = clamavv1alpha1.NotificationStatus{
+				Channel: n.Channel,
+				Event:   n.Event,
+				State:   clamavv1alpha1.NotificationStatePending,
+			}
+		}
+	}
+	enqueueNotification(&scanSchedule.Status.Notifications, notifications, clamavv1alpha1.NotificationEventScanOverdue)
+}
+
+// scheduleNotifications returns the notification channels of the ClusterScan
+// template of a ScanSchedule, if any
+func scheduleNotifications(scanSchedule *clamavv1alpha1.ScanSchedule) *clamavv1alpha1.NotificationConfig {
+	template := scanSchedule.Spec.ClusterScan
+	if template == nil || template.Notifications == nil {
+		return nil
+	}
+	return &template.Notifications.NotificationConfig
+}
+
+// scanOverdueAlert describes the missed runs of a ScanSchedule
+func scanOverdueAlert(scanSchedule *clamavv1alpha1.ScanSchedule) alert {
+	a := alert{
+		title:     "ClamAV Scheduled Scan Overdue",
+		eventType: "clamav.schedule.overdue",
+		severity:  "warning",
+		message:   "Scheduled runs were missed",
+		source:    scanSchedule.Name,
+		dedupKey:  fmt.Sprintf("clamav/scanschedule/%s/%s/overdue", scanSchedule.Namespace, scanSchedule.Name),
+		fields: []alertField{
+			{key: "name", title: "Schedule", value: scanSchedule.Name},
+			{key: "namespace", title: "Namespace", value: scanSchedule.Namespace},
+			{key: "schedule", title: "Cron", value: scanSchedule.Spec.Schedule},
+		},
+	}
+	if t := scanSchedule.Status.LastScheduleTime; t != nil {
+		a.fields = append(a.fields, alertField{key: "lastScheduleTime", title: "Last Run", value: t.Format(time.RFC3339)})
+	}
+	if t := scanSchedule.Status.LastMissedScheduleTime; t != nil {
+		a.fields = append(a.fields, alertField{key: "lastMissedScheduleTime", title: "Last Missed", value: t.Format(time.RFC3339)})
+	}
+	if c := meta.FindStatusCondition(scanSchedule.Status.Conditions, scheduleConditionMissed); c != nil {
+		a.message = c.Message
+	}
+	return a
+}
+
+// reconcileNotifications delivers the pending ScanOverdue notifications of a
+// ScanSchedule whose retry time has come
+func (r *ScanScheduleReconciler) reconcileNotifications(ctx context.Context, scanSchedule *clamavv1alpha1.ScanSchedule) (ctrl.Result, error) {
+	notifications := scheduleNotifications(scanSchedule)
+	queue := &notificationQueue{
+		client:        r.Client,
+		scheme:        r.Scheme,
+		recorder:      r.Recorder,
+		retry:         retryPolicyFor(notifications),
+		notifications: notifications,
+		owner:         scanSchedule,
+		kind:          "scanSchedule",
+		labels: map[string]string{
+			"clamav.io/schedule": scanSchedule.Name,
+		},
+		deliver: func(ctx context.Context, channel, event string) error {
+			if event != clamavv1alpha1.NotificationEventScanOverdue {
+				return fmt.Errorf("unknown notification event %q", event)
+			}
+			if usesCloudEvents(notifications, channel) {
+				if err := checkNotificationChannel(notifications, channel); err != nil {
+					return err
+				}
+				return sendCloudEvents(ctx, r.Client, notifications.Webhook, scanSchedule.Namespace,
+					[]cloudEvent{scanScheduleCloudEvent(scanSchedule)})
+			}
+			if hasNotificationTemplate(notifications, channel) {
+				a := scanOverdueAlert(scanSchedule)
+				data := newNotificationTemplateData(event, a, r.ClusterName)
+				data.ScanSchedule = scanSchedule
+				return sendTemplated(ctx, r.Client, notifications, scanSchedule.Namespace, channel, a, data)
+			}
+			return sendAlert(ctx, r.Client, notifications, scanSchedule.Namespace, channel, scanOverdueAlert(scanSchedule))
+		},
+	}
+
+	if err := queue.replay(ctx, &scanSchedule.Status.Notifications); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(scanSchedule.Status.Notifications) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	changed, nextAttempt, err := queue.process(ctx, scanSchedule.Status.Notifications)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err := r.Status().Update(ctx, scanSchedule); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if nextAttempt.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Until(nextAttempt)}, nil
+}
@@ -0,0 +1,723 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+const (
+	// volumeMountPath is where scan Jobs mount the scanned volume
+	volumeMountPath = "/volume"
+
+	// defaultSnapshotClassAnnotation marks the default VolumeSnapshotClass of a driver
+	defaultSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+
+	// volumeSnapshotRequeue is how often a snapshot is checked until it is ready
+	volumeSnapshotRequeue = 10 * time.Second
+)
+
+// The VolumeSnapshot API is served by the CSI external-snapshotter CRDs,
+// which may not be installed. Its objects are handled as unstructured.
+var (
+	volumeSnapshotGVK          = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+	volumeSnapshotClassListGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshotClassList"}
+)
+
+// VolumeScanReconciler reconciles a VolumeScan object
+type VolumeScanReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Recorder     record.EventRecorder
+	ScannerImage string
+	ClamavHost   string
+	ClamavPort   int
+}
+
+// +kubebuilder:rbac:groups=clamav.io,resources=volumescans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=clamav.io,resources=volumescans/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=clamav.io,resources=scanpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=clamav.io,resources=clusterscanpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;create;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotclasses,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop
+func (r *VolumeScanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var volumeScan clamavv1alpha1.VolumeScan
+	if err := r.Get(ctx, req.NamespacedName, &volumeScan); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	switch volumeScan.Status.Phase {
+	case clamavv1alpha1.NodeScanPhaseCompleted, clamavv1alpha1.NodeScanPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+
+	policies, missing, err := r.scanPolicies(ctx, &volumeScan)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if missing != "" {
+		return r.failVolumeScan(ctx, &volumeScan, missing+"NotFound", fmt.Sprintf("%s does not exist", missing))
+	}
+
+	if volumeScan.Status.Phase == "" {
+		now := metav1.Now()
+		volumeScan.Status.StartTime = &now
+		volumeScan.Status.ParameterSources = clamavv1alpha1.ResolveVolumeScanParameters(&volumeScan.Spec, policies).Sources
+		setVolumeScanPhase(&volumeScan, clamavv1alpha1.NodeScanPhasePending, "Pending", metav1.ConditionTrue,
+			"Preparing the volume for the scan")
+	}
+
+	if volumeScan.Status.JobRef != nil {
+		return r.checkVolumeScanJob(ctx, &volumeScan)
+	}
+
+	var claim corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, types.NamespacedName{Name: volumeScan.Spec.PersistentVolumeClaim, Namespace: volumeScan.Namespace}, &claim); err != nil {
+		if errors.IsNotFound(err) {
+			return r.failVolumeScan(ctx, &volumeScan, "PersistentVolumeClaimNotFound",
+				fmt.Sprintf("PersistentVolumeClaim %s does not exist", volumeScan.Spec.PersistentVolumeClaim))
+		}
+		return ctrl.Result{}, err
+	}
+	if claim.Status.Phase != corev1.ClaimBound {
+		return r.failVolumeScan(ctx, &volumeScan, "PersistentVolumeClaimNotBound",
+			fmt.Sprintf("PersistentVolumeClaim %s is %s", claim.Name, claim.Status.Phase))
+	}
+	if claim.Spec.VolumeMode != nil && *claim.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return r.failVolumeScan(ctx, &volumeScan, "VolumeModeNotSupported",
+			fmt.Sprintf("PersistentVolumeClaim %s is a block volume without filesystem", claim.Name))
+	}
+
+	// The claim is either snapshotted and restored, or mounted as is
+	nodeName := ""
+	if volumeScan.Status.ClaimName == "" {
+		// The class is only looked up before the snapshot is taken
+		class := ""
+		if volumeScan.Status.SnapshotName == "" {
+			if class, err = r.volumeSnapshotClass(ctx, &volumeScan, &claim); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		switch {
+		case volumeScan.Status.SnapshotName != "" || class != "":
+			result, err := r.restoreVolumeSnapshot(ctx, &volumeScan, &claim, class)
+			if err != nil || volumeScan.Status.Phase == clamavv1alpha1.NodeScanPhaseFailed {
+				return result, err
+			}
+			if volumeScan.Status.ClaimName == "" {
+				if err := r.Status().Update(ctx, &volumeScan); err != nil {
+					return ctrl.Result{}, err
+				}
+				return result, nil
+			}
+		case volumeScan.Spec.Snapshot == clamavv1alpha1.VolumeSnapshotAlways:
+			return r.failVolumeScan(ctx, &volumeScan, "SnapshotUnavailable",
+				fmt.Sprintf("No VolumeSnapshotClass matches the volume of PersistentVolumeClaim %s", claim.Name))
+		default:
+			user, err := r.claimUser(ctx, &claim)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if user != nil && hasAccessMode(&claim, corev1.ReadWriteOncePod) {
+				return r.failVolumeScan(ctx, &volumeScan, "ClaimInUse",
+					fmt.Sprintf("PersistentVolumeClaim %s is ReadWriteOncePod and mounted by pod %s, scan a snapshot instead",
+						claim.Name, user.Name))
+			}
+			// A ReadWriteOnce volume is only attached to the node of its pod
+			if user != nil && !hasAccessMode(&claim, corev1.ReadWriteMany) && !hasAccessMode(&claim, corev1.ReadOnlyMany) {
+				nodeName = user.Spec.NodeName
+			}
+			volumeScan.Status.ClaimName = claim.Name
+		}
+	}
+
+	return r.startVolumeScan(ctx, &volumeScan, policies, nodeName)
+}
+
+// scanPolicies returns the policies of a VolumeScan, or the kind of the policy
+// that does not exist
+func (r *VolumeScanReconciler) scanPolicies(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan) (clamavv1alpha1.ScanPolicies, string, error) {
+	var policies clamavv1alpha1.ScanPolicies
+
+	if volumeScan.Spec.ScanPolicy != "" {
+		policies.Namespace = &clamavv1alpha1.ScanPolicy{}
+		err := r.Get(ctx, types.NamespacedName{Name: volumeScan.Spec.ScanPolicy, Namespace: volumeScan.Namespace}, policies.Namespace)
+		if errors.IsNotFound(err) {
+			return policies, "ScanPolicy", nil
+		} else if err != nil {
+			return policies, "", err
+		}
+	}
+
+	if volumeScan.Spec.ClusterScanPolicy != "" {
+		policies.Cluster = &clamavv1alpha1.ClusterScanPolicy{}
+		err := r.Get(ctx, types.NamespacedName{Name: volumeScan.Spec.ClusterScanPolicy}, policies.Cluster)
+		if errors.IsNotFound(err) {
+			return policies, "ClusterScanPolicy", nil
+		} else if err != nil {
+			return policies, "", err
+		}
+	}
+
+	return policies, "", nil
+}
+
+// volumeSnapshotClass returns the VolumeSnapshotClass used to snapshot a
+// claim, or an empty string if the claim is scanned without a snapshot. By
+// default it is a class of the CSI driver of the volume, preferably the
+// default one.
+func (r *VolumeScanReconciler) volumeSnapshotClass(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, claim *corev1.PersistentVolumeClaim) (string, error) {
+	if volumeScan.Spec.Snapshot == clamavv1alpha1.VolumeSnapshotNever {
+		return "", nil
+	}
+	if volumeScan.Spec.VolumeSnapshotClassName != "" {
+		return volumeScan.Spec.VolumeSnapshotClassName, nil
+	}
+
+	var volume corev1.PersistentVolume
+	if err := r.Get(ctx, types.NamespacedName{Name: claim.Spec.VolumeName}, &volume); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if volume.Spec.CSI == nil {
+		return "", nil
+	}
+
+	classes := &unstructured.UnstructuredList{}
+	classes.SetGroupVersionKind(volumeSnapshotClassListGVK)
+	if err := r.List(ctx, classes); err != nil {
+		// The snapshot CRDs are not installed
+		if meta.IsNoMatchError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	class := ""
+	for _, item := range classes.Items {
+		driver, _, _ := unstructured.NestedString(item.Object, "driver")
+		if driver != volume.Spec.CSI.Driver {
+			continue
+		}
+		if class == "" || item.GetAnnotations()[defaultSnapshotClassAnnotation] == "true" {
+			class = item.GetName()
+		}
+	}
+	return class, nil
+}
+
+// restoreVolumeSnapshot snapshots a claim and restores the snapshot to the
+// temporary claim scanned by the Job. It records the claim name in the status
+// once the restored claim exists, and requeues while the snapshot is not
+// ready.
+func (r *VolumeScanReconciler) restoreVolumeSnapshot(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, claim *corev1.PersistentVolumeClaim, class string) (ctrl.Result, error) {
+	name := volumeScanResourceName(volumeScan)
+
+	if volumeScan.Status.SnapshotName == "" {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		snapshot.SetName(name)
+		snapshot.SetNamespace(volumeScan.Namespace)
+		snapshot.SetLabels(volumeScanLabels(volumeScan, "volume-snapshot"))
+		snapshot.Object["spec"] = map[string]interface{}{
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": claim.Name},
+			"volumeSnapshotClassName": class,
+		}
+		if err := controllerutil.SetControllerReference(volumeScan, snapshot, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, snapshot); err != nil && !errors.IsAlreadyExists(err) {
+			if meta.IsNoMatchError(err) {
+				return r.failVolumeScan(ctx, volumeScan, "SnapshotUnavailable", "The VolumeSnapshot API is not installed")
+			}
+			return ctrl.Result{}, err
+		}
+
+		volumeScan.Status.SnapshotName = name
+		r.Recorder.Eventf(volumeScan, corev1.EventTypeNormal, "SnapshotCreated",
+			"Created VolumeSnapshot %s of PersistentVolumeClaim %s", name, claim.Name)
+		return ctrl.Result{RequeueAfter: volumeSnapshotRequeue}, nil
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	if err := r.Get(ctx, types.NamespacedName{Name: volumeScan.Status.SnapshotName, Namespace: volumeScan.Namespace}, snapshot); err != nil {
+		if errors.IsNotFound(err) {
+			return r.failVolumeScan(ctx, volumeScan, "SnapshotFailed",
+				fmt.Sprintf("VolumeSnapshot %s was deleted", volumeScan.Status.SnapshotName))
+		}
+		return ctrl.Result{}, err
+	}
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		return r.failVolumeScan(ctx, volumeScan, "SnapshotFailed",
+			fmt.Sprintf("VolumeSnapshot %s failed: %s", snapshot.GetName(), message))
+	}
+	if ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !ready {
+		setVolumeScanPhase(volumeScan, clamavv1alpha1.NodeScanPhasePending, "Pending", metav1.ConditionTrue,
+			fmt.Sprintf("Waiting for VolumeSnapshot %s to be ready", snapshot.GetName()))
+		return ctrl.Result{RequeueAfter: volumeSnapshotRequeue}, nil
+	}
+
+	// The restored claim is at least as large as the snapshot
+	size := claim.Status.Capacity[corev1.ResourceStorage]
+	if restoreSize, found, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); found {
+		if quantity, err := resource.ParseQuantity(restoreSize); err == nil {
+			size = quantity
+		}
+	}
+
+	restored := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: volumeScan.Namespace,
+			Labels:    volumeScanLabels(volumeScan, "volume-restore"),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: claim.Spec.StorageClassName,
+			VolumeMode:       claim.Spec.VolumeMode,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To(volumeSnapshotGVK.Group),
+				Kind:     volumeSnapshotGVK.Kind,
+				Name:     snapshot.GetName(),
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(volumeScan, restored, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, restored); err != nil && !errors.IsAlreadyExists(err) {
+		return ctrl.Result{}, err
+	}
+
+	volumeScan.Status.ClaimName = restored.Name
+	return ctrl.Result{}, nil
+}
+
+// claimUser returns a pod of the namespace of a claim that mounts it, or nil
+func (r *VolumeScanReconciler) claimUser(ctx context.Context, claim *corev1.PersistentVolumeClaim) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(claim.Namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claim.Name {
+				return pod, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// hasAccessMode reports whether a claim requests an access mode
+func hasAccessMode(claim *corev1.PersistentVolumeClaim, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range claim.Spec.AccessModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// startVolumeScan creates the scan Job of the claim recorded in the status.
+// The Job runs on nodeName, or where the scheduler can attach the volume.
+func (r *VolumeScanReconciler) startVolumeScan(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, policies clamavv1alpha1.ScanPolicies, nodeName string) (ctrl.Result, error) {
+	jobName := volumeScanResourceName(volumeScan)
+
+	if err := r.ensureVolumeScanResultConfigMap(ctx, volumeScan, jobName); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	job, err := r.constructVolumeScanJob(volumeScan, policies, jobName, nodeName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The Job may have been created by a reconcile whose status update failed
+	if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		r.Recorder.Event(volumeScan, corev1.EventTypeWarning, "JobCreationFailed",
+			fmt.Sprintf("Failed to create Job: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	volumeScan.Status.JobRef = &corev1.ObjectReference{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       job.Name,
+		Namespace:  job.Namespace,
+	}
+	setVolumeScanPhase(volumeScan, clamavv1alpha1.NodeScanPhaseRunning, "JobCreated", metav1.ConditionTrue,
+		"Scan job has been created")
+	r.Recorder.Eventf(volumeScan, corev1.EventTypeNormal, "JobCreated",
+		"Scan job created for PersistentVolumeClaim %s", volumeScan.Status.ClaimName)
+
+	if err := r.Status().Update(ctx, volumeScan); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// checkVolumeScanJob records the result of the scan Job once it finished
+func (r *VolumeScanReconciler) checkVolumeScanJob(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan) (ctrl.Result, error) {
+	var job batchv1.Job
+	err := r.Get(ctx, types.NamespacedName{Name: volumeScan.Status.JobRef.Name, Namespace: volumeScan.Namespace}, &job)
+	if errors.IsNotFound(err) {
+		return r.failVolumeScan(ctx, volumeScan, "ScanFailed", fmt.Sprintf("Scan job %s was deleted", volumeScan.Status.JobRef.Name))
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		if err := r.collectVolumeScanResult(ctx, volumeScan, &job); err != nil {
+			log.FromContext(ctx).Error(err, "failed to collect volume scan result", "job", job.Name)
+			return r.failVolumeScan(ctx, volumeScan, "ParseResultsFailed", fmt.Sprintf("Failed to collect the scan result: %v", err))
+		}
+	case jobFailed(&job):
+		return r.failVolumeScan(ctx, volumeScan, "ScanFailed", jobFailureMessage(&job))
+	default:
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	now := metav1.Now()
+	volumeScan.Status.CompletionTime = &now
+	if volumeScan.Status.StartTime != nil {
+		volumeScan.Status.Duration = int64(now.Sub(volumeScan.Status.StartTime.Time).Seconds())
+	}
+	setVolumeScanPhase(volumeScan, clamavv1alpha1.NodeScanPhaseCompleted, "ScanCompleted", metav1.ConditionTrue,
+		"Scan completed successfully")
+
+	r.Recorder.Eventf(volumeScan, corev1.EventTypeNormal, "ScanCompleted", "Scan completed: %d files scanned, %d infected",
+		volumeScan.Status.FilesScanned, volumeScan.Status.FilesInfected)
+	if volumeScan.Status.FilesInfected > 0 {
+		r.Recorder.Eventf(volumeScan, corev1.EventTypeWarning, "VolumeInfected", "PersistentVolumeClaim %s has %d infected files",
+			volumeScan.Spec.PersistentVolumeClaim, volumeScan.Status.FilesInfected)
+	}
+
+	if err := r.deleteVolumeSnapshot(ctx, volumeScan); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.Status().Update(ctx, volumeScan)
+}
+
+// collectVolumeScanResult records the result published by the scanner of a
+// completed Job
+func (r *VolumeScanReconciler) collectVolumeScanResult(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, job *batchv1.Job) error {
+	if job.Spec.Selector == nil {
+		return fmt.Errorf("job %s has no pod selector", job.Name)
+	}
+
+	// The scanner reports the node of its pod
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(job.Namespace), client.MatchingLabels(job.Spec.Selector.MatchLabels)); err != nil {
+		return err
+	}
+	nodeName := ""
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			nodeName = pod.Spec.NodeName
+			break
+		}
+	}
+	if nodeName == "" {
+		return fmt.Errorf("no completed pods found for job %s", job.Name)
+	}
+
+	result, err := readPublishedScanResult(ctx, r.Client, job.Namespace, jobResultConfigMapName(job.Name), nodeName)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return fmt.Errorf("scan job %s published no result", job.Name)
+	}
+
+	volumeScan.Status.NodeName = nodeName
+	applyVolumeScanResult(volumeScan, result)
+	return nil
+}
+
+// applyVolumeScanResult copies a scan result into the status of a VolumeScan,
+// with the paths of the infected files relative to the volume root
+func applyVolumeScanResult(volumeScan *clamavv1alpha1.VolumeScan, result *scanResult) {
+	stats := result.Statistics
+	volumeScan.Status.FilesScanned = stats.FilesScanned
+	volumeScan.Status.FilesInfected = stats.FilesInfected
+	volumeScan.Status.FilesSkipped = stats.FilesSkipped
+	volumeScan.Status.ErrorCount = stats.Errors
+
+	infectedFiles := make([]clamavv1alpha1.InfectedFile, 0, len(result.Infected))
+	for _, f := range result.Infected {
+		infectedFiles = append(infectedFiles, clamavv1alpha1.InfectedFile{
+			Path:    path.Join("/", strings.TrimPrefix(f.Path, volumeMountPath)),
+			Viruses: f.Viruses,
+			Size:    f.Size,
+		})
+	}
+
+	// Limit to 100 infected files for performance
+	if len(infectedFiles) > 100 {
+		infectedFiles = infectedFiles[:100]
+	}
+	volumeScan.Status.InfectedFiles = infectedFiles
+}
+
+// failVolumeScan records the failure of a VolumeScan
+func (r *VolumeScanReconciler) failVolumeScan(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, reason, message string) (ctrl.Result, error) {
+	r.Recorder.Event(volumeScan, corev1.EventTypeWarning, reason, message)
+
+	now := metav1.Now()
+	volumeScan.Status.CompletionTime = &now
+	setVolumeScanPhase(volumeScan, clamavv1alpha1.NodeScanPhaseFailed, reason, metav1.ConditionFalse, message)
+
+	if err := r.deleteVolumeSnapshot(ctx, volumeScan); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.Status().Update(ctx, volumeScan)
+}
+
+// setVolumeScanPhase records the phase of a VolumeScan and the condition explaining it
+func setVolumeScanPhase(volumeScan *clamavv1alpha1.VolumeScan, phase clamavv1alpha1.NodeScanPhase,
+	conditionType string, status metav1.ConditionStatus, message string) {
+
+	volumeScan.Status.Phase = phase
+	meta.SetStatusCondition(&volumeScan.Status.Conditions, metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  string(phase),
+		Message: message,
+	})
+}
+
+// deleteVolumeSnapshot deletes the snapshot of a finished scan and the claim
+// restored from it, which hold a copy of the volume data
+func (r *VolumeScanReconciler) deleteVolumeSnapshot(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan) error {
+	if volumeScan.Status.SnapshotName == "" {
+		return nil
+	}
+
+	if volumeScan.Status.ClaimName != "" && volumeScan.Status.ClaimName != volumeScan.Spec.PersistentVolumeClaim {
+		restored := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: volumeScan.Status.ClaimName, Namespace: volumeScan.Namespace},
+		}
+		if err := r.Delete(ctx, restored); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(volumeScan.Status.SnapshotName)
+	snapshot.SetNamespace(volumeScan.Namespace)
+	if err := r.Delete(ctx, snapshot); client.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	return nil
+}
+
+// volumeScanResourceName returns the name of the Job, snapshot and restored
+// claim of a VolumeScan
+func volumeScanResourceName(volumeScan *clamavv1alpha1.VolumeScan) string {
+	name := fmt.Sprintf("volumescan-%s", volumeScan.Name)
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// volumeScanLabels returns the labels of the objects created for a VolumeScan
+func volumeScanLabels(volumeScan *clamavv1alpha1.VolumeScan, component string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      "clamav",
+		"app.kubernetes.io/component": component,
+		"clamav.io/volumescan":        volumeScan.Name,
+	}
+}
+
+// ensureVolumeScanResultConfigMap creates the empty ConfigMap the scanner of
+// a Job publishes its result to
+func (r *VolumeScanReconciler) ensureVolumeScanResultConfigMap(ctx context.Context, volumeScan *clamavv1alpha1.VolumeScan, jobName string) error {
+	return ensureResultConfigMap(ctx, r.Client, r.Scheme, volumeScan, jobResultConfigMapName(jobName),
+		volumeScanLabels(volumeScan, "scan-result"))
+}
+
+// constructVolumeScanJob returns the Job scanning the claim recorded in the
+// status of a VolumeScan, mounted read-only
+func (r *VolumeScanReconciler) constructVolumeScanJob(volumeScan *clamavv1alpha1.VolumeScan, policies clamavv1alpha1.ScanPolicies, jobName, nodeName string) (*batchv1.Job, error) {
+	params := clamavv1alpha1.ResolveVolumeScanParameters(&volumeScan.Spec, policies)
+
+	paths := make([]string, 0, len(params.Paths))
+	for _, p := range params.Paths {
+		paths = append(paths, path.Join(volumeMountPath, p))
+	}
+
+	// The scanner reports the node the volume was mounted on
+	envVars := []corev1.EnvVar{
+		{
+			Name: "NODE_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+			},
+		},
+		{Name: "HOST_ROOT", Value: volumeMountPath},
+		{Name: "RESULTS_DIR", Value: "/results"},
+		{Name: "CLAMAV_HOST", Value: r.ClamavHost},
+		{Name: "CLAMAV_PORT", Value: fmt.Sprintf("%d", r.ClamavPort)},
+		{Name: "PATHS_TO_SCAN", Value: strings.Join(paths, ",")},
+		{Name: "MAX_CONCURRENT", Value: fmt.Sprintf("%d", params.MaxConcurrent)},
+		{Name: "FILE_TIMEOUT", Value: fmt.Sprintf("%d", params.FileTimeout)},
+		{Name: "CONNECT_TIMEOUT", Value: fmt.Sprintf("%d", params.ConnectTimeout)},
+		{Name: "MAX_FILE_SIZE", Value: fmt.Sprintf("%d", params.MaxFileSize)},
+		{Name: "RESULT_CONFIGMAP", Value: jobResultConfigMapName(jobName)},
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+			},
+		},
+	}
+
+	labels := volumeScanLabels(volumeScan, "volume-scanner")
+	labels["clamav.io/scan-priority"] = volumeScan.Spec.Priority
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: volumeScan.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(3)),
+			TTLSecondsAfterFinished: ptr.To(params.TTLSecondsAfterFinished),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":      "clamav-volume-scanner",
+						"security": "clamav",
+						"clamav":   "scanner",
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: "clamav-scanner",
+					// ReadWriteOnce volumes in use are scanned on the node of their pod
+					NodeName:  nodeName,
+					DNSPolicy: corev1.DNSClusterFirst,
+					SecurityContext: &corev1.PodSecurityContext{
+						// Reading the files of every owner
+						RunAsNonRoot: ptr.To(false),
+						RunAsUser:    ptr.To(int64(0)),
+					},
+					Tolerations: []corev1.Toleration{
+						{Operator: corev1.TolerationOpExists},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "scanner",
+							Image:           r.ScannerImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Env:             envVars,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "volume",
+									MountPath: volumeMountPath,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "scan-results",
+									MountPath: "/results",
+								},
+							},
+							Resources: params.Resources,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "volume",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: volumeScan.Status.ClaimName,
+									ReadOnly:  true,
+								},
+							},
+						},
+						{
+							Name:         "scan-results",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Set VolumeScan as owner
+	if err := controllerutil.SetControllerReference(volumeScan, job, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *VolumeScanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clamavv1alpha1.VolumeScan{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
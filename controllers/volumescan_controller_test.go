@@ -0,0 +1,289 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+func newTestVolumeScanReconciler(objs ...client.Object) *VolumeScanReconciler {
+	scheme := newTestScheme()
+	fakeClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&clamavv1alpha1.VolumeScan{}).
+		Build()
+
+	return &VolumeScanReconciler{
+		Client:       fakeClient,
+		Scheme:       scheme,
+		Recorder:     record.NewFakeRecorder(100),
+		ScannerImage: "test-scanner:latest",
+		ClamavHost:   "clamav.test.svc",
+		ClamavPort:   3310,
+	}
+}
+
+// newVolumeScanTestObjects returns a VolumeScan of a bound ReadWriteOnce
+// claim provisioned by a CSI driver
+func newVolumeScanTestObjects(snapshot clamavv1alpha1.VolumeSnapshotMode) (*clamavv1alpha1.VolumeScan, *corev1.PersistentVolumeClaim, *corev1.PersistentVolume) {
+	volumeScan := &clamavv1alpha1.VolumeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "uploads", Namespace: "shop"},
+		Spec: clamavv1alpha1.VolumeScanSpec{
+			PersistentVolumeClaim: "uploads",
+			Snapshot:              snapshot,
+			Paths:                 []string{"/media"},
+			Priority:              "medium",
+		},
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "uploads", Namespace: "shop"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: ptr.To("fast"),
+			VolumeName:       "pv-uploads",
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+	volume := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-uploads"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-1"},
+			},
+		},
+	}
+	return volumeScan, claim, volume
+}
+
+// newTestVolumeSnapshotClass returns a VolumeSnapshotClass of a CSI driver
+func newTestVolumeSnapshotClass(name, driver string, isDefault bool) *unstructured.Unstructured {
+	class := &unstructured.Unstructured{}
+	class.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotClass"))
+	class.SetName(name)
+	if isDefault {
+		class.SetAnnotations(map[string]string{defaultSnapshotClassAnnotation: "true"})
+	}
+	class.Object["driver"] = driver
+	class.Object["deletionPolicy"] = "Delete"
+	return class
+}
+
+func reconcileVolumeScan(t *testing.T, r *VolumeScanReconciler) (ctrl.Result, *clamavv1alpha1.VolumeScan) {
+	t.Helper()
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "uploads", Namespace: "shop"},
+	})
+	require.NoError(t, err)
+
+	var volumeScan clamavv1alpha1.VolumeScan
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "uploads", Namespace: "shop"}, &volumeScan))
+	return result, &volumeScan
+}
+
+func TestVolumeScanReconciler_Reconcile_MountsClaimOnNodeOfItsPod(t *testing.T) {
+	volumeScan, claim, volume := newVolumeScanTestObjects(clamavv1alpha1.VolumeSnapshotAuto)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "shop"},
+		Spec: corev1.PodSpec{
+			NodeName: "node-2",
+			Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "uploads"},
+			}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	// No VolumeSnapshotClass matches the driver of the volume
+	r := newTestVolumeScanReconciler(volumeScan, claim, volume, pod,
+		newTestVolumeSnapshotClass("gce", "pd.csi.storage.gke.io", true))
+
+	result, updated := reconcileVolumeScan(t, r)
+	assert.Equal(t, 30*time.Second, result.RequeueAfter)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseRunning, updated.Status.Phase)
+	assert.Equal(t, "uploads", updated.Status.ClaimName)
+	assert.Empty(t, updated.Status.SnapshotName)
+	assert.Equal(t, clamavv1alpha1.ParameterSourceVolumeScan, updated.Status.ParameterSources["paths"])
+	require.NotNil(t, updated.Status.JobRef)
+
+	var job batchv1.Job
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, &job))
+	spec := job.Spec.Template.Spec
+	assert.Equal(t, "node-2", spec.NodeName)
+	require.Len(t, spec.Volumes, 2)
+	require.NotNil(t, spec.Volumes[0].PersistentVolumeClaim)
+	assert.Equal(t, "uploads", spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.True(t, spec.Volumes[0].PersistentVolumeClaim.ReadOnly)
+	assert.True(t, spec.Containers[0].VolumeMounts[0].ReadOnly)
+	assert.False(t, spec.HostPID)
+
+	env := map[string]string{}
+	for _, e := range spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "/volume/media", env["PATHS_TO_SCAN"])
+	assert.Equal(t, "volumescan-uploads-result", env["RESULT_CONFIGMAP"])
+
+	var configMap corev1.ConfigMap
+	assert.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "volumescan-uploads-result", Namespace: "shop"}, &configMap))
+}
+
+func TestVolumeScanReconciler_Reconcile_ScansRestoredSnapshot(t *testing.T) {
+	volumeScan, claim, volume := newVolumeScanTestObjects(clamavv1alpha1.VolumeSnapshotAuto)
+	r := newTestVolumeScanReconciler(volumeScan, claim, volume,
+		newTestVolumeSnapshotClass("ebs-backup", "ebs.csi.aws.com", false),
+		newTestVolumeSnapshotClass("ebs-default", "ebs.csi.aws.com", true))
+	ctx := context.Background()
+
+	// The snapshot is taken with the default class of the driver
+	result, updated := reconcileVolumeScan(t, r)
+	assert.Equal(t, volumeSnapshotRequeue, result.RequeueAfter)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhasePending, updated.Status.Phase)
+	assert.Equal(t, "volumescan-uploads", updated.Status.SnapshotName)
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, snapshot))
+	class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "ebs-default", class)
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "uploads", source)
+
+	// The Job waits for the snapshot
+	_, updated = reconcileVolumeScan(t, r)
+	assert.Nil(t, updated.Status.JobRef)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, "12Gi", "status", "restoreSize"))
+	require.NoError(t, r.Update(ctx, snapshot))
+
+	_, updated = reconcileVolumeScan(t, r)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseRunning, updated.Status.Phase)
+	assert.Equal(t, "volumescan-uploads", updated.Status.ClaimName)
+
+	var restored corev1.PersistentVolumeClaim
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, &restored))
+	require.NotNil(t, restored.Spec.DataSource)
+	assert.Equal(t, "VolumeSnapshot", restored.Spec.DataSource.Kind)
+	assert.Equal(t, "volumescan-uploads", restored.Spec.DataSource.Name)
+	assert.Equal(t, "fast", *restored.Spec.StorageClassName)
+	assert.Equal(t, "12Gi", restored.Spec.Resources.Requests.Storage().String())
+
+	// The restored claim is scheduled by its volume, not pinned to a node
+	var job batchv1.Job
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, &job))
+	assert.Empty(t, job.Spec.Template.Spec.NodeName)
+	assert.Equal(t, "volumescan-uploads", job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+}
+
+func TestVolumeScanReconciler_Reconcile_SnapshotUnavailable(t *testing.T) {
+	volumeScan, claim, volume := newVolumeScanTestObjects(clamavv1alpha1.VolumeSnapshotAlways)
+	r := newTestVolumeScanReconciler(volumeScan, claim, volume)
+
+	_, updated := reconcileVolumeScan(t, r)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseFailed, updated.Status.Phase)
+	require.Len(t, updated.Status.Conditions, 2)
+	assert.Equal(t, "SnapshotUnavailable", updated.Status.Conditions[1].Type)
+}
+
+func TestVolumeScanReconciler_Reconcile_ClaimNotFound(t *testing.T) {
+	volumeScan, _, _ := newVolumeScanTestObjects(clamavv1alpha1.VolumeSnapshotNever)
+	r := newTestVolumeScanReconciler(volumeScan)
+
+	_, updated := reconcileVolumeScan(t, r)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseFailed, updated.Status.Phase)
+	assert.NotNil(t, updated.Status.CompletionTime)
+}
+
+func TestVolumeScanReconciler_Reconcile_CollectsResult(t *testing.T) {
+	volumeScan, claim, _ := newVolumeScanTestObjects(clamavv1alpha1.VolumeSnapshotAuto)
+	startTime := metav1.NewTime(time.Now().Add(-time.Minute))
+	volumeScan.Status = clamavv1alpha1.VolumeScanStatus{
+		Phase:        clamavv1alpha1.NodeScanPhaseRunning,
+		StartTime:    &startTime,
+		SnapshotName: "volumescan-uploads",
+		ClaimName:    "volumescan-uploads",
+		JobRef:       &corev1.ObjectReference{Name: "volumescan-uploads", Namespace: "shop"},
+	}
+	restored := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "volumescan-uploads", Namespace: "shop"}}
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName("volumescan-uploads")
+	snapshot.SetNamespace("shop")
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "volumescan-uploads", Namespace: "shop"},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": "volumescan-uploads"}},
+		},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "volumescan-uploads-abcde", Namespace: "shop",
+			Labels: map[string]string{"job-name": "volumescan-uploads"}},
+		Spec:   corev1.PodSpec{NodeName: "node-3"},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	scanResult := newTestScanResult()
+	scanResult.Node = "node-3"
+	scanResult.Infected = []scanResultInfectedFile{
+		{Path: "/volume/media/invoice.pdf.exe", Viruses: []string{"Win.Trojan.Agent"}, Size: 4096},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "volumescan-uploads-result", Namespace: "shop"},
+		BinaryData: map[string][]byte{scanResultKey: encodeTestScanResult(t, scanResult)},
+	}
+	r := newTestVolumeScanReconciler(volumeScan, claim, restored, snapshot, job, pod, configMap)
+	ctx := context.Background()
+
+	_, updated := reconcileVolumeScan(t, r)
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseCompleted, updated.Status.Phase)
+	assert.Equal(t, "node-3", updated.Status.NodeName)
+	assert.Equal(t, int64(40), updated.Status.FilesScanned)
+	assert.Equal(t, int64(1), updated.Status.FilesInfected)
+	require.Len(t, updated.Status.InfectedFiles, 1)
+	assert.Equal(t, "/media/invoice.pdf.exe", updated.Status.InfectedFiles[0].Path)
+	assert.NotZero(t, updated.Status.Duration)
+
+	// The copy of the volume data is deleted
+	err := r.Get(ctx, types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, &corev1.PersistentVolumeClaim{})
+	assert.True(t, errors.IsNotFound(err))
+	err = r.Get(ctx, types.NamespacedName{Name: "volumescan-uploads", Namespace: "shop"}, snapshot)
+	assert.True(t, errors.IsNotFound(err))
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "uploads", Namespace: "shop"}, &corev1.PersistentVolumeClaim{}))
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// containerdTaskDir holds the bundle of each running containerd container. The
+// rootfs directory of a bundle is the mounted root filesystem of the
+// container: its image layers under its writable layer.
+const containerdTaskDir = "/run/containerd/io.containerd.runtime.v2.task/k8s.io/"
+
+// resolveWorkloads returns the containers of the node selected by the
+// workloads of a NodeScan, with the paths of their root filesystems. Containers
+// that cannot be scanned are returned with the reason.
+func (r *NodeScanReconciler) resolveWorkloads(ctx context.Context, nodeScan *clamavv1alpha1.NodeScan) ([]clamavv1alpha1.WorkloadScanStatus, error) {
+	target := nodeScan.Spec.Workloads
+
+	podSelector := labels.Everything()
+	if target.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(target.PodSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod selector: %w", err)
+		}
+		podSelector = selector
+	}
+
+	namespaces, err := workloadNamespaces(ctx, r.Clientset, target)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := r.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeScan.Spec.NodeName).String(),
+		LabelSelector: podSelector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of node %s: %w", nodeScan.Spec.NodeName, err)
+	}
+
+	var workloads []clamavv1alpha1.WorkloadScanStatus
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeScan.Spec.NodeName || !podSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if namespaces != nil && !namespaces[pod.Namespace] {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			workloads = append(workloads, containerScanTarget(pod, status))
+		}
+	}
+	return workloads, nil
+}
+
+// workloadNamespaces returns the namespaces selected by a workload target, or
+// nil if pods of all namespaces are selected
+func workloadNamespaces(ctx context.Context, clientset kubernetes.Interface, target *clamavv1alpha1.WorkloadTarget) (map[string]bool, error) {
+	if len(target.Namespaces) == 0 && target.NamespaceSelector == nil {
+		return nil, nil
+	}
+
+	listed := map[string]bool{}
+	for _, namespace := range target.Namespaces {
+		listed[namespace] = true
+	}
+	if target.NamespaceSelector == nil {
+		return listed, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(target.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	namespaces := map[string]bool{}
+	for _, namespace := range namespaceList.Items {
+		if !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+		if len(listed) == 0 || listed[namespace.Name] {
+			namespaces[namespace.Name] = true
+		}
+	}
+	return namespaces, nil
+}
+
+// containerScanTarget returns the paths scanned for a container. Only the
+// root filesystems of running containerd containers are mounted at a path
+// derived from the container ID.
+func containerScanTarget(pod *corev1.Pod, status corev1.ContainerStatus) clamavv1alpha1.WorkloadScanStatus {
+	workload := clamavv1alpha1.WorkloadScanStatus{
+		Namespace:   pod.Namespace,
+		Pod:         pod.Name,
+		Container:   status.Name,
+		Image:       status.Image,
+		ContainerID: status.ContainerID,
+	}
+
+	runtime, id, _ := strings.Cut(status.ContainerID, "://")
+	switch {
+	case status.State.Running == nil || id == "":
+		workload.SkipReason = "container is not running"
+	case runtime != "containerd":
+		workload.SkipReason = fmt.Sprintf("container runtime %q is not supported", runtime)
+	default:
+		workload.Paths = []string{path.Join(hostRootMount, containerdTaskDir, id, "rootfs")}
+	}
+	return workload
+}
+
+// workloadScanPaths returns the paths of all the scanned containers
+func workloadScanPaths(workloads []clamavv1alpha1.WorkloadScanStatus) []string {
+	var paths []string
+	for _, workload := range workloads {
+		paths = append(paths, workload.Paths...)
+	}
+	return paths
+}
+
+// applyWorkloadPaths points the scanner of a Job at the container root
+// filesystems. The host root is mounted with HostToContainer propagation so
+// the scanner sees the root filesystems mounted by the runtime.
+func applyWorkloadPaths(job *batchv1.Job, paths []string) {
+	container := &job.Spec.Template.Spec.Containers[0]
+	for i := range container.Env {
+		if container.Env[i].Name == "PATHS_TO_SCAN" {
+			container.Env[i].Value = strings.Join(paths, ",")
+		}
+	}
+	for i := range container.VolumeMounts {
+		if container.VolumeMounts[i].Name == "host-root" {
+			container.VolumeMounts[i].MountPropagation = ptr.To(corev1.MountPropagationHostToContainer)
+		}
+	}
+}
+
+// countWorkloadInfections records the number of infected files found in the
+// root filesystem of each scanned container
+func countWorkloadInfections(nodeScan *clamavv1alpha1.NodeScan, result *scanResult) {
+	for i := range nodeScan.Status.Workloads {
+		workload := &nodeScan.Status.Workloads[i]
+		workload.FilesInfected = 0
+		for _, file := range result.Infected {
+			for _, p := range workload.Paths {
+				if file.Path == p || strings.HasPrefix(file.Path, p+"/") {
+					workload.FilesInfected++
+					break
+				}
+			}
+		}
+	}
+}
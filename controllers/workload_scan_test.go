@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clamavv1alpha1 "github.com/SolucTeam/clamav-operator/api/v1alpha1"
+)
+
+// newWorkloadTestPod returns a running pod of worker-1 with one container
+func newWorkloadTestPod(namespace, name, containerID string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:        "app",
+				Image:       "registry.example.com/" + name + ":1.0",
+				ContainerID: containerID,
+				State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+func TestResolveWorkloads(t *testing.T) {
+	web := newWorkloadTestPod("shop", "web", "containerd://a1b2c3", map[string]string{"app": "web"})
+	crio := newWorkloadTestPod("shop", "api", "cri-o://d4e5f6", map[string]string{"app": "api"})
+	waiting := newWorkloadTestPod("shop", "worker", "", map[string]string{"app": "worker"})
+	waiting.Status.ContainerStatuses[0].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+	done := newWorkloadTestPod("shop", "migrate", "containerd://0a0a0a", map[string]string{"app": "migrate"})
+	done.Status.Phase = corev1.PodSucceeded
+	other := newWorkloadTestPod("shop", "web-2", "containerd://ffffff", map[string]string{"app": "web"})
+	other.Spec.NodeName = "worker-2"
+	system := newWorkloadTestPod("kube-system", "dns", "containerd://b0b0b0", map[string]string{"app": "dns"})
+
+	r := newTestNodeScanReconciler()
+	r.Clientset = fake.NewSimpleClientset(web, crio, waiting, done, other, system,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"scan": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}})
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName: "worker-1",
+			Workloads: &clamavv1alpha1.WorkloadTarget{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"scan": "true"}},
+			},
+		},
+	}
+
+	workloads, err := r.resolveWorkloads(context.Background(), nodeScan)
+	require.NoError(t, err)
+	require.Len(t, workloads, 3)
+	assert.Equal(t, clamavv1alpha1.WorkloadScanStatus{
+		Namespace:   "shop",
+		Pod:         "web",
+		Container:   "app",
+		Image:       "registry.example.com/web:1.0",
+		ContainerID: "containerd://a1b2c3",
+		Paths:       []string{"/host/run/containerd/io.containerd.runtime.v2.task/k8s.io/a1b2c3/rootfs"},
+	}, workloads[1])
+	assert.Equal(t, `container runtime "cri-o" is not supported`, workloads[0].SkipReason)
+	assert.Empty(t, workloads[0].Paths)
+	assert.Equal(t, "container is not running", workloads[2].SkipReason)
+
+	// Pod selectors and namespace lists narrow the selection
+	nodeScan.Spec.Workloads = &clamavv1alpha1.WorkloadTarget{
+		Namespaces:  []string{"shop", "kube-system"},
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dns"}},
+	}
+	workloads, err = r.resolveWorkloads(context.Background(), nodeScan)
+	require.NoError(t, err)
+	require.Len(t, workloads, 1)
+	assert.Equal(t, "dns", workloads[0].Pod)
+	assert.Equal(t, []string{"/host/run/containerd/io.containerd.runtime.v2.task/k8s.io/b0b0b0/rootfs"},
+		workloadScanPaths(workloads))
+}
+
+func TestApplyWorkloadPaths(t *testing.T) {
+	r := newTestNodeScanReconciler()
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName:  "worker-1",
+			Workloads: &clamavv1alpha1.WorkloadTarget{},
+		},
+	}
+	job, err := r.constructJobForNodeScan(nodeScan, clamavv1alpha1.ScanPolicies{})
+	require.NoError(t, err)
+
+	applyWorkloadPaths(job, []string{"/host/run/a/rootfs", "/host/run/b/rootfs"})
+	scanner := job.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, scanner.Env, corev1.EnvVar{Name: "PATHS_TO_SCAN", Value: "/host/run/a/rootfs,/host/run/b/rootfs"})
+	require.Equal(t, "host-root", scanner.VolumeMounts[0].Name)
+	require.NotNil(t, scanner.VolumeMounts[0].MountPropagation)
+	assert.Equal(t, corev1.MountPropagationHostToContainer, *scanner.VolumeMounts[0].MountPropagation)
+}
+
+func TestCountWorkloadInfections(t *testing.T) {
+	nodeScan := &clamavv1alpha1.NodeScan{Status: clamavv1alpha1.NodeScanStatus{
+		Workloads: []clamavv1alpha1.WorkloadScanStatus{
+			{Namespace: "shop", Pod: "web", Container: "app", Paths: []string{"/host/run/a1/rootfs"}},
+			{Namespace: "shop", Pod: "api", Container: "app", Paths: []string{"/host/run/a10/rootfs"}},
+			{Namespace: "shop", Pod: "worker", Container: "app", SkipReason: "container is not running"},
+		},
+	}}
+	result := &scanResult{Infected: []scanResultInfectedFile{
+		{Path: "/host/run/a1/rootfs/tmp/miner"},
+		{Path: "/host/run/a1/rootfs/var/www/shell.php"},
+		{Path: "/host/run/a10/rootfs/tmp/miner"},
+	}}
+
+	countWorkloadInfections(nodeScan, result)
+	assert.Equal(t, int64(2), nodeScan.Status.Workloads[0].FilesInfected)
+	assert.Equal(t, int64(1), nodeScan.Status.Workloads[1].FilesInfected)
+	assert.Zero(t, nodeScan.Status.Workloads[2].FilesInfected)
+}
+
+func TestNodeScanReconciler_Reconcile_NoWorkloadsFound(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	nodeScan := &clamavv1alpha1.NodeScan{
+		ObjectMeta: metav1.ObjectMeta{Name: "scan-worker-1", Namespace: "default"},
+		Spec: clamavv1alpha1.NodeScanSpec{
+			NodeName:  "worker-1",
+			Workloads: &clamavv1alpha1.WorkloadTarget{Namespaces: []string{"shop"}},
+		},
+	}
+	r := newTestNodeScanReconciler(node, nodeScan)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "scan-worker-1", Namespace: "default"}}
+
+	// Without selected containers the scan completes without a Job
+	_, err := r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	var updated clamavv1alpha1.NodeScan
+	require.NoError(t, r.Get(context.Background(), req.NamespacedName, &updated))
+	assert.Equal(t, clamavv1alpha1.NodeScanPhaseCompleted, updated.Status.Phase)
+	assert.Equal(t, clamavv1alpha1.ParameterSourceWorkloads, updated.Status.ParameterSources["paths"])
+	var job batchv1.Job
+	assert.Error(t, r.Get(context.Background(), types.NamespacedName{Name: "nodescan-scan-worker-1", Namespace: "default"}, &job))
+
+	// Containers started later are not scanned by the completed NodeScan
+	r.Clientset = fake.NewSimpleClientset(newWorkloadTestPod("shop", "web", "containerd://a1b2c3", nil))
+	_, err = r.Reconcile(context.Background(), req)
+	require.NoError(t, err)
+	assert.Error(t, r.Get(context.Background(), types.NamespacedName{Name: "nodescan-scan-worker-1", Namespace: "default"}, &job))
+
+	// A selected container is scanned by a Job
+	fresh := nodeScan.DeepCopy()
+	fresh.Name, fresh.ResourceVersion = "scan-worker-1b", ""
+	require.NoError(t, r.Create(context.Background(), fresh))
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "scan-worker-1b", Namespace: "default"}})
+	require.NoError(t, err)
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "nodescan-scan-worker-1b", Namespace: "default"}, &job))
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  "PATHS_TO_SCAN",
+		Value: "/host/run/containerd/io.containerd.runtime.v2.task/k8s.io/a1b2c3/rootfs",
+	})
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "scan-worker-1b", Namespace: "default"}, &updated))
+	require.Len(t, updated.Status.Workloads, 1)
+	assert.Equal(t, "web", updated.Status.Workloads[0].Pod)
+}
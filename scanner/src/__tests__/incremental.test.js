@@ -6,6 +6,8 @@ describe('incremental', () => {
     // Force incremental mode for tests
     process.env.INCREMENTAL_ENABLED = 'true';
     process.env.SCAN_STRATEGY = 'incremental';
+    delete process.env.SCAN_CACHE;
+    delete process.env.SCAN_CACHE_FILE;
     // Clear module caches
     delete require.cache[require.resolve('../config')];
     delete require.cache[require.resolve('../incremental')];
@@ -53,6 +55,17 @@ describe('incremental', () => {
     assert.equal(result.reason, 'modified');
   });
 
+  it('shouldScanFile rescans unchanged infected files', () => {
+    const { shouldScanFile, updateCache } = require('../incremental');
+    const fakeStats = { mtimeMs: 1000000, size: 512 };
+
+    updateCache('/tmp/infected.txt', fakeStats, 'infected');
+
+    const result = shouldScanFile('/tmp/infected.txt', fakeStats, 'incremental');
+    assert.equal(result.shouldScan, true);
+    assert.equal(result.reason, 'known_infected');
+  });
+
   it('getIncrementalStats returns a snapshot', () => {
     const { getIncrementalStats } = require('../incremental');
     const stats = getIncrementalStats();
@@ -62,4 +75,44 @@ describe('incremental', () => {
     assert.equal(typeof stats.newFiles, 'number');
     assert.equal(typeof stats.modifiedFiles, 'number');
   });
+
+  it('loadCache applies the cache provided by the operator', async () => {
+    process.env.RESULTS_DIR = '/nonexistent';
+    const now = Math.floor(Date.now() / 1000);
+    process.env.SCAN_CACHE = JSON.stringify([
+      { path: '/tmp/operator.txt', modTime: 1000, size: 512, lastScanned: now, scanResult: 'clean' },
+    ]);
+    const { loadCache, shouldScanFile } = require('../incremental');
+
+    await loadCache();
+
+    const result = shouldScanFile('/tmp/operator.txt', { mtimeMs: 1000000, size: 512 }, 'incremental');
+    assert.equal(result.shouldScan, false);
+  });
+
+  it('loadCache keeps the infections known to the operator', async () => {
+    process.env.RESULTS_DIR = '/nonexistent';
+    const now = Math.floor(Date.now() / 1000);
+    process.env.SCAN_CACHE = JSON.stringify([
+      { path: '/tmp/eicar.com', modTime: 1000, size: 68, lastScanned: now, scanResult: 'infected' },
+    ]);
+    const { loadCache, shouldScanFile } = require('../incremental');
+
+    await loadCache();
+
+    const result = shouldScanFile('/tmp/eicar.com', { mtimeMs: 1000000, size: 68 }, 'incremental');
+    assert.equal(result.shouldScan, true);
+  });
+
+  it('getUpdatedEntries returns the entries written during the run', () => {
+    const { updateCache, getUpdatedEntries } = require('../incremental');
+
+    updateCache('/tmp/updated.txt', { mtimeMs: 2000000, size: 64 }, 'infected');
+
+    const entries = getUpdatedEntries();
+    assert.equal(entries.length, 1);
+    assert.equal(entries[0].path, '/tmp/updated.txt');
+    assert.equal(entries[0].modTime, 2000);
+    assert.equal(entries[0].scanResult, 'infected');
+  });
 });
@@ -0,0 +1,59 @@
+const { describe, it } = require('node:test');
+const assert = require('node:assert/strict');
+const zlib = require('zlib');
+
+describe('result', () => {
+  const stats = { filesScanned: 10, filesInfected: 1, filesSkipped: 2, errors: 1, startTime: Date.now() - 5000 };
+  const incrementalStats = { filesSkipped: 3 };
+  const results = {
+    infected: [{ file: '/host/var/lib/eicar.com', viruses: ['Eicar-Test-Signature'], size: 68 }],
+    errors: [{ file: '/host/var/lib/locked', error: 'EACCES' }],
+    skipped: [{ file: '/host/var/lib/big.iso', reason: 'too_large' }],
+  };
+
+  it('buildScanResult produces a versioned document', () => {
+    const { buildScanResult, RESULT_API_VERSION } = require('../result');
+
+    const result = buildScanResult(results, stats, incrementalStats, 'incremental');
+
+    assert.equal(result.apiVersion, RESULT_API_VERSION);
+    assert.equal(result.status, 'INFECTED');
+    assert.equal(result.strategy, 'incremental');
+    assert.equal(result.statistics.filesScanned, 10);
+    assert.equal(result.statistics.filesSkippedIncremental, 3);
+    assert.deepEqual(result.infected[0], {
+      path: '/host/var/lib/eicar.com',
+      viruses: ['Eicar-Test-Signature'],
+      size: 68,
+    });
+    assert.deepEqual(result.errors[0], { path: '/host/var/lib/locked', error: 'EACCES' });
+    assert.deepEqual(result.skipped[0], { path: '/host/var/lib/big.iso', reason: 'too_large' });
+  });
+
+  it('buildScanResult references the report by file name', () => {
+    const { buildScanResult } = require('../result');
+
+    const result = buildScanResult(results, stats, incrementalStats, 'full', [], '/results/scan-node-1.json');
+
+    assert.equal(result.reportFile, 'scan-node-1.json');
+  });
+
+  it('encodeScanResult gzips the document', () => {
+    const { buildScanResult, encodeScanResult } = require('../result');
+
+    const result = buildScanResult(results, stats, incrementalStats, 'full');
+    const decoded = JSON.parse(zlib.gunzipSync(encodeScanResult(result)).toString('utf-8'));
+
+    assert.deepEqual(decoded, result);
+  });
+
+  it('publishScanResult is a no-op without a result ConfigMap', async () => {
+    delete process.env.RESULT_CONFIGMAP;
+    delete require.cache[require.resolve('../config')];
+    delete require.cache[require.resolve('../result')];
+    const { buildScanResult, publishScanResult } = require('../result');
+
+    const published = await publishScanResult(buildScanResult(results, stats, incrementalStats, 'full'));
+    assert.equal(published, false);
+  });
+});
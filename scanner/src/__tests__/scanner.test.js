@@ -0,0 +1,22 @@
+const { describe, it } = require('node:test');
+const assert = require('node:assert/strict');
+
+const { shouldExclude } = require('../scanner');
+
+describe('shouldExclude', () => {
+  it('excludes pseudo filesystems of the host', () => {
+    assert.equal(shouldExclude('/host/proc/1/environ', '/host'), true);
+    assert.equal(shouldExclude('/host/run/containerd/containerd.sock', '/host'), true);
+    assert.equal(shouldExclude('/host/var/lib/app/data.bin', '/host/var/lib'), false);
+  });
+
+  it('matches below the scanned root', () => {
+    const rootfs = '/host/run/containerd/io.containerd.runtime.v2.task/k8s.io/a1b2c3/rootfs';
+    assert.equal(shouldExclude(`${rootfs}/tmp/miner`, rootfs), false);
+    assert.equal(shouldExclude(`${rootfs}/proc/self/maps`, rootfs), true);
+  });
+
+  it('matches the full path without a root', () => {
+    assert.equal(shouldExclude('/host/run/app.pid'), true);
+  });
+});
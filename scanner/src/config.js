@@ -46,6 +46,12 @@ const CONFIG = {
   // scanning.  In air-gap (false) signatures must already be in the image.
   updateSignatures: process.env.UPDATE_SIGNATURES === 'true',
 
+  // ── Result channel ──────────────────────────────────────────────────────
+  // ConfigMap (pre-created by the operator) that receives the versioned
+  // scan result.  When unset the operator falls back to parsing pod logs.
+  resultConfigMap: process.env.RESULT_CONFIGMAP || '',
+  podNamespace: process.env.POD_NAMESPACE || '',
+
   // ── File exclusion patterns ─────────────────────────────────────────────
   excludePatterns: [
     /\/proc\//,
@@ -69,6 +75,12 @@ const INCREMENTAL_CONFIG = {
   skipUnchangedFiles: process.env.SKIP_UNCHANGED_FILES !== 'false',
   // For "smart" strategy: run a full scan every N incremental runs
   fullScanInterval: parseInt(process.env.FULL_SCAN_INTERVAL || '10', 10),
+  // Cache provided by the operator (ScanCacheResource): inline JSON for small
+  // caches, or a file mounted from a ConfigMap (gzip when it ends in .gz)
+  operatorCache: process.env.SCAN_CACHE || '',
+  operatorCacheFile: process.env.SCAN_CACHE_FILE || '',
+  // Maximum number of cache entries reported back to the operator
+  maxExportedEntries: parseInt(process.env.MAX_EXPORTED_CACHE_ENTRIES || '10000', 10),
 };
 
 module.exports = { CONFIG, INCREMENTAL_CONFIG };
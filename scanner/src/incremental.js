@@ -12,6 +12,7 @@ You may obtain a copy of the License at
 
 const fs = require('fs').promises;
 const path = require('path');
+const zlib = require('zlib');
 const { INCREMENTAL_CONFIG } = require('./config');
 const logger = require('./logger');
 
@@ -25,6 +26,9 @@ const logger = require('./logger');
 
 let SCAN_CACHE = {};
 
+// Paths whose cache entry was written during this run (reported to the operator)
+const UPDATED_PATHS = new Set();
+
 const CACHE_FILE = path.join(
   process.env.RESULTS_DIR || '/results',
   `${process.env.NODE_NAME || 'unknown'}_scan_cache.json`
@@ -64,6 +68,59 @@ async function loadCache() {
     logger.info('Pas de cache précédent trouvé — scan complet');
     SCAN_CACHE = {};
   }
+
+  await loadOperatorCache();
+}
+
+/**
+ * Overlay the cache provided by the operator (ScanCacheResource) on top of the
+ * node-local cache.  Entries use the FileMetadata shape of the CRD:
+ * { path, modTime, size, lastScanned, scanResult }.
+ */
+async function loadOperatorCache() {
+  let entries = [];
+  try {
+    if (INCREMENTAL_CONFIG.operatorCacheFile) {
+      let raw = await fs.readFile(INCREMENTAL_CONFIG.operatorCacheFile);
+      if (INCREMENTAL_CONFIG.operatorCacheFile.endsWith('.gz')) {
+        raw = zlib.gunzipSync(raw);
+      }
+      entries = JSON.parse(raw.toString('utf-8'));
+    } else if (INCREMENTAL_CONFIG.operatorCache) {
+      entries = JSON.parse(INCREMENTAL_CONFIG.operatorCache);
+    }
+  } catch (err) {
+    logger.warn('Cache opérateur illisible — ignoré', { error: err.message });
+    return;
+  }
+
+  if (!Array.isArray(entries) || entries.length === 0) return;
+
+  for (const entry of entries) {
+    if (!entry || !entry.path) continue;
+    SCAN_CACHE[entry.path] = {
+      modTime: entry.modTime,
+      size: entry.size,
+      lastScanned: entry.lastScanned,
+      scanResult: entry.scanResult,
+    };
+  }
+  logger.info('Cache opérateur chargé', { entries: entries.length });
+}
+
+/**
+ * Returns the cache entries written during this run, in the FileMetadata shape
+ * expected by the operator, capped at maxExportedEntries.
+ */
+function getUpdatedEntries() {
+  const entries = [];
+  for (const filePath of UPDATED_PATHS) {
+    if (entries.length >= INCREMENTAL_CONFIG.maxExportedEntries) break;
+    const cached = SCAN_CACHE[filePath];
+    if (!cached) continue;
+    entries.push({ path: filePath, ...cached });
+  }
+  return entries;
 }
 
 async function saveCache() {
@@ -160,6 +217,12 @@ function shouldScanFile(filePath, fileStats, effectiveStrategy) {
     return { shouldScan: true, reason: 'modified' };
   }
 
+  // Known infections are rescanned so that every run reports them until they
+  // are remediated
+  if (cached.scanResult === 'infected') {
+    return { shouldScan: true, reason: 'known_infected' };
+  }
+
   // Check max age — rescan even if unchanged after N hours
   if (INCREMENTAL_CONFIG.maxFileAgeHours > 0 && cached.lastScanned) {
     const ageHours = (Date.now() / 1000 - cached.lastScanned) / 3600;
@@ -181,6 +244,7 @@ function updateCache(filePath, fileStats, scanResult) {
     lastScanned: Math.floor(Date.now() / 1000),
     scanResult, // 'clean' | 'infected'
   };
+  UPDATED_PATHS.add(filePath);
 }
 
 module.exports = {
@@ -189,5 +253,6 @@ module.exports = {
   resolveEffectiveStrategy,
   shouldScanFile,
   updateCache,
+  getUpdatedEntries,
   getIncrementalStats,
 };
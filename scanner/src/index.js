@@ -32,13 +32,18 @@ const logger = require('./logger');
 const { initScanner } = require('./init-scanner');
 const { scanDirectory, getStats } = require('./scanner');
 const { generateReport } = require('./report');
+const { buildScanResult, publishScanResult } = require('./result');
 const {
   loadCache,
   saveCache,
   resolveEffectiveStrategy,
+  getUpdatedEntries,
   getIncrementalStats,
 } = require('./incremental');
 
+// Number of cache entries per exported log line (keeps lines well below 1 MiB)
+const CACHE_EXPORT_CHUNK = 100;
+
 // =============================================================================
 // MAIN
 // =============================================================================
@@ -53,7 +58,7 @@ async function main() {
     update_signatures: CONFIG.updateSignatures,
   });
 
-  const results = { infected: [], errors: [] };
+  const results = { infected: [], errors: [], skipped: [] };
 
   try {
     // ── Ensure results directory exists ────────────────────────────────────
@@ -86,15 +91,33 @@ async function main() {
     // ── Generate report files ─────────────────────────────────────────────
     const stats = getStats();
     const incrementalStats = getIncrementalStats();
-    await generateReport(results, stats, incrementalStats, effectiveStrategy);
+    const reportPath = await generateReport(results, stats, incrementalStats, effectiveStrategy);
+
+    // ── Publish the versioned result to the operator ──────────────────────
+    const cacheEntries = INCREMENTAL_CONFIG.enabled ? getUpdatedEntries() : [];
+    const published = await publishScanResult(
+      buildScanResult(results, stats, incrementalStats, effectiveStrategy, cacheEntries, reportPath)
+    );
+
+    // ── Fallback: report updated cache entries through the logs ──────────
+    if (!published) {
+      for (let i = 0; i < cacheEntries.length; i += CACHE_EXPORT_CHUNK) {
+        logger.info('Entrées du cache exportées', {
+          alert: 'SCAN_CACHE_ENTRIES',
+          cache_entries: cacheEntries.slice(i, i + CACHE_EXPORT_CHUNK),
+        });
+      }
+    }
 
-    // ── Final log line — parsed by the Go operator ────────────────────────
+    // ── Final log line — fallback parsed by the Go operator ──────────────
     logger.info('Scan terminé avec succès', {
       duration: Math.round((Date.now() - stats.startTime) / 1000),
       files_scanned: stats.filesScanned,
       files_infected: stats.filesInfected,
       files_skipped: stats.filesSkipped,
       errors_count: stats.errors,
+      strategy: effectiveStrategy,
+      files_skipped_incremental: incrementalStats.filesSkipped,
       status: results.infected.length > 0 ? 'INFECTED' : 'CLEAN',
     });
 
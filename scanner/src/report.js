@@ -22,11 +22,11 @@ const logger = require('./logger');
  * The text summary is used by the operator controller to quickly determine
  * the scan outcome (STATUS=CLEAN|INFECTED) without parsing JSON.
  *
- * @param {object} results          – { infected: [], errors: [] }
+ * @param {object} results          – { infected: [], errors: [], skipped: [] }
  * @param {object} stats            – from scanner.getStats()
  * @param {object} incrementalStats – from incremental.getIncrementalStats()
  * @param {string} effectiveStrategy
- * @returns {object} The report object (for logging)
+ * @returns {Promise<string>} Path of the JSON report
  */
 async function generateReport(results, stats, incrementalStats, effectiveStrategy) {
   const duration = Math.round((Date.now() - stats.startTime) / 1000);
@@ -57,6 +57,7 @@ async function generateReport(results, stats, incrementalStats, effectiveStrateg
     infected: results.infected,
     // Cap errors in the report to avoid oversized payloads
     errors: results.errors.slice(0, 100),
+    skipped: results.skipped.slice(0, 100),
   };
 
   // ── Write JSON report ──────────────────────────────────────────────────
@@ -85,7 +86,7 @@ async function generateReport(results, stats, incrementalStats, effectiveStrateg
   await fs.writeFile(summaryPath, summaryLines.join('\n'));
 
   logger.info('Rapport généré', { reportPath, summaryPath });
-  return report;
+  return reportPath;
 }
 
 module.exports = { generateReport };
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The ClamAV Operator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+*/
+
+'use strict';
+
+const fs = require('fs').promises;
+const https = require('https');
+const path = require('path');
+const zlib = require('zlib');
+
+const { CONFIG } = require('./config');
+const logger = require('./logger');
+
+// =============================================================================
+// Scan result contract
+//
+// The scan result is a versioned JSON document that the operator reads from
+// a ConfigMap it creates before the Job starts (RESULT_CONFIGMAP).  It is
+// stored gzip-compressed under RESULT_KEY.  Any change to the shape of the
+// document must bump RESULT_API_VERSION; the operator validates the version.
+// =============================================================================
+
+const RESULT_API_VERSION = 'clamav.io/scan-result/v1';
+const RESULT_KEY = 'result.json.gz';
+
+// ConfigMaps are limited to 1 MiB — keep some headroom for metadata
+const MAX_RESULT_BYTES = 900 * 1024;
+
+const SERVICE_ACCOUNT_DIR = '/var/run/secrets/kubernetes.io/serviceaccount';
+
+/**
+ * Build the scan result document.
+ *
+ * @param {object} results          – { infected: [], errors: [], skipped: [] }
+ * @param {object} stats            – from scanner.getStats()
+ * @param {object} incrementalStats – from incremental.getIncrementalStats()
+ * @param {string} effectiveStrategy
+ * @param {Array}  cacheEntries     – from incremental.getUpdatedEntries()
+ * @param {string} reportPath       – JSON report written to RESULTS_DIR
+ */
+function buildScanResult(results, stats, incrementalStats, effectiveStrategy, cacheEntries = [], reportPath = '') {
+  return {
+    apiVersion: RESULT_API_VERSION,
+    node: CONFIG.nodeName,
+    status: results.infected.length > 0 ? 'INFECTED' : 'CLEAN',
+    strategy: effectiveStrategy,
+    startTime: new Date(stats.startTime).toISOString(),
+    completionTime: new Date().toISOString(),
+    duration: Math.round((Date.now() - stats.startTime) / 1000),
+    truncated: false,
+    statistics: {
+      filesScanned: stats.filesScanned,
+      filesInfected: stats.filesInfected,
+      filesSkipped: stats.filesSkipped,
+      filesSkippedIncremental: incrementalStats.filesSkipped,
+      errors: stats.errors,
+    },
+    infected: results.infected.map((f) => ({
+      path: f.file,
+      viruses: f.viruses || [],
+      size: f.size || 0,
+    })),
+    errors: results.errors.map((e) => ({ path: e.file, error: e.error })),
+    skipped: (results.skipped || []).map((f) => ({ path: f.file, reason: f.reason })),
+    reportFile: reportPath ? path.basename(reportPath) : '',
+    cacheEntries,
+  };
+}
+
+/**
+ * Gzip the result, dropping the least important data until it fits in a
+ * ConfigMap: cache entries first, then skipped files, errors and finally
+ * infected files.
+ * The statistics always reflect the full scan.
+ */
+function encodeScanResult(result) {
+  const doc = { ...result };
+  let encoded = zlib.gzipSync(JSON.stringify(doc));
+
+  for (const field of ['cacheEntries', 'skipped', 'errors', 'infected']) {
+    while (encoded.length > MAX_RESULT_BYTES && doc[field].length > 0) {
+      doc[field] = doc[field].slice(0, Math.floor(doc[field].length / 2));
+      doc.truncated = true;
+      encoded = zlib.gzipSync(JSON.stringify(doc));
+    }
+  }
+
+  return encoded;
+}
+
+/**
+ * Merge-patch the result ConfigMap through the Kubernetes API using the
+ * pod's service account.
+ */
+async function patchConfigMap(namespace, name, body) {
+  const [token, ca] = await Promise.all([
+    fs.readFile(`${SERVICE_ACCOUNT_DIR}/token`, 'utf-8'),
+    fs.readFile(`${SERVICE_ACCOUNT_DIR}/ca.crt`),
+  ]);
+  const payload = JSON.stringify(body);
+
+  return new Promise((resolve, reject) => {
+    const req = https.request(
+      {
+        host: process.env.KUBERNETES_SERVICE_HOST,
+        port: process.env.KUBERNETES_SERVICE_PORT || 443,
+        method: 'PATCH',
+        path: `/api/v1/namespaces/${encodeURIComponent(namespace)}/configmaps/${encodeURIComponent(name)}`,
+        ca,
+        headers: {
+          Authorization: `Bearer ${token.trim()}`,
+          'Content-Type': 'application/merge-patch+json',
+          'Content-Length': Buffer.byteLength(payload),
+        },
+        timeout: 30000,
+      },
+      (res) => {
+        let data = '';
+        res.on('data', (chunk) => (data += chunk));
+        res.on('end', () => {
+          if (res.statusCode >= 200 && res.statusCode < 300) resolve();
+          else reject(new Error(`HTTP ${res.statusCode}: ${data.slice(0, 200)}`));
+        });
+      }
+    );
+    req.on('timeout', () => req.destroy(new Error('timeout')));
+    req.on('error', reject);
+    req.end(payload);
+  });
+}
+
+/**
+ * Publish the scan result to the operator.  Returns true when the result was
+ * stored; false when the result channel is not configured or failed, in which
+ * case the operator falls back to parsing the pod logs.
+ */
+async function publishScanResult(result) {
+  if (!CONFIG.resultConfigMap || !CONFIG.podNamespace) return false;
+
+  const encoded = encodeScanResult(result);
+  const body = { binaryData: { [RESULT_KEY]: encoded.toString('base64') } };
+
+  for (let attempt = 1; attempt <= 3; attempt++) {
+    try {
+      await patchConfigMap(CONFIG.podNamespace, CONFIG.resultConfigMap, body);
+      logger.info('Résultat du scan publié', {
+        configmap: CONFIG.resultConfigMap,
+        bytes: encoded.length,
+      });
+      return true;
+    } catch (err) {
+      logger.warn('Échec de publication du résultat', {
+        configmap: CONFIG.resultConfigMap,
+        attempt,
+        error: err.message,
+      });
+      await new Promise((r) => setTimeout(r, attempt * 2000));
+    }
+  }
+  return false;
+}
+
+module.exports = {
+  RESULT_API_VERSION,
+  RESULT_KEY,
+  buildScanResult,
+  encodeScanResult,
+  publishScanResult,
+};
@@ -41,8 +41,16 @@ function getStats() {
 // Exclusion filter
 // =============================================================================
 
-function shouldExclude(filePath) {
-  return CONFIG.excludePatterns.some((re) => re.test(filePath));
+/**
+ * The patterns match below the scanned root, so that roots located under an
+ * excluded directory (e.g. container filesystems under /host/run) are scanned.
+ *
+ * @param {string} filePath
+ * @param {string} [root] — the scanned path the file was found under
+ */
+function shouldExclude(filePath, root) {
+  const relative = root ? path.join('/', path.relative(root, filePath)) : filePath;
+  return CONFIG.excludePatterns.some((re) => re.test(relative));
 }
 
 // =============================================================================
@@ -53,9 +61,10 @@ function shouldExclude(filePath) {
  * @param {import('clamscan')} clamscan
  * @param {string}             filePath
  * @param {string}             effectiveStrategy
+ * @param {string}             [root]
  */
-async function scanFile(clamscan, filePath, effectiveStrategy) {
-  if (shouldExclude(filePath)) {
+async function scanFile(clamscan, filePath, effectiveStrategy, root) {
+  if (shouldExclude(filePath, root)) {
     stats.filesSkipped++;
     return { skipped: true, reason: 'excluded' };
   }
@@ -113,7 +122,7 @@ async function scanFile(clamscan, filePath, effectiveStrategy) {
         virus_names: viruses,
         file_size: fileStats.size,
       });
-      return { infected: true, file, viruses };
+      return { infected: true, file, viruses, size: fileStats.size };
     }
 
     return { infected: false, file };
@@ -131,10 +140,11 @@ async function scanFile(clamscan, filePath, effectiveStrategy) {
 /**
  * @param {import('clamscan')} clamscan
  * @param {string}             dirPath
- * @param {object}             results         — { infected: [], errors: [] }
+ * @param {object}             results         — { infected: [], errors: [], skipped: [] }
  * @param {string}             effectiveStrategy
+ * @param {string}             [root]          — the configured scan path, defaults to dirPath
  */
-async function scanDirectory(clamscan, dirPath, results, effectiveStrategy) {
+async function scanDirectory(clamscan, dirPath, results, effectiveStrategy, root = dirPath) {
   let entries;
   try {
     entries = await fs.readdir(dirPath, { withFileTypes: true });
@@ -148,7 +158,7 @@ async function scanDirectory(clamscan, dirPath, results, effectiveStrategy) {
 
   for (const entry of entries) {
     const fullPath = path.join(dirPath, entry.name);
-    if (shouldExclude(fullPath)) continue;
+    if (shouldExclude(fullPath, root)) continue;
     if (entry.isDirectory()) dirs.push(fullPath);
     else if (entry.isFile()) files.push(fullPath);
   }
@@ -157,16 +167,19 @@ async function scanDirectory(clamscan, dirPath, results, effectiveStrategy) {
   for (let i = 0; i < files.length; i += CONFIG.maxConcurrent) {
     const batch = files.slice(i, i + CONFIG.maxConcurrent);
     const batchResults = await Promise.all(
-      batch.map((f) => scanFile(clamscan, f, effectiveStrategy))
+      batch.map((f) => scanFile(clamscan, f, effectiveStrategy, root))
     );
 
-    for (const result of batchResults) {
+    batchResults.forEach((result, idx) => {
       if (result.infected) {
-        results.infected.push({ file: result.file, viruses: result.viruses });
+        results.infected.push({ file: result.file, viruses: result.viruses, size: result.size });
       } else if (result.error) {
         results.errors.push({ file: result.file, error: result.message });
+      } else if (result.skipped && !result.incremental) {
+        // Incremental skips are expected and only counted
+        results.skipped.push({ file: batch[idx], reason: result.reason });
       }
-    }
+    });
 
     // Progress logging every 500 files
     const incremental = getIncrementalStats();
@@ -184,8 +197,8 @@ async function scanDirectory(clamscan, dirPath, results, effectiveStrategy) {
 
   // Recurse into subdirectories
   for (const subDir of dirs) {
-    await scanDirectory(clamscan, subDir, results, effectiveStrategy);
+    await scanDirectory(clamscan, subDir, results, effectiveStrategy, root);
   }
 }
 
-module.exports = { scanDirectory, getStats };
+module.exports = { scanDirectory, getStats, shouldExclude };